@@ -0,0 +1,442 @@
+/*
+account contains AccountBook, which builds a typed AccountState for each account code
+from UpdateAccountValue, AccountSummary and AccountUpdateMulti.
+*/
+
+package ibapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AccountValueKey identifies a raw value of AccountState
+type AccountValueKey struct {
+	Tag      string
+	Currency string
+}
+
+// CashBalance is the ledger of a currency in AccountState, the currency "BASE" is the sum in base currency.
+// Values which TWS did not send are UNSETFLOAT.
+type CashBalance struct {
+	Currency          string
+	CashBalance       float64
+	TotalCashBalance  float64
+	AccruedCash       float64
+	ExchangeRate      float64
+	NetLiquidation    float64 // NetLiquidationByCurrency
+	StockMarketValue  float64
+	OptionMarketValue float64
+	FutureOptionValue float64
+	FuturesPNL        float64
+	RealizedPnL       float64
+	UnrealizedPnL     float64
+}
+
+func newCashBalance(currency string) *CashBalance {
+	return &CashBalance{
+		Currency:          currency,
+		CashBalance:       UNSETFLOAT,
+		TotalCashBalance:  UNSETFLOAT,
+		AccruedCash:       UNSETFLOAT,
+		ExchangeRate:      UNSETFLOAT,
+		NetLiquidation:    UNSETFLOAT,
+		StockMarketValue:  UNSETFLOAT,
+		OptionMarketValue: UNSETFLOAT,
+		FutureOptionValue: UNSETFLOAT,
+		FuturesPNL:        UNSETFLOAT,
+		RealizedPnL:       UNSETFLOAT,
+		UnrealizedPnL:     UNSETFLOAT,
+	}
+}
+
+// ledgerField returns the field of the ledger tag, or nil if tag is not a ledger tag
+func (cb *CashBalance) ledgerField(tag string) *float64 {
+	switch tag {
+	case "CashBalance":
+		return &cb.CashBalance
+	case "TotalCashBalance":
+		return &cb.TotalCashBalance
+	case "AccruedCash":
+		return &cb.AccruedCash
+	case "ExchangeRate":
+		return &cb.ExchangeRate
+	case "NetLiquidationByCurrency":
+		return &cb.NetLiquidation
+	case "StockMarketValue":
+		return &cb.StockMarketValue
+	case "OptionMarketValue":
+		return &cb.OptionMarketValue
+	case "FutureOptionValue":
+		return &cb.FutureOptionValue
+	case "FuturesPNL":
+		return &cb.FuturesPNL
+	case "RealizedPnL":
+		return &cb.RealizedPnL
+	case "UnrealizedPnL":
+		return &cb.UnrealizedPnL
+	}
+	return nil
+}
+
+// AccountState is the state of an account code, the typed values are in base currency.
+// Values which TWS did not send are UNSETFLOAT.
+type AccountState struct {
+	Name                    string // account code
+	BaseCurrency            string
+	NetLiquidation          float64
+	EquityWithLoanValue     float64
+	TotalCashValue          float64
+	BuyingPower             float64
+	AvailableFunds          float64
+	ExcessLiquidity         float64
+	Cushion                 float64
+	GrossPositionValue      float64
+	InitMarginReq           float64
+	MaintMarginReq          float64
+	FullInitMarginReq       float64
+	FullMaintMarginReq      float64
+	LookAheadInitMarginReq  float64
+	LookAheadMaintMarginReq float64
+	Leverage                float64
+	Cash                    map[string]*CashBalance    // keyed by currency
+	Values                  map[AccountValueKey]string // every raw value received
+	SnapshotComplete        bool                       // AccountDownloadEnd, AccountSummaryEnd or AccountUpdateMultiEnd received
+	LastUpdate              time.Time                  // local time of the last value received
+	ready                   chan struct{}              // closed once the first snapshot completes
+}
+
+func newAccountState(account string) *AccountState {
+	return &AccountState{
+		Name:                    account,
+		NetLiquidation:          UNSETFLOAT,
+		EquityWithLoanValue:     UNSETFLOAT,
+		TotalCashValue:          UNSETFLOAT,
+		BuyingPower:             UNSETFLOAT,
+		AvailableFunds:          UNSETFLOAT,
+		ExcessLiquidity:         UNSETFLOAT,
+		Cushion:                 UNSETFLOAT,
+		GrossPositionValue:      UNSETFLOAT,
+		InitMarginReq:           UNSETFLOAT,
+		MaintMarginReq:          UNSETFLOAT,
+		FullInitMarginReq:       UNSETFLOAT,
+		FullMaintMarginReq:      UNSETFLOAT,
+		LookAheadInitMarginReq:  UNSETFLOAT,
+		LookAheadMaintMarginReq: UNSETFLOAT,
+		Leverage:                UNSETFLOAT,
+		Cash:                    make(map[string]*CashBalance),
+		Values:                  make(map[AccountValueKey]string),
+		ready:                   make(chan struct{}),
+	}
+}
+
+func (a AccountState) String() string {
+	return fmt.Sprintf("AccountState<%s, NetLiquidation: %v %s, BuyingPower: %v, ExcessLiquidity: %v, Cushion: %v, InitMarginReq: %v, MaintMarginReq: %v, Currencies: %d, Complete: %t>",
+		a.Name, a.NetLiquidation, a.BaseCurrency, a.BuyingPower, a.ExcessLiquidity, a.Cushion, a.InitMarginReq, a.MaintMarginReq, len(a.Cash), a.SnapshotComplete)
+}
+
+// Value returns the raw value of tag in currency
+func (a *AccountState) Value(tag string, currency string) (string, bool) {
+	v, ok := a.Values[AccountValueKey{tag, currency}]
+	return v, ok
+}
+
+// accountField returns the typed field of the account tag, or nil if tag is not typed
+func (a *AccountState) accountField(tag string) *float64 {
+	switch tag {
+	case "NetLiquidation":
+		return &a.NetLiquidation
+	case "EquityWithLoanValue":
+		return &a.EquityWithLoanValue
+	case "TotalCashValue":
+		return &a.TotalCashValue
+	case "BuyingPower":
+		return &a.BuyingPower
+	case "AvailableFunds":
+		return &a.AvailableFunds
+	case "ExcessLiquidity":
+		return &a.ExcessLiquidity
+	case "Cushion":
+		return &a.Cushion
+	case "GrossPositionValue":
+		return &a.GrossPositionValue
+	case "InitMarginReq":
+		return &a.InitMarginReq
+	case "MaintMarginReq":
+		return &a.MaintMarginReq
+	case "FullInitMarginReq":
+		return &a.FullInitMarginReq
+	case "FullMaintMarginReq":
+		return &a.FullMaintMarginReq
+	case "LookAheadInitMarginReq":
+		return &a.LookAheadInitMarginReq
+	case "LookAheadMaintMarginReq":
+		return &a.LookAheadMaintMarginReq
+	case "Leverage", "Leverage-S":
+		return &a.Leverage
+	}
+	return nil
+}
+
+// set updates the raw value and the typed field of tag, it returns the previous raw value
+func (a *AccountState) set(tag string, value string, currency string) (string, bool) {
+	key := AccountValueKey{tag, currency}
+	prev, existed := a.Values[key]
+	a.Values[key] = value
+	a.LastUpdate = time.Now()
+
+	if currency != "" {
+		cb, ok := a.Cash[currency]
+		if !ok {
+			cb = newCashBalance(currency)
+		}
+		if field := cb.ledgerField(tag); field != nil {
+			*field = parseAccountValue(tag, value)
+			a.Cash[currency] = cb
+			return prev, existed
+		}
+	}
+
+	if field := a.accountField(tag); field != nil && currency != "BASE" {
+		*field = parseAccountValue(tag, value)
+		if tag == "NetLiquidation" && currency != "" {
+			a.BaseCurrency = currency
+		}
+	}
+
+	return prev, existed
+}
+
+func (a *AccountState) copy() AccountState {
+	c := *a
+	c.ready = nil
+	c.Cash = make(map[string]*CashBalance, len(a.Cash))
+	for currency, cb := range a.Cash {
+		cbCopy := *cb
+		c.Cash[currency] = &cbCopy
+	}
+	c.Values = make(map[AccountValueKey]string, len(a.Values))
+	for k, v := range a.Values {
+		c.Values[k] = v
+	}
+	return c
+}
+
+// parseAccountValue parse the value of a numeric tag, "" and unparsable become UNSETFLOAT
+func parseAccountValue(tag string, value string) float64 {
+	if value == "" {
+		return UNSETFLOAT
+	}
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		log.Warn("failed to parse account value", zap.String("tag", tag), zap.String("value", value), zap.Error(err))
+		return UNSETFLOAT
+	}
+	return f
+}
+
+// AccountChange is delivered to the subscribers of AccountBook when a value changes or a snapshot completes
+type AccountChange struct {
+	Account     string
+	Tag         string
+	Currency    string
+	Value       string
+	PrevValue   string
+	SnapshotEnd bool // Tag, Currency and the values are empty when SnapshotEnd
+}
+
+// AccountBook keeps an AccountState for each account code.
+/*
+It is an observer of IbClient, add it by IbClient.AddObserver, then feed it with any of
+	ReqAccountUpdates -> UpdateAccountValue, AccountDownloadEnd
+	ReqAccountSummary -> AccountSummary, AccountSummaryEnd
+	ReqAccountUpdatesMulti -> AccountUpdateMulti, AccountUpdateMultiEnd
+The updates of AccountUpdateMulti with a model code are ignored, since they don't belong to the account itself.
+The reqIDs pending their *End are forgotten on ConnectionClosed, as the reqIDs restart after reconnecting.
+All the methods are safe for concurrent use.
+*/
+type AccountBook struct {
+	mu          sync.RWMutex
+	accounts    map[string]*AccountState
+	reqAccounts map[int64]map[string]bool // accounts of incomplete snapshot seen by each reqID, completed by *End(reqID)
+	subscribers map[int]func(AccountChange)
+	subSeq      int
+}
+
+// NewAccountBook create an empty AccountBook
+func NewAccountBook() *AccountBook {
+	return &AccountBook{
+		accounts:    make(map[string]*AccountState),
+		reqAccounts: make(map[int64]map[string]bool),
+		subscribers: make(map[int]func(AccountChange)),
+	}
+}
+
+// Subscribe registers f to be called on every AccountChange, it is called in the decoder goroutine and must not block.
+// Call the returned func to unsubscribe.
+func (ab *AccountBook) Subscribe(f func(AccountChange)) (unsubscribe func()) {
+	ab.mu.Lock()
+	id := ab.subSeq
+	ab.subSeq++
+	ab.subscribers[id] = f
+	ab.mu.Unlock()
+
+	return func() {
+		ab.mu.Lock()
+		delete(ab.subscribers, id)
+		ab.mu.Unlock()
+	}
+}
+
+// Accounts returns the account codes in the book
+func (ab *AccountBook) Accounts() []string {
+	ab.mu.RLock()
+	defer ab.mu.RUnlock()
+
+	accounts := make([]string, 0, len(ab.accounts))
+	for account := range ab.accounts {
+		accounts = append(accounts, account)
+	}
+	return accounts
+}
+
+// Snapshot returns a copy of the AccountState, which is safe to read while the book is updating
+func (ab *AccountBook) Snapshot(account string) (AccountState, bool) {
+	ab.mu.RLock()
+	defer ab.mu.RUnlock()
+
+	a, ok := ab.accounts[account]
+	if !ok {
+		return AccountState{}, false
+	}
+	return a.copy(), true
+}
+
+// WaitSnapshot blocks until the first snapshot of account completes, or ctx is done
+func (ab *AccountBook) WaitSnapshot(ctx context.Context, account string) (AccountState, error) {
+	ab.mu.Lock()
+	ready := ab.account(account).ready
+	ab.mu.Unlock()
+
+	select {
+	case <-ready:
+		a, _ := ab.Snapshot(account)
+		return a, nil
+	case <-ctx.Done():
+		return AccountState{}, ctx.Err()
+	}
+}
+
+// account returns the AccountState and creates it if not existed, must be called with mu locked
+func (ab *AccountBook) account(account string) *AccountState {
+	a, ok := ab.accounts[account]
+	if !ok {
+		a = newAccountState(account)
+		ab.accounts[account] = a
+	}
+	return a
+}
+
+func (ab *AccountBook) update(reqID int64, account string, tag string, value string, currency string) {
+	ab.mu.Lock()
+	a := ab.account(account)
+	prev, existed := a.set(tag, value, currency)
+	if reqID != NO_VALID_ID && !a.SnapshotComplete {
+		if _, ok := ab.reqAccounts[reqID]; !ok {
+			ab.reqAccounts[reqID] = make(map[string]bool)
+		}
+		ab.reqAccounts[reqID][account] = true
+	}
+	subscribers := ab.subscriberList()
+	ab.mu.Unlock()
+
+	if existed && prev == value {
+		return
+	}
+
+	change := AccountChange{Account: account, Tag: tag, Currency: currency, Value: value, PrevValue: prev}
+	for _, f := range subscribers {
+		f(change)
+	}
+}
+
+func (ab *AccountBook) complete(accounts ...string) {
+	ab.mu.Lock()
+	for _, account := range accounts {
+		a := ab.account(account)
+		if !a.SnapshotComplete {
+			a.SnapshotComplete = true
+			close(a.ready)
+		}
+	}
+	subscribers := ab.subscriberList()
+	ab.mu.Unlock()
+
+	for _, account := range accounts {
+		change := AccountChange{Account: account, SnapshotEnd: true}
+		for _, f := range subscribers {
+			f(change)
+		}
+	}
+}
+
+func (ab *AccountBook) completeReq(reqID int64) {
+	ab.mu.Lock()
+	accounts := make([]string, 0, len(ab.reqAccounts[reqID]))
+	for account := range ab.reqAccounts[reqID] {
+		accounts = append(accounts, account)
+	}
+	delete(ab.reqAccounts, reqID)
+	ab.mu.Unlock()
+
+	ab.complete(accounts...)
+}
+
+// subscriberList must be called with mu locked
+func (ab *AccountBook) subscriberList() []func(AccountChange) {
+	subscribers := make([]func(AccountChange), 0, len(ab.subscribers))
+	for _, f := range ab.subscribers {
+		subscribers = append(subscribers, f)
+	}
+	return subscribers
+}
+
+func (ab *AccountBook) ConnectionClosed() {
+	ab.mu.Lock()
+	ab.reqAccounts = make(map[int64]map[string]bool)
+	ab.mu.Unlock()
+}
+
+func (ab *AccountBook) UpdateAccountValue(tag string, val string, currency string, accName string) {
+	ab.update(NO_VALID_ID, accName, tag, val, currency)
+}
+
+func (ab *AccountBook) AccountDownloadEnd(accName string) {
+	ab.complete(accName)
+}
+
+func (ab *AccountBook) AccountSummary(reqID int64, account string, tag string, value string, currency string) {
+	ab.update(reqID, account, tag, value, currency)
+}
+
+func (ab *AccountBook) AccountSummaryEnd(reqID int64) {
+	ab.completeReq(reqID)
+}
+
+func (ab *AccountBook) AccountUpdateMulti(reqID int64, account string, modelCode string, tag string, value string, currency string) {
+	if modelCode != "" {
+		return
+	}
+	ab.update(reqID, account, tag, value, currency)
+}
+
+func (ab *AccountBook) AccountUpdateMultiEnd(reqID int64) {
+	ab.completeReq(reqID)
+}
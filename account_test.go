@@ -0,0 +1,104 @@
+package ibapi
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAccountBook(t *testing.T) {
+	ic := NewIbClient(new(Wrapper))
+	book := NewAccountBook()
+	ic.AddObserver(book)
+
+	var mu sync.Mutex
+	var changes []AccountChange
+	unsubscribe := book.Subscribe(func(c AccountChange) {
+		mu.Lock()
+		changes = append(changes, c)
+		mu.Unlock()
+	})
+
+	go func() {
+		ic.wrapper.UpdateAccountValue("NetLiquidation", "100000.5", "USD", "DU001")
+		ic.wrapper.UpdateAccountValue("Cushion", "0.85", "", "DU001")
+		ic.wrapper.UpdateAccountValue("CashBalance", "5000", "HKD", "DU001")
+		ic.wrapper.UpdateAccountValue("CashBalance", "640", "BASE", "DU001")
+		ic.wrapper.UpdateAccountValue("ExchangeRate", "0.128", "HKD", "DU001")
+		ic.wrapper.UpdateAccountValue("Cushion", "0.85", "", "DU001")
+		ic.wrapper.AccountDownloadEnd("DU001")
+
+		ic.wrapper.AccountSummary(1, "DU002", "BuyingPower", "4000", "EUR")
+		ic.wrapper.AccountSummary(1, "DU002", "InitMarginReq", "oops", "EUR")
+		ic.wrapper.AccountSummaryEnd(1)
+
+		ic.wrapper.AccountUpdateMulti(2, "DU002", "MODEL", "BuyingPower", "1", "EUR")
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	a, err := book.WaitSnapshot(ctx, "DU001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.NetLiquidation != 100000.5 || a.BaseCurrency != "USD" || a.Cushion != 0.85 || a.BuyingPower != UNSETFLOAT {
+		t.Errorf("unexpected account: %s", a)
+	}
+	if hkd := a.Cash["HKD"]; hkd == nil || hkd.CashBalance != 5000 || hkd.ExchangeRate != 0.128 {
+		t.Errorf("unexpected HKD ledger: %+v", hkd)
+	}
+	if base := a.Cash["BASE"]; base == nil || base.CashBalance != 640 {
+		t.Errorf("unexpected BASE ledger: %+v", base)
+	}
+
+	b, err := book.WaitSnapshot(ctx, "DU002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.BuyingPower != 4000 || b.InitMarginReq != UNSETFLOAT {
+		t.Errorf("unexpected account: %s", b)
+	}
+	if v, _ := b.Value("InitMarginReq", "EUR"); v != "oops" {
+		t.Errorf("raw value is not kept: %q", v)
+	}
+
+	unsubscribe()
+	mu.Lock()
+	defer mu.Unlock()
+	// 5 changes and 1 SnapshotEnd of DU001, the duplicated Cushion is not a change
+	if len(changes) < 6 || changes[4].Tag != "ExchangeRate" || !changes[5].SnapshotEnd {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+}
+
+func TestAccountBookReqAccounts(t *testing.T) {
+	ic := NewIbClient(new(Wrapper))
+	book := NewAccountBook()
+	ic.AddObserver(book)
+
+	ic.wrapper.AccountSummary(1, "DU001", "BuyingPower", "4000", "USD")
+	ic.wrapper.AccountSummaryEnd(1)
+	// the updates after the snapshot are not pending any more
+	ic.wrapper.AccountSummary(1, "DU001", "BuyingPower", "4100", "USD")
+	// the request canceled before its end is forgotten on disconnection
+	ic.wrapper.AccountUpdateMulti(2, "DU002", "", "BuyingPower", "1", "EUR")
+	ic.wrapper.ConnectionClosed()
+
+	// the reqID reused after reconnecting only completes its own accounts
+	ic.wrapper.AccountSummary(2, "DU003", "BuyingPower", "1", "EUR")
+	ic.wrapper.AccountSummaryEnd(2)
+	if a, _ := book.Snapshot("DU002"); a.SnapshotComplete {
+		t.Error("DU002 should not be completed by the reqID of another request")
+	}
+	if a, _ := book.Snapshot("DU003"); !a.SnapshotComplete {
+		t.Error("DU003 should be completed")
+	}
+
+	book.mu.RLock()
+	defer book.mu.RUnlock()
+	if len(book.reqAccounts) != 0 {
+		t.Errorf("the completed reqIDs should be forgotten: %v", book.reqAccounts)
+	}
+}
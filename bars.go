@@ -0,0 +1,215 @@
+/*
+bars contains the time handling of BarData, and the columnar representation of the bars and ticks for analysis.
+*/
+
+package ibapi
+
+import (
+	"time"
+)
+
+// IsDaily reports whether the bar is a daily or longer bar, whose Date is yyyymmdd without the time
+func (b BarData) IsDaily() bool {
+	return len(b.Date) == 8
+}
+
+// TimeIn returns the time of the bar, the Date without time zone is regarded in loc.
+/*
+The daily bars are regarded as the day at 00:00 in loc, which should be the time zone of the contract,
+the intraday bars without time zone are in the time zone of TWS login.
+*/
+func (b BarData) TimeIn(loc *time.Location) (time.Time, error) {
+	return ParseIBTime(b.Date, loc)
+}
+
+// BarTime decodes the Date of BarData.
+/*
+The daily bars are decoded as the day at 00:00 UTC, since it is a date of the exchange without time zone,
+use Time.Year/Month/Day, or BarData.TimeIn with the time zone of the contract.
+The intraday bars are decoded in loc, the time zone of TWS login, unless the time zone is in the date.
+IbClient decodes BarData.Time in IbClient.Location, loc is time.Local if nil.
+*/
+func BarTime(date string, loc *time.Location) (time.Time, error) {
+	if len(date) == 8 {
+		return ParseIBTime(date, time.UTC)
+	}
+	return ParseIBTime(date, loc)
+}
+
+// BarColumns is the columnar representation of []BarData
+type BarColumns struct {
+	Date     []string
+	Time     []time.Time
+	Open     []float64
+	High     []float64
+	Low      []float64
+	Close    []float64
+	Volume   []Decimal
+	Average  []float64
+	BarCount []int64
+}
+
+// NewBarColumns converts the bars to columns
+func NewBarColumns(bars []BarData) *BarColumns {
+	n := len(bars)
+	bc := &BarColumns{
+		Date:     make([]string, 0, n),
+		Time:     make([]time.Time, 0, n),
+		Open:     make([]float64, 0, n),
+		High:     make([]float64, 0, n),
+		Low:      make([]float64, 0, n),
+		Close:    make([]float64, 0, n),
+		Volume:   make([]Decimal, 0, n),
+		Average:  make([]float64, 0, n),
+		BarCount: make([]int64, 0, n),
+	}
+	for _, bar := range bars {
+		bc.Append(bar)
+	}
+	return bc
+}
+
+// Len returns the number of the bars
+func (bc *BarColumns) Len() int {
+	return len(bc.Date)
+}
+
+// Append appends the bar to the columns
+func (bc *BarColumns) Append(bar BarData) {
+	bc.Date = append(bc.Date, bar.Date)
+	bc.Time = append(bc.Time, bar.Time)
+	bc.Open = append(bc.Open, bar.Open)
+	bc.High = append(bc.High, bar.High)
+	bc.Low = append(bc.Low, bar.Low)
+	bc.Close = append(bc.Close, bar.Close)
+	bc.Volume = append(bc.Volume, bar.Volume)
+	bc.Average = append(bc.Average, bar.Average)
+	bc.BarCount = append(bc.BarCount, bar.BarCount)
+}
+
+// Bar returns the i-th bar
+func (bc *BarColumns) Bar(i int) BarData {
+	return BarData{
+		Date:     bc.Date[i],
+		Time:     bc.Time[i],
+		Open:     bc.Open[i],
+		High:     bc.High[i],
+		Low:      bc.Low[i],
+		Close:    bc.Close[i],
+		Volume:   bc.Volume[i],
+		Average:  bc.Average[i],
+		BarCount: bc.BarCount[i],
+	}
+}
+
+// Bars converts the columns back to the bars
+func (bc *BarColumns) Bars() []BarData {
+	bars := make([]BarData, bc.Len())
+	for i := range bars {
+		bars[i] = bc.Bar(i)
+	}
+	return bars
+}
+
+// TickColumns is the columnar representation of []HistoricalTick or []HistoricalTickLast
+type TickColumns struct {
+	Time  []time.Time
+	Price []float64
+	Size  []Decimal
+}
+
+// NewTickColumns converts the MIDPOINT ticks to columns
+func NewTickColumns(ticks []HistoricalTick) *TickColumns {
+	tc := &TickColumns{
+		Time:  make([]time.Time, 0, len(ticks)),
+		Price: make([]float64, 0, len(ticks)),
+		Size:  make([]Decimal, 0, len(ticks)),
+	}
+	for _, tick := range ticks {
+		tc.Append(tick.Timestamp(), tick.Price, tick.Size)
+	}
+	return tc
+}
+
+// NewTickLastColumns converts the TRADES ticks to columns, the attributes, exchanges and conditions are dropped
+func NewTickLastColumns(ticks []HistoricalTickLast) *TickColumns {
+	tc := &TickColumns{
+		Time:  make([]time.Time, 0, len(ticks)),
+		Price: make([]float64, 0, len(ticks)),
+		Size:  make([]Decimal, 0, len(ticks)),
+	}
+	for _, tick := range ticks {
+		tc.Append(tick.Timestamp(), tick.Price, tick.Size)
+	}
+	return tc
+}
+
+// Len returns the number of the ticks
+func (tc *TickColumns) Len() int {
+	return len(tc.Time)
+}
+
+// Append appends a tick to the columns
+func (tc *TickColumns) Append(t time.Time, price float64, size Decimal) {
+	tc.Time = append(tc.Time, t)
+	tc.Price = append(tc.Price, price)
+	tc.Size = append(tc.Size, size)
+}
+
+// Ticks converts the columns back to the ticks
+func (tc *TickColumns) Ticks() []HistoricalTick {
+	ticks := make([]HistoricalTick, tc.Len())
+	for i := range ticks {
+		ticks[i] = HistoricalTick{Time: tc.Time[i].Unix(), Price: tc.Price[i], Size: tc.Size[i]}
+	}
+	return ticks
+}
+
+// BidAskColumns is the columnar representation of []HistoricalTickBidAsk
+type BidAskColumns struct {
+	Time     []time.Time
+	PriceBid []float64
+	PriceAsk []float64
+	SizeBid  []Decimal
+	SizeAsk  []Decimal
+}
+
+// NewBidAskColumns converts the BID_ASK ticks to columns, the attributes are dropped
+func NewBidAskColumns(ticks []HistoricalTickBidAsk) *BidAskColumns {
+	n := len(ticks)
+	bac := &BidAskColumns{
+		Time:     make([]time.Time, 0, n),
+		PriceBid: make([]float64, 0, n),
+		PriceAsk: make([]float64, 0, n),
+		SizeBid:  make([]Decimal, 0, n),
+		SizeAsk:  make([]Decimal, 0, n),
+	}
+	for _, tick := range ticks {
+		bac.Time = append(bac.Time, tick.Timestamp())
+		bac.PriceBid = append(bac.PriceBid, tick.PriceBid)
+		bac.PriceAsk = append(bac.PriceAsk, tick.PriceAsk)
+		bac.SizeBid = append(bac.SizeBid, tick.SizeBid)
+		bac.SizeAsk = append(bac.SizeAsk, tick.SizeAsk)
+	}
+	return bac
+}
+
+// Len returns the number of the ticks
+func (bac *BidAskColumns) Len() int {
+	return len(bac.Time)
+}
+
+// Ticks converts the columns back to the ticks
+func (bac *BidAskColumns) Ticks() []HistoricalTickBidAsk {
+	ticks := make([]HistoricalTickBidAsk, bac.Len())
+	for i := range ticks {
+		ticks[i] = HistoricalTickBidAsk{
+			Time:     bac.Time[i].Unix(),
+			PriceBid: bac.PriceBid[i],
+			PriceAsk: bac.PriceAsk[i],
+			SizeBid:  bac.SizeBid[i],
+			SizeAsk:  bac.SizeAsk[i],
+		}
+	}
+	return ticks
+}
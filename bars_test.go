@@ -0,0 +1,87 @@
+package ibapi
+
+import (
+	"testing"
+	"time"
+)
+
+type barWrapper struct {
+	Wrapper
+	bar *BarData
+}
+
+func (w *barWrapper) HistoricalDataUpdate(reqID int64, bar *BarData) {
+	w.bar = bar
+}
+
+func TestBarTime(t *testing.T) {
+	hk, _ := LoadTWSLocation("HKT")
+	w := &barWrapper{}
+	d := &ibDecoder{wrapper: w}
+	d.setVersion(151)
+	d.setLocation(connTimeLocation("20200526 09:00:00 HKT"))
+	d.setmsgID2process()
+
+	d.interpret(makeMsgBytes(mHISTORICAL_DATA_UPDATE, 2, 209, "20200526  16:20:00", 23403, 23404, 23406, 23400, 23403.4, 283)[4:])
+	if w.bar == nil {
+		t.Fatal("bar is not decoded")
+	}
+	if want := time.Date(2020, 5, 26, 16, 20, 0, 0, hk); !w.bar.Time.Equal(want) || w.bar.IsDaily() {
+		t.Errorf("unexpected intraday bar time %s, want %s", w.bar.Time, want)
+	}
+
+	daily := BarData{Date: "20200526"}
+	if !daily.IsDaily() {
+		t.Error("bar should be daily")
+	}
+	if tm, err := BarTime(daily.Date, hk); err != nil || !tm.Equal(time.Date(2020, 5, 26, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected daily bar time %s, %v", tm, err)
+	}
+	if tm, err := daily.TimeIn(hk); err != nil || !tm.Equal(time.Date(2020, 5, 26, 0, 0, 0, 0, hk)) {
+		t.Errorf("unexpected daily bar time in HKT %s, %v", tm, err)
+	}
+	if tm, err := BarTime("1590510000", nil); err != nil || tm.Unix() != 1590510000 {
+		t.Errorf("unexpected epoch bar time %s, %v", tm, err)
+	}
+	if loc := connTimeLocation("20200526 09:00:00"); loc != time.Local {
+		t.Errorf("the login time zone should be time.Local if missing, got %s", loc)
+	}
+}
+
+func TestColumns(t *testing.T) {
+	bars := []BarData{
+		{Date: "20200526", Time: time.Date(2020, 5, 26, 0, 0, 0, 0, time.UTC), Open: 1, High: 3, Low: 0.5, Close: 2, Volume: DecimalFromInt(100), Average: 1.5, BarCount: 10},
+		{Date: "20200527", Time: time.Date(2020, 5, 27, 0, 0, 0, 0, time.UTC), Open: 2, High: 4, Low: 1.5, Close: 3, Volume: DecimalFromInt(200), Average: 2.5, BarCount: 20},
+	}
+	bc := NewBarColumns(bars)
+	if bc.Len() != 2 || bc.Close[1] != 3 || bc.BarCount[0] != 10 {
+		t.Fatalf("unexpected columns: %+v", bc)
+	}
+	for i, bar := range bc.Bars() {
+		if bar != bars[i] {
+			t.Errorf("bar %d is not round-tripped: %s", i, bar)
+		}
+	}
+
+	ticks := []HistoricalTick{{Time: 1590510000, Price: 1.5, Size: DecimalFromInt(3)}, {Time: 1590510001, Price: 1.6, Size: DecimalFromInt(4)}}
+	tc := NewTickColumns(ticks)
+	if tc.Len() != 2 || tc.Time[1].Unix() != 1590510001 {
+		t.Fatalf("unexpected tick columns: %+v", tc)
+	}
+	for i, tick := range tc.Ticks() {
+		if tick != ticks[i] {
+			t.Errorf("tick %d is not round-tripped: %s", i, tick)
+		}
+	}
+
+	bidAsks := []HistoricalTickBidAsk{{Time: 1590510000, PriceBid: 1.4, PriceAsk: 1.6, SizeBid: DecimalFromInt(1), SizeAsk: DecimalFromInt(2)}}
+	for i, tick := range NewBidAskColumns(bidAsks).Ticks() {
+		if tick != bidAsks[i] {
+			t.Errorf("bid ask tick %d is not round-tripped: %s", i, tick)
+		}
+	}
+
+	if tc := NewTickLastColumns([]HistoricalTickLast{{Time: 1590510000, Price: 2, Size: DecimalFromInt(5), Exchange: "NYSE"}}); tc.Price[0] != 2 || tc.Size[0] != DecimalFromInt(5) {
+		t.Errorf("unexpected last tick columns: %+v", tc)
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
-	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -22,7 +21,7 @@ const (
 	// RequestInternal is the internal microseconds between requests.
 	RequestInternal = 2
 	// MaxClientVersion is the max client version that this implement could support.
-	MaxClientVersion = 148
+	MaxClientVersion = MAX_CLIENT_VER
 )
 
 // IbClient is the key component which is used to send request to TWS ro Gateway , such subscribe market data or place order
@@ -34,9 +33,14 @@ type IbClient struct {
 	scanner          *bufio.Scanner
 	writer           *bufio.Writer
 	wrapper          IbWrapper
+	dispatcher       *dispatcher
 	decoder          ibDecoder
 	connectOptions   string
 	reqIDSeq         int64
+	pendingReqs      int64         // the requests sent to reqChan but not written yet
+	closing          int32         // set by Shutdown to stop accepting new requests
+	flushed          chan struct{} // signaled when the pending requests are done while closing
+	failedReqs       reqQueue      // the requests failed to be written to TWS
 	reqChan          chan []byte
 	errChan          chan error
 	msgChan          chan []byte
@@ -49,7 +53,16 @@ type IbClient struct {
 	extraAuth        bool
 	wg               sync.WaitGroup
 	ctx              context.Context
-	err              error
+	errMu            sync.Mutex
+	err              error // the error stopping the receiver, see setErr
+	subscriptions    subscriptionTable
+	orders           orderHold
+
+	connStateMu   sync.Mutex
+	connStateSubs map[int]func(ConnStateChange)
+	connStateSeq  int
+
+	decodeErrorHandler func(*DecodeError)
 }
 
 // NewIbClient create IbClient with wrapper
@@ -69,13 +82,15 @@ CONNECTED
 REDIRECT
 */
 func (ic *IbClient) ConnState() int {
-	return ic.conn.state
+	return ic.conn.getState()
 }
 
 func (ic *IbClient) setConnState(connState int) {
-	preState := ic.conn.state
-	ic.conn.state = connState
+	preState := ic.conn.setState(connState)
 	log.Debug("change connection state", zap.Int("previous", preState), zap.Int("current", connState))
+	if preState != connState {
+		ic.notifyConnState(ConnStateChange{Prev: preState, Curr: connState, Time: time.Now()})
+	}
 }
 
 // GetReqID before request data or place order
@@ -85,11 +100,54 @@ func (ic *IbClient) GetReqID() int64 {
 
 // SetWrapper setup the Wrapper
 func (ic *IbClient) SetWrapper(wrapper IbWrapper) {
-	ic.wrapper = wrapper
+	if ic.dispatcher == nil {
+		ic.dispatcher = newDispatcher(wrapper)
+	} else {
+		ic.dispatcher.IbWrapper = wrapper
+	}
+	ic.wrapper = ic.dispatcher
 	log.Debug("set wrapper", zap.Reflect("wrapper", wrapper))
 	ic.decoder = ibDecoder{wrapper: ic.wrapper}
 }
 
+// SetDecodeErrorHandler sets f to receive the malformed msgs from TWS, which are skipped by the decoder.
+/*
+f is called in the decoder goroutine, so it must not block. Set it before Connect.
+If no handler is set, the malformed msg is reported by Error of the wrapper with BAD_MESSAGE.
+*/
+func (ic *IbClient) SetDecodeErrorHandler(f func(*DecodeError)) {
+	ic.decodeErrorHandler = f
+}
+
+// handleDecodeError reports the error returned by the decoder
+func (ic *IbClient) handleDecodeError(err error) {
+	de, ok := err.(*DecodeError)
+	if !ok {
+		de = &DecodeError{MsgID: NO_VALID_ID, Err: err}
+	}
+
+	log.Error("failed to decode msg", zap.Int64("msgID", de.MsgID), zap.Binary("msgBytes", de.MsgBytes), zap.Error(de.Err))
+	if ic.decodeErrorHandler != nil {
+		ic.decodeErrorHandler(de)
+		return
+	}
+	ic.wrapper.Error(NO_VALID_ID, BAD_MESSAGE.code, BAD_MESSAGE.msg+": "+de.Error())
+}
+
+// AddObserver adds o to receive the callbacks before they are delivered to the wrapper.
+/*
+o could implement any of the observable callbacks of IbWrapper listed in dispatcher.go, such as AccountBook,
+and they are called in the decoder goroutine, so o must not block.
+*/
+func (ic *IbClient) AddObserver(o interface{}) {
+	ic.dispatcher.addObserver(o)
+}
+
+// RemoveObserver removes the observer added by AddObserver
+func (ic *IbClient) RemoveObserver(o interface{}) {
+	ic.dispatcher.removeObserver(o)
+}
+
 // SetContext setup the Connection Context
 func (ic *IbClient) SetContext(ctx context.Context) {
 	ic.ctx = ctx
@@ -121,20 +179,32 @@ func (ic *IbClient) Connect(host string, port int, clientID int64) error {
 1.send terminatedSignal to receiver, decoder and requester
 2.disconnect the connection
 3.wait the 3 goroutine
-4.callback  ConnectionClosed
+4.fail the pending requests of the helpers with NOT_CONNECTED, and callback  ConnectionClosed
 5.send the err to done chan
 6.reset the IbClient
 */
 func (ic *IbClient) Disconnect() error {
+	_, err := ic.disconnect()
+	return err
+}
+
+// disconnect is Disconnect, which also returns the requests left in reqChan after the goroutines end
+func (ic *IbClient) disconnect() ([][]byte, error) {
 	log.Debug("close terminatedSignal chan")
 	close(ic.terminatedSignal) // close make the term signal chan unblocked
 
 	if err := ic.conn.disconnect(); err != nil {
-		return err
+		return nil, err
 	}
 
 	ic.wg.Wait()
 
+	var unsent [][]byte
+	for len(ic.reqChan) > 0 {
+		unsent = append(unsent, <-ic.reqChan)
+	}
+	ic.dispatcher.failAll(NOT_CONNECTED.code, NOT_CONNECTED.msg)
+
 	// should not reconnect IbClient in ConnectionClosed
 	// because reset would be called right after ConnectionClosed
 	defer func() {
@@ -146,12 +216,12 @@ func (ic *IbClient) Disconnect() error {
 	defer ic.wrapper.ConnectionClosed()
 	defer log.Info("Disconnected!")
 
-	return ic.err
+	return unsent, ic.getErr()
 }
 
 // IsConnected check if there is a connection to TWS or GateWay
 func (ic *IbClient) IsConnected() bool {
-	return ic.conn.state == CONNECTED
+	return ic.conn.getState() == CONNECTED
 }
 
 // send the clientId to TWS or Gateway
@@ -169,7 +239,11 @@ func (ic *IbClient) startAPI() error {
 		return err
 	}
 
-	return ic.writer.Flush()
+	if err := ic.writer.Flush(); err != nil {
+		return err
+	}
+	ic.conn.msgSent()
+	return nil
 }
 
 // HandShake with the TWS or GateWay to ensure the version,
@@ -212,13 +286,18 @@ func (ic *IbClient) HandShake() error {
 	}
 	// Init server info
 	msgBytes = ic.scanner.Bytes()
+	ic.conn.msgRecv()
 	serverInfo := splitMsgBytes(msgBytes)
+	if len(serverInfo) < 2 {
+		return BAD_MESSAGE
+	}
 	v, _ := strconv.Atoi(string(serverInfo[0]))
 	ic.serverVersion = Version(v)
 	ic.connTime = string(serverInfo[1])
 
 	// Init Decoder
 	ic.decoder.setVersion(ic.serverVersion)
+	ic.decoder.setLocation(ic.Location())
 	// ic.decoder.errChan = make(chan error, 100)
 	ic.decoder.setmsgID2process()
 
@@ -230,6 +309,7 @@ func (ic *IbClient) HandShake() error {
 		return err
 	}
 
+	ic.wg.Add(1)
 	go ic.goReceive() // receive the data, make sure client receives the nextValidID and manageAccount which help comfirm the client.
 	comfirmMsgIDs := []IN{mNEXT_VALID_ID, mMANAGED_ACCTS}
 
@@ -241,10 +321,14 @@ comfirmReadyLoop:
 	for {
 		select {
 		case m := <-ic.msgChan:
-			f := splitMsgBytes(m)
-			MsgID, _ := strconv.ParseInt(string(f[0]), 10, 64)
+			MsgID := NO_VALID_ID
+			if f := splitMsgBytes(m); len(f) > 0 {
+				MsgID, _ = strconv.ParseInt(string(f[0]), 10, 64)
+			}
 
-			ic.decoder.interpret(m)
+			if err := ic.decoder.interpret(m); err != nil {
+				ic.handleDecodeError(err)
+			}
 
 			// check and del the msg ID
 			for i, ID := range comfirmMsgIDs {
@@ -280,15 +364,36 @@ func (ic *IbClient) ServerVersion() Version {
 	return ic.serverVersion
 }
 
+// setErr records the error stopping the client, which is returned by Disconnect
+func (ic *IbClient) setErr(err error) {
+	ic.errMu.Lock()
+	ic.err = err
+	ic.errMu.Unlock()
+}
+
+func (ic *IbClient) getErr() error {
+	ic.errMu.Lock()
+	defer ic.errMu.Unlock()
+	return ic.err
+}
+
 // ConnectionTime is the time that connection is comfirmed
 func (ic *IbClient) ConnectionTime() string {
 	return ic.connTime
 }
 
+// Location is the time zone of TWS login parsed from ConnectionTime, time.Local if it is unknown
+func (ic *IbClient) Location() *time.Location {
+	return connTimeLocation(ic.connTime)
+}
+
 func (ic *IbClient) reset() {
 	log.Debug("reset ibClient")
 	ic.reqIDSeq = 0
-	ic.conn = &IbConnection{}
+	if ic.conn == nil {
+		ic.conn = &IbConnection{}
+	}
+	ic.conn.reset()
 	ic.host = ""
 	ic.port = -1
 	ic.extraAuth = false
@@ -302,34 +407,72 @@ func (ic *IbClient) reset() {
 	ic.scanner.Buffer(make([]byte, 4096), MAX_MSG_LEN)
 
 	ic.writer = bufio.NewWriter(ic.conn)
-	ic.reqChan = make(chan []byte, 10)
-	ic.errChan = make(chan error, 10)
-	ic.msgChan = make(chan []byte, 100)
+	// the chans are kept across the connections, so that they could be read from any goroutine, such as by Stats
+	if ic.reqChan == nil {
+		ic.reqChan = make(chan []byte, 10)
+		ic.errChan = make(chan error, 10)
+		ic.msgChan = make(chan []byte, 100)
+		ic.flushed = make(chan struct{}, 1)
+	}
+	ic.drainChans()
+	atomic.StoreInt64(&ic.pendingReqs, 0)
+	atomic.StoreInt32(&ic.closing, 0)
+	ic.failedReqs.take()
 	ic.terminatedSignal = make(chan int)
 	ic.wg = sync.WaitGroup{}
 	ic.connectOptions = ""
 	ic.setConnState(DISCONNECTED)
-	ic.err = nil
+	ic.setErr(nil)
+	ic.subscriptions.clear()
+	for _, o := range ic.orders.resume() {
+		ic.cancelHeldOrder(o.orderID)
+	}
 	if ic.ctx == nil {
 		ic.ctx = context.TODO()
 	}
 
 }
 
-// SetServerLogLevel setup the log level of server
-func (ic *IbClient) SetServerLogLevel(logLevel int64) {
-	// v := 1
-	const v = 1
-	fields := make([]interface{}, 0, 3)
-	fields = append(fields,
-		mSET_SERVER_LOGLEVEL,
-		v,
-		logLevel,
-	)
+// drainChans discards the requests, errors, msgs and signals left by the last connection
+func (ic *IbClient) drainChans() {
+	for {
+		select {
+		case <-ic.reqChan:
+		case <-ic.errChan:
+		case <-ic.msgChan:
+		case <-ic.flushed:
+		default:
+			return
+		}
+	}
+}
+
+// send sends the encoded request to TWS, or reports the error of encoding to the wrapper instead, it reports whether it is sent
+func (ic *IbClient) send(msg []byte, err error) bool {
+	if err != nil {
+		var reqErr *RequestError
+		if errors.As(err, &reqErr) {
+			ic.wrapper.Error(reqErr.ReqID, reqErr.Err.code, reqErr.Error())
+		} else {
+			ic.wrapper.Error(NO_VALID_ID, BAD_MESSAGE.code, BAD_MESSAGE.msg+": "+err.Error())
+		}
+		return false
+	}
 
-	msg := makeMsgBytes(fields...)
+	atomic.AddInt64(&ic.pendingReqs, 1)
+	if atomic.LoadInt32(&ic.closing) == 1 {
+		ic.reqDone()
+		ic.wrapper.Error(NO_VALID_ID, NOT_CONNECTED.code, NOT_CONNECTED.msg+": shutting down")
+		return false
+	}
 
 	ic.reqChan <- msg
+	return true
+}
+
+// SetServerLogLevel setup the log level of server
+func (ic *IbClient) SetServerLogLevel(logLevel int64) {
+	ic.send(EncodeSetServerLogLevel(ic.serverVersion, logLevel))
 }
 
 // ---------------req func ----------------------------------------------
@@ -361,104 +504,17 @@ Market Data
 	For internal use only.Use default value XYZ.
 */
 func (ic *IbClient) ReqMktData(reqID int64, contract *Contract, genericTickList string, snapshot bool, regulatorySnapshot bool, mktDataOptions []TagValue) {
-	switch {
-	case ic.serverVersion < mMIN_SERVER_VER_DELTA_NEUTRAL && contract.DeltaNeutralContract != nil:
-		ic.wrapper.Error(reqID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support delta-neutral orders.")
-		return
-	case ic.serverVersion < mMIN_SERVER_VER_REQ_MKT_DATA_CONID && contract.ContractID > 0:
-		ic.wrapper.Error(reqID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support conId parameter.")
-		return
-	case ic.serverVersion < mMIN_SERVER_VER_TRADING_CLASS && contract.TradingClass != "":
-		ic.wrapper.Error(reqID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support tradingClass parameter in reqMktData.")
-		return
-	}
-
-	// v := 11
-	const v = 11
-	fields := make([]interface{}, 0, 30)
-	fields = append(fields,
-		mREQ_MKT_DATA,
-		v,
-		reqID,
-	)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_REQ_MKT_DATA_CONID {
-		fields = append(fields, contract.ContractID)
-	}
-
-	fields = append(fields,
-		contract.Symbol,
-		contract.SecurityType,
-		contract.Expiry,
-		contract.Strike,
-		contract.Right,
-		contract.Multiplier,
-		contract.Exchange,
-		contract.PrimaryExchange,
-		contract.Currency,
-		contract.LocalSymbol)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
-		fields = append(fields, contract.TradingClass)
-	}
-
-	if contract.SecurityType == "BAG" {
-		comboLegsCount := len(contract.ComboLegs)
-		fields = append(fields, comboLegsCount)
-		for _, comboLeg := range contract.ComboLegs {
-			fields = append(fields,
-				comboLeg.ContractID,
-				comboLeg.Ratio,
-				comboLeg.Action,
-				comboLeg.Exchange)
-		}
-	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_DELTA_NEUTRAL {
-		if contract.DeltaNeutralContract != nil {
-			fields = append(fields,
-				true,
-				contract.DeltaNeutralContract.ContractID,
-				contract.DeltaNeutralContract.Delta,
-				contract.DeltaNeutralContract.Price)
-		} else {
-			fields = append(fields, false)
-		}
-	}
-
-	fields = append(fields,
-		genericTickList,
-		snapshot)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_REQ_SMART_COMPONENTS {
-		fields = append(fields, regulatorySnapshot)
+	if ic.send(EncodeReqMktData(ic.serverVersion, reqID, contract, genericTickList, snapshot, regulatorySnapshot, mktDataOptions)) && !snapshot && !regulatorySnapshot {
+		ic.subscriptions.add(reqID,
+			func() { ic.ReqMktData(reqID, contract, genericTickList, snapshot, regulatorySnapshot, mktDataOptions) },
+			func() { ic.CancelMktData(reqID) })
 	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_LINKING {
-		if len(mktDataOptions) > 0 {
-			log.Panic("not supported")
-		}
-		fields = append(fields, "")
-	}
-
-	msg := makeMsgBytes(fields...)
-	ic.reqChan <- msg
 }
 
 // CancelMktData cancels the market data
 func (ic *IbClient) CancelMktData(reqID int64) {
-	// v := 2
-	const v = 2
-	fields := make([]interface{}, 0, 3)
-	fields = append(fields,
-		mCANCEL_MKT_DATA,
-		v,
-		reqID,
-	)
-
-	msg := makeMsgBytes(fields...)
-
-	ic.reqChan <- msg
+	ic.subscriptions.remove(reqID)
+	ic.send(EncodeCancelMktData(ic.serverVersion, reqID))
 }
 
 // ReqMarketDataType changes the market data type.
@@ -478,43 +534,17 @@ market data.
 	4 -> delayed frozen market data
 */
 func (ic *IbClient) ReqMarketDataType(marketDataType int64) {
-	if ic.serverVersion < mMIN_SERVER_VER_REQ_MARKET_DATA_TYPE {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support market data type requests.")
-		return
-	}
-
-	// v := 1
-	const v = 1
-	fields := make([]interface{}, 0, 3)
-	fields = append(fields, mREQ_MARKET_DATA_TYPE, v, marketDataType)
-
-	msg := makeMsgBytes(fields...)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqMarketDataType(ic.serverVersion, marketDataType))
 }
 
 // ReqSmartComponents request the smartComponents.
 func (ic *IbClient) ReqSmartComponents(reqID int64, bboExchange string) {
-	if ic.serverVersion < mMIN_SERVER_VER_REQ_SMART_COMPONENTS {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support smart components request.")
-		return
-	}
-
-	msg := makeMsgBytes(mREQ_SMART_COMPONENTS, reqID, bboExchange)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqSmartComponents(ic.serverVersion, reqID, bboExchange))
 }
 
 // ReqMarketRule request the market rule.
 func (ic *IbClient) ReqMarketRule(marketRuleID int64) {
-	if ic.serverVersion < mMIN_SERVER_VER_MARKET_RULES {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+" It does not support market rule requests.")
-		return
-	}
-
-	msg := makeMsgBytes(mREQ_MARKET_RULE, marketRuleID)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqMarketRule(ic.serverVersion, marketRuleID))
 }
 
 // ReqTickByTickData request the tick-by-tick data.
@@ -523,52 +553,17 @@ Call this func to requst tick-by-tick data.Result will be delivered
 via wrapper.TickByTickAllLast() wrapper.TickByTickBidAsk() wrapper.TickByTickMidPoint()
 */
 func (ic *IbClient) ReqTickByTickData(reqID int64, contract *Contract, tickType string, numberOfTicks int64, ignoreSize bool) {
-	if ic.serverVersion < mMIN_SERVER_VER_TICK_BY_TICK {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+" It does not support tick-by-tick data requests.")
-		return
+	if ic.send(EncodeReqTickByTickData(ic.serverVersion, reqID, contract, tickType, numberOfTicks, ignoreSize)) {
+		ic.subscriptions.add(reqID,
+			func() { ic.ReqTickByTickData(reqID, contract, tickType, numberOfTicks, ignoreSize) },
+			func() { ic.CancelTickByTickData(reqID) })
 	}
-
-	if ic.serverVersion < mMIN_SERVER_VER_TICK_BY_TICK_IGNORE_SIZE {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+" It does not support ignoreSize and numberOfTicks parameters in tick-by-tick data requests.")
-		return
-	}
-
-	fields := make([]interface{}, 0, 16)
-	fields = append(fields, mREQ_TICK_BY_TICK_DATA,
-		reqID,
-		contract.ContractID,
-		contract.Symbol,
-		contract.SecurityType,
-		contract.Expiry,
-		contract.Strike,
-		contract.Right,
-		contract.Multiplier,
-		contract.Exchange,
-		contract.PrimaryExchange,
-		contract.Currency,
-		contract.LocalSymbol,
-		contract.TradingClass,
-		tickType)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_TICK_BY_TICK_IGNORE_SIZE {
-		fields = append(fields, numberOfTicks, ignoreSize)
-	}
-
-	msg := makeMsgBytes(fields...)
-
-	ic.reqChan <- msg
 }
 
 // CancelTickByTickData cancel the tick-by-tick data
 func (ic *IbClient) CancelTickByTickData(reqID int64) {
-	if ic.serverVersion < mMIN_SERVER_VER_TICK_BY_TICK {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+" It does not support tick-by-tick data requests.")
-		return
-	}
-
-	msg := makeMsgBytes(mCANCEL_TICK_BY_TICK_DATA, reqID)
-
-	ic.reqChan <- msg
+	ic.subscriptions.remove(reqID)
+	ic.send(EncodeCancelTickByTickData(ic.serverVersion, reqID))
 }
 
 /*
@@ -593,57 +588,7 @@ via wrapper.TickOptionComputation()
 	Price of the underlying.
 */
 func (ic *IbClient) CalculateImpliedVolatility(reqID int64, contract *Contract, optionPrice float64, underPrice float64, impVolOptions []TagValue) {
-	if ic.serverVersion < mMIN_SERVER_VER_REQ_CALC_IMPLIED_VOLAT {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support calculateImpliedVolatility req.")
-		return
-	}
-
-	if ic.serverVersion < mMIN_SERVER_VER_TRADING_CLASS && contract.TradingClass != "" {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support tradingClass parameter in calculateImpliedVolatility.")
-		return
-	}
-
-	// v := 3
-	const v = 3
-	fields := make([]interface{}, 0, 19)
-	fields = append(fields,
-		mREQ_CALC_IMPLIED_VOLAT,
-		v,
-		reqID,
-		contract.ContractID,
-		contract.Symbol,
-		contract.SecurityID,
-		contract.Expiry,
-		contract.Strike,
-		contract.Right,
-		contract.Multiplier,
-		contract.Exchange,
-		contract.PrimaryExchange,
-		contract.Currency,
-		contract.LocalSymbol)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
-		fields = append(fields, contract.TradingClass)
-	}
-
-	fields = append(fields, optionPrice, underPrice)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_LINKING {
-		var implVolOptBuffer bytes.Buffer
-		tagValuesCount := len(impVolOptions)
-		fields = append(fields, tagValuesCount)
-		for _, tv := range impVolOptions {
-			implVolOptBuffer.WriteString(tv.Tag)
-			implVolOptBuffer.WriteString("=")
-			implVolOptBuffer.WriteString(tv.Value)
-			implVolOptBuffer.WriteString(";")
-		}
-		fields = append(fields, implVolOptBuffer.Bytes())
-	}
-
-	msg := makeMsgBytes(fields...)
-
-	ic.reqChan <- msg
+	ic.send(EncodeCalculateImpliedVolatility(ic.serverVersion, reqID, contract, optionPrice, underPrice, impVolOptions))
 }
 
 //CalculateOptionPrice calculate the price of the option
@@ -662,73 +607,12 @@ via wrapper.TickOptionComputation()
 	Price of the underlying.
 */
 func (ic *IbClient) CalculateOptionPrice(reqID int64, contract *Contract, volatility float64, underPrice float64, optPrcOptions []TagValue) {
-
-	if ic.serverVersion < mMIN_SERVER_VER_REQ_CALC_IMPLIED_VOLAT {
-		ic.wrapper.Error(reqID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support calculateImpliedVolatility req.")
-		return
-	}
-
-	if ic.serverVersion < mMIN_SERVER_VER_TRADING_CLASS {
-		ic.wrapper.Error(reqID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support tradingClass parameter in calculateImpliedVolatility.")
-		return
-	}
-
-	// v := 3
-	const v = 3
-	fields := make([]interface{}, 0, 19)
-	fields = append(fields,
-		mREQ_CALC_OPTION_PRICE,
-		v,
-		reqID,
-		contract.ContractID,
-		contract.Symbol,
-		contract.SecurityID,
-		contract.Expiry,
-		contract.Strike,
-		contract.Right,
-		contract.Multiplier,
-		contract.Exchange,
-		contract.PrimaryExchange,
-		contract.Currency,
-		contract.LocalSymbol)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
-		fields = append(fields, contract.TradingClass)
-	}
-
-	fields = append(fields, volatility, underPrice)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_LINKING {
-		var optPrcOptBuffer bytes.Buffer
-		tagValuesCount := len(optPrcOptions)
-		fields = append(fields, tagValuesCount)
-		for _, tv := range optPrcOptions {
-			optPrcOptBuffer.WriteString(tv.Tag)
-			optPrcOptBuffer.WriteString("=")
-			optPrcOptBuffer.WriteString(tv.Value)
-			optPrcOptBuffer.WriteString(";")
-		}
-
-		fields = append(fields, optPrcOptBuffer.Bytes())
-	}
-
-	msg := makeMsgBytes(fields...)
-
-	ic.reqChan <- msg
+	ic.send(EncodeCalculateOptionPrice(ic.serverVersion, reqID, contract, volatility, underPrice, optPrcOptions))
 }
 
 // CancelCalculateOptionPrice cancels the calculation of option price
 func (ic *IbClient) CancelCalculateOptionPrice(reqID int64) {
-	if ic.serverVersion < mMIN_SERVER_VER_REQ_CALC_IMPLIED_VOLAT {
-		ic.wrapper.Error(reqID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support calculateImpliedVolatility req.")
-		return
-	}
-
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mCANCEL_CALC_OPTION_PRICE, v, reqID)
-
-	ic.reqChan <- msg
+	ic.send(EncodeCancelCalculateOptionPrice(ic.serverVersion, reqID))
 }
 
 // ExerciseOptions exercise the options.
@@ -754,45 +638,7 @@ call this func to exercise th options.
 	Values: 0 = no, 1 = yes.
 */
 func (ic *IbClient) ExerciseOptions(reqID int64, contract *Contract, exerciseAction int, exerciseQuantity int, account string, override int) {
-	if ic.serverVersion < mMIN_SERVER_VER_TRADING_CLASS && contract.TradingClass != "" {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support conId, multiplier, tradingClass parameter in exerciseOptions.")
-		return
-	}
-
-	// v := 2
-	const v = 2
-	fields := make([]interface{}, 0, 17)
-
-	fields = append(fields, mEXERCISE_OPTIONS, v, reqID)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
-		fields = append(fields, contract.ContractID)
-	}
-
-	fields = append(fields,
-		contract.Symbol,
-		contract.Expiry,
-		contract.Strike,
-		contract.Right,
-		contract.Multiplier,
-		contract.Exchange,
-		contract.Currency,
-		contract.LocalSymbol)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
-		fields = append(fields, contract.TradingClass)
-	}
-
-	fields = append(fields,
-		exerciseAction,
-		exerciseQuantity,
-		account,
-		override)
-
-	msg := makeMsgBytes(fields...)
-
-	ic.reqChan <- msg
-
+	ic.send(EncodeExerciseOptions(ic.serverVersion, reqID, contract, exerciseAction, exerciseQuantity, account, override))
 }
 
 /*
@@ -814,559 +660,52 @@ Call this function to place an order. The order status will be returned by the o
 	This structure contains the details of tradedhe order.
 */
 func (ic *IbClient) PlaceOrder(orderID int64, contract *Contract, order *Order) {
-	switch v := ic.serverVersion; {
-	case v < mMIN_SERVER_VER_DELTA_NEUTRAL && contract.DeltaNeutralContract != nil:
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support delta-neutral orders.")
-		return
-	case v < mMIN_SERVER_VER_SCALE_ORDERS2 && order.ScaleSubsLevelSize != UNSETINT:
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support Subsequent Level Size for Scale orders.")
-		return
-	case v < mMIN_SERVER_VER_ALGO_ORDERS && order.AlgoStrategy != "":
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support algo orders.")
-		return
-	case v < mMIN_SERVER_VER_NOT_HELD && order.NotHeld:
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support notHeld parameter.")
-		return
-	case v < mMIN_SERVER_VER_SEC_ID_TYPE && (contract.SecurityType != "" || contract.SecurityID != ""):
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support secIdType and secId parameters.")
-		return
-	case v < mMIN_SERVER_VER_PLACE_ORDER_CONID && contract.ContractID != UNSETINT && contract.ContractID > 0:
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support conId parameter.")
-		return
-	case v < mMIN_SERVER_VER_SSHORTX && order.ExemptCode != -1:
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support exemptCode parameter.")
-		return
-	case v < mMIN_SERVER_VER_SSHORTX:
-		for _, comboLeg := range contract.ComboLegs {
-			if comboLeg.ExemptCode != -1 {
-				ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support exemptCode parameter.")
-				return
-			}
-		}
-		fallthrough
-	case v < mMIN_SERVER_VER_HEDGE_ORDERS && order.HedgeType != "":
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support hedge orders.")
-		return
-	case v < mMIN_SERVER_VER_OPT_OUT_SMART_ROUTING && order.OptOutSmartRouting:
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support optOutSmartRouting parameter.")
-		return
-	case v < mMIN_SERVER_VER_DELTA_NEUTRAL_CONID:
-		if order.DeltaNeutralContractID > 0 || order.DeltaNeutralSettlingFirm != "" || order.DeltaNeutralClearingAccount != "" || order.DeltaNeutralClearingIntent != "" {
-			ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support deltaNeutral parameters: ConId, SettlingFirm, ClearingAccount, ClearingIntent.")
-			return
-		}
-		fallthrough
-	case v < mMIN_SERVER_VER_DELTA_NEUTRAL_OPEN_CLOSE:
-		if order.DeltaNeutralOpenClose != "" ||
-			order.DeltaNeutralShortSale ||
-			order.DeltaNeutralShortSaleSlot > 0 ||
-			order.DeltaNeutralDesignatedLocation != "" {
-			ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support deltaNeutral parameters: OpenClose, ShortSale, ShortSaleSlot, DesignatedLocation.")
-			return
-		}
-		fallthrough
-	case v < mMIN_SERVER_VER_SCALE_ORDERS3:
-		if (order.ScalePriceIncrement > 0 && order.ScalePriceIncrement != UNSETFLOAT) &&
-			(order.ScalePriceAdjustValue != UNSETFLOAT ||
-				order.ScalePriceAdjustInterval != UNSETINT ||
-				order.ScaleProfitOffset != UNSETFLOAT ||
-				order.ScaleAutoReset ||
-				order.ScaleInitPosition != UNSETINT ||
-				order.ScaleInitFillQty != UNSETINT ||
-				order.ScaleRandomPercent) {
-			ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+
-				"  It does not support Scale order parameters: PriceAdjustValue, PriceAdjustInterval, "+
-				"ProfitOffset, AutoReset, InitPosition, InitFillQty and RandomPercent.")
-			return
-		}
-		fallthrough
-	case v < mMIN_SERVER_VER_ORDER_COMBO_LEGS_PRICE && contract.SecurityType == "BAG":
-		for _, orderComboLeg := range order.OrderComboLegs {
-			if orderComboLeg.Price != UNSETFLOAT {
-				ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support per-leg prices for order combo legs.")
-				return
-			}
-
-		}
-		fallthrough
-	case v < mMIN_SERVER_VER_TRAILING_PERCENT && order.TrailingPercent != UNSETFLOAT:
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support trailing percent parameter.")
-		return
-	case v < mMIN_SERVER_VER_TRADING_CLASS && contract.TradingClass != "":
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support tradingClass parameter in placeOrder.")
-		return
-	case v < mMIN_SERVER_VER_SCALE_TABLE &&
-		(order.ScaleTable != "" ||
-			order.ActiveStartTime != "" ||
-			order.ActiveStopTime != ""):
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support scaleTable, activeStartTime and activeStopTime parameters.")
-		return
-	case v < mMIN_SERVER_VER_ALGO_ID && order.AlgoID != "":
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support algoId parameter.")
-		return
-	case v < mMIN_SERVER_VER_ORDER_SOLICITED && order.Solictied:
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support order solicited parameter.")
-		return
-	case v < mMIN_SERVER_VER_MODELS_SUPPORT && order.ModelCode != "":
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support model code parameter.")
-		return
-	case v < mMIN_SERVER_VER_EXT_OPERATOR && order.ExtOperator != "":
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support ext operator parameter")
-		return
-	case v < mMIN_SERVER_VER_SOFT_DOLLAR_TIER &&
-		(order.SoftDollarTier.Name != "" || order.SoftDollarTier.Value != ""):
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+" It does not support soft dollar tier")
-		return
-	case v < mMIN_SERVER_VER_CASH_QTY && order.CashQty != UNSETFLOAT:
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+" It does not support cash quantity parameter")
-		return
-	case v < mMIN_SERVER_VER_DECISION_MAKER &&
-		(order.Mifid2DecisionMaker != "" || order.Mifid2DecisionAlgo != ""):
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+" It does not support MIFID II decision maker parameters")
-		return
-	case v < mMIN_SERVER_VER_MIFID_EXECUTION &&
-		(order.Mifid2ExecutionTrader != "" || order.Mifid2ExecutionAlgo != ""):
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+" It does not support MIFID II execution parameters")
-		return
-	case v < mMIN_SERVER_VER_AUTO_PRICE_FOR_HEDGE && order.DontUseAutoPriceForHedge:
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+" It does not support dontUseAutoPriceForHedge parameter")
-		return
-	case v < mMIN_SERVER_VER_ORDER_CONTAINER && order.IsOmsContainer:
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+" It does not support oms container parameter")
-		return
-	case v < mMIN_SERVER_VER_PRICE_MGMT_ALGO && order.UsePriceMgmtAlgo:
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+" It does not support Use price management algo requests")
-		return
-	case v < mMIN_SERVER_VER_DURATION && order.Duration != UNSETINT:
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+" It does not support duration attribute")
-	case v < mMIN_SERVER_VER_POST_TO_ATS && order.PostToAts != UNSETINT:
-		ic.wrapper.Error(orderID, UPDATE_TWS.code, UPDATE_TWS.msg+" It does not support postToAts attribute")
-	}
-
-	var v int
-	if ic.serverVersion < mMIN_SERVER_VER_NOT_HELD {
-		v = 27
-	} else {
-		v = 45
-	}
-
-	fields := make([]interface{}, 0, 150)
-	fields = append(fields, mPLACE_ORDER)
-
-	if ic.serverVersion < mMIN_SERVER_VER_ORDER_CONTAINER {
-		fields = append(fields, v)
-	}
-
-	fields = append(fields, orderID)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_PLACE_ORDER_CONID {
-		fields = append(fields, contract.ContractID)
-	}
-
-	fields = append(fields,
-		contract.Symbol,
-		contract.SecurityType,
-		contract.Expiry,
-		contract.Strike,
-		contract.Right,
-		contract.Multiplier,
-		contract.Exchange,
-		contract.PrimaryExchange,
-		contract.Currency,
-		contract.LocalSymbol)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
-		fields = append(fields, contract.TradingClass)
-	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_SEC_ID_TYPE {
-		fields = append(fields, contract.SecurityIDType, contract.SecurityID)
-	}
-
-	fields = append(fields, order.Action)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_FRACTIONAL_POSITIONS {
-		fields = append(fields, order.TotalQuantity)
-	} else {
-		fields = append(fields, int64(order.TotalQuantity))
-	}
-
-	fields = append(fields, order.OrderType)
-
-	if ic.serverVersion < mMIN_SERVER_VER_ORDER_COMBO_LEGS_PRICE {
-		if order.LimitPrice != UNSETFLOAT {
-			fields = append(fields, order.LimitPrice)
-		} else {
-			fields = append(fields, float64(0))
-		}
-	} else {
-		fields = append(fields, handleEmpty(order.LimitPrice))
-	}
-
-	if ic.serverVersion < mMIN_SERVER_VER_TRAILING_PERCENT {
-		if order.AuxPrice != UNSETFLOAT {
-			fields = append(fields, order.AuxPrice)
-		} else {
-			fields = append(fields, float64(0))
-		}
-	} else {
-		fields = append(fields, handleEmpty(order.AuxPrice))
-	}
-
-	fields = append(fields,
-		order.TIF,
-		order.OCAGroup,
-		order.Account,
-		order.OpenClose,
-		order.Origin,
-		order.OrderRef,
-		order.Transmit,
-		order.ParentID,
-		order.BlockOrder,
-		order.SweepToFill,
-		order.DisplaySize,
-		order.TriggerMethod,
-		order.OutsideRTH,
-		order.Hidden)
-
-	if contract.SecurityType == "BAG" {
-		comboLegsCount := len(contract.ComboLegs)
-		fields = append(fields, comboLegsCount)
-		for _, comboLeg := range contract.ComboLegs {
-			fields = append(fields,
-				comboLeg.ContractID,
-				comboLeg.Ratio,
-				comboLeg.Action,
-				comboLeg.Exchange,
-				comboLeg.OpenClose,
-				comboLeg.ShortSaleSlot,
-				comboLeg.DesignatedLocation)
-			if ic.serverVersion >= mMIN_SERVER_VER_SSHORTX_OLD {
-				fields = append(fields, comboLeg.ExemptCode)
-			}
-		}
-	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_ORDER_COMBO_LEGS_PRICE && contract.SecurityType == "BAG" {
-		orderComboLegsCount := len(order.OrderComboLegs)
-		fields = append(fields, orderComboLegsCount)
-		for _, orderComboLeg := range order.OrderComboLegs {
-			fields = append(fields, handleEmpty(orderComboLeg.Price))
-		}
-	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_SMART_COMBO_ROUTING_PARAMS && contract.SecurityType == "BAG" {
-		smartComboRoutingParamsCount := len(order.SmartComboRoutingParams)
-		fields = append(fields, smartComboRoutingParamsCount)
-		for _, tv := range order.SmartComboRoutingParams {
-			fields = append(fields, tv.Tag, tv.Value)
-		}
-	}
-
-	fields = append(fields,
-		"",
-		order.DiscretionaryAmount,
-		order.GoodAfterTime,
-		order.GoodTillDate,
-
-		order.FAGroup,
-		order.FAMethod,
-		order.FAPercentage,
-		order.FAProfile)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_MODELS_SUPPORT {
-		fields = append(fields, order.ModelCode)
-	}
-
-	fields = append(fields,
-		order.ShortSaleSlot,
-		order.DesignatedLocation)
-
-	//institutional short saleslot data (srv v18 and above)
-	if ic.serverVersion >= mMIN_SERVER_VER_SSHORTX_OLD {
-		fields = append(fields, order.ExemptCode)
-	}
-
-	fields = append(fields, order.OCAType)
-
-	fields = append(fields,
-		order.Rule80A,
-		order.SettlingFirm,
-		order.AllOrNone,
-		handleEmpty(order.MinQty),
-		handleEmpty(order.PercentOffset),
-		false, // order.ETradeOnly
-		false, // order.FirmQuoteOnly
-		handleEmpty(UNSETFLOAT),
-		order.AuctionStrategy,
-		handleEmpty(order.StartingPrice),
-		handleEmpty(order.StockRefPrice),
-		handleEmpty(order.Delta),
-		handleEmpty(order.StockRangeLower),
-		handleEmpty(order.StockRangeUpper),
-
-		order.OverridePercentageConstraints,
-
-		handleEmpty(order.Volatility),
-		handleEmpty(order.VolatilityType),
-		order.DeltaNeutralOrderType,
-		handleEmpty(order.DeltaNeutralAuxPrice))
-
-	if ic.serverVersion >= mMIN_SERVER_VER_DELTA_NEUTRAL_CONID && order.DeltaNeutralOrderType != "" {
-		fields = append(fields,
-			order.DeltaNeutralContractID,
-			order.DeltaNeutralSettlingFirm,
-			order.DeltaNeutralClearingAccount,
-			order.DeltaNeutralClearingIntent)
-	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_DELTA_NEUTRAL_OPEN_CLOSE && order.DeltaNeutralOrderType != "" {
-		fields = append(fields,
-			order.DeltaNeutralOpenClose,
-			order.DeltaNeutralShortSale,
-			order.DeltaNeutralShortSaleSlot,
-			order.DeltaNeutralDesignatedLocation)
-	}
-
-	fields = append(fields,
-		order.ContinuousUpdate,
-		handleEmpty(order.ReferencePriceType),
-		handleEmpty(order.TrailStopPrice))
-
-	if ic.serverVersion >= mMIN_SERVER_VER_TRAILING_PERCENT {
-		fields = append(fields, handleEmpty(order.TrailingPercent))
-	}
-
-	//scale orders
-	if ic.serverVersion >= mMIN_SERVER_VER_SCALE_ORDERS2 {
-		fields = append(fields,
-			handleEmpty(order.ScaleInitLevelSize),
-			handleEmpty(order.ScaleSubsLevelSize))
-	} else {
-		fields = append(fields,
-			"",
-			handleEmpty(order.ScaleInitLevelSize))
-	}
-
-	fields = append(fields, handleEmpty(order.ScalePriceIncrement))
-
-	if ic.serverVersion >= mMIN_SERVER_VER_SCALE_ORDERS3 && order.ScalePriceIncrement != UNSETFLOAT && order.ScalePriceIncrement > 0.0 {
-		fields = append(fields,
-			handleEmpty(order.ScalePriceAdjustValue),
-			handleEmpty(order.ScalePriceAdjustInterval),
-			handleEmpty(order.ScaleProfitOffset),
-			order.ScaleAutoReset,
-			handleEmpty(order.ScaleInitPosition),
-			handleEmpty(order.ScaleInitFillQty),
-			order.ScaleRandomPercent)
-	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_SCALE_TABLE {
-		fields = append(fields,
-			order.ScaleTable,
-			order.ActiveStartTime,
-			order.ActiveStopTime)
-	}
-
-	//hedge orders
-	if ic.serverVersion >= mMIN_SERVER_VER_HEDGE_ORDERS {
-		fields = append(fields, order.HedgeType)
-		if order.HedgeType != "" {
-			fields = append(fields, order.HedgeParam)
-		}
-	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_OPT_OUT_SMART_ROUTING {
-		fields = append(fields, order.OptOutSmartRouting)
-	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_PTA_ORDERS {
-		fields = append(fields,
-			order.ClearingAccount,
-			order.ClearingIntent)
-	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_NOT_HELD {
-		fields = append(fields, order.NotHeld)
-	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_DELTA_NEUTRAL {
-		if contract.DeltaNeutralContract != nil {
-			fields = append(fields,
-				true,
-				contract.DeltaNeutralContract.ContractID,
-				contract.DeltaNeutralContract.Delta,
-				contract.DeltaNeutralContract.Price)
-		} else {
-			fields = append(fields, false)
-		}
-	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_ALGO_ORDERS {
-		fields = append(fields, order.AlgoStrategy)
-
-		if order.AlgoStrategy != "" {
-			algoParamsCount := len(order.AlgoParams)
-			fields = append(fields, algoParamsCount)
-			for _, tv := range order.AlgoParams {
-				fields = append(fields, tv.Tag, tv.Value)
-			}
-		}
-	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_ALGO_ID {
-		fields = append(fields, order.AlgoID)
-	}
-
-	fields = append(fields, order.WhatIf)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_LINKING {
-		var miscOptionsBuffer bytes.Buffer
-		for _, tv := range order.OrderMiscOptions {
-			miscOptionsBuffer.WriteString(tv.Tag)
-			miscOptionsBuffer.WriteString("=")
-			miscOptionsBuffer.WriteString(tv.Value)
-			miscOptionsBuffer.WriteString(";")
-		}
-
-		fields = append(fields, miscOptionsBuffer.Bytes())
-	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_ORDER_SOLICITED {
-		fields = append(fields, order.Solictied)
-	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_RANDOMIZE_SIZE_AND_PRICE {
-		fields = append(fields,
-			order.RandomizeSize,
-			order.RandomizePrice)
+	msg, err := EncodePlaceOrder(ic.serverVersion, orderID, contract, order)
+	if !order.WhatIf {
+		ic.dispatcher.trackOrder(orderID)
 	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_PEGGED_TO_BENCHMARK {
-		if order.OrderType == "PEG BENCH" {
-			fields = append(fields,
-				order.ReferenceContractID,
-				order.IsPeggedChangeAmountDecrease,
-				order.PeggedChangeAmount,
-				order.ReferenceChangeAmount,
-				order.ReferenceExchangeID)
-		}
-
-		orderConditionsCount := len(order.Conditions)
-		fields = append(fields, orderConditionsCount)
-		for _, cond := range order.Conditions {
-			fields = append(fields, cond.CondType())
-			fields = append(fields, cond.toFields()...)
-		}
-		if orderConditionsCount > 0 {
-			fields = append(fields,
-				order.ConditionsIgnoreRth,
-				order.ConditionsCancelOrder)
-		}
-
-		fields = append(fields,
-			order.AdjustedOrderType,
-			order.TriggerPrice,
-			order.LimitPriceOffset,
-			order.AdjustedStopPrice,
-			order.AdjustedStopLimitPrice,
-			order.AdjustedTrailingAmount,
-			order.AdjustableTrailingUnit)
-
-		if ic.serverVersion >= mMIN_SERVER_VER_EXT_OPERATOR {
-			fields = append(fields, order.ExtOperator)
-		}
-
-		if ic.serverVersion >= mMIN_SERVER_VER_SOFT_DOLLAR_TIER {
-			fields = append(fields, order.SoftDollarTier.Name, order.SoftDollarTier.Value)
-		}
-
-		if ic.serverVersion >= mMIN_SERVER_VER_CASH_QTY {
-			fields = append(fields, order.CashQty)
-		}
-
-		if ic.serverVersion >= mMIN_SERVER_VER_DECISION_MAKER {
-			fields = append(fields, order.Mifid2DecisionMaker, order.Mifid2DecisionAlgo)
-		}
-
-		if ic.serverVersion >= mMIN_SERVER_VER_MIFID_EXECUTION {
-			fields = append(fields, order.Mifid2ExecutionTrader, order.Mifid2ExecutionAlgo)
-		}
-
-		if ic.serverVersion >= mMIN_SERVER_VER_AUTO_PRICE_FOR_HEDGE {
-			fields = append(fields, order.DontUseAutoPriceForHedge)
-		}
-
-		if ic.serverVersion >= mMIN_SERVER_VER_ORDER_CONTAINER {
-			fields = append(fields, order.IsOmsContainer)
-		}
-
-		if ic.serverVersion >= mMIN_SERVER_VER_D_PEG_ORDERS {
-			fields = append(fields, order.DiscretionaryUpToLimitPrice)
-		}
-
-		if ic.serverVersion >= mMIN_SERVER_VER_PRICE_MGMT_ALGO {
-			fields = append(fields, order.UsePriceMgmtAlgo)
-		}
-
-		if ic.serverVersion >= mMIN_SERVER_VER_DURATION {
-			fields = append(fields, handleEmpty(order.Duration))
-		}
-
-		if ic.serverVersion >= mMIN_SERVER_VER_POST_TO_ATS {
-			fields = append(fields, handleEmpty(order.PostToAts))
-		}
-
-		msg := makeMsgBytes(fields...)
-
-		ic.reqChan <- msg
+	if err == nil && !order.WhatIf && ic.orders.hold(orderID, msg) {
+		log.Info("order held", zap.Int64("orderID", orderID))
+		return
 	}
-
+	ic.send(msg, err)
 }
 
 // CancelOrder cancel an order by orderId
 func (ic *IbClient) CancelOrder(orderID int64) {
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mCANCEL_ORDER, v, orderID)
+	ic.CancelOrderWithManualTime(orderID, "")
+}
 
-	ic.reqChan <- msg
+// CancelOrderWithManualTime cancel an order by orderId with the manual order cancel time, such as "20220314 19:00:00"
+func (ic *IbClient) CancelOrderWithManualTime(orderID int64, manualCancelOrderTime string) {
+	if ic.orders.drop(orderID) {
+		ic.cancelHeldOrder(orderID)
+		return
+	}
+	ic.send(EncodeCancelOrder(ic.serverVersion, orderID, manualCancelOrderTime))
 }
 
 // ReqOpenOrders request the open orders of this client
 func (ic *IbClient) ReqOpenOrders() {
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mREQ_OPEN_ORDERS, v)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqOpenOrders(ic.serverVersion))
 }
 
 // ReqAutoOpenOrders will make the client access to the TWS Orders (only if clientId=0)
 func (ic *IbClient) ReqAutoOpenOrders(autoBind bool) {
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mREQ_AUTO_OPEN_ORDERS, v, autoBind)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqAutoOpenOrders(ic.serverVersion, autoBind))
 }
 
 // ReqAllOpenOrders request all the open orders including the orders of other clients and tws
 func (ic *IbClient) ReqAllOpenOrders() {
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mREQ_ALL_OPEN_ORDERS, v)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqAllOpenOrders(ic.serverVersion))
 }
 
 // ReqGlobalCancel cancel all the orders including the orders of other clients and tws
 func (ic *IbClient) ReqGlobalCancel() {
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mREQ_GLOBAL_CANCEL, v)
-
-	ic.reqChan <- msg
+	for _, o := range ic.orders.dropAll() {
+		ic.cancelHeldOrder(o.orderID)
+	}
+	ic.send(EncodeReqGlobalCancel(ic.serverVersion))
 }
 
 // ReqIDs request th next valid ID
@@ -1379,11 +718,7 @@ generates new IDs and increments the next valid ID therein).
 
 */
 func (ic *IbClient) ReqIDs() {
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mREQ_IDS, v, 0)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqIDs(ic.serverVersion))
 }
 
 /*
@@ -1399,11 +734,7 @@ or subscribe the update by setting param:subscribe true.
 Result will be delivered via wrapper.UpdateAccountValue() and wrapper.UpdateAccountTime().
 */
 func (ic *IbClient) ReqAccountUpdates(subscribe bool, accName string) {
-	// v := 2
-	const v = 2
-	msg := makeMsgBytes(mREQ_ACCT_DATA, v, subscribe, accName)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqAccountUpdates(ic.serverVersion, subscribe, accName))
 }
 
 // ReqAccountSummary request the account summary.
@@ -1463,99 +794,42 @@ Available tags are:
 	currencies.
 */
 func (ic *IbClient) ReqAccountSummary(reqID int64, groupName string, tags string) {
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mREQ_ACCOUNT_SUMMARY, v, reqID, groupName, tags)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqAccountSummary(ic.serverVersion, reqID, groupName, tags))
 }
 
 // CancelAccountSummary cancel the account summary.
 func (ic *IbClient) CancelAccountSummary(reqID int64) {
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mCANCEL_ACCOUNT_SUMMARY, v, reqID)
-
-	ic.reqChan <- msg
+	ic.send(EncodeCancelAccountSummary(ic.serverVersion, reqID))
 }
 
 // ReqPositions request and subcribe the positions of current account.
 func (ic *IbClient) ReqPositions() {
-	if ic.serverVersion < mMIN_SERVER_VER_POSITIONS {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support positions request.")
-		return
-	}
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mREQ_POSITIONS, v)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqPositions(ic.serverVersion))
 }
 
 // CancelPositions cancel the positions update
 func (ic *IbClient) CancelPositions() {
-	if ic.serverVersion < mMIN_SERVER_VER_POSITIONS {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support positions request.")
-		return
-	}
-
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mCANCEL_POSITIONS, v)
-
-	ic.reqChan <- msg
+	ic.send(EncodeCancelPositions(ic.serverVersion))
 }
 
 // ReqPositionsMulti request the positions update of assigned account.
 func (ic *IbClient) ReqPositionsMulti(reqID int64, account string, modelCode string) {
-	if ic.serverVersion < mMIN_SERVER_VER_MODELS_SUPPORT {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support positions multi request.")
-		return
-	}
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mREQ_POSITIONS_MULTI, v, reqID, account, modelCode)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqPositionsMulti(ic.serverVersion, reqID, account, modelCode))
 }
 
 // CancelPositionsMulti cancel the positions update of assigned account.
 func (ic *IbClient) CancelPositionsMulti(reqID int64) {
-	if ic.serverVersion < mMIN_SERVER_VER_MODELS_SUPPORT {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support cancel positions multi request.")
-		return
-	}
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mCANCEL_POSITIONS_MULTI, v, reqID)
-
-	ic.reqChan <- msg
+	ic.send(EncodeCancelPositionsMulti(ic.serverVersion, reqID))
 }
 
 // ReqAccountUpdatesMulti request and subscrie the assigned account update.
 func (ic *IbClient) ReqAccountUpdatesMulti(reqID int64, account string, modelCode string, ledgerAndNLV bool) {
-	if ic.serverVersion < mMIN_SERVER_VER_MODELS_SUPPORT {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support account updates multi request.")
-		return
-	}
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mREQ_ACCOUNT_UPDATES_MULTI, v, reqID, account, modelCode, ledgerAndNLV)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqAccountUpdatesMulti(ic.serverVersion, reqID, account, modelCode, ledgerAndNLV))
 }
 
 // CancelAccountUpdatesMulti cancel the assigned account update.
 func (ic *IbClient) CancelAccountUpdatesMulti(reqID int64) {
-	if ic.serverVersion < mMIN_SERVER_VER_MODELS_SUPPORT {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support cancel account updates multi request.")
-		return
-	}
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mCANCEL_ACCOUNT_UPDATES_MULTI, v, reqID)
-
-	ic.reqChan <- msg
+	ic.send(EncodeCancelAccountUpdatesMulti(ic.serverVersion, reqID))
 }
 
 /*
@@ -1567,50 +841,22 @@ func (ic *IbClient) CancelAccountUpdatesMulti(reqID int64) {
 
 // ReqPnL request and subscribe the PnL of assigned account.
 func (ic *IbClient) ReqPnL(reqID int64, account string, modelCode string) {
-	if ic.serverVersion < mMIN_SERVER_VER_PNL {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support PnL request.")
-		return
-	}
-
-	msg := makeMsgBytes(mREQ_PNL, reqID, account, modelCode)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqPnL(ic.serverVersion, reqID, account, modelCode))
 }
 
 // CancelPnL cancel the PnL update of assigned account.
 func (ic *IbClient) CancelPnL(reqID int64) {
-	if ic.serverVersion < mMIN_SERVER_VER_PNL {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support PnL request.")
-		return
-	}
-
-	msg := makeMsgBytes(mCANCEL_PNL, reqID)
-
-	ic.reqChan <- msg
+	ic.send(EncodeCancelPnL(ic.serverVersion, reqID))
 }
 
 // ReqPnLSingle request and subscribe the single contract PnL of assigned account.
 func (ic *IbClient) ReqPnLSingle(reqID int64, account string, modelCode string, contractID int64) {
-	if ic.serverVersion < mMIN_SERVER_VER_PNL {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support PnL request.")
-		return
-	}
-
-	msg := makeMsgBytes(mREQ_PNL_SINGLE, reqID, account, modelCode, contractID)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqPnLSingle(ic.serverVersion, reqID, account, modelCode, contractID))
 }
 
 // CancelPnLSingle cancel the single contract PnL update of assigned account.
 func (ic *IbClient) CancelPnLSingle(reqID int64) {
-	if ic.serverVersion < mMIN_SERVER_VER_PNL {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support PnL request.")
-		return
-	}
-
-	msg := makeMsgBytes(mCANCEL_PNL_SINGLE, reqID)
-
-	ic.reqChan <- msg
+	ic.send(EncodeCancelPnLSingle(ic.serverVersion, reqID))
 }
 
 /*
@@ -1637,26 +883,7 @@ NOTE:
 	Time format must be 'yyyymmdd-hh:mm:ss' Eg: '20030702-14:55'
 */
 func (ic *IbClient) ReqExecutions(reqID int64, execFilter ExecutionFilter) {
-	// v := 3
-	const v = 3
-	fields := make([]interface{}, 0, 10)
-	fields = append(fields, mREQ_EXECUTIONS, v)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_EXECUTION_DATA_CHAIN {
-		fields = append(fields, reqID)
-	}
-
-	fields = append(fields,
-		execFilter.ClientID,
-		execFilter.AccountCode,
-		execFilter.Time,
-		execFilter.Symbol,
-		execFilter.SecurityType,
-		execFilter.Exchange,
-		execFilter.Side)
-	msg := makeMsgBytes(fields...)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqExecutions(ic.serverVersion, reqID, execFilter))
 }
 
 /*
@@ -1668,63 +895,7 @@ func (ic *IbClient) ReqExecutions(reqID int64, execFilter ExecutionFilter) {
 
 // ReqContractDetails request the contract details.
 func (ic *IbClient) ReqContractDetails(reqID int64, contract *Contract) {
-	if ic.serverVersion < mMIN_SERVER_VER_SEC_ID_TYPE &&
-		(contract.SecurityIDType != "" || contract.SecurityID != "") {
-		ic.wrapper.Error(reqID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support secIdType and secId parameters.")
-		return
-	}
-
-	if ic.serverVersion < mMIN_SERVER_VER_TRADING_CLASS && contract.TradingClass != "" {
-		ic.wrapper.Error(reqID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support tradingClass parameter in reqContractDetails.")
-		return
-	}
-
-	if ic.serverVersion < mMIN_SERVER_VER_LINKING && contract.PrimaryExchange != "" {
-		ic.wrapper.Error(reqID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support primaryExchange parameter in reqContractDetails.")
-		return
-	}
-
-	// v := 8
-	const v = 8
-	fields := make([]interface{}, 0, 20)
-	fields = append(fields, mREQ_CONTRACT_DATA, v)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_CONTRACT_DATA_CHAIN {
-		fields = append(fields, reqID)
-	}
-
-	fields = append(fields,
-		contract.ContractID,
-		contract.Symbol,
-		contract.SecurityType,
-		contract.Expiry,
-		contract.Strike,
-		contract.Right,
-		contract.Multiplier)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_PRIMARYEXCH {
-		fields = append(fields, contract.Exchange, contract.PrimaryExchange)
-	} else if ic.serverVersion >= mMIN_SERVER_VER_LINKING {
-		if contract.PrimaryExchange != "" && (contract.Exchange == "BEST" || contract.Exchange == "SMART") {
-			fields = append(fields, strings.Join([]string{contract.Exchange, contract.PrimaryExchange}, ":"))
-		} else {
-			fields = append(fields, contract.Exchange)
-		}
-	}
-
-	fields = append(fields, contract.Currency, contract.LocalSymbol)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
-		fields = append(fields, contract.TradingClass, contract.IncludeExpired)
-	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_SEC_ID_TYPE {
-		fields = append(fields, contract.SecurityIDType, contract.SecurityID)
-	}
-
-	msg := makeMsgBytes(fields...)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqContractDetails(ic.serverVersion, reqID, contract))
 }
 
 /*
@@ -1735,14 +906,7 @@ func (ic *IbClient) ReqContractDetails(reqID int64, contract *Contract) {
 
 // ReqMktDepthExchanges request the exchanges of market depth.
 func (ic *IbClient) ReqMktDepthExchanges() {
-	if ic.serverVersion < mMIN_SERVER_VER_REQ_MKT_DEPTH_EXCHANGES {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support market depth exchanges request.")
-		return
-	}
-
-	msg := makeMsgBytes(mREQ_MKT_DEPTH_EXCHANGES)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqMktDepthExchanges(ic.serverVersion))
 }
 
 //ReqMktDepth request the market depth.
@@ -1769,88 +933,17 @@ that looks at an accounts equity, commissions, and quote booster packs.
 	For internal use only. Use default value XYZ.
 */
 func (ic *IbClient) ReqMktDepth(reqID int64, contract *Contract, numRows int, isSmartDepth bool, mktDepthOptions []TagValue) {
-	switch {
-	case ic.serverVersion < mMIN_SERVER_VER_TRADING_CLASS:
-		if contract.TradingClass != "" || contract.ContractID > 0 {
-			ic.wrapper.Error(reqID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support conId and tradingClass parameters in reqMktDepth.")
-			return
-		}
-		fallthrough
-	case ic.serverVersion < mMIN_SERVER_VER_SMART_DEPTH && isSmartDepth:
-		ic.wrapper.Error(reqID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support SMART depth request.")
-		return
-	case ic.serverVersion < mMIN_SERVER_VER_MKT_DEPTH_PRIM_EXCHANGE && contract.PrimaryExchange != "":
-		ic.wrapper.Error(reqID, UPDATE_TWS.code, UPDATE_TWS.msg+" It does not support primaryExchange parameter in reqMktDepth.")
-		return
-	}
-
-	// v := 5
-	const v = 5
-	fields := make([]interface{}, 0, 17)
-	fields = append(fields, mREQ_MKT_DEPTH, v, reqID)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
-		fields = append(fields, contract.ContractID)
-	}
-
-	fields = append(fields,
-		contract.Symbol,
-		contract.SecurityType,
-		contract.Expiry,
-		contract.Strike,
-		contract.Right,
-		contract.Multiplier,
-		contract.Exchange)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_MKT_DEPTH_PRIM_EXCHANGE {
-		fields = append(fields, contract.PrimaryExchange)
-	}
-
-	fields = append(fields,
-		contract.Currency,
-		contract.LocalSymbol)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
-		fields = append(fields, contract.TradingClass)
-	}
-
-	fields = append(fields, numRows)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_SMART_DEPTH {
-		fields = append(fields, isSmartDepth)
-	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_LINKING {
-		//current doc says this part if for "internal use only" -> won't support it
-		if len(mktDepthOptions) > 0 {
-			log.Panic("not supported")
-		}
-
-		fields = append(fields, "")
+	if ic.send(EncodeReqMktDepth(ic.serverVersion, reqID, contract, numRows, isSmartDepth, mktDepthOptions)) {
+		ic.subscriptions.add(reqID,
+			func() { ic.ReqMktDepth(reqID, contract, numRows, isSmartDepth, mktDepthOptions) },
+			func() { ic.CancelMktDepth(reqID, isSmartDepth) })
 	}
-
-	msg := makeMsgBytes(fields...)
-
-	ic.reqChan <- msg
 }
 
-// CancelMktDepth cancel market depth.
-func (ic *IbClient) CancelMktDepth(reqID int64, isSmartDepth bool) {
-	if ic.serverVersion < mMIN_SERVER_VER_SMART_DEPTH && isSmartDepth {
-		ic.wrapper.Error(reqID, UPDATE_TWS.code, UPDATE_TWS.msg+" It does not support SMART depth cancel.")
-		return
-	}
-	// v := 1
-	const v = 1
-	fields := make([]interface{}, 0, 4)
-	fields = append(fields, mCANCEL_MKT_DEPTH, v, reqID)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_SMART_DEPTH {
-		fields = append(fields, isSmartDepth)
-	}
-	msg := makeMsgBytes(fields...)
-
-	ic.reqChan <- msg
+// CancelMktDepth cancel market depth.
+func (ic *IbClient) CancelMktDepth(reqID int64, isSmartDepth bool) {
+	ic.subscriptions.remove(reqID)
+	ic.send(EncodeCancelMktDepth(ic.serverVersion, reqID, isSmartDepth))
 }
 
 /*
@@ -1869,20 +962,12 @@ will be returned by the updateNewsBulletin() event.
 	If set to FALSE, will only return new bulletins.
 */
 func (ic *IbClient) ReqNewsBulletins(allMsgs bool) {
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mREQ_NEWS_BULLETINS, v, allMsgs)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqNewsBulletins(ic.serverVersion, allMsgs))
 }
 
 // CancelNewsBulletins cancel the news bulletins
 func (ic *IbClient) CancelNewsBulletins() {
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mCANCEL_NEWS_BULLETINS, v)
-
-	ic.reqChan <- msg
+	ic.send(EncodeCancelNewsBulletins(ic.serverVersion))
 }
 
 /*
@@ -1900,11 +985,7 @@ Note:
 	This request can only be made when connected to a FA managed account.
 */
 func (ic *IbClient) ReqManagedAccts() {
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mREQ_MANAGED_ACCTS, v)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqManagedAccts(ic.serverVersion))
 }
 
 // RequestFA request fa.
@@ -1913,11 +994,7 @@ func (ic *IbClient) ReqManagedAccts() {
 	0->"N/A", 1->"GROUPS", 2->"PROFILES", 3->"ALIASES"
 */
 func (ic *IbClient) RequestFA(faData int) {
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mREQ_FA, v, faData)
-
-	ic.reqChan <- msg
+	ic.send(EncodeRequestFA(ic.serverVersion, faData))
 }
 
 // ReplaceFA replace fa.
@@ -1935,11 +1012,12 @@ Valid values include:
 	The XML string containing the new FA configuration information.
 */
 func (ic *IbClient) ReplaceFA(faData int, cxml string) {
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mREPLACE_FA, v, faData, cxml)
+	ic.replaceFA(NO_VALID_ID, faData, cxml)
+}
 
-	ic.reqChan <- msg
+// replaceFA sends the reqID which is echoed in ReplaceFAEnd, TWS before mMIN_SERVER_VER_REPLACE_FA_END ignores it.
+func (ic *IbClient) replaceFA(reqID int64, faData int, cxml string) {
+	ic.send(EncodeReplaceFA(ic.serverVersion, reqID, faData, cxml))
 }
 
 /*
@@ -1963,7 +1041,8 @@ Result will be delivered via wrapper.HistoricalData()
 @param endDateTime:
 	Defines a query end date and time at any point during the past 6 mos.
 	Valid values include any date/time within the past six months in the format:
-	yyyymmdd HH:mm:ss ttt where "ttt" is the optional time zone.
+	yyyymmdd HH:mm:ss ttt where "ttt" is the optional time zone, or yyyymmdd-HH:mm:ss in UTC.
+	See ReqHistoricalDataAt for time.Time.
 @param durationStr:
 	Set the query duration up to one week, using a time unit of seconds, days or weeks.
 	Valid values include any integer followed by a space and then S (seconds), D (days) or W (week).
@@ -2011,80 +1090,21 @@ Valid values include:
 	For internal use only. Use default value XYZ.
 */
 func (ic *IbClient) ReqHistoricalData(reqID int64, contract *Contract, endDateTime string, duration string, barSize string, whatToShow string, useRTH bool, formatDate int, keepUpToDate bool, chartOptions []TagValue) {
-	if ic.serverVersion < mMIN_SERVER_VER_TRADING_CLASS {
-		if contract.TradingClass != "" || contract.ContractID > 0 {
-			ic.wrapper.Error(reqID, UPDATE_TWS.code, UPDATE_TWS.msg)
-		}
-	}
-
-	// v := 6
-	const v = 6
-	fields := make([]interface{}, 0, 30)
-	fields = append(fields, mREQ_HISTORICAL_DATA)
-	if ic.serverVersion <= mMIN_SERVER_VER_SYNT_REALTIME_BARS {
-		fields = append(fields, v)
-	}
-
-	fields = append(fields, reqID)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
-		fields = append(fields, contract.ContractID)
-	}
-
-	fields = append(fields,
-		contract.Symbol,
-		contract.SecurityType,
-		contract.Expiry,
-		contract.Strike,
-		contract.Right,
-		contract.Multiplier,
-		contract.Exchange,
-		contract.PrimaryExchange,
-		contract.Currency,
-		contract.LocalSymbol,
-	)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
-		fields = append(fields, contract.TradingClass)
-	}
-	fields = append(fields,
-		contract.IncludeExpired,
-		endDateTime,
-		barSize,
-		duration,
-		useRTH,
-		whatToShow,
-		formatDate,
-	)
-
-	if contract.SecurityType == "BAG" {
-		fields = append(fields, len(contract.ComboLegs))
-		for _, comboLeg := range contract.ComboLegs {
-			fields = append(fields,
-				comboLeg.ContractID,
-				comboLeg.Ratio,
-				comboLeg.Action,
-				comboLeg.Exchange,
-			)
-		}
-	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_SYNT_REALTIME_BARS {
-		fields = append(fields, keepUpToDate)
-	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_LINKING {
-		chartOptionsStr := ""
-		for _, tagValue := range chartOptions {
-			chartOptionsStr += tagValue.Value
-		}
-		fields = append(fields, chartOptionsStr)
-	}
+	ic.send(EncodeReqHistoricalData(ic.serverVersion, reqID, contract, endDateTime, duration, barSize, whatToShow, useRTH, formatDate, keepUpToDate, chartOptions))
+}
 
-	msg := makeMsgBytes(fields...)
-	// fmt.Println(msg)
+// ReqHistoricalDataAt is ReqHistoricalData with endDateTime formatted by FormatIBTime, the zero time means now.
+func (ic *IbClient) ReqHistoricalDataAt(reqID int64, contract *Contract, endDateTime time.Time, duration string, barSize string, whatToShow string, useRTH bool, formatDate int, keepUpToDate bool, chartOptions []TagValue) {
+	ic.ReqHistoricalData(reqID, contract, FormatIBTime(endDateTime), duration, barSize, whatToShow, useRTH, formatDate, keepUpToDate, chartOptions)
+}
 
-	ic.reqChan <- msg
+// ReqHistoricalSchedule requests the trading sessions of the contract, it is ReqHistoricalData with whatToShow="SCHEDULE".
+/*
+The sessions in the time span of endDateTime and duration are delivered via wrapper.HistoricalSchedule(),
+barSize should be "1 day" or larger, useRTH determines whether the sessions are the regular trading hours.
+*/
+func (ic *IbClient) ReqHistoricalSchedule(reqID int64, contract *Contract, endDateTime string, duration string, barSize string, useRTH bool) {
+	ic.ReqHistoricalData(reqID, contract, endDateTime, duration, barSize, "SCHEDULE", useRTH, 1, false, nil)
 }
 
 // CancelHistoricalData cancel the update of historical data.
@@ -2097,11 +1117,7 @@ the data.
 	The ticker ID must be a unique value.
 */
 func (ic *IbClient) CancelHistoricalData(reqID int64) {
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mCANCEL_HISTORICAL_DATA, v, reqID)
-
-	ic.reqChan <- msg
+	ic.send(EncodeCancelHistoricalData(ic.serverVersion, reqID))
 }
 
 // ReqHeadTimeStamp request the head timestamp of assigned contract.
@@ -2109,146 +1125,38 @@ func (ic *IbClient) CancelHistoricalData(reqID int64) {
 call this func to get the headmost data you can get
 */
 func (ic *IbClient) ReqHeadTimeStamp(reqID int64, contract *Contract, whatToShow string, useRTH bool, formatDate int) {
-	if ic.serverVersion < mMIN_SERVER_VER_REQ_HEAD_TIMESTAMP {
-		ic.wrapper.Error(reqID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support head time stamp requests.")
-		return
-	}
-
-	fields := make([]interface{}, 0, 18)
-
-	fields = append(fields,
-		mREQ_HEAD_TIMESTAMP,
-		reqID,
-		contract.ContractID,
-		contract.Symbol,
-		contract.SecurityType,
-		contract.Expiry,
-		contract.Strike,
-		contract.Right,
-		contract.Multiplier,
-		contract.Exchange,
-		contract.PrimaryExchange,
-		contract.Currency,
-		contract.LocalSymbol,
-		contract.TradingClass,
-		contract.IncludeExpired,
-		useRTH,
-		whatToShow,
-		formatDate)
-
-	msg := makeMsgBytes(fields...)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqHeadTimeStamp(ic.serverVersion, reqID, contract, whatToShow, useRTH, formatDate))
 }
 
 // CancelHeadTimeStamp cancel the head timestamp data.
 func (ic *IbClient) CancelHeadTimeStamp(reqID int64) {
-	if ic.serverVersion < mMIN_SERVER_VER_CANCEL_HEADTIMESTAMP {
-		ic.wrapper.Error(reqID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support head time stamp requests.")
-		return
-	}
-
-	msg := makeMsgBytes(mCANCEL_HEAD_TIMESTAMP, reqID)
-
-	ic.reqChan <- msg
+	ic.send(EncodeCancelHeadTimeStamp(ic.serverVersion, reqID))
 }
 
 // ReqHistogramData request histogram data.
 func (ic *IbClient) ReqHistogramData(reqID int64, contract *Contract, useRTH bool, timePeriod string) {
-	if ic.serverVersion < mMIN_SERVER_VER_REQ_HISTOGRAM {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support histogram requests..")
-		return
-	}
-
-	fields := make([]interface{}, 0, 18)
-	fields = append(fields,
-		mREQ_HISTOGRAM_DATA,
-		reqID,
-		contract.ContractID,
-		contract.Symbol,
-		contract.SecurityType,
-		contract.Expiry,
-		contract.Strike,
-		contract.Right,
-		contract.Multiplier,
-		contract.Exchange,
-		contract.PrimaryExchange,
-		contract.Currency,
-		contract.LocalSymbol,
-		contract.TradingClass,
-		contract.IncludeExpired,
-		useRTH,
-		timePeriod)
-
-	msg := makeMsgBytes(fields...)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqHistogramData(ic.serverVersion, reqID, contract, useRTH, timePeriod))
 }
 
 // CancelHistogramData cancel histogram data.
 func (ic *IbClient) CancelHistogramData(reqID int64) {
-	if ic.serverVersion < mMIN_SERVER_VER_REQ_HISTOGRAM {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support histogram requests..")
-		return
-	}
-
-	msg := makeMsgBytes(mCANCEL_HISTOGRAM_DATA, reqID)
-
-	ic.reqChan <- msg
+	ic.send(EncodeCancelHistogramData(ic.serverVersion, reqID))
 }
 
 // ReqHistoricalTicks request historical ticks.
 func (ic *IbClient) ReqHistoricalTicks(reqID int64, contract *Contract, startDateTime string, endDateTime string, numberOfTicks int, whatToShow string, useRTH bool, ignoreSize bool, miscOptions []TagValue) {
-	if ic.serverVersion < mMIN_SERVER_VER_HISTORICAL_TICKS {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support historical ticks requests..")
-		return
-	}
-
-	fields := make([]interface{}, 0, 22)
-	fields = append(fields,
-		mREQ_HISTORICAL_TICKS,
-		reqID,
-		contract.ContractID,
-		contract.Symbol,
-		contract.SecurityType,
-		contract.Expiry,
-		contract.Strike,
-		contract.Right,
-		contract.Multiplier,
-		contract.Exchange,
-		contract.PrimaryExchange,
-		contract.Currency,
-		contract.LocalSymbol,
-		contract.TradingClass,
-		contract.IncludeExpired,
-		startDateTime,
-		endDateTime,
-		numberOfTicks,
-		whatToShow,
-		useRTH,
-		ignoreSize)
-
-	var miscOptionsBuffer bytes.Buffer
-	for _, tv := range miscOptions {
-		miscOptionsBuffer.WriteString(tv.Tag)
-		miscOptionsBuffer.WriteString("=")
-		miscOptionsBuffer.WriteString(tv.Value)
-		miscOptionsBuffer.WriteString(";")
-	}
-	fields = append(fields, miscOptionsBuffer.Bytes())
-
-	msg := makeMsgBytes(fields...)
+	ic.send(EncodeReqHistoricalTicks(ic.serverVersion, reqID, contract, startDateTime, endDateTime, numberOfTicks, whatToShow, useRTH, ignoreSize, miscOptions))
+}
 
-	ic.reqChan <- msg
+// ReqHistoricalTicksBetween is ReqHistoricalTicks with startDateTime and endDateTime formatted by FormatIBTime,
+// one of them should be the zero time.
+func (ic *IbClient) ReqHistoricalTicksBetween(reqID int64, contract *Contract, startDateTime time.Time, endDateTime time.Time, numberOfTicks int, whatToShow string, useRTH bool, ignoreSize bool, miscOptions []TagValue) {
+	ic.ReqHistoricalTicks(reqID, contract, FormatIBTime(startDateTime), FormatIBTime(endDateTime), numberOfTicks, whatToShow, useRTH, ignoreSize, miscOptions)
 }
 
 // ReqScannerParameters requests an XML string that describes all possible scanner queries.
 func (ic *IbClient) ReqScannerParameters() {
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mREQ_SCANNER_PARAMETERS, v)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqScannerParameters(ic.serverVersion))
 }
 
 // ReqScannerSubscription subcribes a scanner that matched the subcription.
@@ -2262,70 +1170,7 @@ call this func to subcribe a scanner which could scan the market.
 	For internal use only.Use default value XYZ.
 */
 func (ic *IbClient) ReqScannerSubscription(reqID int64, subscription *ScannerSubscription, scannerSubscriptionOptions []TagValue, scannerSubscriptionFilterOptions []TagValue) {
-	if ic.serverVersion < mMIN_SERVER_VER_SCANNER_GENERIC_OPTS && len(scannerSubscriptionFilterOptions) > 0 {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+" It does not support API scanner subscription generic filter options")
-		return
-	}
-
-	// v := 4
-	const v = 4
-	fields := make([]interface{}, 0, 25)
-	fields = append(fields, mREQ_SCANNER_SUBSCRIPTION)
-
-	if ic.serverVersion < mMIN_SERVER_VER_SCANNER_GENERIC_OPTS {
-		fields = append(fields, v)
-	}
-
-	fields = append(fields,
-		reqID,
-		handleEmpty(subscription.NumberOfRows),
-		subscription.Instrument,
-		subscription.LocationCode,
-		subscription.ScanCode,
-		handleEmpty(subscription.AbovePrice),
-		handleEmpty(subscription.BelowPrice),
-		handleEmpty(subscription.AboveVolume),
-		handleEmpty(subscription.MarketCapAbove),
-		handleEmpty(subscription.MarketCapBelow),
-		subscription.MoodyRatingAbove,
-		subscription.MoodyRatingBelow,
-		subscription.SpRatingAbove,
-		subscription.SpRatingBelow,
-		subscription.MaturityDateAbove,
-		subscription.MaturityDateBelow,
-		handleEmpty(subscription.CouponRateAbove),
-		handleEmpty(subscription.CouponRateBelow),
-		subscription.ExcludeConvertible,
-		handleEmpty(subscription.AverageOptionVolumeAbove),
-		subscription.ScannerSettingPairs,
-		subscription.StockTypeFilter)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_SCANNER_GENERIC_OPTS {
-		var scannerSubscriptionFilterOptionsBuffer bytes.Buffer
-		for _, tv := range scannerSubscriptionFilterOptions {
-			scannerSubscriptionFilterOptionsBuffer.WriteString(tv.Tag)
-			scannerSubscriptionFilterOptionsBuffer.WriteString("=")
-			scannerSubscriptionFilterOptionsBuffer.WriteString(tv.Value)
-			scannerSubscriptionFilterOptionsBuffer.WriteString(";")
-		}
-		fields = append(fields, scannerSubscriptionFilterOptionsBuffer.Bytes())
-	}
-
-	if ic.serverVersion >= mMIN_SERVER_VER_LINKING {
-		var scannerSubscriptionOptionsBuffer bytes.Buffer
-		for _, tv := range scannerSubscriptionOptions {
-			scannerSubscriptionOptionsBuffer.WriteString(tv.Tag)
-			scannerSubscriptionOptionsBuffer.WriteString("=")
-			scannerSubscriptionOptionsBuffer.WriteString(tv.Value)
-			scannerSubscriptionOptionsBuffer.WriteString(";")
-		}
-		fields = append(fields, scannerSubscriptionOptionsBuffer.Bytes())
-
-	}
-
-	msg := makeMsgBytes(fields...)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqScannerSubscription(ic.serverVersion, reqID, subscription, scannerSubscriptionOptions, scannerSubscriptionFilterOptions))
 }
 
 // CancelScannerSubscription cancel scanner.
@@ -2333,11 +1178,7 @@ func (ic *IbClient) ReqScannerSubscription(reqID int64, subscription *ScannerSub
 	reqId:int - The ticker ID. Must be a unique value.
 */
 func (ic *IbClient) CancelScannerSubscription(reqID int64) {
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mCANCEL_SCANNER_SUBSCRIPTION, v, reqID)
-
-	ic.reqChan <- msg
+	ic.send(EncodeCancelScannerSubscription(ic.serverVersion, reqID))
 }
 
 /*
@@ -2381,65 +1222,17 @@ Valid values include:
 	For internal use only. Use default value XYZ.
 */
 func (ic *IbClient) ReqRealTimeBars(reqID int64, contract *Contract, barSize int, whatToShow string, useRTH bool, realTimeBarsOptions []TagValue) {
-	if ic.serverVersion < mMIN_SERVER_VER_TRADING_CLASS && contract.TradingClass != "" {
-		ic.wrapper.Error(reqID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support conId and tradingClass parameter in reqRealTimeBars.")
-		return
-	}
-
-	// v := 3
-	const v = 3
-	fields := make([]interface{}, 0, 19)
-	fields = append(fields, mREQ_REAL_TIME_BARS, v, reqID)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
-		fields = append(fields, contract.ContractID)
-	}
-
-	fields = append(fields,
-		contract.Symbol,
-		contract.SecurityType,
-		contract.Expiry,
-		contract.Strike,
-		contract.Right,
-		contract.Multiplier,
-		contract.Exchange,
-		contract.PrimaryExchange,
-		contract.Currency,
-		contract.LocalSymbol)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
-		fields = append(fields, contract.TradingClass)
-	}
-
-	fields = append(fields,
-		barSize,
-		whatToShow,
-		useRTH)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_LINKING {
-		var realTimeBarsOptionsBuffer bytes.Buffer
-		for _, tv := range realTimeBarsOptions {
-			realTimeBarsOptionsBuffer.WriteString(tv.Tag)
-			realTimeBarsOptionsBuffer.WriteString("=")
-			realTimeBarsOptionsBuffer.WriteString(tv.Value)
-			realTimeBarsOptionsBuffer.WriteString(";")
-		}
-		fields = append(fields, realTimeBarsOptionsBuffer.Bytes())
-
+	if ic.send(EncodeReqRealTimeBars(ic.serverVersion, reqID, contract, barSize, whatToShow, useRTH, realTimeBarsOptions)) {
+		ic.subscriptions.add(reqID,
+			func() { ic.ReqRealTimeBars(reqID, contract, barSize, whatToShow, useRTH, realTimeBarsOptions) },
+			func() { ic.CancelRealTimeBars(reqID) })
 	}
-
-	msg := makeMsgBytes(fields...)
-
-	ic.reqChan <- msg
 }
 
 // CancelRealTimeBars cancel realtime bars.
 func (ic *IbClient) CancelRealTimeBars(reqID int64) {
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mCANCEL_REAL_TIME_BARS, v, reqID)
-
-	ic.reqChan <- msg
+	ic.subscriptions.remove(reqID)
+	ic.send(EncodeCancelRealTimeBars(ic.serverVersion, reqID))
 }
 
 /*
@@ -2474,65 +1267,12 @@ trading class.
 		CalendarReport (company calendar)
 */
 func (ic *IbClient) ReqFundamentalData(reqID int64, contract *Contract, reportType string, fundamentalDataOptions []TagValue) {
-
-	if ic.serverVersion < mMIN_SERVER_VER_FUNDAMENTAL_DATA {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support fundamental data request.")
-		return
-	}
-
-	if ic.serverVersion < mMIN_SERVER_VER_TRADING_CLASS {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support conId parameter in reqFundamentalData.")
-		return
-	}
-
-	// v := 2
-	const v = 2
-	fields := make([]interface{}, 0, 12)
-	fields = append(fields, mREQ_FUNDAMENTAL_DATA, v, reqID)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
-		fields = append(fields, contract.ContractID)
-	}
-
-	fields = append(fields,
-		contract.Symbol,
-		contract.SecurityType,
-		contract.Exchange,
-		contract.PrimaryExchange,
-		contract.Currency,
-		contract.LocalSymbol,
-		reportType)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_LINKING {
-		var fundamentalDataOptionsBuffer bytes.Buffer
-		for _, tv := range fundamentalDataOptions {
-			fundamentalDataOptionsBuffer.WriteString(tv.Tag)
-			fundamentalDataOptionsBuffer.WriteString("=")
-			fundamentalDataOptionsBuffer.WriteString(tv.Value)
-			fundamentalDataOptionsBuffer.WriteString(";")
-		}
-		fields = append(fields, fundamentalDataOptionsBuffer.Bytes())
-
-	}
-
-	msg := makeMsgBytes(fields...)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqFundamentalData(ic.serverVersion, reqID, contract, reportType, fundamentalDataOptions))
 }
 
 // CancelFundamentalData cancel fundamental data.
 func (ic *IbClient) CancelFundamentalData(reqID int64) {
-	if ic.serverVersion < mMIN_SERVER_VER_FUNDAMENTAL_DATA {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support fundamental data request.")
-		return
-	}
-
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mCANCEL_FUNDAMENTAL_DATA, v, reqID)
-
-	ic.reqChan <- msg
-
+	ic.send(EncodeCancelFundamentalData(ic.serverVersion, reqID))
 }
 
 /*
@@ -2543,77 +1283,17 @@ func (ic *IbClient) CancelFundamentalData(reqID int64) {
 
 // ReqNewsProviders request news providers.
 func (ic *IbClient) ReqNewsProviders() {
-	if ic.serverVersion < mMIN_SERVER_VER_REQ_NEWS_PROVIDERS {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+" It does not support news providers request.")
-		return
-	}
-
-	msg := makeMsgBytes(mREQ_NEWS_PROVIDERS)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqNewsProviders(ic.serverVersion))
 }
 
 // ReqNewsArticle request news article.
 func (ic *IbClient) ReqNewsArticle(reqID int64, providerCode string, articleID string, newsArticleOptions []TagValue) {
-	if ic.serverVersion < mMIN_SERVER_VER_REQ_NEWS_ARTICLE {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support news article request.")
-		return
-	}
-
-	fields := make([]interface{}, 0, 5)
-	fields = append(fields,
-		mREQ_NEWS_ARTICLE,
-		reqID,
-		providerCode,
-		articleID)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_NEWS_QUERY_ORIGINS {
-		var newsArticleOptionsBuffer bytes.Buffer
-		for _, tv := range newsArticleOptions {
-			newsArticleOptionsBuffer.WriteString(tv.Tag)
-			newsArticleOptionsBuffer.WriteString("=")
-			newsArticleOptionsBuffer.WriteString(tv.Value)
-			newsArticleOptionsBuffer.WriteString(";")
-		}
-		fields = append(fields, newsArticleOptionsBuffer.Bytes())
-
-	}
-	msg := makeMsgBytes(fields...)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqNewsArticle(ic.serverVersion, reqID, providerCode, articleID, newsArticleOptions))
 }
 
 // ReqHistoricalNews request historical news.
 func (ic *IbClient) ReqHistoricalNews(reqID int64, contractID int64, providerCode string, startDateTime string, endDateTime string, totalResults int64, historicalNewsOptions []TagValue) {
-	if ic.serverVersion < mMIN_SERVER_VER_REQ_HISTORICAL_NEWS {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support historical news request.")
-		return
-	}
-
-	fields := make([]interface{}, 0, 8)
-	fields = append(fields,
-		mREQ_HISTORICAL_NEWS,
-		reqID,
-		contractID,
-		providerCode,
-		startDateTime,
-		endDateTime,
-		totalResults)
-
-	if ic.serverVersion >= mMIN_SERVER_VER_NEWS_QUERY_ORIGINS {
-		var historicalNewsOptionsBuffer bytes.Buffer
-		for _, tv := range historicalNewsOptions {
-			historicalNewsOptionsBuffer.WriteString(tv.Tag)
-			historicalNewsOptionsBuffer.WriteString("=")
-			historicalNewsOptionsBuffer.WriteString(tv.Value)
-			historicalNewsOptionsBuffer.WriteString(";")
-		}
-		fields = append(fields, historicalNewsOptionsBuffer.Bytes())
-
-	}
-	msg := makeMsgBytes(fields...)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqHistoricalNews(ic.serverVersion, reqID, contractID, providerCode, startDateTime, endDateTime, totalResults, historicalNewsOptions))
 }
 
 /*
@@ -2624,16 +1304,7 @@ func (ic *IbClient) ReqHistoricalNews(reqID int64, contractID int64, providerCod
 
 // QueryDisplayGroups request the display groups in TWS.
 func (ic *IbClient) QueryDisplayGroups(reqID int64) {
-	if ic.serverVersion < mMIN_SERVER_VER_LINKING {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support queryDisplayGroups request.")
-		return
-	}
-
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mQUERY_DISPLAY_GROUPS, v, reqID)
-
-	ic.reqChan <- msg
+	ic.send(EncodeQueryDisplayGroups(ic.serverVersion, reqID))
 }
 
 // SubscribeToGroupEvents subcribe the group events.
@@ -2647,16 +1318,7 @@ call this func to subcribe the group event which is triggered by TWS
 	This is the display group subscription request sent by the API to TWS.
 */
 func (ic *IbClient) SubscribeToGroupEvents(reqID int64, groupID int) {
-	if ic.serverVersion < mMIN_SERVER_VER_LINKING {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support subscribeToGroupEvents request.")
-		return
-	}
-
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mSUBSCRIBE_TO_GROUP_EVENTS, v, reqID, groupID)
-
-	ic.reqChan <- msg
+	ic.send(EncodeSubscribeToGroupEvents(ic.serverVersion, reqID, groupID))
 }
 
 // UpdateDisplayGroup update the display group in TWS.
@@ -2674,30 +1336,12 @@ Possible values include:
 	combo = if any combo is selected.
 */
 func (ic *IbClient) UpdateDisplayGroup(reqID int64, contractInfo string) {
-	if ic.serverVersion < mMIN_SERVER_VER_LINKING {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support updateDisplayGroup request.")
-		return
-	}
-
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mUPDATE_DISPLAY_GROUP, v, reqID, contractInfo)
-
-	ic.reqChan <- msg
+	ic.send(EncodeUpdateDisplayGroup(ic.serverVersion, reqID, contractInfo))
 }
 
 // UnsubscribeFromGroupEvents unsubcribe the display group events.
 func (ic *IbClient) UnsubscribeFromGroupEvents(reqID int64) {
-	if ic.serverVersion < mMIN_SERVER_VER_LINKING {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support unsubscribeFromGroupEvents request.")
-		return
-	}
-
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mUPDATE_DISPLAY_GROUP, v, reqID)
-
-	ic.reqChan <- msg
+	ic.send(EncodeUnsubscribeFromGroupEvents(ic.serverVersion, reqID))
 }
 
 // VerifyRequest is just for IB's internal use.
@@ -2706,22 +1350,14 @@ For IB's internal purpose. Allows to provide means of verification
 between the TWS and third party programs.
 */
 func (ic *IbClient) VerifyRequest(apiName string, apiVersion string) {
-	if ic.serverVersion < mMIN_SERVER_VER_LINKING {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support verification request.")
-		return
-	}
-
-	if ic.extraAuth {
+	msg, err := EncodeVerifyRequest(ic.serverVersion, apiName, apiVersion)
+	if err == nil && ic.extraAuth {
 		ic.wrapper.Error(NO_VALID_ID, BAD_MESSAGE.code, BAD_MESSAGE.msg+
 			"  Intent to authenticate needs to be expressed during initial connect request.")
 		return
 	}
 
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mVERIFY_REQUEST, v, apiName, apiVersion)
-
-	ic.reqChan <- msg
+	ic.send(msg, err)
 }
 
 // VerifyMessage is just for IB's internal use.
@@ -2730,16 +1366,7 @@ For IB's internal purpose. Allows to provide means of verification
 between the TWS and third party programs.
 */
 func (ic *IbClient) VerifyMessage(apiData string) {
-	if ic.serverVersion < mMIN_SERVER_VER_LINKING {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support verification request.")
-		return
-	}
-
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mVERIFY_MESSAGE, v, apiData)
-
-	ic.reqChan <- msg
+	ic.send(EncodeVerifyMessage(ic.serverVersion, apiData))
 }
 
 // VerifyAndAuthRequest is just for IB's internal use.
@@ -2748,22 +1375,14 @@ For IB's internal purpose. Allows to provide means of verification
 between the TWS and third party programs.
 */
 func (ic *IbClient) VerifyAndAuthRequest(apiName string, apiVersion string, opaqueIsvKey string) {
-	if ic.serverVersion < mMIN_SERVER_VER_LINKING {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support verification request.")
-		return
-	}
-
-	if ic.extraAuth {
+	msg, err := EncodeVerifyAndAuthRequest(ic.serverVersion, apiName, apiVersion, opaqueIsvKey)
+	if err == nil && ic.extraAuth {
 		ic.wrapper.Error(NO_VALID_ID, BAD_MESSAGE.code, BAD_MESSAGE.msg+
 			"  Intent to authenticate needs to be expressed during initial connect request.")
 		return
 	}
 
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mVERIFY_AND_AUTH_REQUEST, v, apiName, apiVersion, opaqueIsvKey)
-
-	ic.reqChan <- msg
+	ic.send(msg, err)
 }
 
 // VerifyAndAuthMessage is just for IB's internal use.
@@ -2772,16 +1391,7 @@ For IB's internal purpose. Allows to provide means of verification
 between the TWS and third party programs.
 */
 func (ic *IbClient) VerifyAndAuthMessage(apiData string, xyzResponse string) {
-	if ic.serverVersion < mMIN_SERVER_VER_LINKING {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support verification request.")
-		return
-	}
-
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mVERIFY_MESSAGE, v, apiData, xyzResponse)
-
-	ic.reqChan <- msg
+	ic.send(EncodeVerifyAndAuthMessage(ic.serverVersion, apiData, xyzResponse))
 }
 
 // ReqSecDefOptParams request security definition option parameters.
@@ -2794,14 +1404,7 @@ i.e. STK underlyingConId the contract ID of the underlying security.
 Response comes via wrapper.SecurityDefinitionOptionParameter()
 */
 func (ic *IbClient) ReqSecDefOptParams(reqID int64, underlyingSymbol string, futFopExchange string, underlyingSecurityType string, underlyingContractID int64) {
-	if ic.serverVersion < mMIN_SERVER_VER_SEC_DEF_OPT_PARAMS_REQ {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support security definition option request.")
-		return
-	}
-
-	msg := makeMsgBytes(mREQ_SEC_DEF_OPT_PARAMS, reqID, underlyingSymbol, futFopExchange, underlyingSecurityType, underlyingContractID)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqSecDefOptParams(ic.serverVersion, reqID, underlyingSymbol, futFopExchange, underlyingSecurityType, underlyingContractID))
 }
 
 // ReqSoftDollarTiers request pre-defined Soft Dollar Tiers.
@@ -2810,42 +1413,22 @@ This is only supported for registered professional advisors and hedge and mutual
 who have configured Soft Dollar Tiers in Account Management.
 */
 func (ic *IbClient) ReqSoftDollarTiers(reqID int64) {
-	msg := makeMsgBytes(mREQ_SOFT_DOLLAR_TIERS, reqID)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqSoftDollarTiers(ic.serverVersion, reqID))
 }
 
 // ReqFamilyCodes request family codes.
 func (ic *IbClient) ReqFamilyCodes() {
-	if ic.serverVersion < mMIN_SERVER_VER_REQ_FAMILY_CODES {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support family codes request.")
-		return
-	}
-
-	msg := makeMsgBytes(mREQ_FAMILY_CODES)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqFamilyCodes(ic.serverVersion))
 }
 
 // ReqMatchingSymbols request matching symbols.
 func (ic *IbClient) ReqMatchingSymbols(reqID int64, pattern string) {
-	if ic.serverVersion < mMIN_SERVER_VER_REQ_MATCHING_SYMBOLS {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support matching symbols request.")
-		return
-	}
-
-	msg := makeMsgBytes(mREQ_MATCHING_SYMBOLS, reqID, pattern)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqMatchingSymbols(ic.serverVersion, reqID, pattern))
 }
 
 // ReqCurrentTime request the current system time on the server side.
 func (ic *IbClient) ReqCurrentTime() {
-	// v := 1
-	const v = 1
-	msg := makeMsgBytes(mREQ_CURRENT_TIME, v)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqCurrentTime(ic.serverVersion))
 }
 
 // ReqCompletedOrders request the completed orders
@@ -2854,137 +1437,125 @@ If apiOnly parameter is true, then only completed orders placed from API are req
 Result will be delivered via wrapper.CompletedOrder().
 */
 func (ic *IbClient) ReqCompletedOrders(apiOnly bool) {
-	msg := makeMsgBytes(mREQ_COMPLETED_ORDERS, apiOnly)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqCompletedOrders(ic.serverVersion, apiOnly))
 }
 
+// ReqWshMetaData requests the Wall Street Horizon meta data, such as the event types and filters.
+// Result will be delivered via wrapper.WshMetaData().
 func (ic *IbClient) ReqWshMetaData(reqID int64) {
-	if ic.serverVersion < mMIN_SERVER_VER_WSHE_CALENDAR {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support WSHE Calendar API.")
-		return
-	}
-
-	msg := makeMsgBytes(mREQ_WSH_META_DATA, reqID)
-
-	ic.reqChan <- msg
+	ic.send(EncodeReqWshMetaData(ic.serverVersion, reqID))
 }
 
+// CancelWshMetaData cancels the request of the Wall Street Horizon meta data.
 func (ic *IbClient) CancelWshMetaData(reqID int64) {
-	if ic.serverVersion < mMIN_SERVER_VER_WSHE_CALENDAR {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support WSHE Calendar API.")
-		return
-	}
-
-	msg := makeMsgBytes(mCANCEL_WSH_META_DATA, reqID)
-
-	ic.reqChan <- msg
+	ic.send(EncodeCancelWshMetaData(ic.serverVersion, reqID))
 }
 
+// ReqWshEventData requests the Wall Street Horizon events of the contract, see ReqWshEventDataWith for the filters.
 func (ic *IbClient) ReqWshEventData(reqID int64, conID int64) {
-	if ic.serverVersion < mMIN_SERVER_VER_WSHE_CALENDAR {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support WSHE Calendar API.")
-		return
-	}
-
-	msg := makeMsgBytes(mREQ_WSH_META_DATA, reqID, conID)
+	wshEventData := NewWshEventData()
+	wshEventData.ConID = conID
+	ic.ReqWshEventDataWith(reqID, wshEventData)
+}
 
-	ic.reqChan <- msg
+// ReqWshEventDataWith requests the Wall Street Horizon events matched wshEventData.
+/*
+The events of ConID are requested, or the events matched the Filter on the newer servers,
+such as the events of the watchlist, portfolio or competitors in the date range.
+Result will be delivered via wrapper.WshEventData().
+*/
+func (ic *IbClient) ReqWshEventDataWith(reqID int64, wshEventData *WshEventData) {
+	ic.send(EncodeReqWshEventDataWith(ic.serverVersion, reqID, wshEventData))
 }
 
+// CancelWshEventData cancels the request of the Wall Street Horizon events.
 func (ic *IbClient) CancelWshEventData(reqID int64) {
-	if ic.serverVersion < mMIN_SERVER_VER_WSHE_CALENDAR {
-		ic.wrapper.Error(NO_VALID_ID, UPDATE_TWS.code, UPDATE_TWS.msg+"  It does not support WSHE Calendar API.")
-		return
-	}
-
-	msg := makeMsgBytes(mCANCEL_WSH_EVENT_DATA, reqID)
+	ic.send(EncodeCancelWshEventData(ic.serverVersion, reqID))
+}
 
-	ic.reqChan <- msg
+// ReqUserInfo requests the user info of the logged in user, such as the white branding ID.
+// Result will be delivered via wrapper.UserInfo().
+func (ic *IbClient) ReqUserInfo(reqID int64) {
+	ic.send(EncodeReqUserInfo(ic.serverVersion, reqID))
 }
+
 //--------------------------three major goroutine -----------------------------------------------------
 /*
 1.goReceive scan a whole msg bytes and put it into msgChan
 2.goDecode gets the msg bytes from msgChan and decode the msg, callback wrapper
 3.goRequest create a select loop to get request from reqChan and send it to tws or ib gateway
+The panics in them, such as of the wrapper callbacks, are not recovered.
+The malformed msgs are reported to the DecodeErrorHandler, and the socket errors disconnect the client.
 */
 
 //goRequest will get the req from reqChan and send it to TWS
 func (ic *IbClient) goRequest() {
 	log.Debug("requester start")
-	defer func() {
-		if errMsg := recover(); errMsg != nil {
-			err := errors.New(errMsg.(string))
-			log.Error("requester got unexpected error", zap.Error(err))
-			ic.err = err
-			// ic.Disconnect()
-			log.Debug("try to restart requester")
-			go ic.goRequest()
-		}
-	}()
-	defer log.Debug("requester end")
+	terminated := ic.terminatedSignal
 	defer ic.wg.Done()
-
-	ic.wg.Add(1)
+	defer log.Debug("requester end")
 
 requestLoop:
 	for {
 		select {
 		case req := <-ic.reqChan:
-			if !ic.IsConnected() {
-				ic.wrapper.Error(NO_VALID_ID, NOT_CONNECTED.code, NOT_CONNECTED.msg)
-				break
-			}
-
-			nn, err := ic.writer.Write(req)
-			err = ic.writer.Flush()
-			if err != nil {
-				log.Error("write req error", zap.Int("nbytes", nn), zap.Binary("reqMsg", req), zap.Error(err))
-				ic.writer.Reset(ic.conn)
-				ic.errChan <- err
+			if !ic.writeReq(req) {
+				ic.failedReqs.add(req)
 			}
-		case <-ic.terminatedSignal:
+			ic.reqDone()
+		case <-terminated:
 			break requestLoop
 		}
 	}
 
 }
 
+// writeReq writes the req to TWS, it returns false if the req is not sent
+func (ic *IbClient) writeReq(req []byte) bool {
+	if !ic.IsConnected() {
+		ic.wrapper.Error(NO_VALID_ID, NOT_CONNECTED.code, NOT_CONNECTED.msg)
+		return false
+	}
+
+	nn, err := ic.writer.Write(req)
+	err = ic.writer.Flush()
+	if err != nil {
+		log.Error("write req error", zap.Int("nbytes", nn), zap.Binary("reqMsg", req), zap.Error(err))
+		ic.writer.Reset(ic.conn)
+		ic.errChan <- err
+		return false
+	}
+	ic.conn.msgSent()
+	return true
+}
+
 //goReceive receive the msg from the socket, get the fields and put them into msgChan
 //goReceive handle the msgBuf which is different from the offical.Not continuously read, but split first and then decode
 func (ic *IbClient) goReceive() {
 	log.Debug("receiver start")
+	terminated := ic.terminatedSignal
+	defer ic.wg.Done()
 	defer func() {
-		if errMsg := recover(); errMsg != nil {
-			err := errors.New(errMsg.(string))
-			log.Error("receiver got unexpected error", zap.Error(err))
-			ic.err = err
-			// ic.Disconnect()
-			log.Debug("try to restart receiver")
-			go ic.goReceive()
-		} else {
-			select {
-			case <-ic.terminatedSignal:
-			default:
-				ic.Disconnect()
-			}
+		select {
+		case <-terminated:
+		default:
+			// Disconnect waits for the receiver, so it could not be called before wg.Done
+			go ic.Disconnect()
 		}
 	}()
 	defer log.Debug("receiver end")
-	defer ic.wg.Done()
-
-	ic.wg.Add(1)
 
 	for ic.scanner.Scan() {
 		// msgChan has buffer size, so copy here to avoid underlying arrar being overwritten
 		// or we can just set the msgChan without size so that it's no need to copy, but might block the receiver because of slow consumer
 		msgBytes := make([]byte, len(ic.scanner.Bytes()))
 		copy(msgBytes, ic.scanner.Bytes())
+		ic.conn.msgRecv()
 		ic.msgChan <- msgBytes
 	}
 
 	select {
-	case <-ic.terminatedSignal:
+	case <-terminated:
 	default:
 		switch err := ic.scanner.Err(); err {
 		case nil:
@@ -2993,9 +1564,11 @@ func (ic *IbClient) goReceive() {
 		case bufio.ErrTooLong:
 			errBytes := ic.scanner.Bytes()
 			ic.wrapper.Error(NO_VALID_ID, BAD_LENGTH.code, fmt.Sprintf("%s:%d:%s", BAD_LENGTH.msg, len(errBytes), errBytes))
-			log.Panic(BAD_LENGTH.msg, zap.Error(err))
+			log.Error(BAD_LENGTH.msg, zap.Error(err))
+			ic.setErr(err)
 		default:
-			log.Panic("scanner Error", zap.Error(err))
+			log.Error("scanner Error", zap.Error(err))
+			ic.setErr(err)
 		}
 	}
 
@@ -3004,31 +1577,22 @@ func (ic *IbClient) goReceive() {
 //goDecode decode the fields received from the msgChan
 func (ic *IbClient) goDecode() {
 	log.Debug("decoder start")
-	defer func() {
-		if errMsg := recover(); errMsg != nil {
-			err := errors.New(errMsg.(string))
-			log.Error("decoder got unexpected error", zap.Error(err))
-			ic.err = err
-			// ic.Disconnect()
-			log.Debug("try to restart decoder")
-			go ic.goDecode()
-		}
-	}()
-	defer log.Debug("decoder end")
+	terminated := ic.terminatedSignal
 	defer ic.wg.Done()
-
-	ic.wg.Add(1)
+	defer log.Debug("decoder end")
 
 decodeLoop:
 	for {
 		select {
 		case m := <-ic.msgChan:
-			ic.decoder.interpret(m)
+			if err := ic.decoder.interpret(m); err != nil {
+				ic.handleDecodeError(err)
+			}
 		case e := <-ic.errChan:
 			log.Error("got client error in decode loop", zap.Error(e))
 		// case e := <-ic.decoder.errChan:
 		// 	ic.wrapper.Error(NO_VALID_ID, BAD_MESSAGE.code, BAD_MESSAGE.msg+e.Error())
-		case <-ic.terminatedSignal:
+		case <-terminated:
 			break decodeLoop
 		}
 	}
@@ -3047,6 +1611,7 @@ func (ic *IbClient) Run() error {
 	}
 	log.Info("run client")
 
+	ic.wg.Add(2)
 	go ic.goRequest()
 	go ic.goDecode()
 
@@ -3069,7 +1634,7 @@ func (ic *IbClient) LoopUntilDone(fs ...func()) error {
 
 	select {
 	case <-ic.done:
-		return ic.err
+		return ic.getErr()
 	}
 
 }
@@ -2,6 +2,7 @@ package ibapi
 
 import (
 	"fmt"
+	"time"
 )
 
 // Account ...
@@ -103,19 +104,25 @@ type ExecutionFilter struct {
 }
 
 // BarData ...
+/*
+Date is the raw date from TWS, which is yyyymmdd for the daily bars,
+"yyyymmdd  hh:mm:ss[ tz]" or the epoch seconds for the intraday bars, according to formatDate.
+Time is decoded from Date, see BarTime.
+*/
 type BarData struct {
 	Date     string
+	Time     time.Time
 	Open     float64
 	High     float64
 	Low      float64
 	Close    float64
-	Volume   float64
+	Volume   Decimal
 	BarCount int64
 	Average  float64
 }
 
 func (b BarData) String() string {
-	return fmt.Sprintf("BarData<Date: %s, Open: %f, High: %f, Low: %f, Close: %f, Volume: %f, Average: %f, BarCount: %d>",
+	return fmt.Sprintf("BarData<Date: %s, Open: %f, High: %f, Low: %f, Close: %f, Volume: %s, Average: %f, BarCount: %d>",
 		b.Date,
 		b.Open,
 		b.High,
@@ -134,13 +141,18 @@ type RealTimeBar struct {
 	High    float64
 	Low     float64
 	Close   float64
-	Volume  int64
+	Volume  Decimal
 	Wap     float64
 	Count   int64
 }
 
+// Timestamp returns Time, the start of the bar, as time.Time
+func (rb RealTimeBar) Timestamp() time.Time {
+	return time.Unix(rb.Time, 0)
+}
+
 func (rb RealTimeBar) String() string {
-	return fmt.Sprintf("RealTimeBar<Time: %d, Open: %f, High: %f, Low: %f, Close: %f, Volume: %d, Wap: %f, Count: %d>",
+	return fmt.Sprintf("RealTimeBar<Time: %d, Open: %f, High: %f, Low: %f, Close: %f, Volume: %s, Wap: %f, Count: %d>",
 		rb.Time,
 		rb.Open,
 		rb.High,
@@ -236,11 +248,11 @@ func (np NewsProvider) String() string {
 // HistogramData ...
 type HistogramData struct {
 	Price float64
-	Count int64
+	Count Decimal
 }
 
 func (hgd HistogramData) String() string {
-	return fmt.Sprintf("HistogramData<Price: %f, Count: %d>",
+	return fmt.Sprintf("HistogramData<Price: %f, Count: %s>",
 		hgd.Price,
 		hgd.Count)
 }
@@ -262,11 +274,16 @@ func (p PriceIncrement) String() string {
 type HistoricalTick struct {
 	Time  int64
 	Price float64
-	Size  int64
+	Size  Decimal
+}
+
+// Timestamp returns Time as time.Time
+func (h HistoricalTick) Timestamp() time.Time {
+	return time.Unix(h.Time, 0)
 }
 
 func (h HistoricalTick) String() string {
-	return fmt.Sprintf("Tick<Time: %d, Price: %f, Size: %d>",
+	return fmt.Sprintf("Tick<Time: %d, Price: %f, Size: %s>",
 		h.Time,
 		h.Price,
 		h.Size)
@@ -279,12 +296,17 @@ type HistoricalTickBidAsk struct {
 	TickAttirbBidAsk TickAttribBidAsk
 	PriceBid         float64
 	PriceAsk         float64
-	SizeBid          int64
-	SizeAsk          int64
+	SizeBid          Decimal
+	SizeAsk          Decimal
+}
+
+// Timestamp returns Time as time.Time
+func (h HistoricalTickBidAsk) Timestamp() time.Time {
+	return time.Unix(h.Time, 0)
 }
 
 func (h HistoricalTickBidAsk) String() string {
-	return fmt.Sprintf("TickBidAsk<Time: %d, TickAttriBidAsk: %s, PriceBid: %f, PriceAsk: %f, SizeBid: %d, SizeAsk: %d>",
+	return fmt.Sprintf("TickBidAsk<Time: %d, TickAttriBidAsk: %s, PriceBid: %f, PriceAsk: %f, SizeBid: %s, SizeAsk: %s>",
 		h.Time,
 		h.TickAttirbBidAsk,
 		h.PriceBid,
@@ -311,13 +333,18 @@ type HistoricalTickLast struct {
 	Time              int64
 	TickAttribLast    TickAttribLast
 	Price             float64
-	Size              int64
+	Size              Decimal
 	Exchange          string
 	SpecialConditions string
 }
 
+// Timestamp returns Time as time.Time
+func (h HistoricalTickLast) Timestamp() time.Time {
+	return time.Unix(h.Time, 0)
+}
+
 func (h HistoricalTickLast) String() string {
-	return fmt.Sprintf("TickLast<Time: %d, TickAttribLast: %s, Price: %f, Size: %d, Exchange: %s, SpecialConditions: %s>",
+	return fmt.Sprintf("TickLast<Time: %d, TickAttribLast: %s, Price: %f, Size: %s, Exchange: %s, SpecialConditions: %s>",
 		h.Time,
 		h.TickAttribLast,
 		h.Price,
@@ -337,3 +364,17 @@ func (t TickAttribLast) String() string {
 		t.PastLimit,
 		t.Unreported)
 }
+
+// HistoricalSession is the trading session of the historical schedule, see ReqHistoricalSchedule
+type HistoricalSession struct {
+	StartDateTime string
+	EndDateTime   string
+	RefDate       string
+}
+
+func (h HistoricalSession) String() string {
+	return fmt.Sprintf("HistoricalSession<StartDateTime: %s, EndDateTime: %s, RefDate: %s>",
+		h.StartDateTime,
+		h.EndDateTime,
+		h.RefDate)
+}
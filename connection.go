@@ -3,30 +3,39 @@
 package ibapi
 
 import (
+	"fmt"
 	"net"
 	"strconv"
+	"sync/atomic"
+	"time"
 
 	"go.uber.org/zap"
 )
 
-// IbConnection wrap the tcp connection with TWS or Gateway
+// IbConnection wrap the tcp connection with TWS or Gateway.
+// The state and the counters are accessed atomically, they could be read from any goroutine.
 type IbConnection struct {
+	// the 64-bit atomic fields go first to keep them aligned on 32-bit platforms
+	numBytesSent int64
+	numMsgSent   int64
+	numBytesRecv int64
+	numMsgRecv   int64
+	lastSent     int64 // unix nano of the last msg sent
+	lastRecv     int64 // unix nano of the last msg received
+	connectedAt  int64 // unix nano of the last CONNECTED, 0 if not connected
+	numConnects  int64 // never reset, the connections after the first are reconnections
+
 	*net.TCPConn
-	host         string
-	port         int
-	clientID     int64
-	state        int
-	numBytesSent int
-	numMsgSent   int
-	numBytesRecv int
-	numMsgRecv   int
+	host     string
+	port     int
+	clientID int64
+	state    int32
 }
 
 func (ibconn *IbConnection) Write(bs []byte) (int, error) {
 	n, err := ibconn.TCPConn.Write(bs)
 
-	ibconn.numBytesSent += n
-	ibconn.numMsgSent++
+	atomic.AddInt64(&ibconn.numBytesSent, int64(n))
 
 	log.Debug("conn write", zap.Int("nBytes", n))
 
@@ -36,31 +45,57 @@ func (ibconn *IbConnection) Write(bs []byte) (int, error) {
 func (ibconn *IbConnection) Read(bs []byte) (int, error) {
 	n, err := ibconn.TCPConn.Read(bs)
 
-	ibconn.numBytesRecv += n
-	ibconn.numMsgRecv++
+	atomic.AddInt64(&ibconn.numBytesRecv, int64(n))
 
 	log.Debug("conn read", zap.Int("nBytes", n))
 
 	return n, err
 }
 
-func (ibconn *IbConnection) setState(state int) {
-	ibconn.state = state
+// msgSent counts a msg written to TWS
+func (ibconn *IbConnection) msgSent() {
+	atomic.AddInt64(&ibconn.numMsgSent, 1)
+	atomic.StoreInt64(&ibconn.lastSent, time.Now().UnixNano())
+}
+
+// msgRecv counts a msg received from TWS
+func (ibconn *IbConnection) msgRecv() {
+	atomic.AddInt64(&ibconn.numMsgRecv, 1)
+	atomic.StoreInt64(&ibconn.lastRecv, time.Now().UnixNano())
+}
+
+func (ibconn *IbConnection) getState() int {
+	return int(atomic.LoadInt32(&ibconn.state))
+}
+
+// setState sets the state and returns the previous one
+func (ibconn *IbConnection) setState(state int) int {
+	prev := int(atomic.SwapInt32(&ibconn.state, int32(state)))
+	switch {
+	case state == CONNECTED && prev != CONNECTED:
+		atomic.StoreInt64(&ibconn.connectedAt, time.Now().UnixNano())
+		atomic.AddInt64(&ibconn.numConnects, 1)
+	case state != CONNECTED:
+		atomic.StoreInt64(&ibconn.connectedAt, 0)
+	}
+	return prev
 }
 
 func (ibconn *IbConnection) reset() {
-	ibconn.numBytesSent = 0
-	ibconn.numBytesRecv = 0
-	ibconn.numMsgSent = 0
-	ibconn.numMsgRecv = 0
+	atomic.StoreInt64(&ibconn.numBytesSent, 0)
+	atomic.StoreInt64(&ibconn.numBytesRecv, 0)
+	atomic.StoreInt64(&ibconn.numMsgSent, 0)
+	atomic.StoreInt64(&ibconn.numMsgRecv, 0)
+	atomic.StoreInt64(&ibconn.lastSent, 0)
+	atomic.StoreInt64(&ibconn.lastRecv, 0)
 }
 
 func (ibconn *IbConnection) disconnect() error {
 	log.Debug("conn disconnect",
-		zap.Int("nMsgSent", ibconn.numMsgSent),
-		zap.Int("nBytesSent", ibconn.numBytesSent),
-		zap.Int("nMsgRecv", ibconn.numMsgRecv),
-		zap.Int("nBytesRecv", ibconn.numBytesRecv),
+		zap.Int64("nMsgSent", atomic.LoadInt64(&ibconn.numMsgSent)),
+		zap.Int64("nBytesSent", atomic.LoadInt64(&ibconn.numBytesSent)),
+		zap.Int64("nMsgRecv", atomic.LoadInt64(&ibconn.numMsgRecv)),
+		zap.Int64("nBytesRecv", atomic.LoadInt64(&ibconn.numBytesRecv)),
 	)
 	return ibconn.Close()
 }
@@ -87,3 +122,105 @@ func (ibconn *IbConnection) connect(host string, port int) error {
 
 	return err
 }
+
+// unixNanoTime is the time of the unix nano, the zero time for 0
+func unixNanoTime(nsec int64) time.Time {
+	if nsec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nsec)
+}
+
+// ConnStats is a snapshot of the statistics of the connection.
+/*
+The bytes and msgs are counted since the last Connect, including the handshake.
+ReqChanLen and MsgChanLen are the requests waiting to be sent and the msgs waiting to be decoded.
+Uptime is 0 if not connected, and Reconnects counts the connections after the first one of IbClient.
+*/
+type ConnStats struct {
+	State      int
+	BytesSent  int64
+	BytesRecv  int64
+	MsgsSent   int64
+	MsgsRecv   int64
+	ReqChanLen int
+	ReqChanCap int
+	MsgChanLen int
+	MsgChanCap int
+	LastSent   time.Time
+	LastRecv   time.Time
+	Uptime     time.Duration
+	Reconnects int64
+}
+
+func (s ConnStats) String() string {
+	return fmt.Sprintf("ConnStats<State: %d, BytesSent: %d, BytesRecv: %d, MsgsSent: %d, MsgsRecv: %d, ReqChan: %d/%d, MsgChan: %d/%d, Uptime: %s, Reconnects: %d>",
+		s.State, s.BytesSent, s.BytesRecv, s.MsgsSent, s.MsgsRecv, s.ReqChanLen, s.ReqChanCap, s.MsgChanLen, s.MsgChanCap, s.Uptime, s.Reconnects)
+}
+
+// Stats returns the statistics of the connection, it is safe to call from any goroutine
+func (ic *IbClient) Stats() ConnStats {
+	conn := ic.conn
+	stats := ConnStats{
+		State:      conn.getState(),
+		BytesSent:  atomic.LoadInt64(&conn.numBytesSent),
+		BytesRecv:  atomic.LoadInt64(&conn.numBytesRecv),
+		MsgsSent:   atomic.LoadInt64(&conn.numMsgSent),
+		MsgsRecv:   atomic.LoadInt64(&conn.numMsgRecv),
+		ReqChanLen: len(ic.reqChan),
+		ReqChanCap: cap(ic.reqChan),
+		MsgChanLen: len(ic.msgChan),
+		MsgChanCap: cap(ic.msgChan),
+		LastSent:   unixNanoTime(atomic.LoadInt64(&conn.lastSent)),
+		LastRecv:   unixNanoTime(atomic.LoadInt64(&conn.lastRecv)),
+	}
+
+	if connectedAt := atomic.LoadInt64(&conn.connectedAt); connectedAt != 0 {
+		stats.Uptime = time.Since(time.Unix(0, connectedAt))
+	}
+	if n := atomic.LoadInt64(&conn.numConnects); n > 1 {
+		stats.Reconnects = n - 1
+	}
+
+	return stats
+}
+
+// ConnStateChange is a change of the ConnState
+type ConnStateChange struct {
+	Prev int
+	Curr int
+	Time time.Time
+}
+
+// SubscribeConnState registers f to be called on every change of the ConnState, in the goroutine changing it, so f must not block.
+// Call the returned func to unsubscribe.
+func (ic *IbClient) SubscribeConnState(f func(ConnStateChange)) (unsubscribe func()) {
+	ic.connStateMu.Lock()
+	if ic.connStateSubs == nil {
+		ic.connStateSubs = make(map[int]func(ConnStateChange))
+	}
+	id := ic.connStateSeq
+	ic.connStateSeq++
+	ic.connStateSubs[id] = f
+	ic.connStateMu.Unlock()
+
+	return func() {
+		ic.connStateMu.Lock()
+		delete(ic.connStateSubs, id)
+		ic.connStateMu.Unlock()
+	}
+}
+
+// notifyConnState calls the subscribers of SubscribeConnState
+func (ic *IbClient) notifyConnState(change ConnStateChange) {
+	ic.connStateMu.Lock()
+	subscribers := make([]func(ConnStateChange), 0, len(ic.connStateSubs))
+	for _, f := range ic.connStateSubs {
+		subscribers = append(subscribers, f)
+	}
+	ic.connStateMu.Unlock()
+
+	for _, f := range subscribers {
+		f(change)
+	}
+}
@@ -1,8 +1,15 @@
 package ibapi
 
 import (
+	"bufio"
+	"encoding/binary"
 	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -19,3 +26,154 @@ func TestConnection(t *testing.T) {
 	fmt.Println(string(buf))
 	conn.disconnect()
 }
+
+// fakeTWS accepts a connection on a local port, answers the handshake with the server version,
+// then nextValidID and managedAccounts, and replies CurrentTime to ReqCurrentTime until the connection is closed.
+// received is called with the msg ID of every request after the handshake, if not nil.
+func fakeTWS(t *testing.T, received func(msgID int64)) (port int, stop func()) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeTWS(conn, received)
+		}
+	}()
+
+	return ln.Addr().(*net.TCPAddr).Port, func() { ln.Close() }
+}
+
+func serveFakeTWS(conn net.Conn, received func(msgID int64)) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	head := make([]byte, 4)
+	if _, err := io.ReadFull(r, head); err != nil || string(head) != "API\x00" {
+		return
+	}
+
+	readMsg := func() ([][]byte, error) {
+		size := make([]byte, 4)
+		if _, err := io.ReadFull(r, size); err != nil {
+			return nil, err
+		}
+		msg := make([]byte, binary.BigEndian.Uint32(size))
+		if _, err := io.ReadFull(r, msg); err != nil {
+			return nil, err
+		}
+		return splitMsgBytes(msg), nil
+	}
+
+	if _, err := readMsg(); err != nil { // client version
+		return
+	}
+	conn.Write(makeMsgBytes(MAX_CLIENT_VER, "20221019 10:00:00 EST"))
+	if _, err := readMsg(); err != nil { // startAPI
+		return
+	}
+	conn.Write(makeMsgBytes(mNEXT_VALID_ID, 1, 1))
+	conn.Write(makeMsgBytes(mMANAGED_ACCTS, 1, "DU123456"))
+
+	for {
+		fields, err := readMsg()
+		if err != nil {
+			return
+		}
+		if received != nil {
+			received(fieldInt(fields[0]))
+		}
+		if fieldInt(fields[0]) == mREQ_CURRENT_TIME {
+			conn.Write(makeMsgBytes(mCURRENT_TIME, 1, time.Now().Unix()))
+		}
+	}
+}
+
+// currentTimeWrapper counts CurrentTime
+type currentTimeWrapper struct {
+	Wrapper
+	n int64
+}
+
+func (w *currentTimeWrapper) CurrentTime(t time.Time) {
+	atomic.AddInt64(&w.n, 1)
+}
+
+func TestConnStats(t *testing.T) {
+	port, stop := fakeTWS(t, nil)
+	defer stop()
+
+	w := &currentTimeWrapper{}
+	ic := NewIbClient(w)
+
+	var mu sync.Mutex
+	var changes []ConnStateChange
+	ic.SubscribeConnState(func(c ConnStateChange) {
+		mu.Lock()
+		changes = append(changes, c)
+		mu.Unlock()
+	})
+
+	// read the state and the stats concurrently with the connection goroutines
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				ic.IsConnected()
+				ic.Stats()
+			}
+		}
+	}()
+
+	for i := 0; i < 2; i++ {
+		if err := ic.Connect("127.0.0.1", port, 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := ic.HandShake(); err != nil {
+			t.Fatal(err)
+		}
+		if err := ic.Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		ic.ReqCurrentTime()
+		waitFor(t, func() bool { return atomic.LoadInt64(&w.n) == int64(i+1) })
+
+		stats := ic.Stats()
+		if stats.State != CONNECTED || stats.MsgsSent != 2 || stats.MsgsRecv != 4 || stats.BytesSent == 0 || stats.BytesRecv == 0 {
+			t.Errorf("unexpected stats %v", stats)
+		}
+		if stats.Uptime <= 0 || stats.LastRecv.IsZero() || stats.Reconnects != int64(i) || stats.ReqChanCap != 10 {
+			t.Errorf("unexpected stats %v", stats)
+		}
+
+		if err := ic.Disconnect(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if stats := ic.Stats(); stats.State != DISCONNECTED || stats.Uptime != 0 || stats.MsgsSent != 0 || stats.Reconnects != 1 {
+		t.Errorf("unexpected stats after Disconnect %v", stats)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []int{CONNECTING, CONNECTED, DISCONNECTED, CONNECTING, CONNECTED, DISCONNECTED}
+	if len(changes) != len(want) {
+		t.Fatalf("unexpected state changes %v", changes)
+	}
+	for i, c := range changes {
+		if c.Curr != want[i] {
+			t.Errorf("change %d: got %d, want %d", i, c.Curr, want[i])
+		}
+	}
+}
@@ -0,0 +1,447 @@
+/*
+connectivity contains ConnectivityMonitor, which tracks the data farms and the link between TWS and IB
+from the system messages and farm warnings reported by Error, and the order hold of IbClient used during the connectivity loss.
+*/
+
+package ibapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// FarmKind is the kind of the data farm
+type FarmKind int
+
+const (
+	FARM_MARKET_DATA FarmKind = iota
+	FARM_HMDS                 // historical market data service
+	FARM_SEC_DEF              // security definition
+)
+
+func (k FarmKind) String() string {
+	switch k {
+	case FARM_MARKET_DATA:
+		return "MARKET_DATA"
+	case FARM_HMDS:
+		return "HMDS"
+	case FARM_SEC_DEF:
+		return "SEC_DEF"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// FarmState is the connection state of a data farm
+type FarmState int
+
+const (
+	FARM_UNKNOWN    FarmState = iota
+	FARM_OK                   // 2104, 2106, 2158
+	FARM_INACTIVE             // 2107, 2108, available upon demand
+	FARM_CONNECTING           // 2119
+	FARM_BROKEN               // 2103, 2105, 2157
+)
+
+func (s FarmState) String() string {
+	switch s {
+	case FARM_OK:
+		return "OK"
+	case FARM_INACTIVE:
+		return "INACTIVE"
+	case FARM_CONNECTING:
+		return "CONNECTING"
+	case FARM_BROKEN:
+		return "BROKEN"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// UpstreamState is the state of the link between TWS and IB, which is assumed to be OK until TWS reports otherwise
+type UpstreamState int
+
+const (
+	UPSTREAM_OK     UpstreamState = iota // 1101, 1102
+	UPSTREAM_LOST                        // 1100
+	UPSTREAM_BROKEN                      // 2110, TWS will restore it automatically
+)
+
+func (s UpstreamState) String() string {
+	switch s {
+	case UPSTREAM_OK:
+		return "OK"
+	case UPSTREAM_LOST:
+		return "LOST"
+	case UPSTREAM_BROKEN:
+		return "BROKEN"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// farmCodes is the kind and the state of the farm reported by the code
+var farmCodes = map[int64]struct {
+	kind  FarmKind
+	state FarmState
+}{
+	2103: {FARM_MARKET_DATA, FARM_BROKEN},
+	2104: {FARM_MARKET_DATA, FARM_OK},
+	2105: {FARM_HMDS, FARM_BROKEN},
+	2106: {FARM_HMDS, FARM_OK},
+	2107: {FARM_HMDS, FARM_INACTIVE},
+	2108: {FARM_MARKET_DATA, FARM_INACTIVE},
+	2119: {FARM_MARKET_DATA, FARM_CONNECTING},
+	2157: {FARM_SEC_DEF, FARM_BROKEN},
+	2158: {FARM_SEC_DEF, FARM_OK},
+}
+
+// parseFarmName takes the farm name from the errString, such as "Market data farm connection is OK:usfarm.nj"
+// or "Market data farm connection is inactive but should be available upon demand.cashfarm"
+func parseFarmName(errString string) string {
+	if i := strings.LastIndex(errString, ":"); i >= 0 {
+		return strings.TrimSpace(errString[i+1:])
+	}
+	if i := strings.LastIndex(errString, "demand."); i >= 0 {
+		return strings.TrimSpace(errString[i+len("demand."):])
+	}
+	return ""
+}
+
+// FarmStatus is the state of a data farm since the last message of it
+type FarmStatus struct {
+	Name  string
+	Kind  FarmKind
+	State FarmState
+	Since time.Time
+}
+
+func (f FarmStatus) String() string {
+	return fmt.Sprintf("FarmStatus<Name: %s, Kind: %s, State: %s, Since: %s>", f.Name, f.Kind, f.State, f.Since.Format(time.RFC3339))
+}
+
+// ConnectivityHealth is a snapshot of the state of the upstream and the data farms, keyed by the farm name
+type ConnectivityHealth struct {
+	Upstream      UpstreamState
+	UpstreamSince time.Time // zero until the first system message
+	Farms         map[string]FarmStatus
+}
+
+// Healthy reports whether the upstream is OK and no data farm is broken, the inactive farms are available upon demand
+func (h ConnectivityHealth) Healthy() bool {
+	if h.Upstream != UPSTREAM_OK {
+		return false
+	}
+	for _, f := range h.Farms {
+		if f.State == FARM_BROKEN {
+			return false
+		}
+	}
+	return true
+}
+
+// ConnectivityEvent is a change of the upstream or a data farm reported by Error.
+// Farm is "" for the upstream, and DataLost is true for 1101, after which the market data should be requested again.
+type ConnectivityEvent struct {
+	Time      time.Time
+	Code      int64
+	Msg       string
+	Farm      string
+	FarmKind  FarmKind
+	FarmState FarmState
+	Upstream  UpstreamState
+	DataLost  bool
+}
+
+func (e ConnectivityEvent) String() string {
+	if e.Farm != "" {
+		return fmt.Sprintf("ConnectivityEvent<Code: %d, Farm: %s, Kind: %s, State: %s>", e.Code, e.Farm, e.FarmKind, e.FarmState)
+	}
+	return fmt.Sprintf("ConnectivityEvent<Code: %d, Upstream: %s, DataLost: %t>", e.Code, e.Upstream, e.DataLost)
+}
+
+// ConnectivityMonitor tracks the upstream and the data farms of TWS.
+/*
+It observes Error of IbClient once started, and handles
+	1100 -> UPSTREAM_LOST, the orders are held by IbClient if PauseOrders
+	1101 -> UPSTREAM_OK with the data lost, the held orders are sent, and the subscriptions are sent again if Resubscribe
+	1102 -> UPSTREAM_OK, the held orders are sent
+	2110 -> UPSTREAM_BROKEN
+	2103-2108, 2119, 2157, 2158 -> the FarmState of the farm
+Set PauseOrders and Resubscribe before Start.
+All the methods are safe for concurrent use.
+*/
+type ConnectivityMonitor struct {
+	ic          *IbClient
+	PauseOrders bool
+	Resubscribe bool
+
+	mu            sync.RWMutex
+	upstream      UpstreamState
+	upstreamSince time.Time
+	restored      chan struct{} // closed while the upstream is OK
+	farms         map[string]FarmStatus
+	paused        bool // the orders are paused by the monitor
+	subscribers   map[int]func(ConnectivityEvent)
+	subSeq        int
+}
+
+// NewConnectivityMonitor creates a ConnectivityMonitor of ic
+func NewConnectivityMonitor(ic *IbClient) *ConnectivityMonitor {
+	restored := make(chan struct{})
+	close(restored)
+	return &ConnectivityMonitor{
+		ic:          ic,
+		restored:    restored,
+		farms:       make(map[string]FarmStatus),
+		subscribers: make(map[int]func(ConnectivityEvent)),
+	}
+}
+
+// Start adds the monitor as an observer of IbClient
+func (cm *ConnectivityMonitor) Start() {
+	cm.ic.AddObserver(cm)
+}
+
+// Stop removes the monitor from the observers of IbClient, and sends the orders held by it
+func (cm *ConnectivityMonitor) Stop() {
+	cm.ic.RemoveObserver(cm)
+
+	cm.mu.Lock()
+	paused := cm.paused
+	cm.paused = false
+	cm.mu.Unlock()
+
+	if paused {
+		cm.ic.ResumeOrders()
+	}
+}
+
+// Subscribe registers f to be called on every ConnectivityEvent, it is called in the decoder goroutine and must not block.
+// Call the returned func to unsubscribe.
+func (cm *ConnectivityMonitor) Subscribe(f func(ConnectivityEvent)) (unsubscribe func()) {
+	cm.mu.Lock()
+	id := cm.subSeq
+	cm.subSeq++
+	cm.subscribers[id] = f
+	cm.mu.Unlock()
+
+	return func() {
+		cm.mu.Lock()
+		delete(cm.subscribers, id)
+		cm.mu.Unlock()
+	}
+}
+
+// Health returns the current state of the upstream and the data farms
+func (cm *ConnectivityMonitor) Health() ConnectivityHealth {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	farms := make(map[string]FarmStatus, len(cm.farms))
+	for name, f := range cm.farms {
+		farms[name] = f
+	}
+	return ConnectivityHealth{Upstream: cm.upstream, UpstreamSince: cm.upstreamSince, Farms: farms}
+}
+
+// WaitUpstream blocks until the upstream is OK, or ctx is done
+func (cm *ConnectivityMonitor) WaitUpstream(ctx context.Context) error {
+	cm.mu.RLock()
+	restored := cm.restored
+	cm.mu.RUnlock()
+
+	select {
+	case <-restored:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Error observes the system messages and the farm warnings
+func (cm *ConnectivityMonitor) Error(reqID int64, errCode int64, errString string) {
+	event := ConnectivityEvent{Time: time.Now(), Code: errCode, Msg: errString}
+
+	if fc, ok := farmCodes[errCode]; ok {
+		event.Farm = parseFarmName(errString)
+		event.FarmKind, event.FarmState = fc.kind, fc.state
+		cm.updateFarm(event)
+		return
+	}
+
+	switch errCode {
+	case CONNECTIVITY_LOST.code:
+		event.Upstream = UPSTREAM_LOST
+	case TWS_SERVER_BROKEN.code:
+		event.Upstream = UPSTREAM_BROKEN
+	case CONNECTIVITY_RESTORED_DATA_LOST.code:
+		event.Upstream, event.DataLost = UPSTREAM_OK, true
+	case CONNECTIVITY_RESTORED.code:
+		event.Upstream = UPSTREAM_OK
+	default:
+		return
+	}
+	cm.updateUpstream(event)
+}
+
+func (cm *ConnectivityMonitor) updateFarm(event ConnectivityEvent) {
+	cm.mu.Lock()
+	cm.farms[event.Farm] = FarmStatus{Name: event.Farm, Kind: event.FarmKind, State: event.FarmState, Since: event.Time}
+	subscribers := cm.subscriberList()
+	cm.mu.Unlock()
+
+	log.Info("farm state", zap.String("farm", event.Farm), zap.Stringer("kind", event.FarmKind), zap.Stringer("state", event.FarmState))
+	for _, f := range subscribers {
+		f(event)
+	}
+}
+
+func (cm *ConnectivityMonitor) updateUpstream(event ConnectivityEvent) {
+	cm.mu.Lock()
+	lost := event.Upstream != UPSTREAM_OK
+	if lost && cm.upstream == UPSTREAM_OK {
+		cm.restored = make(chan struct{})
+	} else if !lost && cm.upstream != UPSTREAM_OK {
+		close(cm.restored)
+	}
+	cm.upstream, cm.upstreamSince = event.Upstream, event.Time
+
+	pause := event.Upstream == UPSTREAM_LOST && cm.PauseOrders && !cm.paused
+	resume := !lost && cm.paused
+	if pause || resume {
+		cm.paused = pause
+	}
+	subscribers := cm.subscriberList()
+	cm.mu.Unlock()
+
+	log.Warn("upstream state", zap.Int64("errCode", event.Code), zap.Stringer("state", event.Upstream), zap.Bool("dataLost", event.DataLost))
+	if pause {
+		cm.ic.PauseOrders()
+	}
+	if resume {
+		cm.ic.ResumeOrders()
+	}
+	if event.DataLost && cm.Resubscribe {
+		cm.ic.Resubscribe()
+	}
+
+	for _, f := range subscribers {
+		f(event)
+	}
+}
+
+func (cm *ConnectivityMonitor) subscriberList() []func(ConnectivityEvent) {
+	subscribers := make([]func(ConnectivityEvent), 0, len(cm.subscribers))
+	for _, f := range cm.subscribers {
+		subscribers = append(subscribers, f)
+	}
+	return subscribers
+}
+
+// heldOrder is an order placed while the orders are paused, msg is the encoded PlaceOrder
+type heldOrder struct {
+	orderID int64
+	msg     []byte
+}
+
+// orderHold keeps the orders placed while paused, in the order of placing
+type orderHold struct {
+	mu     sync.Mutex
+	paused bool
+	held   []heldOrder
+}
+
+// hold keeps the order if paused, placing the same orderID again replaces the held one, it reports whether the order is held
+func (h *orderHold) hold(orderID int64, msg []byte) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.paused {
+		return false
+	}
+
+	for i := range h.held {
+		if h.held[i].orderID == orderID {
+			h.held[i].msg = msg
+			return true
+		}
+	}
+	h.held = append(h.held, heldOrder{orderID, msg})
+	return true
+}
+
+// drop removes the held order, it reports whether the order is held
+func (h *orderHold) drop(orderID int64) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := range h.held {
+		if h.held[i].orderID == orderID {
+			h.held = append(h.held[:i], h.held[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (h *orderHold) pause() {
+	h.mu.Lock()
+	h.paused = true
+	h.mu.Unlock()
+}
+
+// resume stops holding the orders and returns the held ones
+func (h *orderHold) resume() []heldOrder {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	held := h.held
+	h.paused, h.held = false, nil
+	return held
+}
+
+func (h *orderHold) isPaused() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.paused
+}
+
+// dropAll removes and returns the held orders, the orders are still paused
+func (h *orderHold) dropAll() []heldOrder {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	held := h.held
+	h.held = nil
+	return held
+}
+
+// cancelHeldOrder reports ORDER_CANCELLED of the held order which is dropped
+func (ic *IbClient) cancelHeldOrder(orderID int64) {
+	ic.wrapper.Error(orderID, ORDER_CANCELLED.code, ORDER_CANCELLED.msg+" The held order is never sent.")
+}
+
+// PauseOrders holds the orders placed from now on until ResumeOrders, instead of sending them to TWS.
+// The what-if orders are never held. CancelOrder of a held order drops it and reports ORDER_CANCELLED to the wrapper,
+// so do ReqGlobalCancel and the disconnection to all of them.
+func (ic *IbClient) PauseOrders() {
+	log.Warn("pause orders")
+	ic.orders.pause()
+}
+
+// ResumeOrders sends the held orders in the order of placing, and returns the number of them
+func (ic *IbClient) ResumeOrders() int {
+	held := ic.orders.resume()
+	log.Info("resume orders", zap.Int("held", len(held)))
+	for _, o := range held {
+		ic.send(o.msg, nil)
+	}
+	return len(held)
+}
+
+// OrdersPaused reports whether the orders are held by PauseOrders
+func (ic *IbClient) OrdersPaused() bool {
+	return ic.orders.isPaused()
+}
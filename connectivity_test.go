@@ -0,0 +1,202 @@
+package ibapi
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// reqRecorder records the msg IDs of the requests sent to the test client
+type reqRecorder struct {
+	mu     sync.Mutex
+	msgIDs []int64
+}
+
+func (r *reqRecorder) respond(ic *IbClient, req []byte) {
+	r.record(fieldInt(splitMsgBytes(req[4:])[0]))
+}
+
+func (r *reqRecorder) record(msgID int64) {
+	r.mu.Lock()
+	r.msgIDs = append(r.msgIDs, msgID)
+	r.mu.Unlock()
+}
+
+func (r *reqRecorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.msgIDs)
+}
+
+func (r *reqRecorder) msgID(i int) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.msgIDs[i]
+}
+
+func TestParseFarmName(t *testing.T) {
+	cases := map[string]string{
+		"Market data farm connection is OK:usfarm.nj":                                          "usfarm.nj",
+		"HMDS data farm connection is broken:ushmds":                                           "ushmds",
+		"Sec-def data farm connection is OK:secdefil":                                          "secdefil",
+		"Market data farm connection is inactive but should be available upon demand.cashfarm": "cashfarm",
+		"Market data farm connection is broken":                                                "",
+	}
+	for s, want := range cases {
+		if got := parseFarmName(s); got != want {
+			t.Errorf("%q: got %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestConnectivityMonitorFarms(t *testing.T) {
+	ic := newTestClient(func(ic *IbClient, req []byte) {})
+	cm := NewConnectivityMonitor(ic)
+	cm.Start()
+	defer cm.Stop()
+
+	var events []ConnectivityEvent
+	cm.Subscribe(func(e ConnectivityEvent) { events = append(events, e) })
+
+	ic.dispatcher.Error(NO_VALID_ID, 2104, "Market data farm connection is OK:usfarm")
+	ic.dispatcher.Error(NO_VALID_ID, 2106, "HMDS data farm connection is OK:ushmds")
+	ic.dispatcher.Error(NO_VALID_ID, 2158, "Sec-def data farm connection is OK:secdefnj")
+	if h := cm.Health(); !h.Healthy() || len(h.Farms) != 3 || h.Farms["ushmds"].Kind != FARM_HMDS {
+		t.Errorf("unexpected health %+v", h)
+	}
+
+	ic.dispatcher.Error(NO_VALID_ID, 2103, "Market data farm connection is broken:usfarm")
+	h := cm.Health()
+	if h.Healthy() || h.Farms["usfarm"].State != FARM_BROKEN {
+		t.Errorf("usfarm should be broken, got %+v", h)
+	}
+
+	ic.dispatcher.Error(NO_VALID_ID, 2108, "Market data farm connection is inactive but should be available upon demand.usfarm")
+	if h := cm.Health(); !h.Healthy() || h.Farms["usfarm"].State != FARM_INACTIVE {
+		t.Errorf("the inactive farm should be healthy, got %+v", h)
+	}
+
+	ic.dispatcher.Error(NO_VALID_ID, 2100, "API client has been unsubscribed from account data.")
+	if len(events) != 5 || events[3].Farm != "usfarm" || events[3].FarmState != FARM_BROKEN {
+		t.Errorf("unexpected events %v", events)
+	}
+}
+
+func TestConnectivityMonitorPauseOrders(t *testing.T) {
+	rec := &reqRecorder{}
+	ic := newTestClient(rec.respond)
+	cm := NewConnectivityMonitor(ic)
+	cm.PauseOrders = true
+	cm.Start()
+	defer cm.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	ic.dispatcher.Error(NO_VALID_ID, 1100, "Connectivity between IB and Trader Workstation has been lost.")
+	if h := cm.Health(); h.Healthy() || h.Upstream != UPSTREAM_LOST || !ic.OrdersPaused() {
+		t.Fatalf("unexpected health %+v", h)
+	}
+	if err := cm.WaitUpstream(ctx); err != context.DeadlineExceeded {
+		t.Errorf("the upstream should be lost, got %v", err)
+	}
+
+	aapl := &Contract{Symbol: "AAPL", SecurityType: "STK", Exchange: "SMART", Currency: "USD"}
+	ic.PlaceOrder(1, aapl, NewLimitOrder("BUY", 100, 1))
+	ic.PlaceOrder(2, aapl, NewLimitOrder("BUY", 100, 1))
+	ic.PlaceOrder(3, aapl, NewLimitOrder("BUY", 100, 1))
+	ic.CancelOrder(2)
+	time.Sleep(10 * time.Millisecond)
+	if n := rec.count(); n != 0 {
+		t.Fatalf("the orders should be held, got %d requests", n)
+	}
+
+	ic.dispatcher.Error(NO_VALID_ID, 1102, "Connectivity between IB and Trader Workstation has been restored - data maintained.")
+	waitFor(t, func() bool { return rec.count() == 2 })
+	if rec.msgID(0) != mPLACE_ORDER || rec.msgID(1) != mPLACE_ORDER || ic.OrdersPaused() {
+		t.Errorf("the held orders should be sent, got %v", rec.msgIDs)
+	}
+	if err := cm.WaitUpstream(context.Background()); err != nil || !cm.Health().Healthy() {
+		t.Errorf("the upstream should be restored, got %v", err)
+	}
+}
+
+func TestConnectivityMonitorResubscribe(t *testing.T) {
+	rec := &reqRecorder{}
+	ic := newTestClient(rec.respond)
+	cm := NewConnectivityMonitor(ic)
+	cm.Resubscribe = true
+	cm.Start()
+	defer cm.Stop()
+
+	aapl := &Contract{ContractID: 265598, Symbol: "AAPL", SecurityType: "STK", Exchange: "SMART", Currency: "USD"}
+	ic.ReqMktData(1, aapl, "", false, false, nil)
+	ic.ReqMktData(2, aapl, "", true, false, nil) // snapshot
+	ic.ReqRealTimeBars(3, aapl, 5, "TRADES", true, nil)
+	ic.ReqMktDepth(4, aapl, 5, false, nil)
+	ic.CancelMktDepth(4, false)
+	if ids := ic.Subscriptions(); len(ids) != 2 || ids[0] != 1 || ids[1] != 3 {
+		t.Fatalf("unexpected subscriptions %v", ids)
+	}
+	waitFor(t, func() bool { return rec.count() == 5 })
+
+	ic.dispatcher.Error(NO_VALID_ID, 1101, "Connectivity between IB and Trader Workstation has been restored - data lost.")
+	waitFor(t, func() bool { return rec.count() == 9 })
+	want := []int64{mCANCEL_MKT_DATA, mREQ_MKT_DATA, mCANCEL_REAL_TIME_BARS, mREQ_REAL_TIME_BARS}
+	for i, msgID := range want {
+		if got := rec.msgID(5 + i); got != msgID {
+			t.Errorf("request %d: got msg %d, want %d", i, got, msgID)
+		}
+	}
+	if ids := ic.Subscriptions(); len(ids) != 2 {
+		t.Errorf("the subscriptions should be kept, got %v", ids)
+	}
+
+	ic.CancelSubscriptions()
+	if ids := ic.Subscriptions(); len(ids) != 0 {
+		t.Errorf("the subscriptions should be canceled, got %v", ids)
+	}
+}
+
+func TestHeldOrdersCancelled(t *testing.T) {
+	rec := &reqRecorder{}
+	ic := newTestClient(rec.respond)
+	w := &orderEventWrapper{orderIDs: make(chan int64, 10)}
+	ic.SetWrapper(w)
+	ic.PauseOrders()
+
+	aapl := &Contract{Symbol: "AAPL", SecurityType: "STK", Exchange: "SMART", Currency: "USD"}
+	ic.PlaceOrder(1, aapl, NewLimitOrder("BUY", 100, 1))
+	ic.PlaceOrder(2, aapl, NewLimitOrder("BUY", 100, 1))
+	whatIf := NewLimitOrder("BUY", 100, 1)
+	whatIf.WhatIf = true
+	ic.PlaceOrder(3, aapl, whatIf)
+	waitFor(t, func() bool { return rec.count() == 1 })
+	if rec.msgID(0) != mPLACE_ORDER {
+		t.Errorf("the what-if order should be sent, got %v", rec.msgIDs)
+	}
+
+	ic.ReqGlobalCancel()
+	for _, want := range []int64{1, 2} {
+		if orderID := <-w.orderIDs; orderID != want {
+			t.Errorf("expect ORDER_CANCELLED of %d, got %d", want, orderID)
+		}
+	}
+	waitFor(t, func() bool { return rec.count() == 2 })
+	if rec.msgID(1) != mREQ_GLOBAL_CANCEL || !ic.OrdersPaused() {
+		t.Errorf("the global cancel should be sent and the orders still paused, got %v", rec.msgIDs)
+	}
+
+	// the held orders are cancelled on the disconnection
+	ic.PlaceOrder(4, aapl, NewLimitOrder("BUY", 100, 1))
+	ic.reset()
+	select {
+	case orderID := <-w.orderIDs:
+		if orderID != 4 {
+			t.Errorf("expect ORDER_CANCELLED of 4, got %d", orderID)
+		}
+	default:
+		t.Error("the held order should be cancelled by reset")
+	}
+}
@@ -95,6 +95,8 @@ const (
 	mREPLACE_FA_END                           IN = 103
 	mWSH_META_DATA                            IN = 104
 	mWSH_EVENT_DATA                           IN = 105
+	mHISTORICAL_SCHEDULE                      IN = 106
+	mUSER_INFO                                IN = 107
 )
 
 const (
@@ -177,6 +179,7 @@ const (
 	mCANCEL_WSH_META_DATA          OUT = 101
 	mREQ_WSH_EVENT_DATA            OUT = 102
 	mCANCEL_WSH_EVENT_DATA         OUT = 103
+	mREQ_USER_INFO                 OUT = 104
 )
 
 const (
@@ -186,105 +189,117 @@ const (
 	// mMIN_SERVER_VER_SSHORT_COMBO_LEGS    = 35
 	// mMIN_SERVER_VER_WHAT_IF_ORDERS       = 36
 	// mMIN_SERVER_VER_CONTRACT_CONID       = 37
-	mMIN_SERVER_VER_PTA_ORDERS                 Version = 39
-	mMIN_SERVER_VER_FUNDAMENTAL_DATA           Version = 40
-	mMIN_SERVER_VER_DELTA_NEUTRAL              Version = 40
-	mMIN_SERVER_VER_CONTRACT_DATA_CHAIN        Version = 40
-	mMIN_SERVER_VER_SCALE_ORDERS2              Version = 40
-	mMIN_SERVER_VER_ALGO_ORDERS                Version = 41
-	mMIN_SERVER_VER_EXECUTION_DATA_CHAIN       Version = 42
-	mMIN_SERVER_VER_NOT_HELD                   Version = 44
-	mMIN_SERVER_VER_SEC_ID_TYPE                Version = 45
-	mMIN_SERVER_VER_PLACE_ORDER_CONID          Version = 46
-	mMIN_SERVER_VER_REQ_MKT_DATA_CONID         Version = 47
-	mMIN_SERVER_VER_REQ_CALC_IMPLIED_VOLAT     Version = 49
-	mMIN_SERVER_VER_REQ_CALC_OPTION_PRICE      Version = 50
-	mMIN_SERVER_VER_SSHORTX_OLD                Version = 51
-	mMIN_SERVER_VER_SSHORTX                    Version = 52
-	mMIN_SERVER_VER_REQ_GLOBAL_CANCEL          Version = 53
-	mMIN_SERVER_VER_HEDGE_ORDERS               Version = 54
-	mMIN_SERVER_VER_REQ_MARKET_DATA_TYPE       Version = 55
-	mMIN_SERVER_VER_OPT_OUT_SMART_ROUTING      Version = 56
-	mMIN_SERVER_VER_SMART_COMBO_ROUTING_PARAMS Version = 57
-	mMIN_SERVER_VER_DELTA_NEUTRAL_CONID        Version = 58
-	mMIN_SERVER_VER_SCALE_ORDERS3              Version = 60
-	mMIN_SERVER_VER_ORDER_COMBO_LEGS_PRICE     Version = 61
-	mMIN_SERVER_VER_TRAILING_PERCENT           Version = 62
-	mMIN_SERVER_VER_DELTA_NEUTRAL_OPEN_CLOSE   Version = 66
-	mMIN_SERVER_VER_POSITIONS                  Version = 67
-	mMIN_SERVER_VER_ACCOUNT_SUMMARY            Version = 67
-	mMIN_SERVER_VER_TRADING_CLASS              Version = 68
-	mMIN_SERVER_VER_SCALE_TABLE                Version = 69
-	mMIN_SERVER_VER_LINKING                    Version = 70
-	mMIN_SERVER_VER_ALGO_ID                    Version = 71
-	mMIN_SERVER_VER_OPTIONAL_CAPABILITIES      Version = 72
-	mMIN_SERVER_VER_ORDER_SOLICITED            Version = 73
-	mMIN_SERVER_VER_LINKING_AUTH               Version = 74
-	mMIN_SERVER_VER_PRIMARYEXCH                Version = 75
-	mMIN_SERVER_VER_RANDOMIZE_SIZE_AND_PRICE   Version = 76
-	mMIN_SERVER_VER_FRACTIONAL_POSITIONS       Version = 101
-	mMIN_SERVER_VER_PEGGED_TO_BENCHMARK        Version = 102
-	mMIN_SERVER_VER_MODELS_SUPPORT             Version = 103
-	mMIN_SERVER_VER_SEC_DEF_OPT_PARAMS_REQ     Version = 104
-	mMIN_SERVER_VER_EXT_OPERATOR               Version = 105
-	mMIN_SERVER_VER_SOFT_DOLLAR_TIER           Version = 106
-	mMIN_SERVER_VER_REQ_FAMILY_CODES           Version = 107
-	mMIN_SERVER_VER_REQ_MATCHING_SYMBOLS       Version = 108
-	mMIN_SERVER_VER_PAST_LIMIT                 Version = 109
-	mMIN_SERVER_VER_MD_SIZE_MULTIPLIER         Version = 110
-	mMIN_SERVER_VER_CASH_QTY                   Version = 111
-	mMIN_SERVER_VER_REQ_MKT_DEPTH_EXCHANGES    Version = 112
-	mMIN_SERVER_VER_TICK_NEWS                  Version = 113
-	mMIN_SERVER_VER_REQ_SMART_COMPONENTS       Version = 114
-	mMIN_SERVER_VER_REQ_NEWS_PROVIDERS         Version = 115
-	mMIN_SERVER_VER_REQ_NEWS_ARTICLE           Version = 116
-	mMIN_SERVER_VER_REQ_HISTORICAL_NEWS        Version = 117
-	mMIN_SERVER_VER_REQ_HEAD_TIMESTAMP         Version = 118
-	mMIN_SERVER_VER_REQ_HISTOGRAM              Version = 119
-	mMIN_SERVER_VER_SERVICE_DATA_TYPE          Version = 120
-	mMIN_SERVER_VER_AGG_GROUP                  Version = 121
-	mMIN_SERVER_VER_UNDERLYING_INFO            Version = 122
-	mMIN_SERVER_VER_CANCEL_HEADTIMESTAMP       Version = 123
-	mMIN_SERVER_VER_SYNT_REALTIME_BARS         Version = 124
-	mMIN_SERVER_VER_CFD_REROUTE                Version = 125
-	mMIN_SERVER_VER_MARKET_RULES               Version = 126
-	mMIN_SERVER_VER_PNL                        Version = 127
-	mMIN_SERVER_VER_NEWS_QUERY_ORIGINS         Version = 128
-	mMIN_SERVER_VER_UNREALIZED_PNL             Version = 129
-	mMIN_SERVER_VER_HISTORICAL_TICKS           Version = 130
-	mMIN_SERVER_VER_MARKET_CAP_PRICE           Version = 131
-	mMIN_SERVER_VER_PRE_OPEN_BID_ASK           Version = 132
-	mMIN_SERVER_VER_REAL_EXPIRATION_DATE       Version = 134
-	mMIN_SERVER_VER_REALIZED_PNL               Version = 135
-	mMIN_SERVER_VER_LAST_LIQUIDITY             Version = 136
-	mMIN_SERVER_VER_TICK_BY_TICK               Version = 137
-	mMIN_SERVER_VER_DECISION_MAKER             Version = 138
-	mMIN_SERVER_VER_MIFID_EXECUTION            Version = 139
-	mMIN_SERVER_VER_TICK_BY_TICK_IGNORE_SIZE   Version = 140
-	mMIN_SERVER_VER_AUTO_PRICE_FOR_HEDGE       Version = 141
-	mMIN_SERVER_VER_WHAT_IF_EXT_FIELDS         Version = 142
-	mMIN_SERVER_VER_SCANNER_GENERIC_OPTS       Version = 143
-	mMIN_SERVER_VER_API_BIND_ORDER             Version = 144
-	mMIN_SERVER_VER_ORDER_CONTAINER            Version = 145
-	mMIN_SERVER_VER_SMART_DEPTH                Version = 146
-	mMIN_SERVER_VER_REMOVE_NULL_ALL_CASTING    Version = 147
-	mMIN_SERVER_VER_D_PEG_ORDERS               Version = 148
-	mMIN_SERVER_VER_MKT_DEPTH_PRIM_EXCHANGE    Version = 149
-	mMIN_SERVER_VER_COMPLETED_ORDERS           Version = 150
-	mMIN_SERVER_VER_PRICE_MGMT_ALGO            Version = 151
-	mMIN_SERVER_VER_STOCK_TYPE                 Version = 152
-	mMIN_SERVER_VER_ENCODE_MSG_ASCII7          Version = 153
-	mMIN_SERVER_VER_SEND_ALL_FAMILY_CODES      Version = 154
-	mMIN_SERVER_VER_NO_DEFAULT_OPEN_CLOSE      Version = 155
-	mMIN_SERVER_VER_PRICE_BASED_VOLATILITY     Version = 156
-	mMIN_SERVER_VER_REPLACE_FA_END             Version = 157
-	mMIN_SERVER_VER_DURATION                   Version = 158
-	mMIN_SERVER_VER_MARKET_DATA_IN_SHARES      Version = 159
-	mMIN_SERVER_VER_POST_TO_ATS                Version = 160
-	mMIN_SERVER_VER_WSHE_CALENDAR              Version = 161
+	mMIN_SERVER_VER_PTA_ORDERS                  Version = 39
+	mMIN_SERVER_VER_FUNDAMENTAL_DATA            Version = 40
+	mMIN_SERVER_VER_DELTA_NEUTRAL               Version = 40
+	mMIN_SERVER_VER_CONTRACT_DATA_CHAIN         Version = 40
+	mMIN_SERVER_VER_SCALE_ORDERS2               Version = 40
+	mMIN_SERVER_VER_ALGO_ORDERS                 Version = 41
+	mMIN_SERVER_VER_EXECUTION_DATA_CHAIN        Version = 42
+	mMIN_SERVER_VER_NOT_HELD                    Version = 44
+	mMIN_SERVER_VER_SEC_ID_TYPE                 Version = 45
+	mMIN_SERVER_VER_PLACE_ORDER_CONID           Version = 46
+	mMIN_SERVER_VER_REQ_MKT_DATA_CONID          Version = 47
+	mMIN_SERVER_VER_REQ_CALC_IMPLIED_VOLAT      Version = 49
+	mMIN_SERVER_VER_REQ_CALC_OPTION_PRICE       Version = 50
+	mMIN_SERVER_VER_SSHORTX_OLD                 Version = 51
+	mMIN_SERVER_VER_SSHORTX                     Version = 52
+	mMIN_SERVER_VER_REQ_GLOBAL_CANCEL           Version = 53
+	mMIN_SERVER_VER_HEDGE_ORDERS                Version = 54
+	mMIN_SERVER_VER_REQ_MARKET_DATA_TYPE        Version = 55
+	mMIN_SERVER_VER_OPT_OUT_SMART_ROUTING       Version = 56
+	mMIN_SERVER_VER_SMART_COMBO_ROUTING_PARAMS  Version = 57
+	mMIN_SERVER_VER_DELTA_NEUTRAL_CONID         Version = 58
+	mMIN_SERVER_VER_SCALE_ORDERS3               Version = 60
+	mMIN_SERVER_VER_ORDER_COMBO_LEGS_PRICE      Version = 61
+	mMIN_SERVER_VER_TRAILING_PERCENT            Version = 62
+	mMIN_SERVER_VER_DELTA_NEUTRAL_OPEN_CLOSE    Version = 66
+	mMIN_SERVER_VER_POSITIONS                   Version = 67
+	mMIN_SERVER_VER_ACCOUNT_SUMMARY             Version = 67
+	mMIN_SERVER_VER_TRADING_CLASS               Version = 68
+	mMIN_SERVER_VER_SCALE_TABLE                 Version = 69
+	mMIN_SERVER_VER_LINKING                     Version = 70
+	mMIN_SERVER_VER_ALGO_ID                     Version = 71
+	mMIN_SERVER_VER_OPTIONAL_CAPABILITIES       Version = 72
+	mMIN_SERVER_VER_ORDER_SOLICITED             Version = 73
+	mMIN_SERVER_VER_LINKING_AUTH                Version = 74
+	mMIN_SERVER_VER_PRIMARYEXCH                 Version = 75
+	mMIN_SERVER_VER_RANDOMIZE_SIZE_AND_PRICE    Version = 76
+	mMIN_SERVER_VER_FRACTIONAL_POSITIONS        Version = 101
+	mMIN_SERVER_VER_PEGGED_TO_BENCHMARK         Version = 102
+	mMIN_SERVER_VER_MODELS_SUPPORT              Version = 103
+	mMIN_SERVER_VER_SEC_DEF_OPT_PARAMS_REQ      Version = 104
+	mMIN_SERVER_VER_EXT_OPERATOR                Version = 105
+	mMIN_SERVER_VER_SOFT_DOLLAR_TIER            Version = 106
+	mMIN_SERVER_VER_REQ_FAMILY_CODES            Version = 107
+	mMIN_SERVER_VER_REQ_MATCHING_SYMBOLS        Version = 108
+	mMIN_SERVER_VER_PAST_LIMIT                  Version = 109
+	mMIN_SERVER_VER_MD_SIZE_MULTIPLIER          Version = 110
+	mMIN_SERVER_VER_CASH_QTY                    Version = 111
+	mMIN_SERVER_VER_REQ_MKT_DEPTH_EXCHANGES     Version = 112
+	mMIN_SERVER_VER_TICK_NEWS                   Version = 113
+	mMIN_SERVER_VER_REQ_SMART_COMPONENTS        Version = 114
+	mMIN_SERVER_VER_REQ_NEWS_PROVIDERS          Version = 115
+	mMIN_SERVER_VER_REQ_NEWS_ARTICLE            Version = 116
+	mMIN_SERVER_VER_REQ_HISTORICAL_NEWS         Version = 117
+	mMIN_SERVER_VER_REQ_HEAD_TIMESTAMP          Version = 118
+	mMIN_SERVER_VER_REQ_HISTOGRAM               Version = 119
+	mMIN_SERVER_VER_SERVICE_DATA_TYPE           Version = 120
+	mMIN_SERVER_VER_AGG_GROUP                   Version = 121
+	mMIN_SERVER_VER_UNDERLYING_INFO             Version = 122
+	mMIN_SERVER_VER_CANCEL_HEADTIMESTAMP        Version = 123
+	mMIN_SERVER_VER_SYNT_REALTIME_BARS          Version = 124
+	mMIN_SERVER_VER_CFD_REROUTE                 Version = 125
+	mMIN_SERVER_VER_MARKET_RULES                Version = 126
+	mMIN_SERVER_VER_PNL                         Version = 127
+	mMIN_SERVER_VER_NEWS_QUERY_ORIGINS          Version = 128
+	mMIN_SERVER_VER_UNREALIZED_PNL              Version = 129
+	mMIN_SERVER_VER_HISTORICAL_TICKS            Version = 130
+	mMIN_SERVER_VER_MARKET_CAP_PRICE            Version = 131
+	mMIN_SERVER_VER_PRE_OPEN_BID_ASK            Version = 132
+	mMIN_SERVER_VER_REAL_EXPIRATION_DATE        Version = 134
+	mMIN_SERVER_VER_REALIZED_PNL                Version = 135
+	mMIN_SERVER_VER_LAST_LIQUIDITY              Version = 136
+	mMIN_SERVER_VER_TICK_BY_TICK                Version = 137
+	mMIN_SERVER_VER_DECISION_MAKER              Version = 138
+	mMIN_SERVER_VER_MIFID_EXECUTION             Version = 139
+	mMIN_SERVER_VER_TICK_BY_TICK_IGNORE_SIZE    Version = 140
+	mMIN_SERVER_VER_AUTO_PRICE_FOR_HEDGE        Version = 141
+	mMIN_SERVER_VER_WHAT_IF_EXT_FIELDS          Version = 142
+	mMIN_SERVER_VER_SCANNER_GENERIC_OPTS        Version = 143
+	mMIN_SERVER_VER_API_BIND_ORDER              Version = 144
+	mMIN_SERVER_VER_ORDER_CONTAINER             Version = 145
+	mMIN_SERVER_VER_SMART_DEPTH                 Version = 146
+	mMIN_SERVER_VER_REMOVE_NULL_ALL_CASTING     Version = 147
+	mMIN_SERVER_VER_D_PEG_ORDERS                Version = 148
+	mMIN_SERVER_VER_MKT_DEPTH_PRIM_EXCHANGE     Version = 149
+	mMIN_SERVER_VER_COMPLETED_ORDERS            Version = 150
+	mMIN_SERVER_VER_PRICE_MGMT_ALGO             Version = 151
+	mMIN_SERVER_VER_STOCK_TYPE                  Version = 152
+	mMIN_SERVER_VER_ENCODE_MSG_ASCII7           Version = 153
+	mMIN_SERVER_VER_SEND_ALL_FAMILY_CODES       Version = 154
+	mMIN_SERVER_VER_NO_DEFAULT_OPEN_CLOSE       Version = 155
+	mMIN_SERVER_VER_PRICE_BASED_VOLATILITY      Version = 156
+	mMIN_SERVER_VER_REPLACE_FA_END              Version = 157
+	mMIN_SERVER_VER_DURATION                    Version = 158
+	mMIN_SERVER_VER_MARKET_DATA_IN_SHARES       Version = 159
+	mMIN_SERVER_VER_POST_TO_ATS                 Version = 160
+	mMIN_SERVER_VER_WSHE_CALENDAR               Version = 161
+	mMIN_SERVER_VER_AUTO_CANCEL_PARENT          Version = 162
+	mMIN_SERVER_VER_FRACTIONAL_SIZE_SUPPORT     Version = 163
+	mMIN_SERVER_VER_SIZE_RULES                  Version = 164
+	mMIN_SERVER_VER_HISTORICAL_SCHEDULE         Version = 165
+	mMIN_SERVER_VER_ADVANCED_ORDER_REJECT       Version = 166
+	mMIN_SERVER_VER_USER_INFO                   Version = 167
+	mMIN_SERVER_VER_CRYPTO_AGGREGATED_TRADES    Version = 168
+	mMIN_SERVER_VER_MANUAL_ORDER_TIME           Version = 169
+	mMIN_SERVER_VER_PEGBEST_PEGMID_OFFSETS      Version = 170
+	mMIN_SERVER_VER_WSH_EVENT_DATA_FILTERS      Version = 171
+	mMIN_SERVER_VER_IPO_PRICES                  Version = 172
+	mMIN_SERVER_VER_WSH_EVENT_DATA_FILTERS_DATE Version = 173
 
 	MIN_CLIENT_VER Version = 100
-	MAX_CLIENT_VER Version = mMIN_SERVER_VER_WSHE_CALENDAR
+	MAX_CLIENT_VER Version = mMIN_SERVER_VER_WSH_EVENT_DATA_FILTERS_DATE
 )
 
 // tick const
@@ -379,6 +394,19 @@ const (
 	AVG_OPT_VOLUME
 	DELAYED_LAST_TIMESTAMP
 	SHORTABLE_SHARES
+	DELAYED_HALTED
+	REUTERS_2_MUTUAL_FUNDS
+	ETF_NAV_CLOSE
+	ETF_NAV_PRIOR_CLOSE
+	ETF_NAV_BID
+	ETF_NAV_ASK
+	ETF_NAV_LAST
+	ETF_FROZEN_NAV_LAST
+	ETF_NAV_HIGH
+	ETF_NAV_LOW
+	SOCIAL_MARKET_ANALYTICS
+	ESTIMATED_IPO_MIDPOINT
+	FINAL_IPO_LAST
 	NOT_SET
 )
 
@@ -100,6 +100,11 @@ type ContractDetails struct {
 	LastTradeTime      string
 	StockType          string
 
+	// size rules
+	MinSize                Decimal
+	SizeIncrement          Decimal
+	SuggestedSizeIncrement Decimal
+
 	// BOND values
 	Cusip             string
 	Ratings           string
@@ -0,0 +1,394 @@
+/*
+contractspec parses and formats the human-readable contract specs, such as
+	AAPL STK SMART/NASDAQ USD
+	ES FUT 202412 CME
+	SPY OPT 20241220 450 C SMART
+as well as the OCC option symbols and the localSymbol of futures.
+*/
+
+package ibapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// secTypes are the security types accepted in the contract spec
+var secTypes = map[string]bool{
+	"STK": true, "OPT": true, "FUT": true, "CONTFUT": true, "FOP": true, "CASH": true, "IND": true, "CFD": true,
+	"BOND": true, "CMDTY": true, "FUND": true, "WAR": true, "IOPT": true, "BAG": true, "NEWS": true, "CRYPTO": true,
+}
+
+// currencies are the ISO codes which a lone trailing token of the contract spec is read as
+var currencies = map[string]bool{
+	"USD": true, "EUR": true, "GBP": true, "JPY": true, "HKD": true, "CNH": true, "CHF": true, "CAD": true,
+	"AUD": true, "NZD": true, "SGD": true, "KRW": true, "SEK": true, "NOK": true, "DKK": true, "MXN": true,
+	"ILS": true, "INR": true, "ZAR": true, "CZK": true, "HUF": true, "PLN": true, "RUB": true, "TRY": true,
+}
+
+var (
+	specExpiryRe        = regexp.MustCompile(`^\d{6}(\d{2})?$`)
+	occSymbolRe         = regexp.MustCompile(`^([A-Z0-9.]{1,6})\s*(\d{6})([CP])(\d{8})$`)
+	futureLocalSymbolRe = regexp.MustCompile(`^([A-Z0-9]{1,4}?)([FGHJKMNQUVXZ])(\d{1,2})$`)
+)
+
+// futureMonthCodes maps the month codes of futures to the months
+var futureMonthCodes = map[string]int{
+	"F": 1, "G": 2, "H": 3, "J": 4, "K": 5, "M": 6, "N": 7, "Q": 8, "U": 9, "V": 10, "X": 11, "Z": 12,
+}
+
+// ParseContract parses a contract spec, an OCC option symbol or a future localSymbol into *Contract.
+/*
+The contract spec is the whitespace separated
+	SYMBOL SECTYPE [EXPIRY] [STRIKE] [RIGHT] [EXCHANGE[/PRIMARYEXCHANGE]] [CURRENCY] [key=value...]
+EXPIRY, STRIKE and RIGHT are only for the derivatives.
+A lone token after them is the CURRENCY if it is a known currency code, otherwise the EXCHANGE.
+The keys are conid, expiry, strike, right, multiplier, tradingclass, localsymbol, secid(TYPE:ID),
+exchange, currency and includeexpired,
+and the values with spaces are double quoted.
+
+The OCC option symbol, such as "SPY   241220C00450000", is a SMART OPT in USD.
+The future localSymbol, such as "ESZ4", is a FUT with Symbol and LocalSymbol only,
+since the decade of the expiry is unknown.
+*/
+func ParseContract(spec string) (*Contract, error) {
+	spec = strings.TrimSpace(spec)
+	if c, err := ParseOCCSymbol(spec); err == nil {
+		return c, nil
+	}
+
+	tokens, err := tokenizeSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 1 {
+		if c, err := ParseFutureLocalSymbol(tokens[0]); err == nil {
+			return c, nil
+		}
+	}
+
+	if len(tokens) < 2 {
+		return nil, fmt.Errorf("invalid contract spec %q: SYMBOL and SECTYPE are required", spec)
+	}
+
+	c := &Contract{Symbol: tokens[0], SecurityType: strings.ToUpper(tokens[1])}
+	if !secTypes[c.SecurityType] {
+		return nil, fmt.Errorf("invalid contract spec %q: unknown security type %s", spec, tokens[1])
+	}
+
+	var positional []string
+	for _, tok := range tokens[2:] {
+		if i := strings.Index(tok, "="); i > 0 {
+			if err := setSpecOption(c, strings.ToLower(tok[:i]), tok[i+1:]); err != nil {
+				return nil, fmt.Errorf("invalid contract spec %q: %v", spec, err)
+			}
+			continue
+		}
+		positional = append(positional, tok)
+	}
+
+	switch c.SecurityType {
+	case "FUT", "CONTFUT", "OPT", "FOP", "WAR", "IOPT":
+		if len(positional) > 0 && specExpiryRe.MatchString(positional[0]) {
+			c.Expiry = positional[0]
+			positional = positional[1:]
+		}
+	}
+
+	switch c.SecurityType {
+	case "OPT", "FOP", "WAR", "IOPT":
+		if len(positional) > 0 {
+			if strike, err := strconv.ParseFloat(positional[0], 64); err == nil {
+				c.Strike = strike
+				positional = positional[1:]
+			}
+		}
+		if len(positional) > 0 {
+			if right, ok := parseRight(positional[0]); ok {
+				c.Right = right
+				positional = positional[1:]
+			}
+		}
+	}
+
+	switch len(positional) {
+	case 0:
+	case 1:
+		if currencies[strings.ToUpper(positional[0])] {
+			c.Currency = strings.ToUpper(positional[0])
+		} else {
+			setSpecExchange(c, positional[0])
+		}
+	case 2:
+		setSpecExchange(c, positional[0])
+		c.Currency = strings.ToUpper(positional[1])
+	default:
+		return nil, fmt.Errorf("invalid contract spec %q: unexpected %s", spec, strings.Join(positional[2:], " "))
+	}
+
+	return c, nil
+}
+
+// FormatContract formats contract into the canonical contract spec, which ParseContract parses back to the same contract
+func FormatContract(c *Contract) string {
+	tokens := []string{quoteSpec(c.Symbol), c.SecurityType}
+	options := map[string]string{}
+
+	// the positional EXPIRY, STRIKE and RIGHT are only parsed for the derivatives, the others are keyed
+	hasExpiry, hasStrike := false, false
+	switch c.SecurityType {
+	case "FUT", "CONTFUT":
+		hasExpiry = true
+	case "OPT", "FOP", "WAR", "IOPT":
+		hasExpiry, hasStrike = true, true
+	}
+
+	if c.Expiry != "" {
+		if hasExpiry && specExpiryRe.MatchString(c.Expiry) {
+			tokens = append(tokens, c.Expiry)
+		} else {
+			options["expiry"] = c.Expiry
+		}
+	}
+	if c.Strike != 0 {
+		if hasStrike {
+			tokens = append(tokens, strconv.FormatFloat(c.Strike, 'f', -1, 64))
+		} else {
+			options["strike"] = strconv.FormatFloat(c.Strike, 'f', -1, 64)
+		}
+	}
+	if c.Right != "" {
+		if hasStrike && (c.Right == "C" || c.Right == "P") {
+			tokens = append(tokens, c.Right)
+		} else {
+			options["right"] = c.Right
+		}
+	}
+
+	exchange := c.Exchange
+	if c.PrimaryExchange != "" {
+		exchange += "/" + c.PrimaryExchange
+	}
+	switch {
+	case exchange != "" && c.Currency != "":
+		tokens = append(tokens, exchange, c.Currency)
+	case exchange != "":
+		if currencies[strings.ToUpper(exchange)] {
+			options["exchange"] = exchange
+		} else {
+			tokens = append(tokens, exchange)
+		}
+	case c.Currency != "":
+		if currencies[c.Currency] {
+			tokens = append(tokens, c.Currency)
+		} else {
+			options["currency"] = c.Currency
+		}
+	}
+
+	if c.ContractID != 0 {
+		options["conid"] = strconv.FormatInt(c.ContractID, 10)
+	}
+	if c.Multiplier != "" {
+		options["multiplier"] = c.Multiplier
+	}
+	if c.TradingClass != "" {
+		options["tradingclass"] = c.TradingClass
+	}
+	if c.LocalSymbol != "" {
+		options["localsymbol"] = c.LocalSymbol
+	}
+	if c.SecurityIDType != "" || c.SecurityID != "" {
+		options["secid"] = c.SecurityIDType + ":" + c.SecurityID
+	}
+	if c.IncludeExpired {
+		options["includeexpired"] = "true"
+	}
+
+	keys := make([]string, 0, len(options))
+	for k := range options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		tokens = append(tokens, k+"="+quoteSpec(options[k]))
+	}
+
+	return strings.Join(tokens, " ")
+}
+
+func setSpecExchange(c *Contract, exchange string) {
+	if i := strings.Index(exchange, "/"); i >= 0 {
+		c.Exchange = exchange[:i]
+		c.PrimaryExchange = exchange[i+1:]
+		return
+	}
+	c.Exchange = exchange
+}
+
+func setSpecOption(c *Contract, key string, value string) error {
+	switch key {
+	case "conid":
+		conID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid conid %s", value)
+		}
+		c.ContractID = conID
+	case "expiry":
+		c.Expiry = value
+	case "strike":
+		strike, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid strike %s", value)
+		}
+		c.Strike = strike
+	case "right":
+		c.Right = value
+	case "multiplier":
+		c.Multiplier = value
+	case "tradingclass":
+		c.TradingClass = value
+	case "localsymbol":
+		c.LocalSymbol = value
+	case "secid":
+		i := strings.Index(value, ":")
+		if i < 0 {
+			return fmt.Errorf("invalid secid %s, TYPE:ID is expected", value)
+		}
+		c.SecurityIDType, c.SecurityID = value[:i], value[i+1:]
+	case "currency":
+		c.Currency = value
+	case "exchange":
+		setSpecExchange(c, value)
+	case "includeexpired":
+		includeExpired, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid includeexpired %s", value)
+		}
+		c.IncludeExpired = includeExpired
+	default:
+		return fmt.Errorf("unknown key %s", key)
+	}
+	return nil
+}
+
+func parseRight(s string) (string, bool) {
+	switch strings.ToUpper(s) {
+	case "C", "CALL":
+		return "C", true
+	case "P", "PUT":
+		return "P", true
+	}
+	return "", false
+}
+
+// tokenizeSpec splits spec by whitespace, the double quoted parts are kept in a token
+func tokenizeSpec(spec string) ([]string, error) {
+	var tokens []string
+	var tok strings.Builder
+	inToken, inQuote := false, false
+
+	for i := 0; i < len(spec); i++ {
+		ch := spec[i]
+		switch {
+		case inQuote && ch == '\\' && i+1 < len(spec):
+			i++
+			tok.WriteByte(spec[i])
+		case ch == '"':
+			inQuote = !inQuote
+			inToken = true
+		case !inQuote && (ch == ' ' || ch == '\t'):
+			if inToken {
+				tokens = append(tokens, tok.String())
+				tok.Reset()
+				inToken = false
+			}
+		default:
+			tok.WriteByte(ch)
+			inToken = true
+		}
+	}
+
+	if inQuote {
+		return nil, fmt.Errorf("invalid contract spec %q: unterminated quote", spec)
+	}
+	if inToken {
+		tokens = append(tokens, tok.String())
+	}
+	return tokens, nil
+}
+
+func quoteSpec(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\"\\") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// ParseOCCSymbol parses the OCC option symbol, such as "SPY   241220C00450000", into a SMART OPT in USD
+func ParseOCCSymbol(symbol string) (*Contract, error) {
+	m := occSymbolRe.FindStringSubmatch(strings.TrimSpace(symbol))
+	if m == nil {
+		return nil, fmt.Errorf("invalid OCC option symbol %q", symbol)
+	}
+
+	strike, _ := strconv.ParseInt(m[4], 10, 64)
+	c := &Contract{
+		Symbol:       m[1],
+		SecurityType: "OPT",
+		Expiry:       "20" + m[2],
+		Strike:       float64(strike) / 1000,
+		Right:        m[3],
+		Exchange:     "SMART",
+		Currency:     "USD",
+	}
+	c.LocalSymbol, _ = FormatOCCSymbol(c)
+
+	return c, nil
+}
+
+// FormatOCCSymbol formats the option into the OCC option symbol, which is also the localSymbol of US options
+func FormatOCCSymbol(c *Contract) (string, error) {
+	if len(c.Symbol) == 0 || len(c.Symbol) > 6 {
+		return "", fmt.Errorf("invalid OCC root symbol %q", c.Symbol)
+	}
+	if len(c.Expiry) != 8 || !specExpiryRe.MatchString(c.Expiry) {
+		return "", fmt.Errorf("invalid OCC expiry %q, yyyymmdd is expected", c.Expiry)
+	}
+	right, ok := parseRight(c.Right)
+	if !ok {
+		return "", fmt.Errorf("invalid OCC right %q", c.Right)
+	}
+
+	strike := int64(c.Strike*1000 + 0.5)
+	if strike < 0 || strike > 99999999 {
+		return "", fmt.Errorf("invalid OCC strike %v", c.Strike)
+	}
+
+	return fmt.Sprintf("%-6s%s%s%08d", c.Symbol, c.Expiry[2:], right, strike), nil
+}
+
+// ParseFutureLocalSymbol parses the localSymbol of futures, such as "ESZ4", into a FUT with Symbol and LocalSymbol.
+// The expiry is not filled since the decade is unknown, use FutureLocalSymbolMonth if needed.
+func ParseFutureLocalSymbol(localSymbol string) (*Contract, error) {
+	m := futureLocalSymbolRe.FindStringSubmatch(localSymbol)
+	if m == nil || m[1] == "" {
+		return nil, fmt.Errorf("invalid future localSymbol %q", localSymbol)
+	}
+
+	return &Contract{Symbol: m[1], SecurityType: "FUT", LocalSymbol: localSymbol}, nil
+}
+
+// FutureLocalSymbolMonth returns the month and the year digits of the future localSymbol, such as 12 and "4" of "ESZ4"
+func FutureLocalSymbolMonth(localSymbol string) (month int, year string, err error) {
+	m := futureLocalSymbolRe.FindStringSubmatch(localSymbol)
+	if m == nil || m[1] == "" {
+		return 0, "", fmt.Errorf("invalid future localSymbol %q", localSymbol)
+	}
+
+	return futureMonthCodes[m[2]], m[3], nil
+}
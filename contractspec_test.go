@@ -0,0 +1,70 @@
+package ibapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseContract(t *testing.T) {
+	cases := map[string]Contract{
+		"AAPL STK SMART/NASDAQ USD":               {Symbol: "AAPL", SecurityType: "STK", Exchange: "SMART", PrimaryExchange: "NASDAQ", Currency: "USD"},
+		"ES FUT 202412 CME":                       {Symbol: "ES", SecurityType: "FUT", Expiry: "202412", Exchange: "CME"},
+		"SPY OPT 20241220 450 C SMART":            {Symbol: "SPY", SecurityType: "OPT", Expiry: "20241220", Strike: 450, Right: "C", Exchange: "SMART"},
+		"EUR CASH IDEALPRO usd":                   {Symbol: "EUR", SecurityType: "CASH", Exchange: "IDEALPRO", Currency: "USD"},
+		`"BRK B" STK USD secid=ISIN:US0846707026`: {Symbol: "BRK B", SecurityType: "STK", Currency: "USD", SecurityIDType: "ISIN", SecurityID: "US0846707026"},
+		"SPY   241220P00450500":                   {Symbol: "SPY", SecurityType: "OPT", Expiry: "20241220", Strike: 450.5, Right: "P", Exchange: "SMART", Currency: "USD", LocalSymbol: "SPY   241220P00450500"},
+		"ESZ4":                                    {Symbol: "ES", SecurityType: "FUT", LocalSymbol: "ESZ4"},
+	}
+
+	for spec, expected := range cases {
+		c, err := ParseContract(spec)
+		if err != nil {
+			t.Errorf("%s: %v", spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(*c, expected) {
+			t.Errorf("%s: unexpected contract %+v", spec, *c)
+		}
+	}
+
+	for _, spec := range []string{"", "AAPL", "AAPL XYZ", "ES FUT 202412 CME USD EXTRA", "AAPL STK conid=x", `"AAPL STK`} {
+		if _, err := ParseContract(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
+
+func TestFormatContractRoundTrip(t *testing.T) {
+	contracts := []Contract{
+		{Symbol: "AAPL", SecurityType: "STK", Exchange: "SMART", PrimaryExchange: "NASDAQ", Currency: "USD", ContractID: 265598},
+		{Symbol: "ES", SecurityType: "FUT", Expiry: "20241220", Exchange: "CME", Multiplier: "50", LocalSymbol: "ESZ4", TradingClass: "ES", IncludeExpired: true},
+		{Symbol: "SPY", SecurityType: "OPT", Expiry: "20241220", Strike: 450.5, Right: "P", Exchange: "SMART", LocalSymbol: "SPY   241220P00450500"},
+		{Symbol: "ES", SecurityType: "FOP", Right: "C", Exchange: "CME"},
+		{Symbol: "BRK B", SecurityType: "STK", Currency: "XXX", Expiry: "ignored", Strike: 1},
+		{Symbol: "EUR", SecurityType: "CASH", Exchange: "USD"},
+		{Symbol: "HSI", SecurityType: "IND", Currency: "HKD"},
+	}
+
+	for _, expected := range contracts {
+		spec := FormatContract(&expected)
+		c, err := ParseContract(spec)
+		if err != nil {
+			t.Errorf("%s: %v", spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(*c, expected) {
+			t.Errorf("%s: round trip mismatch %+v", spec, *c)
+		}
+	}
+}
+
+func TestOCCSymbol(t *testing.T) {
+	symbol, err := FormatOCCSymbol(&Contract{Symbol: "AAPL", Expiry: "20250117", Strike: 187.5, Right: "CALL"})
+	if err != nil || symbol != "AAPL  250117C00187500" {
+		t.Errorf("unexpected OCC symbol %q: %v", symbol, err)
+	}
+
+	if month, year, err := FutureLocalSymbolMonth("CLF5"); err != nil || month != 1 || year != "5" {
+		t.Errorf("unexpected month %d, year %s: %v", month, year, err)
+	}
+}
@@ -0,0 +1,336 @@
+package ibapi
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Decimal is the lossless decimal of IB for the sizes, volumes and quantities, such as fractional shares and crypto.
+/*
+The value is coef * 10^exp, the coefficient holds 18 significant digits, which is more than the 16 digits of the decimal
+of TWS. The zero value is 0 and UNSETDECIMAL is the unset one, which is encoded as an empty field. Decimals are normalized,
+so that the same values are ==, such as ParseDecimal("1.50") and NewDecimal(15, -1).
+*/
+type Decimal struct {
+	coef  int64
+	exp   int32
+	unset bool
+}
+
+// UNSETDECIMAL represent unset value of Decimal.
+var UNSETDECIMAL = Decimal{unset: true}
+
+var decimalType = reflect.TypeOf(Decimal{})
+
+// the fields TWS sends for an unset decimal
+var unsetDecimalFields = map[string]bool{
+	"":                        true,
+	"2147483647":              true,
+	"9223372036854775807":     true,
+	"-9223372036854775808":    true,
+	"1.7976931348623157E308":  true,
+	"1.7976931348623157e+308": true,
+	"170141183460469231731687303715884105727": true,
+}
+
+// maxDecimalExp bounds the exponent of the parsed decimal, which is ±398 for the decimal of TWS
+const maxDecimalExp = 400
+
+var (
+	errDecimalSyntax = errors.New("invalid decimal syntax")
+	errDecimalRange  = errors.New("decimal out of range")
+	bigTen           = big.NewInt(10)
+	bigMaxInt64      = big.NewInt(math.MaxInt64)
+	bigMinInt64      = big.NewInt(math.MinInt64)
+)
+
+// NewDecimal returns the Decimal of value * 10^exp, such as NewDecimal(12345, -2) for 123.45
+func NewDecimal(value int64, exp int32) Decimal {
+	d, err := decimalFromBig(big.NewInt(value), exp)
+	if err != nil {
+		// only a positive exp could overflow, keep it as it is
+		return Decimal{coef: value, exp: exp}
+	}
+	return d
+}
+
+// DecimalFromInt returns the Decimal of the integer i
+func DecimalFromInt(i int64) Decimal {
+	return NewDecimal(i, 0)
+}
+
+// DecimalFromFloat returns the Decimal of the shortest decimal of f, UNSETFLOAT, NaN and Inf are UNSETDECIMAL
+func DecimalFromFloat(f float64) Decimal {
+	if f == UNSETFLOAT || math.IsNaN(f) || math.IsInf(f, 0) {
+		return UNSETDECIMAL
+	}
+
+	d, err := ParseDecimal(strconv.FormatFloat(f, 'g', -1, 64))
+	if err != nil {
+		return UNSETDECIMAL
+	}
+	return d
+}
+
+// ParseDecimal parses the decimal s, such as "100", "-0.0001" or "1.5E-8".
+// The empty string and the unset values sent by TWS are UNSETDECIMAL.
+func ParseDecimal(s string) (Decimal, error) {
+	if unsetDecimalFields[s] {
+		return UNSETDECIMAL, nil
+	}
+
+	mantissa, exp := s, int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("%w: %q", errDecimalSyntax, s)
+		}
+		mantissa, exp = s[:i], e
+	}
+
+	neg := false
+	switch {
+	case strings.HasPrefix(mantissa, "-"):
+		neg, mantissa = true, mantissa[1:]
+	case strings.HasPrefix(mantissa, "+"):
+		mantissa = mantissa[1:]
+	}
+
+	digits := mantissa
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		digits = mantissa[:i] + mantissa[i+1:]
+		exp -= int64(len(mantissa) - i - 1)
+	}
+	if digits == "" || strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return Decimal{}, fmt.Errorf("%w: %q", errDecimalSyntax, s)
+	}
+	if exp < -maxDecimalExp || exp > maxDecimalExp {
+		return Decimal{}, fmt.Errorf("%w: %q", errDecimalRange, s)
+	}
+
+	coef, _ := new(big.Int).SetString(digits, 10)
+	if neg {
+		coef.Neg(coef)
+	}
+
+	d, err := decimalFromBig(coef, int32(exp))
+	if err != nil {
+		return Decimal{}, fmt.Errorf("%w: %q", err, s)
+	}
+	return d, nil
+}
+
+// decimalFromBig normalizes coef * 10^exp into a Decimal, the digits beyond the int64 coefficient are rounded half away from zero
+func decimalFromBig(coef *big.Int, exp int32) (Decimal, error) {
+	c := new(big.Int).Set(coef)
+	if c.Sign() == 0 {
+		return Decimal{}, nil
+	}
+
+	r := new(big.Int)
+	for c.CmpAbs(bigMaxInt64) > 0 && exp < 0 {
+		c.QuoRem(c, bigTen, r)
+		if r.CmpAbs(big.NewInt(5)) >= 0 {
+			if c.Sign() < 0 || r.Sign() < 0 {
+				c.Sub(c, big.NewInt(1))
+			} else {
+				c.Add(c, big.NewInt(1))
+			}
+		}
+		exp++
+	}
+
+	// strip the trailing zeros of the fraction
+	for exp < 0 {
+		q, m := new(big.Int).QuoRem(c, bigTen, r)
+		if m.Sign() != 0 {
+			break
+		}
+		c, exp = q, exp+1
+	}
+
+	// move the positive exponent into the coefficient as long as it fits
+	for exp > 0 {
+		m := new(big.Int).Mul(c, bigTen)
+		if m.Cmp(bigMaxInt64) > 0 || m.Cmp(bigMinInt64) < 0 {
+			break
+		}
+		c, exp = m, exp-1
+	}
+
+	if c.Cmp(bigMaxInt64) > 0 || c.Cmp(bigMinInt64) < 0 {
+		return Decimal{}, errDecimalRange
+	}
+	return Decimal{coef: c.Int64(), exp: exp}, nil
+}
+
+// IsUnset reports whether d is UNSETDECIMAL
+func (d Decimal) IsUnset() bool {
+	return d.unset
+}
+
+// IsZero reports whether d is 0
+func (d Decimal) IsZero() bool {
+	return !d.unset && d.coef == 0
+}
+
+// Sign returns -1, 0 or +1 by the sign of d, UNSETDECIMAL is 0
+func (d Decimal) Sign() int {
+	switch {
+	case d.unset || d.coef == 0:
+		return 0
+	case d.coef < 0:
+		return -1
+	default:
+		return 1
+	}
+}
+
+func (d Decimal) big() *big.Int {
+	return big.NewInt(d.coef)
+}
+
+// align returns the coefficients of d and o in the same exponent
+func (d Decimal) align(o Decimal) (*big.Int, *big.Int, int32) {
+	a, b := d.big(), o.big()
+	exp := d.exp
+	switch {
+	case d.exp > o.exp:
+		a.Mul(a, new(big.Int).Exp(bigTen, big.NewInt(int64(d.exp-o.exp)), nil))
+		exp = o.exp
+	case d.exp < o.exp:
+		b.Mul(b, new(big.Int).Exp(bigTen, big.NewInt(int64(o.exp-d.exp)), nil))
+	}
+	return a, b, exp
+}
+
+// Cmp compares d and o, it returns -1 if d < o, 0 if d == o and +1 if d > o. UNSETDECIMAL is less than any other decimal.
+func (d Decimal) Cmp(o Decimal) int {
+	switch {
+	case d.unset && o.unset:
+		return 0
+	case d.unset:
+		return -1
+	case o.unset:
+		return 1
+	}
+
+	a, b, _ := d.align(o)
+	return a.Cmp(b)
+}
+
+// Add returns d + o, it is UNSETDECIMAL if any of them is unset
+func (d Decimal) Add(o Decimal) Decimal {
+	if d.unset || o.unset {
+		return UNSETDECIMAL
+	}
+
+	a, b, exp := d.align(o)
+	r, err := decimalFromBig(a.Add(a, b), exp)
+	if err != nil {
+		return UNSETDECIMAL
+	}
+	return r
+}
+
+// Sub returns d - o, it is UNSETDECIMAL if any of them is unset
+func (d Decimal) Sub(o Decimal) Decimal {
+	return d.Add(o.Neg())
+}
+
+// Neg returns -d
+func (d Decimal) Neg() Decimal {
+	if d.unset {
+		return d
+	}
+
+	r, err := decimalFromBig(new(big.Int).Neg(d.big()), d.exp)
+	if err != nil {
+		return UNSETDECIMAL
+	}
+	return r
+}
+
+// Float64 returns the nearest float64 of d, UNSETDECIMAL is UNSETFLOAT
+func (d Decimal) Float64() float64 {
+	if d.unset {
+		return UNSETFLOAT
+	}
+
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// Int64 returns the integer part of d, UNSETDECIMAL is UNSETINT
+func (d Decimal) Int64() int64 {
+	if d.unset {
+		return UNSETINT
+	}
+	if d.exp >= 0 {
+		return d.coef * int64(math.Pow10(int(d.exp)))
+	}
+	if d.exp < -18 {
+		return 0
+	}
+	return d.coef / int64(math.Pow10(int(-d.exp)))
+}
+
+// String returns the plain decimal of d, such as "0.0001", UNSETDECIMAL is the empty string as it is encoded to TWS
+func (d Decimal) String() string {
+	if d.unset {
+		return ""
+	}
+
+	s := strconv.FormatInt(d.coef, 10)
+	if d.exp >= 0 {
+		return s + strings.Repeat("0", int(d.exp))
+	}
+
+	neg := d.coef < 0
+	if neg {
+		s = s[1:]
+	}
+	if n := int(-d.exp); len(s) <= n {
+		s = "0." + strings.Repeat("0", n-len(s)) + s
+	} else {
+		s = s[:len(s)-n] + "." + s[len(s)-n:]
+	}
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
+
+// MarshalText encodes d as String, so that Decimal is a string in json
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText decodes d by ParseDecimal
+func (d *Decimal) UnmarshalText(text []byte) error {
+	v, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+func (m *MsgBuffer) readDecimal() Decimal {
+	if !m.readField() {
+		return Decimal{}
+	}
+
+	d, err := ParseDecimal(string(m.bs))
+	if err != nil {
+		m.err = fmt.Errorf("decode decimal error: %w", err)
+		return Decimal{}
+	}
+
+	return d
+}
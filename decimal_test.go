@@ -0,0 +1,120 @@
+package ibapi
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	cases := []struct {
+		s    string
+		want Decimal
+		str  string
+	}{
+		{"100", NewDecimal(100, 0), "100"},
+		{"0", Decimal{}, "0"},
+		{"-0.0001", NewDecimal(-1, -4), "-0.0001"},
+		{"1.50", NewDecimal(15, -1), "1.5"},
+		{"+2.5", NewDecimal(25, -1), "2.5"},
+		{"1.5E-8", NewDecimal(15, -9), "0.000000015"},
+		{"1e3", DecimalFromInt(1000), "1000"},
+		{"1.23456789012345678951", NewDecimal(1234567890123456790, -18), "1.23456789012345679"},
+		{"", UNSETDECIMAL, ""},
+		{"2147483647", UNSETDECIMAL, ""},
+		{"9223372036854775807", UNSETDECIMAL, ""},
+		{"1.7976931348623157E308", UNSETDECIMAL, ""},
+		{"170141183460469231731687303715884105727", UNSETDECIMAL, ""},
+	}
+
+	for _, c := range cases {
+		d, err := ParseDecimal(c.s)
+		if err != nil {
+			t.Errorf("%q: %v", c.s, err)
+			continue
+		}
+		if d != c.want || d.String() != c.str {
+			t.Errorf("%q: got %#v %q, want %#v %q", c.s, d, d, c.want, c.str)
+		}
+	}
+
+	for _, s := range []string{"abc", "1.2.3", "-", "1e", "1e999", "1-2"} {
+		if _, err := ParseDecimal(s); err == nil {
+			t.Errorf("%q should be invalid", s)
+		}
+	}
+	if _, err := ParseDecimal("1e999"); !errors.Is(err, errDecimalRange) {
+		t.Errorf("1e999 should be out of range, got %v", err)
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	a, b := NewDecimal(1, -1), NewDecimal(2, -1)
+	if sum := a.Add(b); sum != NewDecimal(3, -1) || sum.String() != "0.3" {
+		t.Errorf("0.1 + 0.2 should be 0.3, got %s", sum)
+	}
+	if diff := a.Sub(b); diff != NewDecimal(-1, -1) || diff.Sign() != -1 {
+		t.Errorf("0.1 - 0.2 should be -0.1, got %s", diff)
+	}
+	if a.Cmp(b) != -1 || b.Cmp(a) != 1 || a.Cmp(NewDecimal(10, -2)) != 0 {
+		t.Error("unexpected Cmp")
+	}
+	if UNSETDECIMAL.Cmp(DecimalFromInt(-1)) != -1 || !a.Add(UNSETDECIMAL).IsUnset() {
+		t.Error("UNSETDECIMAL should be less than any decimal and absorb Add")
+	}
+	if !NewDecimal(0, 5).IsZero() || UNSETDECIMAL.IsZero() || UNSETDECIMAL.Sign() != 0 {
+		t.Error("unexpected IsZero or Sign")
+	}
+}
+
+func TestDecimalConversion(t *testing.T) {
+	if d := DecimalFromFloat(0.1); d != NewDecimal(1, -1) || d.Float64() != 0.1 {
+		t.Errorf("0.1 should be lossless, got %s", d)
+	}
+	if d := DecimalFromFloat(1e6); d != DecimalFromInt(1000000) || d.Int64() != 1000000 {
+		t.Errorf("1e6 should be 1000000, got %s", d)
+	}
+	if !DecimalFromFloat(UNSETFLOAT).IsUnset() || UNSETDECIMAL.Float64() != UNSETFLOAT || UNSETDECIMAL.Int64() != UNSETINT {
+		t.Error("the unset values should be converted to each other")
+	}
+	if i := NewDecimal(-25, -1).Int64(); i != -2 {
+		t.Errorf("the integer part of -2.5 should be -2, got %d", i)
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	in := struct {
+		Size  Decimal
+		Unset Decimal
+	}{NewDecimal(12345, -4), UNSETDECIMAL}
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"Size":"1.2345","Unset":""}` {
+		t.Errorf("unexpected json %s", data)
+	}
+
+	out := in
+	out.Size, out.Unset = Decimal{}, Decimal{}
+	if err := json.Unmarshal(data, &out); err != nil || out != in {
+		t.Errorf("json should round trip, got %+v, %v", out, err)
+	}
+}
+
+func TestReadDecimal(t *testing.T) {
+	msgBuf := NewMsgBuffer(makeMsgBytes("0.5", "", "2147483647", "x")[4:])
+	if d := msgBuf.readDecimal(); d != NewDecimal(5, -1) {
+		t.Errorf("unexpected decimal %s", d)
+	}
+	if d := msgBuf.readDecimal(); !d.IsUnset() {
+		t.Errorf("the empty field should be unset, got %s", d)
+	}
+	if d := msgBuf.readDecimal(); !d.IsUnset() {
+		t.Errorf("2147483647 should be unset, got %s", d)
+	}
+	if _ = msgBuf.readDecimal(); msgBuf.err == nil {
+		t.Error("x should fail to decode")
+	}
+}
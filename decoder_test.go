@@ -1,7 +1,9 @@
 package ibapi
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 	"testing"
 )
 
@@ -19,6 +21,85 @@ func TestDecodeLongName(t *testing.T) {
 	fmt.Println(longName)
 }
 
+func TestDecodeError(t *testing.T) {
+	w := &barWrapper{}
+	d := &ibDecoder{wrapper: w}
+	d.setVersion(151)
+	d.setmsgID2process()
+
+	cases := []struct {
+		name  string
+		msg   []byte
+		msgID IN
+	}{
+		{"bad msg id", []byte("x\x00"), NO_VALID_ID},
+		{"bad int", makeMsgBytes(mHISTORICAL_DATA_UPDATE, 2, "x", "20200526  16:20:00", 1, 2, 3, 4, 5, 6)[4:], mHISTORICAL_DATA_UPDATE},
+		{"bad float", makeMsgBytes(mHISTORICAL_DATA_UPDATE, 2, 209, "20200526  16:20:00", "x", 2, 3, 4, 5, 6)[4:], mHISTORICAL_DATA_UPDATE},
+		{"truncated", makeMsgBytes(mHISTORICAL_DATA_UPDATE, 2, 209, "20200526  16:20:00")[4:], mHISTORICAL_DATA_UPDATE},
+		{"unterminated", []byte("90\x002\x00209"), mHISTORICAL_DATA_UPDATE},
+		{"huge count", makeMsgBytes(mHISTORICAL_TICKS, 1, 9223372036854775807)[4:], mHISTORICAL_TICKS},
+	}
+
+	for _, c := range cases {
+		err := d.interpret(c.msg)
+		var de *DecodeError
+		if !errors.As(err, &de) {
+			t.Errorf("%s: expected DecodeError, got %v", c.name, err)
+			continue
+		}
+		if de.MsgID != c.msgID || string(de.MsgBytes) != string(c.msg) {
+			t.Errorf("%s: unexpected DecodeError %+v", c.name, de)
+		}
+	}
+	if w.bar != nil {
+		t.Errorf("malformed bar should not be delivered: %v", w.bar)
+	}
+
+	if err := d.interpret(makeMsgBytes(mSOFT_DOLLAR_TIERS, 1, -5)[4:]); err != nil {
+		t.Errorf("negative count should be decoded as empty: %v", err)
+	}
+	if err := d.interpret(makeMsgBytes(mHISTORICAL_DATA_UPDATE, 2, 209, "20200526  16:20:00", 1, 2, 3, 4, 5, 6)[4:]); err != nil || w.bar == nil {
+		t.Errorf("the next msg should be decoded: %v", err)
+	}
+}
+
+// panicWrapper panics in HistoricalDataUpdate
+type panicWrapper struct {
+	Wrapper
+}
+
+func (w *panicWrapper) HistoricalDataUpdate(reqID int64, bar *BarData) {
+	panic("callback bug")
+}
+
+func TestWrapperPanic(t *testing.T) {
+	d := &ibDecoder{wrapper: &panicWrapper{}}
+	d.setVersion(151)
+	d.setmsgID2process()
+
+	defer func() {
+		if r := recover(); r != "callback bug" {
+			t.Errorf("the panic of the wrapper should propagate, got %v", r)
+		}
+	}()
+	err := d.interpret(makeMsgBytes(mHISTORICAL_DATA_UPDATE, 2, 209, "20200526  16:20:00", 1, 2, 3, 4, 5, 6)[4:])
+	t.Errorf("the panic of the wrapper is recovered as %v", err)
+}
+
+func TestHandleDecodeError(t *testing.T) {
+	var got []*DecodeError
+	ic := newTestClient(func(ic *IbClient, req []byte) {})
+	ic.SetDecodeErrorHandler(func(de *DecodeError) { got = append(got, de) })
+
+	bad := []byte(strconv.FormatInt(mERR_MSG, 10) + "\x002\x00x\x00")
+	if err := ic.decoder.interpret(bad); err != nil {
+		ic.handleDecodeError(err)
+	}
+	if len(got) != 1 || got[0].MsgID != mERR_MSG || string(got[0].MsgBytes) != string(bad) {
+		t.Errorf("unexpected decode errors: %v", got)
+	}
+}
+
 func BenchmarkDecode(b *testing.B) {
 	// log, _ = zap.NewDevelopment()
 	msgUpdateAccountValue := []byte{54, 0, 50, 0, 78, 101, 116, 76, 105, 113, 117, 105, 100, 97, 116, 105, 111, 110, 66, 121, 67, 117, 114, 114, 101, 110, 99, 121, 0, 45, 49, 49, 48, 53, 54, 49, 50, 0, 72, 75, 68, 0, 68, 85, 49, 51, 56, 50, 56, 51, 55, 0}
@@ -0,0 +1,756 @@
+package ibapi
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// muteDuration is how long the late callbacks of a finished or canceled request are still discarded
+const muteDuration = time.Minute
+
+// reqHandler receives the callbacks of a request issued by the client itself
+type reqHandler func(msg interface{})
+
+// mailbox is an unbounded queue of callbacks, so that a slow or gone consumer never blocks the decoder
+type mailbox struct {
+	mu     sync.Mutex
+	msgs   []interface{}
+	notify chan struct{}
+}
+
+func newMailbox() *mailbox {
+	return &mailbox{notify: make(chan struct{}, 1)}
+}
+
+// put is a reqHandler
+func (m *mailbox) put(msg interface{}) {
+	m.mu.Lock()
+	m.msgs = append(m.msgs, msg)
+	m.mu.Unlock()
+
+	select {
+	case m.notify <- struct{}{}:
+	default:
+	}
+}
+
+// take returns all the queued msgs, call it after receiving from notify
+func (m *mailbox) take() []interface{} {
+	m.mu.Lock()
+	msgs := m.msgs
+	m.msgs = nil
+	m.mu.Unlock()
+	return msgs
+}
+
+// request registers a mailbox to the reqID id, calls send, then passes the msgs of id to handle until it returns true.
+/*
+The warnings reported by Error are logged and skipped, the other errors of id fail the request.
+It returns ctx.Err() if ctx is done first, the late callbacks of id are muted anyway.
+*/
+func (ic *IbClient) request(ctx context.Context, id int64, send func(), handle func(msg interface{}) bool) error {
+	return ic.await(ctx, ic.dispatcher.handlers, id, send, handle)
+}
+
+// requestOrder is request of the orderID id, whose callbacks are routed apart from those of the reqIDs
+func (ic *IbClient) requestOrder(ctx context.Context, id int64, send func(), handle func(msg interface{}) bool) error {
+	return ic.await(ctx, ic.dispatcher.orderHandlers, id, send, handle)
+}
+
+func (ic *IbClient) await(ctx context.Context, table *handlerTable, id int64, send func(), handle func(msg interface{}) bool) error {
+	mb := newMailbox()
+	ic.dispatcher.register(table, id, mb.put)
+	defer ic.dispatcher.mute(table, id, muteDuration)
+
+	send()
+
+	for {
+		select {
+		case <-mb.notify:
+			for _, msg := range mb.take() {
+				if m, ok := msg.(*errorMsg); ok {
+					if isWarningCode(m.code) {
+						log.Warn("request warning", zap.Int64("id", id), zap.Int64("errCode", m.code), zap.String("errString", m.msg))
+						continue
+					}
+					return m.err()
+				}
+
+				if handle(msg) {
+					return nil
+				}
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// errorMsg is delivered to a reqHandler when Error is called with its id
+type errorMsg struct {
+	code int64
+	msg  string
+}
+
+func (e errorMsg) err() error {
+	return IbError{e.code, e.msg}
+}
+
+// openOrderMsg is delivered to a reqHandler when OpenOrder is called with its id
+type openOrderMsg struct {
+	contract   *Contract
+	order      *Order
+	orderState *OrderState
+}
+
+// orderStatusMsg is delivered to a reqHandler when OrderStatus is called with its id
+type orderStatusMsg struct {
+	status    string
+	filled    Decimal
+	remaining Decimal
+}
+
+// dispatcher sits between the decoder and the user's IbWrapper.
+/*
+Callbacks belonging to the requests issued by the helpers of IbClient, such as PreviewOrder,
+are routed to the handler registered with the reqID or orderID, and are never delivered to the user's wrapper.
+Everything else is passed through to the wrapped IbWrapper.
+
+The reqIDs of GetReqID and the orderIDs of NextValidID overlap, so their handlers are kept apart:
+OpenOrder and OrderStatus only look up the orderIDs, and the ids of the orders placed by the user
+are never routed to the handler of a reqID, as Error carries either of them.
+*/
+type dispatcher struct {
+	IbWrapper
+	mu            sync.RWMutex
+	handlers      *handlerTable        // keyed by reqID
+	orderHandlers *handlerTable        // keyed by orderID
+	faHandlers    map[int64]reqHandler // ReceiveFA carries no reqID, so its handlers are keyed by faData
+	placedOrders  map[int64]bool       // the live orders placed by the user, see trackOrder
+	observers     []interface{}        // copy on write
+	muteSeq       uint64
+}
+
+// handlerTable is the reqHandlers keyed by reqID or orderID
+type handlerTable struct {
+	byID  map[int64]reqHandler
+	mutes map[int64]uint64 // the tokens of the muted ids, see mute
+}
+
+func newHandlerTable() *handlerTable {
+	return &handlerTable{
+		byID:  make(map[int64]reqHandler),
+		mutes: make(map[int64]uint64),
+	}
+}
+
+func newDispatcher(wrapper IbWrapper) *dispatcher {
+	return &dispatcher{
+		IbWrapper:     wrapper,
+		handlers:      newHandlerTable(),
+		orderHandlers: newHandlerTable(),
+		faHandlers:    make(map[int64]reqHandler),
+		placedOrders:  make(map[int64]bool),
+	}
+}
+
+// register routes the callbacks of id to h, the muted id of either table is claimed by it
+func (d *dispatcher) register(table *handlerTable, id int64, h reqHandler) {
+	d.mu.Lock()
+	d.unmute(id)
+	table.byID[id] = h
+	d.mu.Unlock()
+}
+
+// mute discards the late callbacks of id for a while before it is unregistered,
+// unless id is claimed by register or trackOrder in the meantime
+func (d *dispatcher) mute(table *handlerTable, id int64, after time.Duration) {
+	d.mu.Lock()
+	d.muteSeq++
+	token := d.muteSeq
+	table.byID[id] = func(interface{}) {}
+	table.mutes[id] = token
+	d.mu.Unlock()
+
+	time.AfterFunc(after, func() {
+		d.mu.Lock()
+		if t, ok := table.mutes[id]; ok && t == token {
+			delete(table.mutes, id)
+			delete(table.byID, id)
+		}
+		d.mu.Unlock()
+	})
+}
+
+// unmute unregisters the muted id of both tables, d.mu must be held
+func (d *dispatcher) unmute(id int64) {
+	for _, table := range []*handlerTable{d.handlers, d.orderHandlers} {
+		if _, ok := table.mutes[id]; ok {
+			delete(table.mutes, id)
+			delete(table.byID, id)
+		}
+	}
+}
+
+// failAll delivers the error to all the registered handlers, such as NOT_CONNECTED on disconnection,
+// so that the pending requests fail instead of waiting for their ctx
+func (d *dispatcher) failAll(errCode int64, errString string) {
+	d.mu.RLock()
+	hs := make([]reqHandler, 0, len(d.handlers.byID)+len(d.orderHandlers.byID)+len(d.faHandlers))
+	for _, table := range []map[int64]reqHandler{d.handlers.byID, d.orderHandlers.byID, d.faHandlers} {
+		for _, h := range table {
+			hs = append(hs, h)
+		}
+	}
+	d.mu.RUnlock()
+
+	for _, h := range hs {
+		h(&errorMsg{errCode, errString})
+	}
+}
+
+// trackOrder records orderID as placed by the user until its terminal OrderStatus,
+// so that its callbacks are never taken by the handler of a reqID or a muted request
+func (d *dispatcher) trackOrder(orderID int64) {
+	d.mu.Lock()
+	d.unmute(orderID)
+	d.placedOrders[orderID] = true
+	d.mu.Unlock()
+}
+
+// untrackOrder forgets orderID once it is done
+func (d *dispatcher) untrackOrder(orderID int64) {
+	d.mu.Lock()
+	delete(d.placedOrders, orderID)
+	d.mu.Unlock()
+}
+
+// isTerminalStatus reports if no more OrderStatus follows status
+func isTerminalStatus(status string) bool {
+	switch status {
+	case "Filled", "Cancelled", "ApiCancelled", "Inactive":
+		return true
+	}
+	return false
+}
+
+// registerFA routes the next ReceiveFA of faData to h, it fails if another one is waiting for the same faData
+func (d *dispatcher) registerFA(faData int64, h reqHandler) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.faHandlers[faData]; ok {
+		return false
+	}
+	d.faHandlers[faData] = h
+	return true
+}
+
+func (d *dispatcher) unregisterFA(faData int64) {
+	d.mu.Lock()
+	delete(d.faHandlers, faData)
+	d.mu.Unlock()
+}
+
+func (d *dispatcher) addObserver(o interface{}) {
+	d.mu.Lock()
+	observers := make([]interface{}, 0, len(d.observers)+1)
+	d.observers = append(append(observers, d.observers...), o)
+	d.mu.Unlock()
+}
+
+func (d *dispatcher) removeObserver(o interface{}) {
+	d.mu.Lock()
+	observers := make([]interface{}, 0, len(d.observers))
+	for _, obs := range d.observers {
+		if obs != o {
+			observers = append(observers, obs)
+		}
+	}
+	d.observers = observers
+	d.mu.Unlock()
+}
+
+func (d *dispatcher) observerList() []interface{} {
+	d.mu.RLock()
+	observers := d.observers
+	d.mu.RUnlock()
+	return observers
+}
+
+func (d *dispatcher) handler(id int64) reqHandler {
+	d.mu.RLock()
+	h := d.handlers.byID[id]
+	d.mu.RUnlock()
+	return h
+}
+
+func (d *dispatcher) orderHandler(id int64) reqHandler {
+	d.mu.RLock()
+	h := d.orderHandlers.byID[id]
+	d.mu.RUnlock()
+	return h
+}
+
+// errorHandler is the handler of the id of Error, which is the handler of the orderID first,
+// then that of the reqID unless id is an order placed by the user
+func (d *dispatcher) errorHandler(id int64) reqHandler {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if h := d.orderHandlers.byID[id]; h != nil {
+		return h
+	}
+	if d.placedOrders[id] {
+		return nil
+	}
+	return d.handlers.byID[id]
+}
+
+// observeError passes the Error to the observers, such as ConnectivityMonitor, whether it is routed to a handler or not
+func (d *dispatcher) observeError(reqID int64, errCode int64, errString string) {
+	for _, o := range d.observerList() {
+		if o, ok := o.(interface {
+			Error(reqID int64, errCode int64, errString string)
+		}); ok {
+			o.Error(reqID, errCode, errString)
+		}
+	}
+}
+
+func (d *dispatcher) Error(reqID int64, errCode int64, errString string) {
+	d.observeError(reqID, errCode, errString)
+	if h := d.errorHandler(reqID); h != nil {
+		h(&errorMsg{errCode, errString})
+		return
+	}
+	d.IbWrapper.Error(reqID, errCode, errString)
+}
+
+func (d *dispatcher) ErrorWithAdvancedOrderReject(reqID int64, errCode int64, errString string, advancedOrderRejectJSON string) {
+	d.observeError(reqID, errCode, errString)
+	if h := d.errorHandler(reqID); h != nil {
+		h(&errorMsg{errCode, errString})
+		return
+	}
+	if w, ok := d.IbWrapper.(AdvancedOrderRejecter); ok {
+		w.ErrorWithAdvancedOrderReject(reqID, errCode, errString, advancedOrderRejectJSON)
+		return
+	}
+	d.IbWrapper.Error(reqID, errCode, errString)
+}
+
+func (d *dispatcher) OpenOrder(orderID int64, contract *Contract, order *Order, orderState *OrderState) {
+	if h := d.orderHandler(orderID); h != nil {
+		h(&openOrderMsg{contract, order, orderState})
+		return
+	}
+	d.IbWrapper.OpenOrder(orderID, contract, order, orderState)
+}
+
+func (d *dispatcher) OrderStatus(orderID int64, status string, filled Decimal, remaining Decimal, avgFillPrice float64, permID int64, parentID int64, lastFillPrice float64, clientID int64, whyHeld string, mktCapPrice float64) {
+	if isTerminalStatus(status) {
+		d.untrackOrder(orderID)
+	}
+	if h := d.orderHandler(orderID); h != nil {
+		h(&orderStatusMsg{status, filled, remaining})
+		return
+	}
+	d.IbWrapper.OrderStatus(orderID, status, filled, remaining, avgFillPrice, permID, parentID, lastFillPrice, clientID, whyHeld, mktCapPrice)
+}
+
+// pnlSingleMsg is delivered to a reqHandler when PnlSingle is called with its id
+type pnlSingleMsg struct {
+	position      Decimal
+	dailyPnL      float64
+	unrealizedPnL float64
+	realizedPnL   float64
+	value         float64
+}
+
+func (d *dispatcher) PnlSingle(reqID int64, position Decimal, dailyPnL float64, unrealizedPnL float64, realizedPnL float64, value float64) {
+	if h := d.handler(reqID); h != nil {
+		h(&pnlSingleMsg{position, dailyPnL, unrealizedPnL, realizedPnL, value})
+		return
+	}
+	d.IbWrapper.PnlSingle(reqID, position, dailyPnL, unrealizedPnL, realizedPnL, value)
+}
+
+// receiveFAMsg is delivered to a reqHandler registered by registerFA
+type receiveFAMsg struct {
+	faData int64
+	cxml   string
+}
+
+// replaceFAEndMsg is delivered to a reqHandler when ReplaceFAEnd is called with its id
+type replaceFAEndMsg struct {
+	text string
+}
+
+func (d *dispatcher) ReceiveFA(faData int64, cxml string) {
+	d.mu.RLock()
+	h := d.faHandlers[faData]
+	d.mu.RUnlock()
+	if h != nil {
+		h(&receiveFAMsg{faData, cxml})
+		return
+	}
+	d.IbWrapper.ReceiveFA(faData, cxml)
+}
+
+func (d *dispatcher) ReplaceFAEnd(reqID int64, text string) {
+	if h := d.handler(reqID); h != nil {
+		h(&replaceFAEndMsg{text})
+		return
+	}
+	d.IbWrapper.ReplaceFAEnd(reqID, text)
+}
+
+/*
+   #########################################################################
+   ################## Observable callbacks
+   #########################################################################
+*/
+
+func (d *dispatcher) ConnectionClosed() {
+	for _, o := range d.observerList() {
+		if o, ok := o.(interface{ ConnectionClosed() }); ok {
+			o.ConnectionClosed()
+		}
+	}
+	d.IbWrapper.ConnectionClosed()
+}
+
+func (d *dispatcher) UpdateAccountValue(tag string, val string, currency string, accName string) {
+	for _, o := range d.observerList() {
+		if o, ok := o.(interface {
+			UpdateAccountValue(tag string, val string, currency string, accName string)
+		}); ok {
+			o.UpdateAccountValue(tag, val, currency, accName)
+		}
+	}
+	d.IbWrapper.UpdateAccountValue(tag, val, currency, accName)
+}
+
+func (d *dispatcher) AccountDownloadEnd(accName string) {
+	for _, o := range d.observerList() {
+		if o, ok := o.(interface{ AccountDownloadEnd(accName string) }); ok {
+			o.AccountDownloadEnd(accName)
+		}
+	}
+	d.IbWrapper.AccountDownloadEnd(accName)
+}
+
+func (d *dispatcher) AccountSummary(reqID int64, account string, tag string, value string, currency string) {
+	for _, o := range d.observerList() {
+		if o, ok := o.(interface {
+			AccountSummary(reqID int64, account string, tag string, value string, currency string)
+		}); ok {
+			o.AccountSummary(reqID, account, tag, value, currency)
+		}
+	}
+	d.IbWrapper.AccountSummary(reqID, account, tag, value, currency)
+}
+
+func (d *dispatcher) AccountSummaryEnd(reqID int64) {
+	for _, o := range d.observerList() {
+		if o, ok := o.(interface{ AccountSummaryEnd(reqID int64) }); ok {
+			o.AccountSummaryEnd(reqID)
+		}
+	}
+	d.IbWrapper.AccountSummaryEnd(reqID)
+}
+
+func (d *dispatcher) AccountUpdateMulti(reqID int64, account string, modelCode string, tag string, value string, currency string) {
+	for _, o := range d.observerList() {
+		if o, ok := o.(interface {
+			AccountUpdateMulti(reqID int64, account string, modelCode string, tag string, value string, currency string)
+		}); ok {
+			o.AccountUpdateMulti(reqID, account, modelCode, tag, value, currency)
+		}
+	}
+	d.IbWrapper.AccountUpdateMulti(reqID, account, modelCode, tag, value, currency)
+}
+
+func (d *dispatcher) AccountUpdateMultiEnd(reqID int64) {
+	for _, o := range d.observerList() {
+		if o, ok := o.(interface{ AccountUpdateMultiEnd(reqID int64) }); ok {
+			o.AccountUpdateMultiEnd(reqID)
+		}
+	}
+	d.IbWrapper.AccountUpdateMultiEnd(reqID)
+}
+
+func (d *dispatcher) UpdatePortfolio(contract *Contract, position Decimal, marketPrice float64, marketValue float64, averageCost float64, unrealizedPNL float64, realizedPNL float64, accName string) {
+	for _, o := range d.observerList() {
+		if o, ok := o.(interface {
+			UpdatePortfolio(contract *Contract, position Decimal, marketPrice float64, marketValue float64, averageCost float64, unrealizedPNL float64, realizedPNL float64, accName string)
+		}); ok {
+			o.UpdatePortfolio(contract, position, marketPrice, marketValue, averageCost, unrealizedPNL, realizedPNL, accName)
+		}
+	}
+	d.IbWrapper.UpdatePortfolio(contract, position, marketPrice, marketValue, averageCost, unrealizedPNL, realizedPNL, accName)
+}
+
+func (d *dispatcher) Position(account string, contract *Contract, position Decimal, avgCost float64) {
+	for _, o := range d.observerList() {
+		if o, ok := o.(interface {
+			Position(account string, contract *Contract, position Decimal, avgCost float64)
+		}); ok {
+			o.Position(account, contract, position, avgCost)
+		}
+	}
+	d.IbWrapper.Position(account, contract, position, avgCost)
+}
+
+func (d *dispatcher) PositionEnd() {
+	for _, o := range d.observerList() {
+		if o, ok := o.(interface{ PositionEnd() }); ok {
+			o.PositionEnd()
+		}
+	}
+	d.IbWrapper.PositionEnd()
+}
+
+func (d *dispatcher) PositionMulti(reqID int64, account string, modelCode string, contract *Contract, position Decimal, avgCost float64) {
+	for _, o := range d.observerList() {
+		if o, ok := o.(interface {
+			PositionMulti(reqID int64, account string, modelCode string, contract *Contract, position Decimal, avgCost float64)
+		}); ok {
+			o.PositionMulti(reqID, account, modelCode, contract, position, avgCost)
+		}
+	}
+	d.IbWrapper.PositionMulti(reqID, account, modelCode, contract, position, avgCost)
+}
+
+func (d *dispatcher) PositionMultiEnd(reqID int64) {
+	for _, o := range d.observerList() {
+		if o, ok := o.(interface{ PositionMultiEnd(reqID int64) }); ok {
+			o.PositionMultiEnd(reqID)
+		}
+	}
+	d.IbWrapper.PositionMultiEnd(reqID)
+}
+
+// contractDetailsMsg is delivered to a reqHandler when ContractDetails or BondContractDetails is called with its id
+type contractDetailsMsg struct {
+	conDetails *ContractDetails
+}
+
+// contractDetailsEndMsg is delivered to a reqHandler when ContractDetailsEnd is called with its id
+type contractDetailsEndMsg struct{}
+
+func (d *dispatcher) ContractDetails(reqID int64, conDetails *ContractDetails) {
+	if h := d.handler(reqID); h != nil {
+		h(&contractDetailsMsg{conDetails})
+		return
+	}
+	d.IbWrapper.ContractDetails(reqID, conDetails)
+}
+
+func (d *dispatcher) BondContractDetails(reqID int64, conDetails *ContractDetails) {
+	if h := d.handler(reqID); h != nil {
+		h(&contractDetailsMsg{conDetails})
+		return
+	}
+	d.IbWrapper.BondContractDetails(reqID, conDetails)
+}
+
+func (d *dispatcher) ContractDetailsEnd(reqID int64) {
+	if h := d.handler(reqID); h != nil {
+		h(&contractDetailsEndMsg{})
+		return
+	}
+	d.IbWrapper.ContractDetailsEnd(reqID)
+}
+
+// historicalDataMsg is delivered to a reqHandler when HistoricalData is called with its id
+type historicalDataMsg struct {
+	bar *BarData
+}
+
+// historicalDataEndMsg is delivered to a reqHandler when HistoricalDataEnd is called with its id
+type historicalDataEndMsg struct {
+	startDateStr string
+	endDateStr   string
+}
+
+func (d *dispatcher) HistoricalData(reqID int64, bar *BarData) {
+	if h := d.handler(reqID); h != nil {
+		h(&historicalDataMsg{bar})
+		return
+	}
+	d.IbWrapper.HistoricalData(reqID, bar)
+}
+
+func (d *dispatcher) HistoricalDataEnd(reqID int64, startDateStr string, endDateStr string) {
+	if h := d.handler(reqID); h != nil {
+		h(&historicalDataEndMsg{startDateStr, endDateStr})
+		return
+	}
+	d.IbWrapper.HistoricalDataEnd(reqID, startDateStr, endDateStr)
+}
+
+// historicalScheduleMsg is delivered to a reqHandler when HistoricalSchedule is called with its id
+type historicalScheduleMsg struct {
+	schedule HistoricalSchedule
+}
+
+func (d *dispatcher) HistoricalSchedule(reqID int64, startDateTime string, endDateTime string, timeZone string, sessions []HistoricalSession) {
+	if h := d.handler(reqID); h != nil {
+		h(&historicalScheduleMsg{HistoricalSchedule{startDateTime, endDateTime, timeZone, sessions}})
+		return
+	}
+	d.IbWrapper.HistoricalSchedule(reqID, startDateTime, endDateTime, timeZone, sessions)
+}
+
+// fundamentalDataMsg is delivered to a reqHandler when FundamentalData is called with its id
+type fundamentalDataMsg struct {
+	data string
+}
+
+func (d *dispatcher) FundamentalData(reqID int64, data string) {
+	if h := d.handler(reqID); h != nil {
+		h(&fundamentalDataMsg{data})
+		return
+	}
+	d.IbWrapper.FundamentalData(reqID, data)
+}
+
+// wshMetaDataMsg is delivered to a reqHandler when WshMetaData is called with its id
+type wshMetaDataMsg struct {
+	dataJSON string
+}
+
+// wshEventDataMsg is delivered to a reqHandler when WshEventData is called with its id
+type wshEventDataMsg struct {
+	dataJSON string
+}
+
+func (d *dispatcher) WshMetaData(reqID int64, dataJson string) {
+	if h := d.handler(reqID); h != nil {
+		h(&wshMetaDataMsg{dataJson})
+		return
+	}
+	d.IbWrapper.WshMetaData(reqID, dataJson)
+}
+
+func (d *dispatcher) WshEventData(reqID int64, dataJson string) {
+	if h := d.handler(reqID); h != nil {
+		h(&wshEventDataMsg{dataJson})
+		return
+	}
+	d.IbWrapper.WshEventData(reqID, dataJson)
+}
+
+// tickNewsMsg is delivered to a reqHandler when TickNews is called with its id
+type tickNewsMsg struct {
+	timeStamp    int64
+	providerCode string
+	articleID    string
+	headline     string
+	extraData    string
+}
+
+// newsArticleMsg is delivered to a reqHandler when NewsArticle is called with its id
+type newsArticleMsg struct {
+	articleType int64
+	articleText string
+}
+
+// historicalNewsMsg is delivered to a reqHandler when HistoricalNews is called with its id
+type historicalNewsMsg struct {
+	time         string
+	providerCode string
+	articleID    string
+	headline     string
+}
+
+// historicalNewsEndMsg is delivered to a reqHandler when HistoricalNewsEnd is called with its id
+type historicalNewsEndMsg struct {
+	hasMore bool
+}
+
+func (d *dispatcher) TickNews(tickerID int64, timeStamp int64, providerCode string, articleID string, headline string, extraData string) {
+	if h := d.handler(tickerID); h != nil {
+		h(&tickNewsMsg{timeStamp, providerCode, articleID, headline, extraData})
+		return
+	}
+	d.IbWrapper.TickNews(tickerID, timeStamp, providerCode, articleID, headline, extraData)
+}
+
+func (d *dispatcher) NewsProviders(newsProviders []NewsProvider) {
+	for _, o := range d.observerList() {
+		if o, ok := o.(interface {
+			NewsProviders(newsProviders []NewsProvider)
+		}); ok {
+			o.NewsProviders(newsProviders)
+		}
+	}
+	d.IbWrapper.NewsProviders(newsProviders)
+}
+
+func (d *dispatcher) NewsArticle(reqID int64, articleType int64, articleText string) {
+	if h := d.handler(reqID); h != nil {
+		h(&newsArticleMsg{articleType, articleText})
+		return
+	}
+	d.IbWrapper.NewsArticle(reqID, articleType, articleText)
+}
+
+func (d *dispatcher) HistoricalNews(reqID int64, time string, providerCode string, articleID string, headline string) {
+	if h := d.handler(reqID); h != nil {
+		h(&historicalNewsMsg{time, providerCode, articleID, headline})
+		return
+	}
+	d.IbWrapper.HistoricalNews(reqID, time, providerCode, articleID, headline)
+}
+
+func (d *dispatcher) HistoricalNewsEnd(reqID int64, hasMore bool) {
+	if h := d.handler(reqID); h != nil {
+		h(&historicalNewsEndMsg{hasMore})
+		return
+	}
+	d.IbWrapper.HistoricalNewsEnd(reqID, hasMore)
+}
+
+// scannerDataMsg is delivered to a reqHandler when ScannerData is called with its id
+type scannerDataMsg struct {
+	data ScanData
+}
+
+// scannerDataEndMsg is delivered to a reqHandler when ScannerDataEnd is called with its id
+type scannerDataEndMsg struct{}
+
+func (d *dispatcher) ScannerParameters(xml string) {
+	for _, o := range d.observerList() {
+		if o, ok := o.(interface{ ScannerParameters(xml string) }); ok {
+			o.ScannerParameters(xml)
+		}
+	}
+	d.IbWrapper.ScannerParameters(xml)
+}
+
+func (d *dispatcher) ScannerData(reqID int64, rank int64, conDetails *ContractDetails, distance string, benchmark string, projection string, legs string) {
+	if h := d.handler(reqID); h != nil {
+		h(&scannerDataMsg{ScanData{
+			ContractDetails: *conDetails,
+			Rank:            rank,
+			Distance:        distance,
+			Benchmark:       benchmark,
+			Projection:      projection,
+			Legs:            legs,
+		}})
+		return
+	}
+	d.IbWrapper.ScannerData(reqID, rank, conDetails, distance, benchmark, projection, legs)
+}
+
+func (d *dispatcher) ScannerDataEnd(reqID int64) {
+	if h := d.handler(reqID); h != nil {
+		h(&scannerDataEndMsg{})
+		return
+	}
+	d.IbWrapper.ScannerDataEnd(reqID)
+}
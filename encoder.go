@@ -0,0 +1,1937 @@
+package ibapi
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Encode* are the pure encoders of the requests to TWS.
+/*
+Each of them encodes the request of the IbClient method of the same name for the server version, the bytes returned are
+the msg with the size header, which is what the IbClient writes to the socket. A request which is not supported by the
+server version is returned as *RequestError of UPDATE_TWS instead of being reported to the wrapper, so the encoders could
+be used without a connection, such as by a recorder or in tests:
+
+	msg, err := ibapi.EncodePlaceOrder(ibapi.MAX_CLIENT_VER, orderID, contract, order)
+	if errors.Is(err, ibapi.UPDATE_TWS) {
+		...
+	}
+*/
+
+// EncodeSetServerLogLevel encodes the request of SetServerLogLevel
+func EncodeSetServerLogLevel(serverVersion Version, logLevel int64) ([]byte, error) {
+	// v := 1
+	const v = 1
+	fields := make([]interface{}, 0, 3)
+	fields = append(fields,
+		mSET_SERVER_LOGLEVEL,
+		v,
+		logLevel,
+	)
+
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeReqMktData encodes the request of ReqMktData
+func EncodeReqMktData(serverVersion Version, reqID int64, contract *Contract, genericTickList string, snapshot bool, regulatorySnapshot bool, mktDataOptions []TagValue) ([]byte, error) {
+	switch {
+	case serverVersion < mMIN_SERVER_VER_DELTA_NEUTRAL && contract.DeltaNeutralContract != nil:
+		return nil, errUpdateTWS(reqID, "  It does not support delta-neutral orders.")
+	case serverVersion < mMIN_SERVER_VER_REQ_MKT_DATA_CONID && contract.ContractID > 0:
+		return nil, errUpdateTWS(reqID, "  It does not support conId parameter.")
+	case serverVersion < mMIN_SERVER_VER_TRADING_CLASS && contract.TradingClass != "":
+		return nil, errUpdateTWS(reqID, "  It does not support tradingClass parameter in reqMktData.")
+	}
+
+	// v := 11
+	const v = 11
+	fields := make([]interface{}, 0, 30)
+	fields = append(fields,
+		mREQ_MKT_DATA,
+		v,
+		reqID,
+	)
+
+	if serverVersion >= mMIN_SERVER_VER_REQ_MKT_DATA_CONID {
+		fields = append(fields, contract.ContractID)
+	}
+
+	fields = append(fields,
+		contract.Symbol,
+		contract.SecurityType,
+		contract.Expiry,
+		contract.Strike,
+		contract.Right,
+		contract.Multiplier,
+		contract.Exchange,
+		contract.PrimaryExchange,
+		contract.Currency,
+		contract.LocalSymbol)
+
+	if serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
+		fields = append(fields, contract.TradingClass)
+	}
+
+	if contract.SecurityType == "BAG" {
+		comboLegsCount := len(contract.ComboLegs)
+		fields = append(fields, comboLegsCount)
+		for _, comboLeg := range contract.ComboLegs {
+			fields = append(fields,
+				comboLeg.ContractID,
+				comboLeg.Ratio,
+				comboLeg.Action,
+				comboLeg.Exchange)
+		}
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_DELTA_NEUTRAL {
+		if contract.DeltaNeutralContract != nil {
+			fields = append(fields,
+				true,
+				contract.DeltaNeutralContract.ContractID,
+				contract.DeltaNeutralContract.Delta,
+				contract.DeltaNeutralContract.Price)
+		} else {
+			fields = append(fields, false)
+		}
+	}
+
+	fields = append(fields,
+		genericTickList,
+		snapshot)
+
+	if serverVersion >= mMIN_SERVER_VER_REQ_SMART_COMPONENTS {
+		fields = append(fields, regulatorySnapshot)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_LINKING {
+		var mktDataOptionsBuffer bytes.Buffer
+		for _, tv := range mktDataOptions {
+			mktDataOptionsBuffer.WriteString(tv.Tag)
+			mktDataOptionsBuffer.WriteString("=")
+			mktDataOptionsBuffer.WriteString(tv.Value)
+			mktDataOptionsBuffer.WriteString(";")
+		}
+		fields = append(fields, mktDataOptionsBuffer.Bytes())
+	}
+
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeCancelMktData encodes the request of CancelMktData
+func EncodeCancelMktData(serverVersion Version, reqID int64) ([]byte, error) {
+	// v := 2
+	const v = 2
+	fields := make([]interface{}, 0, 3)
+	fields = append(fields,
+		mCANCEL_MKT_DATA,
+		v,
+		reqID,
+	)
+
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeReqMarketDataType encodes the request of ReqMarketDataType
+func EncodeReqMarketDataType(serverVersion Version, marketDataType int64) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_REQ_MARKET_DATA_TYPE {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support market data type requests.")
+	}
+
+	// v := 1
+	const v = 1
+	fields := make([]interface{}, 0, 3)
+	fields = append(fields, mREQ_MARKET_DATA_TYPE, v, marketDataType)
+
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeReqSmartComponents encodes the request of ReqSmartComponents
+func EncodeReqSmartComponents(serverVersion Version, reqID int64, bboExchange string) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_REQ_SMART_COMPONENTS {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support smart components request.")
+	}
+
+	return makeMsgBytes(mREQ_SMART_COMPONENTS, reqID, bboExchange), nil
+}
+
+// EncodeReqMarketRule encodes the request of ReqMarketRule
+func EncodeReqMarketRule(serverVersion Version, marketRuleID int64) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_MARKET_RULES {
+		return nil, errUpdateTWS(NO_VALID_ID, " It does not support market rule requests.")
+	}
+
+	return makeMsgBytes(mREQ_MARKET_RULE, marketRuleID), nil
+}
+
+// EncodeReqTickByTickData encodes the request of ReqTickByTickData
+func EncodeReqTickByTickData(serverVersion Version, reqID int64, contract *Contract, tickType string, numberOfTicks int64, ignoreSize bool) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_TICK_BY_TICK {
+		return nil, errUpdateTWS(NO_VALID_ID, " It does not support tick-by-tick data requests.")
+	}
+
+	if serverVersion < mMIN_SERVER_VER_TICK_BY_TICK_IGNORE_SIZE {
+		return nil, errUpdateTWS(NO_VALID_ID, " It does not support ignoreSize and numberOfTicks parameters in tick-by-tick data requests.")
+	}
+
+	fields := make([]interface{}, 0, 16)
+	fields = append(fields, mREQ_TICK_BY_TICK_DATA,
+		reqID,
+		contract.ContractID,
+		contract.Symbol,
+		contract.SecurityType,
+		contract.Expiry,
+		contract.Strike,
+		contract.Right,
+		contract.Multiplier,
+		contract.Exchange,
+		contract.PrimaryExchange,
+		contract.Currency,
+		contract.LocalSymbol,
+		contract.TradingClass,
+		tickType)
+
+	if serverVersion >= mMIN_SERVER_VER_TICK_BY_TICK_IGNORE_SIZE {
+		fields = append(fields, numberOfTicks, ignoreSize)
+	}
+
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeCancelTickByTickData encodes the request of CancelTickByTickData
+func EncodeCancelTickByTickData(serverVersion Version, reqID int64) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_TICK_BY_TICK {
+		return nil, errUpdateTWS(NO_VALID_ID, " It does not support tick-by-tick data requests.")
+	}
+
+	return makeMsgBytes(mCANCEL_TICK_BY_TICK_DATA, reqID), nil
+}
+
+// EncodeCalculateImpliedVolatility encodes the request of CalculateImpliedVolatility
+func EncodeCalculateImpliedVolatility(serverVersion Version, reqID int64, contract *Contract, optionPrice float64, underPrice float64, impVolOptions []TagValue) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_REQ_CALC_IMPLIED_VOLAT {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support calculateImpliedVolatility req.")
+	}
+
+	if serverVersion < mMIN_SERVER_VER_TRADING_CLASS && contract.TradingClass != "" {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support tradingClass parameter in calculateImpliedVolatility.")
+	}
+
+	// v := 3
+	const v = 3
+	fields := make([]interface{}, 0, 19)
+	fields = append(fields,
+		mREQ_CALC_IMPLIED_VOLAT,
+		v,
+		reqID,
+		contract.ContractID,
+		contract.Symbol,
+		contract.SecurityID,
+		contract.Expiry,
+		contract.Strike,
+		contract.Right,
+		contract.Multiplier,
+		contract.Exchange,
+		contract.PrimaryExchange,
+		contract.Currency,
+		contract.LocalSymbol)
+
+	if serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
+		fields = append(fields, contract.TradingClass)
+	}
+
+	fields = append(fields, optionPrice, underPrice)
+
+	if serverVersion >= mMIN_SERVER_VER_LINKING {
+		var implVolOptBuffer bytes.Buffer
+		tagValuesCount := len(impVolOptions)
+		fields = append(fields, tagValuesCount)
+		for _, tv := range impVolOptions {
+			implVolOptBuffer.WriteString(tv.Tag)
+			implVolOptBuffer.WriteString("=")
+			implVolOptBuffer.WriteString(tv.Value)
+			implVolOptBuffer.WriteString(";")
+		}
+		fields = append(fields, implVolOptBuffer.Bytes())
+	}
+
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeCalculateOptionPrice encodes the request of CalculateOptionPrice
+func EncodeCalculateOptionPrice(serverVersion Version, reqID int64, contract *Contract, volatility float64, underPrice float64, optPrcOptions []TagValue) ([]byte, error) {
+
+	if serverVersion < mMIN_SERVER_VER_REQ_CALC_IMPLIED_VOLAT {
+		return nil, errUpdateTWS(reqID, "  It does not support calculateImpliedVolatility req.")
+	}
+
+	if serverVersion < mMIN_SERVER_VER_TRADING_CLASS {
+		return nil, errUpdateTWS(reqID, "  It does not support tradingClass parameter in calculateImpliedVolatility.")
+	}
+
+	// v := 3
+	const v = 3
+	fields := make([]interface{}, 0, 19)
+	fields = append(fields,
+		mREQ_CALC_OPTION_PRICE,
+		v,
+		reqID,
+		contract.ContractID,
+		contract.Symbol,
+		contract.SecurityID,
+		contract.Expiry,
+		contract.Strike,
+		contract.Right,
+		contract.Multiplier,
+		contract.Exchange,
+		contract.PrimaryExchange,
+		contract.Currency,
+		contract.LocalSymbol)
+
+	if serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
+		fields = append(fields, contract.TradingClass)
+	}
+
+	fields = append(fields, volatility, underPrice)
+
+	if serverVersion >= mMIN_SERVER_VER_LINKING {
+		var optPrcOptBuffer bytes.Buffer
+		tagValuesCount := len(optPrcOptions)
+		fields = append(fields, tagValuesCount)
+		for _, tv := range optPrcOptions {
+			optPrcOptBuffer.WriteString(tv.Tag)
+			optPrcOptBuffer.WriteString("=")
+			optPrcOptBuffer.WriteString(tv.Value)
+			optPrcOptBuffer.WriteString(";")
+		}
+
+		fields = append(fields, optPrcOptBuffer.Bytes())
+	}
+
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeCancelCalculateOptionPrice encodes the request of CancelCalculateOptionPrice
+func EncodeCancelCalculateOptionPrice(serverVersion Version, reqID int64) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_REQ_CALC_IMPLIED_VOLAT {
+		return nil, errUpdateTWS(reqID, "  It does not support calculateImpliedVolatility req.")
+	}
+
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mCANCEL_CALC_OPTION_PRICE, v, reqID), nil
+}
+
+// EncodeExerciseOptions encodes the request of ExerciseOptions
+func EncodeExerciseOptions(serverVersion Version, reqID int64, contract *Contract, exerciseAction int, exerciseQuantity int, account string, override int) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_TRADING_CLASS && contract.TradingClass != "" {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support conId, multiplier, tradingClass parameter in exerciseOptions.")
+	}
+
+	// v := 2
+	const v = 2
+	fields := make([]interface{}, 0, 17)
+
+	fields = append(fields, mEXERCISE_OPTIONS, v, reqID)
+
+	if serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
+		fields = append(fields, contract.ContractID)
+	}
+
+	fields = append(fields,
+		contract.Symbol,
+		contract.Expiry,
+		contract.Strike,
+		contract.Right,
+		contract.Multiplier,
+		contract.Exchange,
+		contract.Currency,
+		contract.LocalSymbol)
+
+	if serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
+		fields = append(fields, contract.TradingClass)
+	}
+
+	fields = append(fields,
+		exerciseAction,
+		exerciseQuantity,
+		account,
+		override)
+
+	return makeMsgBytes(fields...), nil
+
+}
+
+// EncodePlaceOrder encodes the request of PlaceOrder
+func EncodePlaceOrder(serverVersion Version, orderID int64, contract *Contract, order *Order) ([]byte, error) {
+	switch v := serverVersion; {
+	case v < mMIN_SERVER_VER_DELTA_NEUTRAL && contract.DeltaNeutralContract != nil:
+		return nil, errUpdateTWS(orderID, "  It does not support delta-neutral orders.")
+	case v < mMIN_SERVER_VER_SCALE_ORDERS2 && order.ScaleSubsLevelSize != UNSETINT:
+		return nil, errUpdateTWS(orderID, "  It does not support Subsequent Level Size for Scale orders.")
+	case v < mMIN_SERVER_VER_ALGO_ORDERS && order.AlgoStrategy != "":
+		return nil, errUpdateTWS(orderID, "  It does not support algo orders.")
+	case v < mMIN_SERVER_VER_NOT_HELD && order.NotHeld:
+		return nil, errUpdateTWS(orderID, "  It does not support notHeld parameter.")
+	case v < mMIN_SERVER_VER_SEC_ID_TYPE && (contract.SecurityType != "" || contract.SecurityID != ""):
+		return nil, errUpdateTWS(orderID, "  It does not support secIdType and secId parameters.")
+	case v < mMIN_SERVER_VER_PLACE_ORDER_CONID && contract.ContractID != UNSETINT && contract.ContractID > 0:
+		return nil, errUpdateTWS(orderID, "  It does not support conId parameter.")
+	case v < mMIN_SERVER_VER_SSHORTX && order.ExemptCode != -1:
+		return nil, errUpdateTWS(orderID, "  It does not support exemptCode parameter.")
+	case v < mMIN_SERVER_VER_SSHORTX:
+		for _, comboLeg := range contract.ComboLegs {
+			if comboLeg.ExemptCode != -1 {
+				return nil, errUpdateTWS(orderID, "  It does not support exemptCode parameter.")
+			}
+		}
+		fallthrough
+	case v < mMIN_SERVER_VER_HEDGE_ORDERS && order.HedgeType != "":
+		return nil, errUpdateTWS(orderID, "  It does not support hedge orders.")
+	case v < mMIN_SERVER_VER_OPT_OUT_SMART_ROUTING && order.OptOutSmartRouting:
+		return nil, errUpdateTWS(orderID, "  It does not support optOutSmartRouting parameter.")
+	case v < mMIN_SERVER_VER_DELTA_NEUTRAL_CONID:
+		if order.DeltaNeutralContractID > 0 || order.DeltaNeutralSettlingFirm != "" || order.DeltaNeutralClearingAccount != "" || order.DeltaNeutralClearingIntent != "" {
+			return nil, errUpdateTWS(orderID, "  It does not support deltaNeutral parameters: ConId, SettlingFirm, ClearingAccount, ClearingIntent.")
+		}
+		fallthrough
+	case v < mMIN_SERVER_VER_DELTA_NEUTRAL_OPEN_CLOSE:
+		if order.DeltaNeutralOpenClose != "" ||
+			order.DeltaNeutralShortSale ||
+			order.DeltaNeutralShortSaleSlot > 0 ||
+			order.DeltaNeutralDesignatedLocation != "" {
+			return nil, errUpdateTWS(orderID, "  It does not support deltaNeutral parameters: OpenClose, ShortSale, ShortSaleSlot, DesignatedLocation.")
+		}
+		fallthrough
+	case v < mMIN_SERVER_VER_SCALE_ORDERS3:
+		if (order.ScalePriceIncrement > 0 && order.ScalePriceIncrement != UNSETFLOAT) &&
+			(order.ScalePriceAdjustValue != UNSETFLOAT ||
+				order.ScalePriceAdjustInterval != UNSETINT ||
+				order.ScaleProfitOffset != UNSETFLOAT ||
+				order.ScaleAutoReset ||
+				order.ScaleInitPosition != UNSETINT ||
+				order.ScaleInitFillQty != UNSETINT ||
+				order.ScaleRandomPercent) {
+			return nil, errUpdateTWS(orderID, "  It does not support Scale order parameters: PriceAdjustValue, PriceAdjustInterval, "+
+				"ProfitOffset, AutoReset, InitPosition, InitFillQty and RandomPercent.")
+		}
+		fallthrough
+	case v < mMIN_SERVER_VER_ORDER_COMBO_LEGS_PRICE && contract.SecurityType == "BAG":
+		for _, orderComboLeg := range order.OrderComboLegs {
+			if orderComboLeg.Price != UNSETFLOAT {
+				return nil, errUpdateTWS(orderID, "  It does not support per-leg prices for order combo legs.")
+			}
+
+		}
+		fallthrough
+	case v < mMIN_SERVER_VER_TRAILING_PERCENT && order.TrailingPercent != UNSETFLOAT:
+		return nil, errUpdateTWS(orderID, "  It does not support trailing percent parameter.")
+	case v < mMIN_SERVER_VER_TRADING_CLASS && contract.TradingClass != "":
+		return nil, errUpdateTWS(orderID, "  It does not support tradingClass parameter in placeOrder.")
+	case v < mMIN_SERVER_VER_SCALE_TABLE &&
+		(order.ScaleTable != "" ||
+			order.ActiveStartTime != "" ||
+			order.ActiveStopTime != ""):
+		return nil, errUpdateTWS(orderID, "  It does not support scaleTable, activeStartTime and activeStopTime parameters.")
+	case v < mMIN_SERVER_VER_ALGO_ID && order.AlgoID != "":
+		return nil, errUpdateTWS(orderID, "  It does not support algoId parameter.")
+	case v < mMIN_SERVER_VER_ORDER_SOLICITED && order.Solictied:
+		return nil, errUpdateTWS(orderID, "  It does not support order solicited parameter.")
+	case v < mMIN_SERVER_VER_MODELS_SUPPORT && order.ModelCode != "":
+		return nil, errUpdateTWS(orderID, "  It does not support model code parameter.")
+	case v < mMIN_SERVER_VER_EXT_OPERATOR && order.ExtOperator != "":
+		return nil, errUpdateTWS(orderID, "  It does not support ext operator parameter")
+	case v < mMIN_SERVER_VER_SOFT_DOLLAR_TIER &&
+		(order.SoftDollarTier.Name != "" || order.SoftDollarTier.Value != ""):
+		return nil, errUpdateTWS(orderID, " It does not support soft dollar tier")
+	case v < mMIN_SERVER_VER_CASH_QTY && order.CashQty != UNSETFLOAT:
+		return nil, errUpdateTWS(orderID, " It does not support cash quantity parameter")
+	case v < mMIN_SERVER_VER_DECISION_MAKER &&
+		(order.Mifid2DecisionMaker != "" || order.Mifid2DecisionAlgo != ""):
+		return nil, errUpdateTWS(orderID, " It does not support MIFID II decision maker parameters")
+	case v < mMIN_SERVER_VER_MIFID_EXECUTION &&
+		(order.Mifid2ExecutionTrader != "" || order.Mifid2ExecutionAlgo != ""):
+		return nil, errUpdateTWS(orderID, " It does not support MIFID II execution parameters")
+	case v < mMIN_SERVER_VER_AUTO_PRICE_FOR_HEDGE && order.DontUseAutoPriceForHedge:
+		return nil, errUpdateTWS(orderID, " It does not support dontUseAutoPriceForHedge parameter")
+	case v < mMIN_SERVER_VER_ORDER_CONTAINER && order.IsOmsContainer:
+		return nil, errUpdateTWS(orderID, " It does not support oms container parameter")
+	case v < mMIN_SERVER_VER_PRICE_MGMT_ALGO && order.UsePriceMgmtAlgo:
+		return nil, errUpdateTWS(orderID, " It does not support Use price management algo requests")
+	case v < mMIN_SERVER_VER_DURATION && order.Duration != UNSETINT:
+		return nil, errUpdateTWS(orderID, " It does not support duration attribute")
+	case v < mMIN_SERVER_VER_POST_TO_ATS && order.PostToAts != UNSETINT:
+		return nil, errUpdateTWS(orderID, " It does not support postToAts attribute")
+	case v < mMIN_SERVER_VER_AUTO_CANCEL_PARENT && order.AutoCancelParent:
+		return nil, errUpdateTWS(orderID, " It does not support autoCancelParent attribute")
+	case v < mMIN_SERVER_VER_ADVANCED_ORDER_REJECT && order.AdvancedErrorOverride != "":
+		return nil, errUpdateTWS(orderID, " It does not support advanced error override attribute")
+	case v < mMIN_SERVER_VER_MANUAL_ORDER_TIME && order.ManualOrderTime != "":
+		return nil, errUpdateTWS(orderID, " It does not support manual order time attribute")
+	case v < mMIN_SERVER_VER_PEGBEST_PEGMID_OFFSETS && contract.Exchange == "IBKRATS" && order.MinTradeQty != UNSETINT:
+		return nil, errUpdateTWS(orderID, " It does not support minTradeQty attribute")
+	case v < mMIN_SERVER_VER_PEGBEST_PEGMID_OFFSETS && order.OrderType == "PEG BEST" &&
+		(order.MinCompeteSize != UNSETINT || order.CompeteAgainstBestOffset != UNSETFLOAT):
+		return nil, errUpdateTWS(orderID, " It does not support PEG BEST order types, minCompeteSize and competeAgainstBestOffset attributes")
+	case v < mMIN_SERVER_VER_PEGBEST_PEGMID_OFFSETS && (order.OrderType == "PEG BEST" || order.OrderType == "PEG MID") &&
+		(order.MidOffsetAtWhole != UNSETFLOAT || order.MidOffsetAtHalf != UNSETFLOAT):
+		return nil, errUpdateTWS(orderID, " It does not support midOffsetAtWhole and midOffsetAtHalf attributes")
+	}
+
+	var v int
+	if serverVersion < mMIN_SERVER_VER_NOT_HELD {
+		v = 27
+	} else {
+		v = 45
+	}
+
+	fields := make([]interface{}, 0, 150)
+	fields = append(fields, mPLACE_ORDER)
+
+	if serverVersion < mMIN_SERVER_VER_ORDER_CONTAINER {
+		fields = append(fields, v)
+	}
+
+	fields = append(fields, orderID)
+
+	if serverVersion >= mMIN_SERVER_VER_PLACE_ORDER_CONID {
+		fields = append(fields, contract.ContractID)
+	}
+
+	fields = append(fields,
+		contract.Symbol,
+		contract.SecurityType,
+		contract.Expiry,
+		contract.Strike,
+		contract.Right,
+		contract.Multiplier,
+		contract.Exchange,
+		contract.PrimaryExchange,
+		contract.Currency,
+		contract.LocalSymbol)
+
+	if serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
+		fields = append(fields, contract.TradingClass)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_SEC_ID_TYPE {
+		fields = append(fields, contract.SecurityIDType, contract.SecurityID)
+	}
+
+	fields = append(fields, order.Action)
+
+	if serverVersion >= mMIN_SERVER_VER_FRACTIONAL_POSITIONS {
+		fields = append(fields, order.TotalQuantity)
+	} else {
+		fields = append(fields, order.TotalQuantity.Int64())
+	}
+
+	fields = append(fields, order.OrderType)
+
+	if serverVersion < mMIN_SERVER_VER_ORDER_COMBO_LEGS_PRICE {
+		if order.LimitPrice != UNSETFLOAT {
+			fields = append(fields, order.LimitPrice)
+		} else {
+			fields = append(fields, float64(0))
+		}
+	} else {
+		fields = append(fields, handleEmpty(order.LimitPrice))
+	}
+
+	if serverVersion < mMIN_SERVER_VER_TRAILING_PERCENT {
+		if order.AuxPrice != UNSETFLOAT {
+			fields = append(fields, order.AuxPrice)
+		} else {
+			fields = append(fields, float64(0))
+		}
+	} else {
+		fields = append(fields, handleEmpty(order.AuxPrice))
+	}
+
+	fields = append(fields,
+		order.TIF,
+		order.OCAGroup,
+		order.Account,
+		order.OpenClose,
+		order.Origin,
+		order.OrderRef,
+		order.Transmit,
+		order.ParentID,
+		order.BlockOrder,
+		order.SweepToFill,
+		order.DisplaySize,
+		order.TriggerMethod,
+		order.OutsideRTH,
+		order.Hidden)
+
+	if contract.SecurityType == "BAG" {
+		comboLegsCount := len(contract.ComboLegs)
+		fields = append(fields, comboLegsCount)
+		for _, comboLeg := range contract.ComboLegs {
+			fields = append(fields,
+				comboLeg.ContractID,
+				comboLeg.Ratio,
+				comboLeg.Action,
+				comboLeg.Exchange,
+				comboLeg.OpenClose,
+				comboLeg.ShortSaleSlot,
+				comboLeg.DesignatedLocation)
+			if serverVersion >= mMIN_SERVER_VER_SSHORTX_OLD {
+				fields = append(fields, comboLeg.ExemptCode)
+			}
+		}
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_ORDER_COMBO_LEGS_PRICE && contract.SecurityType == "BAG" {
+		orderComboLegsCount := len(order.OrderComboLegs)
+		fields = append(fields, orderComboLegsCount)
+		for _, orderComboLeg := range order.OrderComboLegs {
+			fields = append(fields, handleEmpty(orderComboLeg.Price))
+		}
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_SMART_COMBO_ROUTING_PARAMS && contract.SecurityType == "BAG" {
+		smartComboRoutingParamsCount := len(order.SmartComboRoutingParams)
+		fields = append(fields, smartComboRoutingParamsCount)
+		for _, tv := range order.SmartComboRoutingParams {
+			fields = append(fields, tv.Tag, tv.Value)
+		}
+	}
+
+	fields = append(fields,
+		"",
+		order.DiscretionaryAmount,
+		order.GoodAfterTime,
+		order.GoodTillDate,
+
+		order.FAGroup,
+		order.FAMethod,
+		order.FAPercentage,
+		order.FAProfile)
+
+	if serverVersion >= mMIN_SERVER_VER_MODELS_SUPPORT {
+		fields = append(fields, order.ModelCode)
+	}
+
+	fields = append(fields,
+		order.ShortSaleSlot,
+		order.DesignatedLocation)
+
+	//institutional short saleslot data (srv v18 and above)
+	if serverVersion >= mMIN_SERVER_VER_SSHORTX_OLD {
+		fields = append(fields, order.ExemptCode)
+	}
+
+	fields = append(fields, order.OCAType)
+
+	fields = append(fields,
+		order.Rule80A,
+		order.SettlingFirm,
+		order.AllOrNone,
+		handleEmpty(order.MinQty),
+		handleEmpty(order.PercentOffset),
+		false, // order.ETradeOnly
+		false, // order.FirmQuoteOnly
+		handleEmpty(UNSETFLOAT),
+		order.AuctionStrategy,
+		handleEmpty(order.StartingPrice),
+		handleEmpty(order.StockRefPrice),
+		handleEmpty(order.Delta),
+		handleEmpty(order.StockRangeLower),
+		handleEmpty(order.StockRangeUpper),
+
+		order.OverridePercentageConstraints,
+
+		handleEmpty(order.Volatility),
+		handleEmpty(order.VolatilityType),
+		order.DeltaNeutralOrderType,
+		handleEmpty(order.DeltaNeutralAuxPrice))
+
+	if serverVersion >= mMIN_SERVER_VER_DELTA_NEUTRAL_CONID && order.DeltaNeutralOrderType != "" {
+		fields = append(fields,
+			order.DeltaNeutralContractID,
+			order.DeltaNeutralSettlingFirm,
+			order.DeltaNeutralClearingAccount,
+			order.DeltaNeutralClearingIntent)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_DELTA_NEUTRAL_OPEN_CLOSE && order.DeltaNeutralOrderType != "" {
+		fields = append(fields,
+			order.DeltaNeutralOpenClose,
+			order.DeltaNeutralShortSale,
+			order.DeltaNeutralShortSaleSlot,
+			order.DeltaNeutralDesignatedLocation)
+	}
+
+	fields = append(fields,
+		order.ContinuousUpdate,
+		handleEmpty(order.ReferencePriceType),
+		handleEmpty(order.TrailStopPrice))
+
+	if serverVersion >= mMIN_SERVER_VER_TRAILING_PERCENT {
+		fields = append(fields, handleEmpty(order.TrailingPercent))
+	}
+
+	//scale orders
+	if serverVersion >= mMIN_SERVER_VER_SCALE_ORDERS2 {
+		fields = append(fields,
+			handleEmpty(order.ScaleInitLevelSize),
+			handleEmpty(order.ScaleSubsLevelSize))
+	} else {
+		fields = append(fields,
+			"",
+			handleEmpty(order.ScaleInitLevelSize))
+	}
+
+	fields = append(fields, handleEmpty(order.ScalePriceIncrement))
+
+	if serverVersion >= mMIN_SERVER_VER_SCALE_ORDERS3 && order.ScalePriceIncrement != UNSETFLOAT && order.ScalePriceIncrement > 0.0 {
+		fields = append(fields,
+			handleEmpty(order.ScalePriceAdjustValue),
+			handleEmpty(order.ScalePriceAdjustInterval),
+			handleEmpty(order.ScaleProfitOffset),
+			order.ScaleAutoReset,
+			handleEmpty(order.ScaleInitPosition),
+			handleEmpty(order.ScaleInitFillQty),
+			order.ScaleRandomPercent)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_SCALE_TABLE {
+		fields = append(fields,
+			order.ScaleTable,
+			order.ActiveStartTime,
+			order.ActiveStopTime)
+	}
+
+	//hedge orders
+	if serverVersion >= mMIN_SERVER_VER_HEDGE_ORDERS {
+		fields = append(fields, order.HedgeType)
+		if order.HedgeType != "" {
+			fields = append(fields, order.HedgeParam)
+		}
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_OPT_OUT_SMART_ROUTING {
+		fields = append(fields, order.OptOutSmartRouting)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_PTA_ORDERS {
+		fields = append(fields,
+			order.ClearingAccount,
+			order.ClearingIntent)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_NOT_HELD {
+		fields = append(fields, order.NotHeld)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_DELTA_NEUTRAL {
+		if contract.DeltaNeutralContract != nil {
+			fields = append(fields,
+				true,
+				contract.DeltaNeutralContract.ContractID,
+				contract.DeltaNeutralContract.Delta,
+				contract.DeltaNeutralContract.Price)
+		} else {
+			fields = append(fields, false)
+		}
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_ALGO_ORDERS {
+		fields = append(fields, order.AlgoStrategy)
+
+		if order.AlgoStrategy != "" {
+			algoParamsCount := len(order.AlgoParams)
+			fields = append(fields, algoParamsCount)
+			for _, tv := range order.AlgoParams {
+				fields = append(fields, tv.Tag, tv.Value)
+			}
+		}
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_ALGO_ID {
+		fields = append(fields, order.AlgoID)
+	}
+
+	fields = append(fields, order.WhatIf)
+
+	if serverVersion >= mMIN_SERVER_VER_LINKING {
+		var miscOptionsBuffer bytes.Buffer
+		for _, tv := range order.OrderMiscOptions {
+			miscOptionsBuffer.WriteString(tv.Tag)
+			miscOptionsBuffer.WriteString("=")
+			miscOptionsBuffer.WriteString(tv.Value)
+			miscOptionsBuffer.WriteString(";")
+		}
+
+		fields = append(fields, miscOptionsBuffer.Bytes())
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_ORDER_SOLICITED {
+		fields = append(fields, order.Solictied)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_RANDOMIZE_SIZE_AND_PRICE {
+		fields = append(fields,
+			order.RandomizeSize,
+			order.RandomizePrice)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_PEGGED_TO_BENCHMARK {
+		if order.OrderType == "PEG BENCH" {
+			fields = append(fields,
+				order.ReferenceContractID,
+				order.IsPeggedChangeAmountDecrease,
+				order.PeggedChangeAmount,
+				order.ReferenceChangeAmount,
+				order.ReferenceExchangeID)
+		}
+
+		orderConditionsCount := len(order.Conditions)
+		fields = append(fields, orderConditionsCount)
+		for _, cond := range order.Conditions {
+			fields = append(fields, cond.CondType())
+			fields = append(fields, cond.toFields()...)
+		}
+		if orderConditionsCount > 0 {
+			fields = append(fields,
+				order.ConditionsIgnoreRth,
+				order.ConditionsCancelOrder)
+		}
+
+		fields = append(fields,
+			order.AdjustedOrderType,
+			order.TriggerPrice,
+			order.LimitPriceOffset,
+			order.AdjustedStopPrice,
+			order.AdjustedStopLimitPrice,
+			order.AdjustedTrailingAmount,
+			order.AdjustableTrailingUnit)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_EXT_OPERATOR {
+		fields = append(fields, order.ExtOperator)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_SOFT_DOLLAR_TIER {
+		fields = append(fields, order.SoftDollarTier.Name, order.SoftDollarTier.Value)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_CASH_QTY {
+		fields = append(fields, order.CashQty)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_DECISION_MAKER {
+		fields = append(fields, order.Mifid2DecisionMaker, order.Mifid2DecisionAlgo)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_MIFID_EXECUTION {
+		fields = append(fields, order.Mifid2ExecutionTrader, order.Mifid2ExecutionAlgo)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_AUTO_PRICE_FOR_HEDGE {
+		fields = append(fields, order.DontUseAutoPriceForHedge)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_ORDER_CONTAINER {
+		fields = append(fields, order.IsOmsContainer)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_D_PEG_ORDERS {
+		fields = append(fields, order.DiscretionaryUpToLimitPrice)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_PRICE_MGMT_ALGO {
+		fields = append(fields, order.UsePriceMgmtAlgo)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_DURATION {
+		fields = append(fields, handleEmpty(order.Duration))
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_POST_TO_ATS {
+		fields = append(fields, handleEmpty(order.PostToAts))
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_AUTO_CANCEL_PARENT {
+		fields = append(fields, order.AutoCancelParent)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_ADVANCED_ORDER_REJECT {
+		fields = append(fields, order.AdvancedErrorOverride)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_MANUAL_ORDER_TIME {
+		fields = append(fields, order.ManualOrderTime)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_PEGBEST_PEGMID_OFFSETS {
+		sendMidOffsets := false
+		if contract.Exchange == "IBKRATS" {
+			fields = append(fields, handleEmpty(order.MinTradeQty))
+		}
+
+		switch order.OrderType {
+		case "PEG BEST":
+			fields = append(fields, handleEmpty(order.MinCompeteSize), handleEmpty(order.CompeteAgainstBestOffset))
+			sendMidOffsets = order.CompeteAgainstBestOffset == COMPETE_AGAINST_BEST_OFFSET_UP_TO_MID
+		case "PEG MID":
+			sendMidOffsets = true
+		}
+
+		if sendMidOffsets {
+			fields = append(fields, handleEmpty(order.MidOffsetAtWhole), handleEmpty(order.MidOffsetAtHalf))
+		}
+	}
+
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeCancelOrder encodes the request of CancelOrder, manualCancelOrderTime is only sent since mMIN_SERVER_VER_MANUAL_ORDER_TIME
+func EncodeCancelOrder(serverVersion Version, orderID int64, manualCancelOrderTime string) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_MANUAL_ORDER_TIME && manualCancelOrderTime != "" {
+		return nil, errUpdateTWS(orderID, "  It does not support manual order cancel time attribute")
+	}
+
+	// v := 1
+	const v = 1
+	fields := make([]interface{}, 0, 4)
+	fields = append(fields, mCANCEL_ORDER, v, orderID)
+	if serverVersion >= mMIN_SERVER_VER_MANUAL_ORDER_TIME {
+		fields = append(fields, manualCancelOrderTime)
+	}
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeReqOpenOrders encodes the request of ReqOpenOrders
+func EncodeReqOpenOrders(serverVersion Version) ([]byte, error) {
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mREQ_OPEN_ORDERS, v), nil
+}
+
+// EncodeReqAutoOpenOrders encodes the request of ReqAutoOpenOrders
+func EncodeReqAutoOpenOrders(serverVersion Version, autoBind bool) ([]byte, error) {
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mREQ_AUTO_OPEN_ORDERS, v, autoBind), nil
+}
+
+// EncodeReqAllOpenOrders encodes the request of ReqAllOpenOrders
+func EncodeReqAllOpenOrders(serverVersion Version) ([]byte, error) {
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mREQ_ALL_OPEN_ORDERS, v), nil
+}
+
+// EncodeReqGlobalCancel encodes the request of ReqGlobalCancel
+func EncodeReqGlobalCancel(serverVersion Version) ([]byte, error) {
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mREQ_GLOBAL_CANCEL, v), nil
+}
+
+// EncodeReqIDs encodes the request of ReqIDs
+func EncodeReqIDs(serverVersion Version) ([]byte, error) {
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mREQ_IDS, v, 0), nil
+}
+
+// EncodeReqAccountUpdates encodes the request of ReqAccountUpdates
+func EncodeReqAccountUpdates(serverVersion Version, subscribe bool, accName string) ([]byte, error) {
+	// v := 2
+	const v = 2
+	return makeMsgBytes(mREQ_ACCT_DATA, v, subscribe, accName), nil
+}
+
+// EncodeReqAccountSummary encodes the request of ReqAccountSummary
+func EncodeReqAccountSummary(serverVersion Version, reqID int64, groupName string, tags string) ([]byte, error) {
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mREQ_ACCOUNT_SUMMARY, v, reqID, groupName, tags), nil
+}
+
+// EncodeCancelAccountSummary encodes the request of CancelAccountSummary
+func EncodeCancelAccountSummary(serverVersion Version, reqID int64) ([]byte, error) {
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mCANCEL_ACCOUNT_SUMMARY, v, reqID), nil
+}
+
+// EncodeReqPositions encodes the request of ReqPositions
+func EncodeReqPositions(serverVersion Version) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_POSITIONS {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support positions request.")
+	}
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mREQ_POSITIONS, v), nil
+}
+
+// EncodeCancelPositions encodes the request of CancelPositions
+func EncodeCancelPositions(serverVersion Version) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_POSITIONS {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support positions request.")
+	}
+
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mCANCEL_POSITIONS, v), nil
+}
+
+// EncodeReqPositionsMulti encodes the request of ReqPositionsMulti
+func EncodeReqPositionsMulti(serverVersion Version, reqID int64, account string, modelCode string) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_MODELS_SUPPORT {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support positions multi request.")
+	}
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mREQ_POSITIONS_MULTI, v, reqID, account, modelCode), nil
+}
+
+// EncodeCancelPositionsMulti encodes the request of CancelPositionsMulti
+func EncodeCancelPositionsMulti(serverVersion Version, reqID int64) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_MODELS_SUPPORT {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support cancel positions multi request.")
+	}
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mCANCEL_POSITIONS_MULTI, v, reqID), nil
+}
+
+// EncodeReqAccountUpdatesMulti encodes the request of ReqAccountUpdatesMulti
+func EncodeReqAccountUpdatesMulti(serverVersion Version, reqID int64, account string, modelCode string, ledgerAndNLV bool) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_MODELS_SUPPORT {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support account updates multi request.")
+	}
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mREQ_ACCOUNT_UPDATES_MULTI, v, reqID, account, modelCode, ledgerAndNLV), nil
+}
+
+// EncodeCancelAccountUpdatesMulti encodes the request of CancelAccountUpdatesMulti
+func EncodeCancelAccountUpdatesMulti(serverVersion Version, reqID int64) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_MODELS_SUPPORT {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support cancel account updates multi request.")
+	}
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mCANCEL_ACCOUNT_UPDATES_MULTI, v, reqID), nil
+}
+
+// EncodeReqPnL encodes the request of ReqPnL
+func EncodeReqPnL(serverVersion Version, reqID int64, account string, modelCode string) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_PNL {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support PnL request.")
+	}
+
+	return makeMsgBytes(mREQ_PNL, reqID, account, modelCode), nil
+}
+
+// EncodeCancelPnL encodes the request of CancelPnL
+func EncodeCancelPnL(serverVersion Version, reqID int64) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_PNL {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support PnL request.")
+	}
+
+	return makeMsgBytes(mCANCEL_PNL, reqID), nil
+}
+
+// EncodeReqPnLSingle encodes the request of ReqPnLSingle
+func EncodeReqPnLSingle(serverVersion Version, reqID int64, account string, modelCode string, contractID int64) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_PNL {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support PnL request.")
+	}
+
+	return makeMsgBytes(mREQ_PNL_SINGLE, reqID, account, modelCode, contractID), nil
+}
+
+// EncodeCancelPnLSingle encodes the request of CancelPnLSingle
+func EncodeCancelPnLSingle(serverVersion Version, reqID int64) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_PNL {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support PnL request.")
+	}
+
+	return makeMsgBytes(mCANCEL_PNL_SINGLE, reqID), nil
+}
+
+// EncodeReqExecutions encodes the request of ReqExecutions
+func EncodeReqExecutions(serverVersion Version, reqID int64, execFilter ExecutionFilter) ([]byte, error) {
+	// v := 3
+	const v = 3
+	fields := make([]interface{}, 0, 10)
+	fields = append(fields, mREQ_EXECUTIONS, v)
+
+	if serverVersion >= mMIN_SERVER_VER_EXECUTION_DATA_CHAIN {
+		fields = append(fields, reqID)
+	}
+
+	fields = append(fields,
+		execFilter.ClientID,
+		execFilter.AccountCode,
+		execFilter.Time,
+		execFilter.Symbol,
+		execFilter.SecurityType,
+		execFilter.Exchange,
+		execFilter.Side)
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeReqContractDetails encodes the request of ReqContractDetails
+func EncodeReqContractDetails(serverVersion Version, reqID int64, contract *Contract) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_SEC_ID_TYPE &&
+		(contract.SecurityIDType != "" || contract.SecurityID != "") {
+		return nil, errUpdateTWS(reqID, "  It does not support secIdType and secId parameters.")
+	}
+
+	if serverVersion < mMIN_SERVER_VER_TRADING_CLASS && contract.TradingClass != "" {
+		return nil, errUpdateTWS(reqID, "  It does not support tradingClass parameter in reqContractDetails.")
+	}
+
+	if serverVersion < mMIN_SERVER_VER_LINKING && contract.PrimaryExchange != "" {
+		return nil, errUpdateTWS(reqID, "  It does not support primaryExchange parameter in reqContractDetails.")
+	}
+
+	// v := 8
+	const v = 8
+	fields := make([]interface{}, 0, 20)
+	fields = append(fields, mREQ_CONTRACT_DATA, v)
+
+	if serverVersion >= mMIN_SERVER_VER_CONTRACT_DATA_CHAIN {
+		fields = append(fields, reqID)
+	}
+
+	fields = append(fields,
+		contract.ContractID,
+		contract.Symbol,
+		contract.SecurityType,
+		contract.Expiry,
+		contract.Strike,
+		contract.Right,
+		contract.Multiplier)
+
+	if serverVersion >= mMIN_SERVER_VER_PRIMARYEXCH {
+		fields = append(fields, contract.Exchange, contract.PrimaryExchange)
+	} else if serverVersion >= mMIN_SERVER_VER_LINKING {
+		if contract.PrimaryExchange != "" && (contract.Exchange == "BEST" || contract.Exchange == "SMART") {
+			fields = append(fields, strings.Join([]string{contract.Exchange, contract.PrimaryExchange}, ":"))
+		} else {
+			fields = append(fields, contract.Exchange)
+		}
+	}
+
+	fields = append(fields, contract.Currency, contract.LocalSymbol)
+
+	if serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
+		fields = append(fields, contract.TradingClass, contract.IncludeExpired)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_SEC_ID_TYPE {
+		fields = append(fields, contract.SecurityIDType, contract.SecurityID)
+	}
+
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeReqMktDepthExchanges encodes the request of ReqMktDepthExchanges
+func EncodeReqMktDepthExchanges(serverVersion Version) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_REQ_MKT_DEPTH_EXCHANGES {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support market depth exchanges request.")
+	}
+
+	return makeMsgBytes(mREQ_MKT_DEPTH_EXCHANGES), nil
+}
+
+// EncodeReqMktDepth encodes the request of ReqMktDepth
+func EncodeReqMktDepth(serverVersion Version, reqID int64, contract *Contract, numRows int, isSmartDepth bool, mktDepthOptions []TagValue) ([]byte, error) {
+	switch {
+	case serverVersion < mMIN_SERVER_VER_TRADING_CLASS:
+		if contract.TradingClass != "" || contract.ContractID > 0 {
+			return nil, errUpdateTWS(reqID, "  It does not support conId and tradingClass parameters in reqMktDepth.")
+		}
+		fallthrough
+	case serverVersion < mMIN_SERVER_VER_SMART_DEPTH && isSmartDepth:
+		return nil, errUpdateTWS(reqID, "  It does not support SMART depth request.")
+	case serverVersion < mMIN_SERVER_VER_MKT_DEPTH_PRIM_EXCHANGE && contract.PrimaryExchange != "":
+		return nil, errUpdateTWS(reqID, " It does not support primaryExchange parameter in reqMktDepth.")
+	}
+
+	// v := 5
+	const v = 5
+	fields := make([]interface{}, 0, 17)
+	fields = append(fields, mREQ_MKT_DEPTH, v, reqID)
+
+	if serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
+		fields = append(fields, contract.ContractID)
+	}
+
+	fields = append(fields,
+		contract.Symbol,
+		contract.SecurityType,
+		contract.Expiry,
+		contract.Strike,
+		contract.Right,
+		contract.Multiplier,
+		contract.Exchange)
+
+	if serverVersion >= mMIN_SERVER_VER_MKT_DEPTH_PRIM_EXCHANGE {
+		fields = append(fields, contract.PrimaryExchange)
+	}
+
+	fields = append(fields,
+		contract.Currency,
+		contract.LocalSymbol)
+
+	if serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
+		fields = append(fields, contract.TradingClass)
+	}
+
+	fields = append(fields, numRows)
+
+	if serverVersion >= mMIN_SERVER_VER_SMART_DEPTH {
+		fields = append(fields, isSmartDepth)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_LINKING {
+		var mktDepthOptionsBuffer bytes.Buffer
+		for _, tv := range mktDepthOptions {
+			mktDepthOptionsBuffer.WriteString(tv.Tag)
+			mktDepthOptionsBuffer.WriteString("=")
+			mktDepthOptionsBuffer.WriteString(tv.Value)
+			mktDepthOptionsBuffer.WriteString(";")
+		}
+		fields = append(fields, mktDepthOptionsBuffer.Bytes())
+	}
+
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeCancelMktDepth encodes the request of CancelMktDepth
+func EncodeCancelMktDepth(serverVersion Version, reqID int64, isSmartDepth bool) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_SMART_DEPTH && isSmartDepth {
+		return nil, errUpdateTWS(reqID, " It does not support SMART depth cancel.")
+	}
+	// v := 1
+	const v = 1
+	fields := make([]interface{}, 0, 4)
+	fields = append(fields, mCANCEL_MKT_DEPTH, v, reqID)
+
+	if serverVersion >= mMIN_SERVER_VER_SMART_DEPTH {
+		fields = append(fields, isSmartDepth)
+	}
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeReqNewsBulletins encodes the request of ReqNewsBulletins
+func EncodeReqNewsBulletins(serverVersion Version, allMsgs bool) ([]byte, error) {
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mREQ_NEWS_BULLETINS, v, allMsgs), nil
+}
+
+// EncodeCancelNewsBulletins encodes the request of CancelNewsBulletins
+func EncodeCancelNewsBulletins(serverVersion Version) ([]byte, error) {
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mCANCEL_NEWS_BULLETINS, v), nil
+}
+
+// EncodeReqManagedAccts encodes the request of ReqManagedAccts
+func EncodeReqManagedAccts(serverVersion Version) ([]byte, error) {
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mREQ_MANAGED_ACCTS, v), nil
+}
+
+// EncodeRequestFA encodes the request of RequestFA
+func EncodeRequestFA(serverVersion Version, faData int) ([]byte, error) {
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mREQ_FA, v, faData), nil
+}
+
+// EncodeReplaceFA encodes the request of replaceFA
+func EncodeReplaceFA(serverVersion Version, reqID int64, faData int, cxml string) ([]byte, error) {
+	// v := 1
+	const v = 1
+	fields := make([]interface{}, 0, 5)
+	fields = append(fields, mREPLACE_FA, v, faData, cxml)
+	if serverVersion >= mMIN_SERVER_VER_REPLACE_FA_END {
+		fields = append(fields, reqID)
+	}
+
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeReqHistoricalData encodes the request of ReqHistoricalData
+func EncodeReqHistoricalData(serverVersion Version, reqID int64, contract *Contract, endDateTime string, duration string, barSize string, whatToShow string, useRTH bool, formatDate int, keepUpToDate bool, chartOptions []TagValue) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_TRADING_CLASS {
+		if contract.TradingClass != "" || contract.ContractID > 0 {
+			return nil, errUpdateTWS(reqID, "  It does not support conId and tradingClass parameters in reqHistoricalData.")
+		}
+	}
+
+	if serverVersion < mMIN_SERVER_VER_HISTORICAL_SCHEDULE && whatToShow == "SCHEDULE" {
+		return nil, errUpdateTWS(reqID, "  It does not support requesting of historical schedule.")
+	}
+
+	// v := 6
+	const v = 6
+	fields := make([]interface{}, 0, 30)
+	fields = append(fields, mREQ_HISTORICAL_DATA)
+	if serverVersion <= mMIN_SERVER_VER_SYNT_REALTIME_BARS {
+		fields = append(fields, v)
+	}
+
+	fields = append(fields, reqID)
+
+	if serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
+		fields = append(fields, contract.ContractID)
+	}
+
+	fields = append(fields,
+		contract.Symbol,
+		contract.SecurityType,
+		contract.Expiry,
+		contract.Strike,
+		contract.Right,
+		contract.Multiplier,
+		contract.Exchange,
+		contract.PrimaryExchange,
+		contract.Currency,
+		contract.LocalSymbol,
+	)
+
+	if serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
+		fields = append(fields, contract.TradingClass)
+	}
+	fields = append(fields,
+		contract.IncludeExpired,
+		endDateTime,
+		barSize,
+		duration,
+		useRTH,
+		whatToShow,
+		formatDate,
+	)
+
+	if contract.SecurityType == "BAG" {
+		fields = append(fields, len(contract.ComboLegs))
+		for _, comboLeg := range contract.ComboLegs {
+			fields = append(fields,
+				comboLeg.ContractID,
+				comboLeg.Ratio,
+				comboLeg.Action,
+				comboLeg.Exchange,
+			)
+		}
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_SYNT_REALTIME_BARS {
+		fields = append(fields, keepUpToDate)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_LINKING {
+		chartOptionsStr := ""
+		for _, tagValue := range chartOptions {
+			chartOptionsStr += tagValue.Value
+		}
+		fields = append(fields, chartOptionsStr)
+	}
+
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeCancelHistoricalData encodes the request of CancelHistoricalData
+func EncodeCancelHistoricalData(serverVersion Version, reqID int64) ([]byte, error) {
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mCANCEL_HISTORICAL_DATA, v, reqID), nil
+}
+
+// EncodeReqHeadTimeStamp encodes the request of ReqHeadTimeStamp
+func EncodeReqHeadTimeStamp(serverVersion Version, reqID int64, contract *Contract, whatToShow string, useRTH bool, formatDate int) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_REQ_HEAD_TIMESTAMP {
+		return nil, errUpdateTWS(reqID, "  It does not support head time stamp requests.")
+	}
+
+	fields := make([]interface{}, 0, 18)
+
+	fields = append(fields,
+		mREQ_HEAD_TIMESTAMP,
+		reqID,
+		contract.ContractID,
+		contract.Symbol,
+		contract.SecurityType,
+		contract.Expiry,
+		contract.Strike,
+		contract.Right,
+		contract.Multiplier,
+		contract.Exchange,
+		contract.PrimaryExchange,
+		contract.Currency,
+		contract.LocalSymbol,
+		contract.TradingClass,
+		contract.IncludeExpired,
+		useRTH,
+		whatToShow,
+		formatDate)
+
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeCancelHeadTimeStamp encodes the request of CancelHeadTimeStamp
+func EncodeCancelHeadTimeStamp(serverVersion Version, reqID int64) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_CANCEL_HEADTIMESTAMP {
+		return nil, errUpdateTWS(reqID, "  It does not support head time stamp requests.")
+	}
+
+	return makeMsgBytes(mCANCEL_HEAD_TIMESTAMP, reqID), nil
+}
+
+// EncodeReqHistogramData encodes the request of ReqHistogramData
+func EncodeReqHistogramData(serverVersion Version, reqID int64, contract *Contract, useRTH bool, timePeriod string) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_REQ_HISTOGRAM {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support histogram requests..")
+	}
+
+	fields := make([]interface{}, 0, 18)
+	fields = append(fields,
+		mREQ_HISTOGRAM_DATA,
+		reqID,
+		contract.ContractID,
+		contract.Symbol,
+		contract.SecurityType,
+		contract.Expiry,
+		contract.Strike,
+		contract.Right,
+		contract.Multiplier,
+		contract.Exchange,
+		contract.PrimaryExchange,
+		contract.Currency,
+		contract.LocalSymbol,
+		contract.TradingClass,
+		contract.IncludeExpired,
+		useRTH,
+		timePeriod)
+
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeCancelHistogramData encodes the request of CancelHistogramData
+func EncodeCancelHistogramData(serverVersion Version, reqID int64) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_REQ_HISTOGRAM {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support histogram requests..")
+	}
+
+	return makeMsgBytes(mCANCEL_HISTOGRAM_DATA, reqID), nil
+}
+
+// EncodeReqHistoricalTicks encodes the request of ReqHistoricalTicks
+func EncodeReqHistoricalTicks(serverVersion Version, reqID int64, contract *Contract, startDateTime string, endDateTime string, numberOfTicks int, whatToShow string, useRTH bool, ignoreSize bool, miscOptions []TagValue) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_HISTORICAL_TICKS {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support historical ticks requests..")
+	}
+
+	fields := make([]interface{}, 0, 22)
+	fields = append(fields,
+		mREQ_HISTORICAL_TICKS,
+		reqID,
+		contract.ContractID,
+		contract.Symbol,
+		contract.SecurityType,
+		contract.Expiry,
+		contract.Strike,
+		contract.Right,
+		contract.Multiplier,
+		contract.Exchange,
+		contract.PrimaryExchange,
+		contract.Currency,
+		contract.LocalSymbol,
+		contract.TradingClass,
+		contract.IncludeExpired,
+		startDateTime,
+		endDateTime,
+		numberOfTicks,
+		whatToShow,
+		useRTH,
+		ignoreSize)
+
+	var miscOptionsBuffer bytes.Buffer
+	for _, tv := range miscOptions {
+		miscOptionsBuffer.WriteString(tv.Tag)
+		miscOptionsBuffer.WriteString("=")
+		miscOptionsBuffer.WriteString(tv.Value)
+		miscOptionsBuffer.WriteString(";")
+	}
+	fields = append(fields, miscOptionsBuffer.Bytes())
+
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeReqScannerParameters encodes the request of ReqScannerParameters
+func EncodeReqScannerParameters(serverVersion Version) ([]byte, error) {
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mREQ_SCANNER_PARAMETERS, v), nil
+}
+
+// EncodeReqScannerSubscription encodes the request of ReqScannerSubscription
+func EncodeReqScannerSubscription(serverVersion Version, reqID int64, subscription *ScannerSubscription, scannerSubscriptionOptions []TagValue, scannerSubscriptionFilterOptions []TagValue) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_SCANNER_GENERIC_OPTS && len(scannerSubscriptionFilterOptions) > 0 {
+		return nil, errUpdateTWS(NO_VALID_ID, " It does not support API scanner subscription generic filter options")
+	}
+
+	// v := 4
+	const v = 4
+	fields := make([]interface{}, 0, 25)
+	fields = append(fields, mREQ_SCANNER_SUBSCRIPTION)
+
+	if serverVersion < mMIN_SERVER_VER_SCANNER_GENERIC_OPTS {
+		fields = append(fields, v)
+	}
+
+	fields = append(fields,
+		reqID,
+		handleEmpty(subscription.NumberOfRows),
+		subscription.Instrument,
+		subscription.LocationCode,
+		subscription.ScanCode,
+		handleEmpty(subscription.AbovePrice),
+		handleEmpty(subscription.BelowPrice),
+		handleEmpty(subscription.AboveVolume),
+		handleEmpty(subscription.MarketCapAbove),
+		handleEmpty(subscription.MarketCapBelow),
+		subscription.MoodyRatingAbove,
+		subscription.MoodyRatingBelow,
+		subscription.SpRatingAbove,
+		subscription.SpRatingBelow,
+		subscription.MaturityDateAbove,
+		subscription.MaturityDateBelow,
+		handleEmpty(subscription.CouponRateAbove),
+		handleEmpty(subscription.CouponRateBelow),
+		subscription.ExcludeConvertible,
+		handleEmpty(subscription.AverageOptionVolumeAbove),
+		subscription.ScannerSettingPairs,
+		subscription.StockTypeFilter)
+
+	if serverVersion >= mMIN_SERVER_VER_SCANNER_GENERIC_OPTS {
+		var scannerSubscriptionFilterOptionsBuffer bytes.Buffer
+		for _, tv := range scannerSubscriptionFilterOptions {
+			scannerSubscriptionFilterOptionsBuffer.WriteString(tv.Tag)
+			scannerSubscriptionFilterOptionsBuffer.WriteString("=")
+			scannerSubscriptionFilterOptionsBuffer.WriteString(tv.Value)
+			scannerSubscriptionFilterOptionsBuffer.WriteString(";")
+		}
+		fields = append(fields, scannerSubscriptionFilterOptionsBuffer.Bytes())
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_LINKING {
+		var scannerSubscriptionOptionsBuffer bytes.Buffer
+		for _, tv := range scannerSubscriptionOptions {
+			scannerSubscriptionOptionsBuffer.WriteString(tv.Tag)
+			scannerSubscriptionOptionsBuffer.WriteString("=")
+			scannerSubscriptionOptionsBuffer.WriteString(tv.Value)
+			scannerSubscriptionOptionsBuffer.WriteString(";")
+		}
+		fields = append(fields, scannerSubscriptionOptionsBuffer.Bytes())
+
+	}
+
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeCancelScannerSubscription encodes the request of CancelScannerSubscription
+func EncodeCancelScannerSubscription(serverVersion Version, reqID int64) ([]byte, error) {
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mCANCEL_SCANNER_SUBSCRIPTION, v, reqID), nil
+}
+
+// EncodeReqRealTimeBars encodes the request of ReqRealTimeBars
+func EncodeReqRealTimeBars(serverVersion Version, reqID int64, contract *Contract, barSize int, whatToShow string, useRTH bool, realTimeBarsOptions []TagValue) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_TRADING_CLASS && contract.TradingClass != "" {
+		return nil, errUpdateTWS(reqID, "  It does not support conId and tradingClass parameter in reqRealTimeBars.")
+	}
+
+	// v := 3
+	const v = 3
+	fields := make([]interface{}, 0, 19)
+	fields = append(fields, mREQ_REAL_TIME_BARS, v, reqID)
+
+	if serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
+		fields = append(fields, contract.ContractID)
+	}
+
+	fields = append(fields,
+		contract.Symbol,
+		contract.SecurityType,
+		contract.Expiry,
+		contract.Strike,
+		contract.Right,
+		contract.Multiplier,
+		contract.Exchange,
+		contract.PrimaryExchange,
+		contract.Currency,
+		contract.LocalSymbol)
+
+	if serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
+		fields = append(fields, contract.TradingClass)
+	}
+
+	fields = append(fields,
+		barSize,
+		whatToShow,
+		useRTH)
+
+	if serverVersion >= mMIN_SERVER_VER_LINKING {
+		var realTimeBarsOptionsBuffer bytes.Buffer
+		for _, tv := range realTimeBarsOptions {
+			realTimeBarsOptionsBuffer.WriteString(tv.Tag)
+			realTimeBarsOptionsBuffer.WriteString("=")
+			realTimeBarsOptionsBuffer.WriteString(tv.Value)
+			realTimeBarsOptionsBuffer.WriteString(";")
+		}
+		fields = append(fields, realTimeBarsOptionsBuffer.Bytes())
+
+	}
+
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeCancelRealTimeBars encodes the request of CancelRealTimeBars
+func EncodeCancelRealTimeBars(serverVersion Version, reqID int64) ([]byte, error) {
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mCANCEL_REAL_TIME_BARS, v, reqID), nil
+}
+
+// EncodeReqFundamentalData encodes the request of ReqFundamentalData
+func EncodeReqFundamentalData(serverVersion Version, reqID int64, contract *Contract, reportType string, fundamentalDataOptions []TagValue) ([]byte, error) {
+
+	if serverVersion < mMIN_SERVER_VER_FUNDAMENTAL_DATA {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support fundamental data request.")
+	}
+
+	if serverVersion < mMIN_SERVER_VER_TRADING_CLASS {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support conId parameter in reqFundamentalData.")
+	}
+
+	// v := 2
+	const v = 2
+	fields := make([]interface{}, 0, 12)
+	fields = append(fields, mREQ_FUNDAMENTAL_DATA, v, reqID)
+
+	if serverVersion >= mMIN_SERVER_VER_TRADING_CLASS {
+		fields = append(fields, contract.ContractID)
+	}
+
+	fields = append(fields,
+		contract.Symbol,
+		contract.SecurityType,
+		contract.Exchange,
+		contract.PrimaryExchange,
+		contract.Currency,
+		contract.LocalSymbol,
+		reportType)
+
+	if serverVersion >= mMIN_SERVER_VER_LINKING {
+		var fundamentalDataOptionsBuffer bytes.Buffer
+		for _, tv := range fundamentalDataOptions {
+			fundamentalDataOptionsBuffer.WriteString(tv.Tag)
+			fundamentalDataOptionsBuffer.WriteString("=")
+			fundamentalDataOptionsBuffer.WriteString(tv.Value)
+			fundamentalDataOptionsBuffer.WriteString(";")
+		}
+		fields = append(fields, fundamentalDataOptionsBuffer.Bytes())
+
+	}
+
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeCancelFundamentalData encodes the request of CancelFundamentalData
+func EncodeCancelFundamentalData(serverVersion Version, reqID int64) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_FUNDAMENTAL_DATA {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support fundamental data request.")
+	}
+
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mCANCEL_FUNDAMENTAL_DATA, v, reqID), nil
+
+}
+
+// EncodeReqNewsProviders encodes the request of ReqNewsProviders
+func EncodeReqNewsProviders(serverVersion Version) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_REQ_NEWS_PROVIDERS {
+		return nil, errUpdateTWS(NO_VALID_ID, " It does not support news providers request.")
+	}
+
+	return makeMsgBytes(mREQ_NEWS_PROVIDERS), nil
+}
+
+// EncodeReqNewsArticle encodes the request of ReqNewsArticle
+func EncodeReqNewsArticle(serverVersion Version, reqID int64, providerCode string, articleID string, newsArticleOptions []TagValue) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_REQ_NEWS_ARTICLE {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support news article request.")
+	}
+
+	fields := make([]interface{}, 0, 5)
+	fields = append(fields,
+		mREQ_NEWS_ARTICLE,
+		reqID,
+		providerCode,
+		articleID)
+
+	if serverVersion >= mMIN_SERVER_VER_NEWS_QUERY_ORIGINS {
+		var newsArticleOptionsBuffer bytes.Buffer
+		for _, tv := range newsArticleOptions {
+			newsArticleOptionsBuffer.WriteString(tv.Tag)
+			newsArticleOptionsBuffer.WriteString("=")
+			newsArticleOptionsBuffer.WriteString(tv.Value)
+			newsArticleOptionsBuffer.WriteString(";")
+		}
+		fields = append(fields, newsArticleOptionsBuffer.Bytes())
+
+	}
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeReqHistoricalNews encodes the request of ReqHistoricalNews
+func EncodeReqHistoricalNews(serverVersion Version, reqID int64, contractID int64, providerCode string, startDateTime string, endDateTime string, totalResults int64, historicalNewsOptions []TagValue) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_REQ_HISTORICAL_NEWS {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support historical news request.")
+	}
+
+	fields := make([]interface{}, 0, 8)
+	fields = append(fields,
+		mREQ_HISTORICAL_NEWS,
+		reqID,
+		contractID,
+		providerCode,
+		startDateTime,
+		endDateTime,
+		totalResults)
+
+	if serverVersion >= mMIN_SERVER_VER_NEWS_QUERY_ORIGINS {
+		var historicalNewsOptionsBuffer bytes.Buffer
+		for _, tv := range historicalNewsOptions {
+			historicalNewsOptionsBuffer.WriteString(tv.Tag)
+			historicalNewsOptionsBuffer.WriteString("=")
+			historicalNewsOptionsBuffer.WriteString(tv.Value)
+			historicalNewsOptionsBuffer.WriteString(";")
+		}
+		fields = append(fields, historicalNewsOptionsBuffer.Bytes())
+
+	}
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeQueryDisplayGroups encodes the request of QueryDisplayGroups
+func EncodeQueryDisplayGroups(serverVersion Version, reqID int64) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_LINKING {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support queryDisplayGroups request.")
+	}
+
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mQUERY_DISPLAY_GROUPS, v, reqID), nil
+}
+
+// EncodeSubscribeToGroupEvents encodes the request of SubscribeToGroupEvents
+func EncodeSubscribeToGroupEvents(serverVersion Version, reqID int64, groupID int) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_LINKING {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support subscribeToGroupEvents request.")
+	}
+
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mSUBSCRIBE_TO_GROUP_EVENTS, v, reqID, groupID), nil
+}
+
+// EncodeUpdateDisplayGroup encodes the request of UpdateDisplayGroup
+func EncodeUpdateDisplayGroup(serverVersion Version, reqID int64, contractInfo string) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_LINKING {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support updateDisplayGroup request.")
+	}
+
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mUPDATE_DISPLAY_GROUP, v, reqID, contractInfo), nil
+}
+
+// EncodeUnsubscribeFromGroupEvents encodes the request of UnsubscribeFromGroupEvents
+func EncodeUnsubscribeFromGroupEvents(serverVersion Version, reqID int64) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_LINKING {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support unsubscribeFromGroupEvents request.")
+	}
+
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mUPDATE_DISPLAY_GROUP, v, reqID), nil
+}
+
+// EncodeVerifyRequest encodes the request of VerifyRequest
+func EncodeVerifyRequest(serverVersion Version, apiName string, apiVersion string) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_LINKING {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support verification request.")
+	}
+
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mVERIFY_REQUEST, v, apiName, apiVersion), nil
+}
+
+// EncodeVerifyMessage encodes the request of VerifyMessage
+func EncodeVerifyMessage(serverVersion Version, apiData string) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_LINKING {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support verification request.")
+	}
+
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mVERIFY_MESSAGE, v, apiData), nil
+}
+
+// EncodeVerifyAndAuthRequest encodes the request of VerifyAndAuthRequest
+func EncodeVerifyAndAuthRequest(serverVersion Version, apiName string, apiVersion string, opaqueIsvKey string) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_LINKING {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support verification request.")
+	}
+
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mVERIFY_AND_AUTH_REQUEST, v, apiName, apiVersion, opaqueIsvKey), nil
+}
+
+// EncodeVerifyAndAuthMessage encodes the request of VerifyAndAuthMessage
+func EncodeVerifyAndAuthMessage(serverVersion Version, apiData string, xyzResponse string) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_LINKING {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support verification request.")
+	}
+
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mVERIFY_MESSAGE, v, apiData, xyzResponse), nil
+}
+
+// EncodeReqSecDefOptParams encodes the request of ReqSecDefOptParams
+func EncodeReqSecDefOptParams(serverVersion Version, reqID int64, underlyingSymbol string, futFopExchange string, underlyingSecurityType string, underlyingContractID int64) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_SEC_DEF_OPT_PARAMS_REQ {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support security definition option request.")
+	}
+
+	return makeMsgBytes(mREQ_SEC_DEF_OPT_PARAMS, reqID, underlyingSymbol, futFopExchange, underlyingSecurityType, underlyingContractID), nil
+}
+
+// EncodeReqSoftDollarTiers encodes the request of ReqSoftDollarTiers
+func EncodeReqSoftDollarTiers(serverVersion Version, reqID int64) ([]byte, error) {
+	return makeMsgBytes(mREQ_SOFT_DOLLAR_TIERS, reqID), nil
+}
+
+// EncodeReqFamilyCodes encodes the request of ReqFamilyCodes
+func EncodeReqFamilyCodes(serverVersion Version) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_REQ_FAMILY_CODES {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support family codes request.")
+	}
+
+	return makeMsgBytes(mREQ_FAMILY_CODES), nil
+}
+
+// EncodeReqMatchingSymbols encodes the request of ReqMatchingSymbols
+func EncodeReqMatchingSymbols(serverVersion Version, reqID int64, pattern string) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_REQ_MATCHING_SYMBOLS {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support matching symbols request.")
+	}
+
+	return makeMsgBytes(mREQ_MATCHING_SYMBOLS, reqID, pattern), nil
+}
+
+// EncodeReqCurrentTime encodes the request of ReqCurrentTime
+func EncodeReqCurrentTime(serverVersion Version) ([]byte, error) {
+	// v := 1
+	const v = 1
+	return makeMsgBytes(mREQ_CURRENT_TIME, v), nil
+}
+
+// EncodeReqCompletedOrders encodes the request of ReqCompletedOrders
+func EncodeReqCompletedOrders(serverVersion Version, apiOnly bool) ([]byte, error) {
+	return makeMsgBytes(mREQ_COMPLETED_ORDERS, apiOnly), nil
+}
+
+// EncodeReqWshMetaData encodes the request of ReqWshMetaData
+func EncodeReqWshMetaData(serverVersion Version, reqID int64) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_WSHE_CALENDAR {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support WSHE Calendar API.")
+	}
+
+	return makeMsgBytes(mREQ_WSH_META_DATA, reqID), nil
+}
+
+// EncodeCancelWshMetaData encodes the request of CancelWshMetaData
+func EncodeCancelWshMetaData(serverVersion Version, reqID int64) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_WSHE_CALENDAR {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support WSHE Calendar API.")
+	}
+
+	return makeMsgBytes(mCANCEL_WSH_META_DATA, reqID), nil
+}
+
+// EncodeReqWshEventDataWith encodes the request of ReqWshEventDataWith
+func EncodeReqWshEventDataWith(serverVersion Version, reqID int64, wshEventData *WshEventData) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_WSHE_CALENDAR {
+		return nil, errUpdateTWS(reqID, "  It does not support WSHE Calendar API.")
+	}
+
+	if serverVersion < mMIN_SERVER_VER_WSH_EVENT_DATA_FILTERS {
+		if wshEventData.Filter != "" || wshEventData.FillWatchlist || wshEventData.FillPortfolio || wshEventData.FillCompetitors {
+			return nil, errUpdateTWS(reqID, "  It does not support WSH event data filters.")
+		}
+	}
+
+	if serverVersion < mMIN_SERVER_VER_WSH_EVENT_DATA_FILTERS_DATE {
+		if wshEventData.StartDate != "" || wshEventData.EndDate != "" || wshEventData.TotalLimit != UNSETINT {
+			return nil, errUpdateTWS(reqID, "  It does not support WSH event data date filters.")
+		}
+	}
+
+	fields := make([]interface{}, 0, 11)
+	fields = append(fields, mREQ_WSH_EVENT_DATA, reqID, handleEmpty(wshEventData.ConID))
+
+	if serverVersion >= mMIN_SERVER_VER_WSH_EVENT_DATA_FILTERS {
+		fields = append(fields,
+			wshEventData.Filter,
+			wshEventData.FillWatchlist,
+			wshEventData.FillPortfolio,
+			wshEventData.FillCompetitors)
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_WSH_EVENT_DATA_FILTERS_DATE {
+		fields = append(fields,
+			wshEventData.StartDate,
+			wshEventData.EndDate,
+			handleEmpty(wshEventData.TotalLimit))
+	}
+
+	return makeMsgBytes(fields...), nil
+}
+
+// EncodeCancelWshEventData encodes the request of CancelWshEventData
+func EncodeCancelWshEventData(serverVersion Version, reqID int64) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_WSHE_CALENDAR {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support WSHE Calendar API.")
+	}
+
+	return makeMsgBytes(mCANCEL_WSH_EVENT_DATA, reqID), nil
+}
+
+// EncodeReqUserInfo encodes the request of ReqUserInfo
+func EncodeReqUserInfo(serverVersion Version, reqID int64) ([]byte, error) {
+	if serverVersion < mMIN_SERVER_VER_USER_INFO {
+		return nil, errUpdateTWS(NO_VALID_ID, "  It does not support user info requests.")
+	}
+
+	return makeMsgBytes(mREQ_USER_INFO, reqID), nil
+}
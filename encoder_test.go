@@ -0,0 +1,239 @@
+package ibapi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update-golden", false, "write the golden files of the encoders into testdata/encoder")
+
+// encoderGoldenVersions are the server versions the golden files are written for
+var encoderGoldenVersions = []Version{MIN_CLIENT_VER, 151, mMIN_SERVER_VER_WSHE_CALENDAR, MAX_CLIENT_VER}
+
+type encoderCase struct {
+	name   string
+	encode func(v Version) ([]byte, error)
+}
+
+func encoderCases() []encoderCase {
+	stk := &Contract{ContractID: 265598, Symbol: "AAPL", SecurityType: "STK", Exchange: "SMART", PrimaryExchange: "NASDAQ", Currency: "USD", LocalSymbol: "AAPL", TradingClass: "NMS"}
+	opt := &Contract{ContractID: 437828839, Symbol: "AAPL", SecurityType: "OPT", Expiry: "20210917", Strike: 150, Right: "C", Multiplier: "100", Exchange: "SMART", Currency: "USD"}
+	fut := &Contract{Symbol: "HSI", SecurityType: "FUT", Expiry: "202106", Exchange: "HKFE", Currency: "HKD"}
+	options := []TagValue{{Tag: "XYZ", Value: "1"}}
+
+	lmt := NewLimitOrder("BUY", 187.5, 100)
+	lmt.Account = "DU1382837"
+	lmt.OrderRef = "golden"
+
+	pegBest := NewOrder()
+	pegBest.OrderType = "PEG BEST"
+	pegBest.Action = "SELL"
+	pegBest.TotalQuantity = NewDecimal(25, -1)
+	pegBest.MinTradeQty = 1
+	pegBest.MinCompeteSize = 100
+	pegBest.CompeteAgainstBestOffset = COMPETE_AGAINST_BEST_OFFSET_UP_TO_MID
+	pegBest.MidOffsetAtWhole = 0.01
+	pegBest.MidOffsetAtHalf = 0.005
+	ats := &Contract{ContractID: 265598, Symbol: "AAPL", SecurityType: "STK", Exchange: "IBKRATS", Currency: "USD"}
+
+	sub := NewScannerSubscription()
+	sub.Instrument = "STK"
+	sub.LocationCode = "STK.US.MAJOR"
+	sub.ScanCode = "TOP_PERC_GAIN"
+	sub.NumberOfRows = 10
+	sub.AbovePrice = 5
+
+	wsh := NewWshEventData()
+	wsh.ConID = 8314
+	wsh.StartDate = "20210601"
+	wsh.TotalLimit = 10
+
+	return []encoderCase{
+		{"set_server_log_level", func(v Version) ([]byte, error) { return EncodeSetServerLogLevel(v, 5) }},
+		{"req_mkt_data", func(v Version) ([]byte, error) { return EncodeReqMktData(v, 1, stk, "233,236", false, false, nil) }},
+		{"req_mkt_data_options", func(v Version) ([]byte, error) { return EncodeReqMktData(v, 1, fut, "", true, false, options) }},
+		{"cancel_mkt_data", func(v Version) ([]byte, error) { return EncodeCancelMktData(v, 1) }},
+		{"req_market_data_type", func(v Version) ([]byte, error) { return EncodeReqMarketDataType(v, 3) }},
+		{"req_smart_components", func(v Version) ([]byte, error) { return EncodeReqSmartComponents(v, 1, "a6") }},
+		{"req_market_rule", func(v Version) ([]byte, error) { return EncodeReqMarketRule(v, 26) }},
+		{"req_tick_by_tick_data", func(v Version) ([]byte, error) { return EncodeReqTickByTickData(v, 1, stk, "BidAsk", 0, false) }},
+		{"cancel_tick_by_tick_data", func(v Version) ([]byte, error) { return EncodeCancelTickByTickData(v, 1) }},
+		{"calculate_implied_volatility", func(v Version) ([]byte, error) {
+			return EncodeCalculateImpliedVolatility(v, 1, opt, 5.2, 152.3, options)
+		}},
+		{"calculate_option_price", func(v Version) ([]byte, error) { return EncodeCalculateOptionPrice(v, 1, opt, 0.25, 152.3, nil) }},
+		{"cancel_calculate_option_price", func(v Version) ([]byte, error) { return EncodeCancelCalculateOptionPrice(v, 1) }},
+		{"exercise_options", func(v Version) ([]byte, error) { return EncodeExerciseOptions(v, 1, opt, 1, 2, "DU1382837", 0) }},
+		{"place_order", func(v Version) ([]byte, error) { return EncodePlaceOrder(v, 7, stk, lmt) }},
+		{"place_order_peg_best", func(v Version) ([]byte, error) { return EncodePlaceOrder(v, 8, ats, pegBest) }},
+		{"cancel_order", func(v Version) ([]byte, error) { return EncodeCancelOrder(v, 7, "") }},
+		{"cancel_order_manual_time", func(v Version) ([]byte, error) { return EncodeCancelOrder(v, 7, "20220314 19:00:00") }},
+		{"req_open_orders", EncodeReqOpenOrders},
+		{"req_auto_open_orders", func(v Version) ([]byte, error) { return EncodeReqAutoOpenOrders(v, true) }},
+		{"req_all_open_orders", EncodeReqAllOpenOrders},
+		{"req_global_cancel", EncodeReqGlobalCancel},
+		{"req_ids", EncodeReqIDs},
+		{"req_account_updates", func(v Version) ([]byte, error) { return EncodeReqAccountUpdates(v, true, "DU1382837") }},
+		{"req_account_summary", func(v Version) ([]byte, error) {
+			return EncodeReqAccountSummary(v, 1, "All", "NetLiquidation,BuyingPower")
+		}},
+		{"cancel_account_summary", func(v Version) ([]byte, error) { return EncodeCancelAccountSummary(v, 1) }},
+		{"req_positions", EncodeReqPositions},
+		{"cancel_positions", EncodeCancelPositions},
+		{"req_positions_multi", func(v Version) ([]byte, error) { return EncodeReqPositionsMulti(v, 1, "DU1382837", "") }},
+		{"cancel_positions_multi", func(v Version) ([]byte, error) { return EncodeCancelPositionsMulti(v, 1) }},
+		{"req_account_updates_multi", func(v Version) ([]byte, error) {
+			return EncodeReqAccountUpdatesMulti(v, 1, "DU1382837", "", true)
+		}},
+		{"cancel_account_updates_multi", func(v Version) ([]byte, error) { return EncodeCancelAccountUpdatesMulti(v, 1) }},
+		{"req_pnl", func(v Version) ([]byte, error) { return EncodeReqPnL(v, 1, "DU1382837", "") }},
+		{"cancel_pnl", func(v Version) ([]byte, error) { return EncodeCancelPnL(v, 1) }},
+		{"req_pnl_single", func(v Version) ([]byte, error) { return EncodeReqPnLSingle(v, 1, "DU1382837", "", 265598) }},
+		{"cancel_pnl_single", func(v Version) ([]byte, error) { return EncodeCancelPnLSingle(v, 1) }},
+		{"req_executions", func(v Version) ([]byte, error) {
+			return EncodeReqExecutions(v, 1, ExecutionFilter{AccountCode: "DU1382837", Symbol: "AAPL", Side: "BUY"})
+		}},
+		{"req_contract_details", func(v Version) ([]byte, error) { return EncodeReqContractDetails(v, 1, opt) }},
+		{"req_mkt_depth_exchanges", EncodeReqMktDepthExchanges},
+		{"req_mkt_depth", func(v Version) ([]byte, error) { return EncodeReqMktDepth(v, 1, stk, 5, true, options) }},
+		{"cancel_mkt_depth", func(v Version) ([]byte, error) { return EncodeCancelMktDepth(v, 1, true) }},
+		{"req_news_bulletins", func(v Version) ([]byte, error) { return EncodeReqNewsBulletins(v, true) }},
+		{"cancel_news_bulletins", EncodeCancelNewsBulletins},
+		{"req_managed_accts", EncodeReqManagedAccts},
+		{"request_fa", func(v Version) ([]byte, error) { return EncodeRequestFA(v, 1) }},
+		{"replace_fa", func(v Version) ([]byte, error) {
+			return EncodeReplaceFA(v, 1, 1, "<ListOfGroups><Group><name>golden</name></Group></ListOfGroups>")
+		}},
+		{"req_historical_data", func(v Version) ([]byte, error) {
+			return EncodeReqHistoricalData(v, 1, stk, "20210601 16:00:00", "1 D", "1 min", "TRADES", true, 1, false, nil)
+		}},
+		{"req_historical_data_keep_up_to_date", func(v Version) ([]byte, error) {
+			return EncodeReqHistoricalData(v, 1, fut, "", "2 D", "5 mins", "MIDPOINT", false, 2, true, nil)
+		}},
+		{"req_historical_schedule", func(v Version) ([]byte, error) {
+			return EncodeReqHistoricalData(v, 1, stk, "20220304 00:00:00", "1 M", "1 day", "SCHEDULE", true, 1, false, nil)
+		}},
+		{"cancel_historical_data", func(v Version) ([]byte, error) { return EncodeCancelHistoricalData(v, 1) }},
+		{"req_head_time_stamp", func(v Version) ([]byte, error) { return EncodeReqHeadTimeStamp(v, 1, stk, "TRADES", true, 1) }},
+		{"cancel_head_time_stamp", func(v Version) ([]byte, error) { return EncodeCancelHeadTimeStamp(v, 1) }},
+		{"req_histogram_data", func(v Version) ([]byte, error) { return EncodeReqHistogramData(v, 1, stk, true, "3 days") }},
+		{"cancel_histogram_data", func(v Version) ([]byte, error) { return EncodeCancelHistogramData(v, 1) }},
+		{"req_historical_ticks", func(v Version) ([]byte, error) {
+			return EncodeReqHistoricalTicks(v, 1, stk, "20210601 09:30:00", "", 100, "TRADES", true, false, nil)
+		}},
+		{"req_scanner_parameters", EncodeReqScannerParameters},
+		{"req_scanner_subscription", func(v Version) ([]byte, error) {
+			return EncodeReqScannerSubscription(v, 1, sub, nil, []TagValue{{Tag: "changePercAbove", Value: "5"}})
+		}},
+		{"cancel_scanner_subscription", func(v Version) ([]byte, error) { return EncodeCancelScannerSubscription(v, 1) }},
+		{"req_real_time_bars", func(v Version) ([]byte, error) { return EncodeReqRealTimeBars(v, 1, stk, 5, "TRADES", true, nil) }},
+		{"cancel_real_time_bars", func(v Version) ([]byte, error) { return EncodeCancelRealTimeBars(v, 1) }},
+		{"req_fundamental_data", func(v Version) ([]byte, error) { return EncodeReqFundamentalData(v, 1, stk, "ReportSnapshot", nil) }},
+		{"cancel_fundamental_data", func(v Version) ([]byte, error) { return EncodeCancelFundamentalData(v, 1) }},
+		{"req_news_providers", EncodeReqNewsProviders},
+		{"req_news_article", func(v Version) ([]byte, error) { return EncodeReqNewsArticle(v, 1, "BRFG", "BRFG$0ecb9e6b", nil) }},
+		{"req_historical_news", func(v Version) ([]byte, error) {
+			return EncodeReqHistoricalNews(v, 1, 265598, "BRFG+BRFUPDN", "", "", 10, nil)
+		}},
+		{"query_display_groups", func(v Version) ([]byte, error) { return EncodeQueryDisplayGroups(v, 1) }},
+		{"subscribe_to_group_events", func(v Version) ([]byte, error) { return EncodeSubscribeToGroupEvents(v, 1, 4) }},
+		{"update_display_group", func(v Version) ([]byte, error) { return EncodeUpdateDisplayGroup(v, 1, "265598@SMART") }},
+		{"unsubscribe_from_group_events", func(v Version) ([]byte, error) { return EncodeUnsubscribeFromGroupEvents(v, 1) }},
+		{"verify_request", func(v Version) ([]byte, error) { return EncodeVerifyRequest(v, "golden", "1.0") }},
+		{"verify_message", func(v Version) ([]byte, error) { return EncodeVerifyMessage(v, "data") }},
+		{"verify_and_auth_request", func(v Version) ([]byte, error) { return EncodeVerifyAndAuthRequest(v, "golden", "1.0", "key") }},
+		{"verify_and_auth_message", func(v Version) ([]byte, error) { return EncodeVerifyAndAuthMessage(v, "data", "xyz") }},
+		{"req_sec_def_opt_params", func(v Version) ([]byte, error) { return EncodeReqSecDefOptParams(v, 1, "AAPL", "", "STK", 265598) }},
+		{"req_soft_dollar_tiers", func(v Version) ([]byte, error) { return EncodeReqSoftDollarTiers(v, 1) }},
+		{"req_family_codes", EncodeReqFamilyCodes},
+		{"req_matching_symbols", func(v Version) ([]byte, error) { return EncodeReqMatchingSymbols(v, 1, "AAP") }},
+		{"req_current_time", EncodeReqCurrentTime},
+		{"req_completed_orders", func(v Version) ([]byte, error) { return EncodeReqCompletedOrders(v, true) }},
+		{"req_wsh_meta_data", func(v Version) ([]byte, error) { return EncodeReqWshMetaData(v, 1) }},
+		{"cancel_wsh_meta_data", func(v Version) ([]byte, error) { return EncodeCancelWshMetaData(v, 1) }},
+		{"req_wsh_event_data", func(v Version) ([]byte, error) { return EncodeReqWshEventDataWith(v, 1, wsh) }},
+		{"cancel_wsh_event_data", func(v Version) ([]byte, error) { return EncodeCancelWshEventData(v, 1) }},
+		{"req_user_info", func(v Version) ([]byte, error) { return EncodeReqUserInfo(v, 1) }},
+	}
+}
+
+// goldenText writes the fields of the encoded msg one per line, or the error if the request is not supported
+func goldenText(msg []byte, err error) []byte {
+	if err != nil {
+		return []byte(fmt.Sprintf("error: %v\n", err))
+	}
+
+	var buf bytes.Buffer
+	for _, f := range splitMsgBytes(msg[4:]) {
+		buf.Write(f)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+func TestEncodeGolden(t *testing.T) {
+	for _, v := range encoderGoldenVersions {
+		for _, c := range encoderCases() {
+			msg, err := c.encode(v)
+			if err == nil && int(binary.BigEndian.Uint32(msg)) != len(msg)-4 {
+				t.Errorf("%s@%d: the size header is %d, want %d", c.name, v, binary.BigEndian.Uint32(msg), len(msg)-4)
+			}
+			got := goldenText(msg, err)
+
+			file := filepath.Join("testdata", "encoder", fmt.Sprint(v), c.name+".golden")
+			if *updateGolden {
+				if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(file, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+
+			want, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s@%d:\n%s\nwant:\n%s", c.name, v, got, want)
+			}
+		}
+	}
+}
+
+func TestEncodeRequestError(t *testing.T) {
+	_, err := EncodeReqWshMetaData(151, 9)
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) || !errors.Is(err, UPDATE_TWS) || reqErr.ReqID != NO_VALID_ID {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	w := &errorWrapper{}
+	ic := newTestClient(func(ic *IbClient, req []byte) {
+		t.Errorf("request should not be sent: %q", req)
+	})
+	ic.SetWrapper(w)
+	ic.ReqWshMetaData(9)
+	if w.reqID != NO_VALID_ID || w.code != UPDATE_TWS.code || w.msg != err.Error() {
+		t.Errorf("unexpected error reported to the wrapper: %d %d %s", w.reqID, w.code, w.msg)
+	}
+}
+
+type errorWrapper struct {
+	Wrapper
+	reqID int64
+	code  int64
+	msg   string
+}
+
+func (w *errorWrapper) Error(reqID int64, errCode int64, errString string) {
+	w.reqID, w.code, w.msg = reqID, errCode, errString
+}
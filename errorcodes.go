@@ -0,0 +1,269 @@
+/*
+errorcodes contains the catalogue of the codes reported by Error, which classifies them by severity, category and class.
+*/
+
+package ibapi
+
+// ErrorSeverity is how serious the code of Error is
+type ErrorSeverity int
+
+const (
+	SEVERITY_INFO ErrorSeverity = iota
+	SEVERITY_WARNING
+	SEVERITY_ERROR
+	SEVERITY_CRITICAL
+)
+
+func (s ErrorSeverity) String() string {
+	switch s {
+	case SEVERITY_INFO:
+		return "INFO"
+	case SEVERITY_WARNING:
+		return "WARNING"
+	case SEVERITY_ERROR:
+		return "ERROR"
+	case SEVERITY_CRITICAL:
+		return "CRITICAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// ErrorCategory is the subject of the code of Error.
+// It is an error, so that errors.Is(err, CATEGORY_PACING) reports whether err is an IbError of the category.
+type ErrorCategory int
+
+const (
+	CATEGORY_OTHER ErrorCategory = iota
+	CATEGORY_CONNECTIVITY
+	CATEGORY_PACING
+	CATEGORY_CONTRACT
+	CATEGORY_ORDER
+	CATEGORY_MARKET_DATA
+	CATEGORY_HISTORICAL_DATA
+	CATEGORY_ACCOUNT
+	CATEGORY_API
+)
+
+func (c ErrorCategory) String() string {
+	switch c {
+	case CATEGORY_CONNECTIVITY:
+		return "CONNECTIVITY"
+	case CATEGORY_PACING:
+		return "PACING"
+	case CATEGORY_CONTRACT:
+		return "CONTRACT"
+	case CATEGORY_ORDER:
+		return "ORDER"
+	case CATEGORY_MARKET_DATA:
+		return "MARKET_DATA"
+	case CATEGORY_HISTORICAL_DATA:
+		return "HISTORICAL_DATA"
+	case CATEGORY_ACCOUNT:
+		return "ACCOUNT"
+	case CATEGORY_API:
+		return "API"
+	default:
+		return "OTHER"
+	}
+}
+
+func (c ErrorCategory) Error() string {
+	return "error category " + c.String()
+}
+
+// ErrorClass tells what the code of Error means to the request or the connection it is reported for.
+// It is an error, so that errors.Is(err, ERROR_REQUEST_FATAL) reports whether err is an IbError of the class.
+/*
+	ERROR_INFORMATIONAL  a notice or warning, the request goes on, such as 2104 farm connection is OK
+	ERROR_REQUEST_FATAL  the request of reqID is failed or finished, such as 200 no security definition
+	ERROR_CONNECTION     the connection to TWS or of TWS to IB is affected, such as 1100 connectivity lost
+*/
+type ErrorClass int
+
+const (
+	ERROR_REQUEST_FATAL ErrorClass = iota
+	ERROR_INFORMATIONAL
+	ERROR_CONNECTION
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ERROR_INFORMATIONAL:
+		return "INFORMATIONAL"
+	case ERROR_CONNECTION:
+		return "CONNECTION"
+	default:
+		return "REQUEST_FATAL"
+	}
+}
+
+func (c ErrorClass) Error() string {
+	return "error class " + c.String()
+}
+
+// ErrorCodeInfo is the catalogue entry of a code of Error, Name is "" for the codes not in the catalogue
+type ErrorCodeInfo struct {
+	Code        int64
+	Name        string
+	Severity    ErrorSeverity
+	Category    ErrorCategory
+	Class       ErrorClass
+	Description string
+}
+
+// IsInformational reports whether the code does not fail the request it is attached to
+func (info ErrorCodeInfo) IsInformational() bool {
+	return info.Class == ERROR_INFORMATIONAL
+}
+
+// IsRequestFatal reports whether the request of the code is failed or finished
+func (info ErrorCodeInfo) IsRequestFatal() bool {
+	return info.Class == ERROR_REQUEST_FATAL
+}
+
+// IsConnection reports whether the code is about the connectivity rather than a request
+func (info ErrorCodeInfo) IsConnection() bool {
+	return info.Class == ERROR_CONNECTION
+}
+
+// errorCodeList is the catalogue of the well-known codes, the client side 5xx codes are those of errors.go
+var errorCodeList = []ErrorCodeInfo{
+	// request and order errors
+	{100, "MAX_RATE_EXCEEDED", SEVERITY_ERROR, CATEGORY_PACING, ERROR_REQUEST_FATAL, "Max rate of messages per second has been exceeded."},
+	{101, "MAX_TICKERS_REACHED", SEVERITY_ERROR, CATEGORY_MARKET_DATA, ERROR_REQUEST_FATAL, "Max number of tickers has been reached."},
+	{102, "DUPLICATE_TICKER_ID", SEVERITY_ERROR, CATEGORY_API, ERROR_REQUEST_FATAL, "Duplicate ticker ID."},
+	{103, "DUPLICATE_ORDER_ID", SEVERITY_ERROR, CATEGORY_ORDER, ERROR_REQUEST_FATAL, "Duplicate order ID."},
+	{104, "MODIFY_FILLED_ORDER", SEVERITY_ERROR, CATEGORY_ORDER, ERROR_REQUEST_FATAL, "Can't modify a filled order."},
+	{105, "MODIFY_ORDER_MISMATCH", SEVERITY_ERROR, CATEGORY_ORDER, ERROR_REQUEST_FATAL, "Order being modified does not match original order."},
+	{110, "PRICE_NOT_CONFORM_TICK", SEVERITY_ERROR, CATEGORY_ORDER, ERROR_REQUEST_FATAL, "The price does not conform to the minimum price variation for this contract."},
+	{135, "ORDER_ID_NOT_FOUND", SEVERITY_ERROR, CATEGORY_ORDER, ERROR_REQUEST_FATAL, "Can't find order with ID."},
+	{161, "ORDER_NOT_CANCELLABLE", SEVERITY_ERROR, CATEGORY_ORDER, ERROR_REQUEST_FATAL, "Cancel attempted when order is not in a cancellable state."},
+	{162, "PACING_VIOLATION", SEVERITY_ERROR, CATEGORY_PACING, ERROR_REQUEST_FATAL, "Historical market data Service error message, such as pacing violation or no data."},
+	{165, "HMDS_QUERY_MESSAGE", SEVERITY_INFO, CATEGORY_HISTORICAL_DATA, ERROR_INFORMATIONAL, "Historical market Data Service query message."},
+	{166, "HMDS_EXPIRED_CONTRACT", SEVERITY_ERROR, CATEGORY_HISTORICAL_DATA, ERROR_REQUEST_FATAL, "HMDS Expired Contract Violation."},
+	{200, "NO_SECURITY_DEFINITION", SEVERITY_ERROR, CATEGORY_CONTRACT, ERROR_REQUEST_FATAL, "No security definition has been found for the request."},
+	{201, "ORDER_REJECTED", SEVERITY_ERROR, CATEGORY_ORDER, ERROR_REQUEST_FATAL, "Order rejected."},
+	{202, "ORDER_CANCELLED", SEVERITY_INFO, CATEGORY_ORDER, ERROR_REQUEST_FATAL, "Order cancelled."},
+	{203, "SECURITY_NOT_ALLOWED", SEVERITY_ERROR, CATEGORY_ACCOUNT, ERROR_REQUEST_FATAL, "The security is not available or allowed for this account."},
+	{300, "TICKER_ID_NOT_FOUND", SEVERITY_ERROR, CATEGORY_API, ERROR_REQUEST_FATAL, "Can't find EId with ticker Id."},
+	{309, "MAX_MARKET_DEPTH_REACHED", SEVERITY_ERROR, CATEGORY_MARKET_DATA, ERROR_REQUEST_FATAL, "Max number of market depth requests has been reached."},
+	{316, "MARKET_DEPTH_HALTED", SEVERITY_WARNING, CATEGORY_MARKET_DATA, ERROR_REQUEST_FATAL, "Market depth data has been HALTED."},
+	{317, "MARKET_DEPTH_RESET", SEVERITY_WARNING, CATEGORY_MARKET_DATA, ERROR_INFORMATIONAL, "Market depth data has been RESET."},
+	{321, "INVALID_REQUEST", SEVERITY_ERROR, CATEGORY_API, ERROR_REQUEST_FATAL, "Server error when validating an API client request."},
+	{322, "REQUEST_PROCESSING_ERROR", SEVERITY_ERROR, CATEGORY_API, ERROR_REQUEST_FATAL, "Server error when processing an API client request."},
+	{326, "CLIENT_ID_IN_USE", SEVERITY_CRITICAL, CATEGORY_CONNECTIVITY, ERROR_CONNECTION, "Unable to connect as the client id is already in use."},
+	{354, "MARKET_DATA_NOT_SUBSCRIBED", SEVERITY_ERROR, CATEGORY_MARKET_DATA, ERROR_REQUEST_FATAL, "Requested market data is not subscribed."},
+	{365, "NO_SCANNER_SUBSCRIPTION", SEVERITY_ERROR, CATEGORY_API, ERROR_REQUEST_FATAL, "No scanner subscription found for ticker id."},
+	{366, "NO_HISTORICAL_QUERY", SEVERITY_ERROR, CATEGORY_HISTORICAL_DATA, ERROR_REQUEST_FATAL, "No historical data query found for ticker id."},
+	{399, "ORDER_MESSAGE", SEVERITY_WARNING, CATEGORY_ORDER, ERROR_INFORMATIONAL, "Order message, such as the order will not be placed at the exchange until the market opens."},
+	{404, "SHARES_NOT_AVAILABLE_FOR_SHORT", SEVERITY_WARNING, CATEGORY_ORDER, ERROR_INFORMATIONAL, "Shares for this order are not immediately available for short sale, the order will be held."},
+	{420, "INVALID_REALTIME_QUERY", SEVERITY_ERROR, CATEGORY_PACING, ERROR_REQUEST_FATAL, "Invalid real-time query, such as pacing violation."},
+
+	// client side errors, see errors.go
+	{501, "ALREADY_CONNECTED", SEVERITY_ERROR, CATEGORY_CONNECTIVITY, ERROR_CONNECTION, ALREADY_CONNECTED.msg},
+	{502, "CONNECT_FAIL", SEVERITY_CRITICAL, CATEGORY_CONNECTIVITY, ERROR_CONNECTION, "Couldn't connect to TWS."},
+	{503, "UPDATE_TWS", SEVERITY_ERROR, CATEGORY_API, ERROR_REQUEST_FATAL, UPDATE_TWS.msg},
+	{504, "NOT_CONNECTED", SEVERITY_CRITICAL, CATEGORY_CONNECTIVITY, ERROR_CONNECTION, NOT_CONNECTED.msg},
+	{505, "UNKNOWN_ID", SEVERITY_ERROR, CATEGORY_API, ERROR_CONNECTION, UNKNOWN_ID.msg},
+	{506, "UNSUPPORTED_VERSION", SEVERITY_CRITICAL, CATEGORY_CONNECTIVITY, ERROR_CONNECTION, UNSUPPORTED_VERSION.msg},
+	{507, "BAD_LENGTH", SEVERITY_CRITICAL, CATEGORY_CONNECTIVITY, ERROR_CONNECTION, BAD_LENGTH.msg},
+	{508, "BAD_MESSAGE", SEVERITY_ERROR, CATEGORY_API, ERROR_REQUEST_FATAL, BAD_MESSAGE.msg},
+	{509, "SOCKET_EXCEPTION", SEVERITY_CRITICAL, CATEGORY_CONNECTIVITY, ERROR_CONNECTION, SOCKET_EXCEPTION.msg},
+	{520, "FAIL_CREATE_SOCK", SEVERITY_CRITICAL, CATEGORY_CONNECTIVITY, ERROR_CONNECTION, FAIL_CREATE_SOCK.msg},
+	{530, "SSL_FAIL", SEVERITY_CRITICAL, CATEGORY_CONNECTIVITY, ERROR_CONNECTION, SSL_FAIL.msg},
+
+	// system messages
+	{1100, "CONNECTIVITY_LOST", SEVERITY_CRITICAL, CATEGORY_CONNECTIVITY, ERROR_CONNECTION, "Connectivity between IB and Trader Workstation has been lost."},
+	{1101, "CONNECTIVITY_RESTORED_DATA_LOST", SEVERITY_WARNING, CATEGORY_CONNECTIVITY, ERROR_CONNECTION, "Connectivity between IB and TWS has been restored- data lost."},
+	{1102, "CONNECTIVITY_RESTORED", SEVERITY_INFO, CATEGORY_CONNECTIVITY, ERROR_CONNECTION, "Connectivity between IB and TWS has been restored- data maintained."},
+	{1300, "SOCKET_PORT_RESET", SEVERITY_CRITICAL, CATEGORY_CONNECTIVITY, ERROR_CONNECTION, "TWS socket port has been reset and this connection is being dropped."},
+
+	// warnings
+	{2100, "ACCOUNT_UPDATES_UNSUBSCRIBED", SEVERITY_WARNING, CATEGORY_ACCOUNT, ERROR_INFORMATIONAL, "New account data requested from TWS, API client has been unsubscribed from account data."},
+	{2103, "MARKET_DATA_FARM_BROKEN", SEVERITY_WARNING, CATEGORY_CONNECTIVITY, ERROR_INFORMATIONAL, "A market data farm is disconnected."},
+	{2104, "MARKET_DATA_FARM_OK", SEVERITY_INFO, CATEGORY_CONNECTIVITY, ERROR_INFORMATIONAL, "Market data farm connection is OK."},
+	{2105, "HMDS_FARM_BROKEN", SEVERITY_WARNING, CATEGORY_CONNECTIVITY, ERROR_INFORMATIONAL, "A historical data farm is disconnected."},
+	{2106, "HMDS_FARM_OK", SEVERITY_INFO, CATEGORY_CONNECTIVITY, ERROR_INFORMATIONAL, "A historical data farm is connected."},
+	{2107, "HMDS_FARM_INACTIVE", SEVERITY_INFO, CATEGORY_CONNECTIVITY, ERROR_INFORMATIONAL, "A historical data farm connection has become inactive but should be available upon demand."},
+	{2108, "MARKET_DATA_FARM_INACTIVE", SEVERITY_INFO, CATEGORY_CONNECTIVITY, ERROR_INFORMATIONAL, "A market data farm connection has become inactive but should be available upon demand."},
+	{2109, "ORDER_EVENT_WARNING", SEVERITY_WARNING, CATEGORY_ORDER, ERROR_INFORMATIONAL, "Order Event Warning: Attribute 'Outside Regular Trading Hours' is ignored."},
+	{2110, "TWS_SERVER_BROKEN", SEVERITY_CRITICAL, CATEGORY_CONNECTIVITY, ERROR_CONNECTION, "Connectivity between TWS and server is broken. It will be restored automatically."},
+	{2119, "MARKET_DATA_FARM_CONNECTING", SEVERITY_INFO, CATEGORY_CONNECTIVITY, ERROR_INFORMATIONAL, "Market data farm is connecting."},
+	{2137, "CROSS_SIDE_WARNING", SEVERITY_WARNING, CATEGORY_ORDER, ERROR_INFORMATIONAL, "The closing order quantity is greater than your current position."},
+	{2157, "SEC_DEF_FARM_BROKEN", SEVERITY_WARNING, CATEGORY_CONNECTIVITY, ERROR_INFORMATIONAL, "Sec-def data farm connection is broken."},
+	{2158, "SEC_DEF_FARM_OK", SEVERITY_INFO, CATEGORY_CONNECTIVITY, ERROR_INFORMATIONAL, "Sec-def data farm connection is OK."},
+
+	// TWS errors of the newer versions
+	// 10167 and 10197 are only warnings, the delayed data or that after the competing session still follows
+	{10090, "MARKET_DATA_PARTIALLY_SUBSCRIBED", SEVERITY_WARNING, CATEGORY_MARKET_DATA, ERROR_INFORMATIONAL, "Part of requested market data is not subscribed."},
+	{10147, "ORDER_TO_CANCEL_NOT_FOUND", SEVERITY_ERROR, CATEGORY_ORDER, ERROR_REQUEST_FATAL, "OrderId that needs to be cancelled is not found."},
+	{10148, "ORDER_CANNOT_BE_CANCELLED", SEVERITY_ERROR, CATEGORY_ORDER, ERROR_REQUEST_FATAL, "OrderId that needs to be cancelled can not be cancelled."},
+	{10167, "DELAYED_MARKET_DATA", SEVERITY_WARNING, CATEGORY_MARKET_DATA, ERROR_INFORMATIONAL, "Requested market data is not subscribed. Displaying delayed market data."},
+	{10168, "DELAYED_MARKET_DATA_DISABLED", SEVERITY_ERROR, CATEGORY_MARKET_DATA, ERROR_REQUEST_FATAL, "Requested market data is not subscribed. Delayed market data is not enabled."},
+	{10182, "LIVE_UPDATES_FAILED", SEVERITY_ERROR, CATEGORY_HISTORICAL_DATA, ERROR_REQUEST_FATAL, "Failed to request live updates (disconnected)."},
+	{10187, "HISTORICAL_TICKS_FAILED", SEVERITY_ERROR, CATEGORY_HISTORICAL_DATA, ERROR_REQUEST_FATAL, "Failed to request historical ticks."},
+	{10189, "TICK_BY_TICK_FAILED", SEVERITY_ERROR, CATEGORY_MARKET_DATA, ERROR_REQUEST_FATAL, "Failed to request tick-by-tick data."},
+	{10190, "MAX_TICK_BY_TICK_REACHED", SEVERITY_ERROR, CATEGORY_MARKET_DATA, ERROR_REQUEST_FATAL, "Max number of tick-by-tick requests has been reached."},
+	{10197, "COMPETING_LIVE_SESSION", SEVERITY_WARNING, CATEGORY_MARKET_DATA, ERROR_INFORMATIONAL, "No market data during competing live session."},
+	{10225, "BUST_EVENT", SEVERITY_ERROR, CATEGORY_MARKET_DATA, ERROR_REQUEST_FATAL, "Bust event occurred, current subscription is deactivated."},
+}
+
+var errorCodes = func() map[int64]ErrorCodeInfo {
+	m := make(map[int64]ErrorCodeInfo, len(errorCodeList))
+	for _, info := range errorCodeList {
+		m[info.Code] = info
+	}
+	return m
+}()
+
+// LookupErrorCode returns the catalogue entry of code.
+/*
+The codes not in the catalogue are classified by the ranges of TWS:
+	2100-2199    the warnings, ERROR_INFORMATIONAL
+	1100-1399    the system messages, ERROR_CONNECTION
+	the others   ERROR_REQUEST_FATAL
+*/
+func LookupErrorCode(code int64) ErrorCodeInfo {
+	if info, ok := errorCodes[code]; ok {
+		return info
+	}
+
+	info := ErrorCodeInfo{Code: code, Severity: SEVERITY_ERROR, Class: ERROR_REQUEST_FATAL}
+	switch {
+	case code >= 2100 && code < 2200:
+		info.Severity, info.Class = SEVERITY_WARNING, ERROR_INFORMATIONAL
+	case code >= 1100 && code < 1400:
+		info.Severity, info.Category, info.Class = SEVERITY_WARNING, CATEGORY_CONNECTIVITY, ERROR_CONNECTION
+	}
+	return info
+}
+
+// catalogued returns the IbError of the code in the catalogue, which is used as the target of errors.Is
+func catalogued(code int64) IbError {
+	return IbError{code, errorCodes[code].Description}
+}
+
+// the IbErrors of the well-known codes of TWS, errors.Is matches the IbError of Error with the same code
+var (
+	MAX_RATE_EXCEEDED               = catalogued(100)
+	DUPLICATE_TICKER_ID             = catalogued(102)
+	DUPLICATE_ORDER_ID              = catalogued(103)
+	PACING_VIOLATION                = catalogued(162)
+	NO_SECURITY_DEFINITION          = catalogued(200)
+	ORDER_REJECTED                  = catalogued(201)
+	ORDER_CANCELLED                 = catalogued(202)
+	CLIENT_ID_IN_USE                = catalogued(326)
+	MARKET_DATA_NOT_SUBSCRIBED      = catalogued(354)
+	CONNECTIVITY_LOST               = catalogued(1100)
+	CONNECTIVITY_RESTORED_DATA_LOST = catalogued(1101)
+	CONNECTIVITY_RESTORED           = catalogued(1102)
+	MARKET_DATA_FARM_BROKEN         = catalogued(2103)
+	MARKET_DATA_FARM_OK             = catalogued(2104)
+	HMDS_FARM_BROKEN                = catalogued(2105)
+	HMDS_FARM_OK                    = catalogued(2106)
+	TWS_SERVER_BROKEN               = catalogued(2110)
+	SEC_DEF_FARM_BROKEN             = catalogued(2157)
+	SEC_DEF_FARM_OK                 = catalogued(2158)
+	DELAYED_MARKET_DATA             = catalogued(10167)
+)
@@ -0,0 +1,132 @@
+package ibapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLookupErrorCode(t *testing.T) {
+	cases := []struct {
+		code     int64
+		name     string
+		category ErrorCategory
+		class    ErrorClass
+	}{
+		{162, "PACING_VIOLATION", CATEGORY_PACING, ERROR_REQUEST_FATAL},
+		{200, "NO_SECURITY_DEFINITION", CATEGORY_CONTRACT, ERROR_REQUEST_FATAL},
+		{201, "ORDER_REJECTED", CATEGORY_ORDER, ERROR_REQUEST_FATAL},
+		{202, "ORDER_CANCELLED", CATEGORY_ORDER, ERROR_REQUEST_FATAL},
+		{503, "UPDATE_TWS", CATEGORY_API, ERROR_REQUEST_FATAL},
+		{1100, "CONNECTIVITY_LOST", CATEGORY_CONNECTIVITY, ERROR_CONNECTION},
+		{1102, "CONNECTIVITY_RESTORED", CATEGORY_CONNECTIVITY, ERROR_CONNECTION},
+		{2104, "MARKET_DATA_FARM_OK", CATEGORY_CONNECTIVITY, ERROR_INFORMATIONAL},
+		{2158, "SEC_DEF_FARM_OK", CATEGORY_CONNECTIVITY, ERROR_INFORMATIONAL},
+		{10167, "DELAYED_MARKET_DATA", CATEGORY_MARKET_DATA, ERROR_INFORMATIONAL},
+		// not in the catalogue, classified by the range
+		{2199, "", CATEGORY_OTHER, ERROR_INFORMATIONAL},
+		{1350, "", CATEGORY_CONNECTIVITY, ERROR_CONNECTION},
+		{99999, "", CATEGORY_OTHER, ERROR_REQUEST_FATAL},
+	}
+
+	for _, c := range cases {
+		info := LookupErrorCode(c.code)
+		if info.Code != c.code || info.Name != c.name || info.Category != c.category || info.Class != c.class {
+			t.Errorf("%d: unexpected %+v", c.code, info)
+		}
+	}
+
+	seen := map[int64]bool{}
+	for _, info := range errorCodeList {
+		if seen[info.Code] || info.Name == "" || info.Description == "" {
+			t.Errorf("invalid catalogue entry %+v", info)
+		}
+		seen[info.Code] = true
+	}
+}
+
+func TestIbErrorIs(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", NewIbError(200, "No security definition has been found for the request"))
+
+	if !errors.Is(err, NO_SECURITY_DEFINITION) || errors.Is(err, ORDER_REJECTED) {
+		t.Error("IbError should be matched by the code")
+	}
+	if !errors.Is(err, ERROR_REQUEST_FATAL) || errors.Is(err, ERROR_CONNECTION) {
+		t.Error("IbError should be matched by the class")
+	}
+	if !errors.Is(err, CATEGORY_CONTRACT) || errors.Is(err, CATEGORY_ORDER) {
+		t.Error("IbError should be matched by the category")
+	}
+	if !errors.Is(errUpdateTWS(1, "  It does not support something."), UPDATE_TWS) {
+		t.Error("RequestError should unwrap to UPDATE_TWS")
+	}
+
+	var ie IbError
+	if !errors.As(err, &ie) || ie.Code() != 200 || ie.Info().Name != "NO_SECURITY_DEFINITION" {
+		t.Errorf("unexpected IbError %v", ie)
+	}
+}
+
+func TestIsWarningCode(t *testing.T) {
+	for _, code := range []int64{399, 2104, 2106, 2158, 10090, 10167, 10197} {
+		if !isWarningCode(code) {
+			t.Errorf("%d should not fail the request", code)
+		}
+	}
+	for _, code := range []int64{162, 200, 201, 202, 321, 503, 504, 1100, 1102, 2110} {
+		if isWarningCode(code) {
+			t.Errorf("%d should fail the request", code)
+		}
+	}
+}
+
+func TestRequestErrorClassification(t *testing.T) {
+	ic := newTestClient(func(ic *IbClient, req []byte) {
+		reqID := fieldInt(splitMsgBytes(req[4:])[1])
+		ic.dispatcher.Error(reqID, 2106, "HMDS data farm connection is OK:ushmds")
+		ic.dispatcher.Error(reqID, 162, "Historical Market Data Service error message:API historical data query cancelled")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := ic.FetchHistoricalData(ctx, &Contract{ContractID: 265598}, "", "1 D", "1 hour", "TRADES", true, 1)
+	if !errors.Is(err, PACING_VIOLATION) || !errors.Is(err, ERROR_REQUEST_FATAL) || !errors.Is(err, CATEGORY_PACING) {
+		t.Errorf("expect the error of 162, got %v", err)
+	}
+}
+
+func TestRequestFailsWithoutData(t *testing.T) {
+	ic := newTestClient(func(ic *IbClient, req []byte) {
+		ic.dispatcher.Error(fieldInt(splitMsgBytes(req[4:])[1]), 504, LookupErrorCode(504).Description)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := ic.FetchHistoricalData(ctx, &Contract{ContractID: 265598}, "", "1 D", "1 hour", "TRADES", true, 1)
+	if ie, ok := err.(IbError); !ok || ie.Code() != 504 {
+		t.Errorf("expect the error of 504, got %v", err)
+	}
+}
+
+func TestRequestGoesOnWithDelayedData(t *testing.T) {
+	for _, code := range []int64{10167, 10197} {
+		code := code
+		ic := newTestClient(func(ic *IbClient, req []byte) {
+			reqID := fieldInt(splitMsgBytes(req[4:])[1])
+			ic.dispatcher.Error(reqID, code, LookupErrorCode(code).Description)
+			ic.dispatcher.HistoricalData(reqID, &BarData{Date: "20221019", Close: 187.5})
+			ic.dispatcher.HistoricalDataEnd(reqID, "", "")
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		bars, err := ic.FetchHistoricalData(ctx, &Contract{ContractID: 265598}, "", "1 D", "1 hour", "TRADES", true, 1)
+		cancel()
+		if err != nil || len(bars) != 1 {
+			t.Errorf("%d: expect the delayed bar, got %v %v", code, bars, err)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package ibapi
 
+import "fmt"
+
 // IbError is ib internal errors
 type IbError struct {
 	code int64
@@ -10,6 +12,35 @@ func (ie IbError) Error() string {
 	return ie.msg
 }
 
+// Code is the code of Error, see LookupErrorCode
+func (ie IbError) Code() int64 {
+	return ie.code
+}
+
+// Info is the catalogue entry of the code
+func (ie IbError) Info() ErrorCodeInfo {
+	return LookupErrorCode(ie.code)
+}
+
+// Is reports whether target is an IbError with the same code, or the ErrorClass or ErrorCategory of the code,
+// so that errors.Is(err, NO_SECURITY_DEFINITION) and errors.Is(err, ERROR_CONNECTION) work whatever the msg is.
+func (ie IbError) Is(target error) bool {
+	switch t := target.(type) {
+	case IbError:
+		return ie.code == t.code
+	case ErrorClass:
+		return ie.Info().Class == t
+	case ErrorCategory:
+		return ie.Info().Category == t
+	}
+	return false
+}
+
+// NewIbError returns the IbError of the code and msg reported by Error
+func NewIbError(code int64, msg string) IbError {
+	return IbError{code, msg}
+}
+
 var (
 	ALREADY_CONNECTED = IbError{501, "Already connected."}
 	CONNECT_FAIL      = IbError{502, `Couldn't connect to TWS. Confirm that "Enable ActiveX and Socket EClients" 
@@ -27,3 +58,47 @@ var (
 	FAIL_CREATE_SOCK    = IbError{520, "Failed to create socket"}
 	SSL_FAIL            = IbError{530, "SSL specific error: "}
 )
+
+// isWarningCode reports whether the code of Error is just a warning or notice from TWS,
+// which should not fail the request it is attached to.
+// The connection-level codes fail the request, as its response may never come, such as 504 not connected.
+func isWarningCode(code int64) bool {
+	return LookupErrorCode(code).Class == ERROR_INFORMATIONAL
+}
+
+// DecodeError is the error of a malformed msg from TWS, which is skipped by the decoder.
+// MsgID is NO_VALID_ID if even the msg ID could not be decoded.
+type DecodeError struct {
+	MsgID    IN
+	MsgBytes []byte
+	Err      error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("failed to decode msg %d: %v", e.MsgID, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// RequestError is the error of a request which could not be encoded, such as a parameter not supported by the server version.
+// It unwraps to Err, so errors.Is(err, UPDATE_TWS) reports whether the TWS is out of date for the request.
+type RequestError struct {
+	ReqID int64
+	Err   IbError
+	Msg   string
+}
+
+func (e *RequestError) Error() string {
+	return e.Err.msg + e.Msg
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// errUpdateTWS is the RequestError of a request not supported by the server version, msg tells what is not supported
+func errUpdateTWS(reqID int64, msg string) error {
+	return &RequestError{ReqID: reqID, Err: UPDATE_TWS, Msg: msg}
+}
@@ -0,0 +1,322 @@
+/*
+fa contains the Financial Advisor configurations exchanged by RequestFA, ReplaceFA and ReceiveFA,
+such as FaGroups, FaProfiles and FaAliases, and the helpers to request or replace them synchronously.
+*/
+
+package ibapi
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// FaDataType is the faData of RequestFA, ReplaceFA and ReceiveFA
+type FaDataType = int
+
+const (
+	FA_GROUPS   FaDataType = 1
+	FA_PROFILES FaDataType = 2
+	FA_ALIASES  FaDataType = 3
+)
+
+// allocation methods of FaGroup
+const (
+	FA_METHOD_EQUAL_QUANTITY   = "EqualQuantity"
+	FA_METHOD_AVAILABLE_EQUITY = "AvailableEquity"
+	FA_METHOD_NET_LIQ          = "NetLiq"
+	FA_METHOD_PCT_CHANGE       = "PctChange"
+)
+
+// FaProfileType is the allocation type of FaProfile
+type FaProfileType = int64
+
+const (
+	FA_PROFILE_PERCENTAGES      FaProfileType = 1
+	FA_PROFILE_FINANCIAL_RATIOS FaProfileType = 2
+	FA_PROFILE_SHARES           FaProfileType = 3
+)
+
+// ErrFARequestPending is returned when another typed FA request of the same faData is waiting for ReceiveFA,
+// since ReceiveFA can not be told apart by reqID.
+var ErrFARequestPending = errors.New("another request of the same fa data is pending")
+
+// FaGroups is the xml of FA_GROUPS
+type FaGroups struct {
+	XMLName xml.Name  `xml:"ListOfGroups"`
+	Groups  []FaGroup `xml:"Group"`
+}
+
+// FaGroup is a group of accounts sharing the allocation method
+type FaGroup struct {
+	Name          string
+	Accounts      []string
+	DefaultMethod string // FA_METHOD_*
+}
+
+type faStrings struct {
+	VarName string   `xml:"varName,attr"`
+	Strings []string `xml:"String"`
+}
+
+type faGroupXML struct {
+	Name          string    `xml:"name"`
+	ListOfAccts   faStrings `xml:"ListOfAccts"`
+	DefaultMethod string    `xml:"defaultMethod"`
+}
+
+func (g FaGroup) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(faGroupXML{
+		Name:          g.Name,
+		ListOfAccts:   faStrings{VarName: "list", Strings: g.Accounts},
+		DefaultMethod: g.DefaultMethod,
+	}, start)
+}
+
+func (g *FaGroup) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var gx faGroupXML
+	if err := d.DecodeElement(&gx, &start); err != nil {
+		return err
+	}
+
+	g.Name = strings.TrimSpace(gx.Name)
+	g.DefaultMethod = strings.TrimSpace(gx.DefaultMethod)
+	g.Accounts = g.Accounts[:0]
+	for _, acct := range gx.ListOfAccts.Strings {
+		g.Accounts = append(g.Accounts, strings.TrimSpace(acct))
+	}
+	return nil
+}
+
+// FaProfiles is the xml of FA_PROFILES
+type FaProfiles struct {
+	XMLName  xml.Name    `xml:"ListOfAllocationProfiles"`
+	Profiles []FaProfile `xml:"AllocationProfile"`
+}
+
+// FaProfile is an allocation profile, the meaning of FaAllocation.Amount depends on its Type
+type FaProfile struct {
+	Name        string
+	Type        FaProfileType // FA_PROFILE_*
+	Allocations []FaAllocation
+}
+
+// FaAllocation is the allocation of an account in FaProfile
+type FaAllocation struct {
+	Account string  `xml:"acct"`
+	Amount  float64 `xml:"amount"`
+	PosEff  string  `xml:"posEff,omitempty"` // O->open only, C->close only
+}
+
+type faAllocations struct {
+	VarName     string         `xml:"varName,attr"`
+	Allocations []FaAllocation `xml:"Allocation"`
+}
+
+type faProfileXML struct {
+	Name              string        `xml:"name"`
+	Type              FaProfileType `xml:"type"`
+	ListOfAllocations faAllocations `xml:"ListOfAllocations"`
+}
+
+func (p FaProfile) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(faProfileXML{
+		Name:              p.Name,
+		Type:              p.Type,
+		ListOfAllocations: faAllocations{VarName: "listOfAllocations", Allocations: p.Allocations},
+	}, start)
+}
+
+func (p *FaProfile) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var px faProfileXML
+	if err := d.DecodeElement(&px, &start); err != nil {
+		return err
+	}
+
+	p.Name = strings.TrimSpace(px.Name)
+	p.Type = px.Type
+	p.Allocations = px.ListOfAllocations.Allocations
+	for i := range p.Allocations {
+		p.Allocations[i].Account = strings.TrimSpace(p.Allocations[i].Account)
+		p.Allocations[i].PosEff = strings.TrimSpace(p.Allocations[i].PosEff)
+	}
+	return nil
+}
+
+// FaAliases is the xml of FA_ALIASES
+type FaAliases struct {
+	XMLName xml.Name  `xml:"ListOfAccountAliases"`
+	Aliases []FaAlias `xml:"AccountAlias"`
+}
+
+// FaAlias is the alias of an account
+type FaAlias struct {
+	Account string `xml:"account"`
+	Alias   string `xml:"alias"`
+}
+
+// MarshalFA encodes FaGroups, FaProfiles or FaAliases to the cxml of ReplaceFA
+func MarshalFA(v interface{}) (string, error) {
+	b, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return xml.Header + string(b), nil
+}
+
+// UnmarshalFA decodes the cxml of ReceiveFA to FaGroups, FaProfiles or FaAliases
+func UnmarshalFA(cxml string, v interface{}) error {
+	return xml.Unmarshal([]byte(cxml), v)
+}
+
+/*
+   #########################################################################
+   ################## Typed FA requests
+   #########################################################################
+*/
+
+// RequestFAGroups requests the FA groups and waits for ReceiveFA
+func (ic *IbClient) RequestFAGroups(ctx context.Context) (*FaGroups, error) {
+	groups := &FaGroups{}
+	if err := ic.requestFA(ctx, FA_GROUPS, groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// RequestFAProfiles requests the FA allocation profiles and waits for ReceiveFA
+func (ic *IbClient) RequestFAProfiles(ctx context.Context) (*FaProfiles, error) {
+	profiles := &FaProfiles{}
+	if err := ic.requestFA(ctx, FA_PROFILES, profiles); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// RequestFAAliases requests the FA account aliases and waits for ReceiveFA
+func (ic *IbClient) RequestFAAliases(ctx context.Context) (*FaAliases, error) {
+	aliases := &FaAliases{}
+	if err := ic.requestFA(ctx, FA_ALIASES, aliases); err != nil {
+		return nil, err
+	}
+	return aliases, nil
+}
+
+// ReplaceFAGroups replaces the whole FA groups, see replaceFAConfig
+func (ic *IbClient) ReplaceFAGroups(ctx context.Context, groups *FaGroups) error {
+	return ic.replaceFAConfig(ctx, FA_GROUPS, groups)
+}
+
+// ReplaceFAProfiles replaces the whole FA allocation profiles, see replaceFAConfig
+func (ic *IbClient) ReplaceFAProfiles(ctx context.Context, profiles *FaProfiles) error {
+	return ic.replaceFAConfig(ctx, FA_PROFILES, profiles)
+}
+
+// ReplaceFAAliases replaces the whole FA account aliases, see replaceFAConfig
+func (ic *IbClient) ReplaceFAAliases(ctx context.Context, aliases *FaAliases) error {
+	return ic.replaceFAConfig(ctx, FA_ALIASES, aliases)
+}
+
+// requestFA sends RequestFA and decodes the cxml of ReceiveFA into v.
+/*
+The ReceiveFA of the request is not delivered to the wrapper.
+Since ReceiveFA carries no reqID, only one typed request of each faData could be waiting at a time,
+otherwise ErrFARequestPending is returned.
+The errors of RequestFA are reported with NO_VALID_ID, so such an error of faErrorCodes fails the request,
+such as 321 for the account which is not FA.
+*/
+func (ic *IbClient) requestFA(ctx context.Context, faData FaDataType, v interface{}) error {
+	if !ic.IsConnected() {
+		return NOT_CONNECTED
+	}
+
+	mb := newMailbox()
+	if !ic.dispatcher.registerFA(int64(faData), mb.put) {
+		return ErrFARequestPending
+	}
+	defer ic.dispatcher.unregisterFA(int64(faData))
+
+	o := &faErrorObserver{mb.put}
+	ic.dispatcher.addObserver(o)
+	defer ic.dispatcher.removeObserver(o)
+
+	ic.RequestFA(faData)
+
+	for {
+		select {
+		case <-mb.notify:
+			for _, msg := range mb.take() {
+				switch m := msg.(type) {
+				case *receiveFAMsg:
+					return UnmarshalFA(m.cxml, v)
+				case *errorMsg:
+					return m.err()
+				}
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// faErrorCodes are the codes of Error reported with NO_VALID_ID, which fail the pending requestFA
+var faErrorCodes = map[int64]bool{
+	321:                true, // validating the request, such as FA data operations ignored for non FA customers
+	UPDATE_TWS.code:    true,
+	NOT_CONNECTED.code: true,
+}
+
+// faErrorObserver puts the errors of faErrorCodes into the mailbox of requestFA
+type faErrorObserver struct {
+	put reqHandler
+}
+
+func (o *faErrorObserver) Error(reqID int64, errCode int64, errString string) {
+	if reqID == NO_VALID_ID && faErrorCodes[errCode] {
+		o.put(&errorMsg{errCode, errString})
+	}
+}
+
+// replaceFAConfig sends ReplaceFA with the cxml of v and waits for its confirmation.
+/*
+From mMIN_SERVER_VER_REPLACE_FA_END it waits for ReplaceFAEnd, or the error reported with its reqID.
+Older TWS never confirms ReplaceFA, so the configuration is requested again after replacing,
+which returns once TWS has processed the ReplaceFA before it.
+*/
+func (ic *IbClient) replaceFAConfig(ctx context.Context, faData FaDataType, v interface{}) error {
+	if !ic.IsConnected() {
+		return NOT_CONNECTED
+	}
+
+	cxml, err := MarshalFA(v)
+	if err != nil {
+		return err
+	}
+
+	if ic.serverVersion < mMIN_SERVER_VER_REPLACE_FA_END {
+		ic.ReplaceFA(faData, cxml)
+		var discard interface{}
+		switch faData {
+		case FA_GROUPS:
+			discard = &FaGroups{}
+		case FA_PROFILES:
+			discard = &FaProfiles{}
+		default:
+			discard = &FaAliases{}
+		}
+		return ic.requestFA(ctx, faData, discard)
+	}
+
+	reqID := ic.GetReqID()
+	return ic.request(ctx, reqID, func() { ic.replaceFA(reqID, faData, cxml) }, func(msg interface{}) bool {
+		if m, ok := msg.(*replaceFAEndMsg); ok {
+			log.Info("replace fa done", zap.Int64("reqID", reqID), zap.Int("faData", faData), zap.String("text", m.text))
+			return true
+		}
+		return false
+	})
+}
@@ -0,0 +1,119 @@
+package ibapi
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testFaGroupsXML = `<?xml version="1.0" encoding="UTF-8"?>
+<ListOfGroups>
+	<Group>
+		<name>Equal</name>
+		<ListOfAccts varName="list">
+			<String>DU001</String>
+			<String>DU002</String>
+		</ListOfAccts>
+		<defaultMethod>EqualQuantity</defaultMethod>
+	</Group>
+	<Group>
+		<name>NetLiq</name>
+		<ListOfAccts varName="list">
+			<String>DU003</String>
+		</ListOfAccts>
+		<defaultMethod>NetLiq</defaultMethod>
+	</Group>
+</ListOfGroups>`
+
+func TestFaXMLRoundTrip(t *testing.T) {
+	groups := FaGroups{}
+	if err := UnmarshalFA(testFaGroupsXML, &groups); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []FaGroup{
+		{Name: "Equal", Accounts: []string{"DU001", "DU002"}, DefaultMethod: FA_METHOD_EQUAL_QUANTITY},
+		{Name: "NetLiq", Accounts: []string{"DU003"}, DefaultMethod: FA_METHOD_NET_LIQ},
+	}
+	if !reflect.DeepEqual(groups.Groups, expected) {
+		t.Fatalf("unexpected groups: %+v", groups.Groups)
+	}
+
+	profiles := FaProfiles{Profiles: []FaProfile{{
+		Name: "Pct",
+		Type: FA_PROFILE_PERCENTAGES,
+		Allocations: []FaAllocation{
+			{Account: "DU001", Amount: 60},
+			{Account: "DU002", Amount: 40, PosEff: "O"},
+		},
+	}}}
+	aliases := FaAliases{Aliases: []FaAlias{{Account: "DU001", Alias: "alice"}}}
+
+	for _, v := range []interface{}{&groups, &profiles, &aliases} {
+		cxml, err := MarshalFA(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+		if err := UnmarshalFA(cxml, decoded); err != nil {
+			t.Fatal(err)
+		}
+
+		reflect.ValueOf(decoded).Elem().FieldByName("XMLName").Set(reflect.ValueOf(v).Elem().FieldByName("XMLName"))
+		if !reflect.DeepEqual(decoded, v) {
+			t.Errorf("round trip mismatch:\n%s\n%+v", cxml, decoded)
+		}
+	}
+}
+
+func TestRequestAndReplaceFAGroups(t *testing.T) {
+	var replaced string
+	ic := newTestClient(func(ic *IbClient, req []byte) {
+		fields := splitMsgBytes(req[4:])
+		switch fieldInt(fields[0]) {
+		case mREQ_FA:
+			ic.dispatcher.ReceiveFA(fieldInt(fields[2]), testFaGroupsXML)
+		case mREPLACE_FA:
+			replaced = decodeString(fields[3])
+			ic.dispatcher.ReplaceFAEnd(fieldInt(fields[4]), "")
+		}
+	})
+	ic.serverVersion = mMIN_SERVER_VER_REPLACE_FA_END
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	groups, err := ic.RequestFAGroups(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groups.Groups[0].DefaultMethod = FA_METHOD_AVAILABLE_EQUITY
+	if err := ic.ReplaceFAGroups(ctx, groups); err != nil {
+		t.Fatal(err)
+	}
+
+	replacedGroups := FaGroups{}
+	if err := UnmarshalFA(replaced, &replacedGroups); err != nil {
+		t.Fatal(err)
+	}
+	if replacedGroups.Groups[0].DefaultMethod != FA_METHOD_AVAILABLE_EQUITY {
+		t.Errorf("unexpected replaced cxml: %s", replaced)
+	}
+}
+
+func TestRequestFANotFA(t *testing.T) {
+	ic := newTestClient(func(ic *IbClient, req []byte) {
+		ic.dispatcher.Error(NO_VALID_ID, 321, "Error validating request:-'bN' : cause - FA data operations ignored for non FA customers.")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err := ic.RequestFAGroups(ctx); !errors.Is(err, IbError{code: 321}) {
+		t.Errorf("expect the error of 321, got %v", err)
+	}
+}
@@ -0,0 +1,708 @@
+/*
+fundamental contains the typed reports of ReqFundamentalData parsed from the XML,
+and the helpers to request and parse them in one call.
+*/
+
+package ibapi
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// the reportType of ReqFundamentalData
+const (
+	REPORT_SNAPSHOT          = "ReportSnapshot"
+	REPORT_FIN_SUMMARY       = "ReportsFinSummary"
+	REPORT_FIN_STATEMENTS    = "ReportsFinStatements"
+	REPORT_ANALYST_ESTIMATES = "RESC"
+	REPORT_OWNERSHIP         = "ReportsOwnership"
+	REPORT_RATIOS            = "ReportRatios"
+	REPORT_CALENDAR          = "CalendarReport"
+)
+
+// the Type of FinancialStatement
+const (
+	STATEMENT_INCOME        = "INC"
+	STATEMENT_BALANCE_SHEET = "BAL"
+	STATEMENT_CASH_FLOW     = "CAS"
+)
+
+// the periodType and the stats of AnalystEstimates
+const (
+	ESTIMATE_PERIOD_ANNUAL    = "A"
+	ESTIMATE_PERIOD_QUARTER   = "Q"
+	ESTIMATE_DATE_CURRENT     = "CURR"
+	CONSENSUS_MEAN            = "Mean"
+	CONSENSUS_MEDIAN          = "Median"
+	CONSENSUS_HIGH            = "High"
+	CONSENSUS_LOW             = "Low"
+	CONSENSUS_STD_DEV         = "StdDev"
+	CONSENSUS_NUM_OF_ESTIMATE = "NumOfEst"
+)
+
+/*
+   #########################################################################
+   ################## Common
+   #########################################################################
+*/
+
+// FundamentalCode is an element with a Code attribute, such as <CoStatus Code="1">Active</CoStatus>
+type FundamentalCode struct {
+	Code string `xml:"Code,attr"`
+	Name string `xml:",chardata"`
+}
+
+// FundamentalID is an element with a Type attribute, such as <CoID Type="RepNo">05680</CoID>
+type FundamentalID struct {
+	Type  string `xml:"Type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// FundamentalIDs is the ids of the company or the issue
+type FundamentalIDs []FundamentalID
+
+// Get returns the id of typ, such as "CompanyName", "RepNo", "Ticker" or "ISIN"
+func (ids FundamentalIDs) Get(typ string) string {
+	for _, id := range ids {
+		if id.Type == typ {
+			return strings.TrimSpace(id.Value)
+		}
+	}
+	return ""
+}
+
+// FundamentalIssue is an issue of the company, such as the common stock
+type FundamentalIssue struct {
+	ID       string         `xml:"ID,attr"`
+	Type     string         `xml:"Type,attr"`
+	Desc     string         `xml:"Desc,attr"`
+	Order    int            `xml:"Order,attr"`
+	IDs      FundamentalIDs `xml:"IssueID"`
+	Exchange struct {
+		Code    string `xml:"Code,attr"`
+		Country string `xml:"Country,attr"`
+		Name    string `xml:",chardata"`
+	} `xml:"Exchange"`
+	MostRecentSplit struct {
+		Date  string  `xml:"Date,attr"`
+		Ratio float64 `xml:",chardata"`
+	} `xml:"MostRecentSplit"`
+}
+
+// FundamentalGeneralInfo is the CoGeneralInfo of the reports
+type FundamentalGeneralInfo struct {
+	CoStatus               FundamentalCode `xml:"CoStatus"`
+	CoType                 FundamentalCode `xml:"CoType"`
+	LastModified           string          `xml:"LastModified"`
+	LatestAvailableAnnual  string          `xml:"LatestAvailableAnnual"`
+	LatestAvailableInterim string          `xml:"LatestAvailableInterim"`
+	Employees              struct {
+		LastUpdated string `xml:"LastUpdated,attr"`
+		Value       int64  `xml:",chardata"`
+	} `xml:"Employees"`
+	SharesOut struct {
+		Date       string  `xml:"Date,attr"`
+		TotalFloat float64 `xml:"TotalFloat,attr"`
+		Value      float64 `xml:",chardata"`
+	} `xml:"SharesOut"`
+	ReportingCurrency  FundamentalCode `xml:"ReportingCurrency"`
+	MostRecentExchange struct {
+		Date  string  `xml:"Date,attr"`
+		Value float64 `xml:",chardata"`
+	} `xml:"MostRecentExchange"`
+}
+
+/*
+   #########################################################################
+   ################## ReportsFinSummary
+   #########################################################################
+*/
+
+// FinancialSummary is the report of ReportsFinSummary
+type FinancialSummary struct {
+	XMLName           xml.Name             `xml:"FinancialSummary"`
+	TotalRevenues     FinSummarySeries     `xml:"TotalRevenues"`
+	DividendPerShares FinSummarySeries     `xml:"DividendPerShares"`
+	EPSs              FinSummarySeries     `xml:"EPSs"`
+	Dividends         []FinSummaryDividend `xml:"Dividends>Dividend"`
+}
+
+// FinSummarySeries is a series of FinancialSummary, such as TotalRevenues
+type FinSummarySeries struct {
+	Currency string           `xml:"currency,attr"`
+	Items    []FinSummaryItem `xml:",any"`
+}
+
+// FinSummaryItem is an item of FinSummarySeries.
+// ReportType is A (actual), P (preliminary) or R (restated) etc, Period is 3M, 12M or TTM.
+type FinSummaryItem struct {
+	AsOfDate   string  `xml:"asofDate,attr"`
+	ReportType string  `xml:"reportType,attr"`
+	Period     string  `xml:"period,attr"`
+	Value      float64 `xml:",chardata"`
+}
+
+// FinSummaryDividend is a dividend of FinancialSummary
+type FinSummaryDividend struct {
+	Type            string  `xml:"type,attr"`
+	ExDate          string  `xml:"exDate,attr"`
+	RecordDate      string  `xml:"recordDate,attr"`
+	PayDate         string  `xml:"payDate,attr"`
+	DeclarationDate string  `xml:"declarationDate,attr"`
+	Value           float64 `xml:",chardata"`
+}
+
+// ByPeriod returns the items of period, such as 3M, 12M or TTM
+func (s FinSummarySeries) ByPeriod(period string) []FinSummaryItem {
+	var items []FinSummaryItem
+	for _, item := range s.Items {
+		if item.Period == period {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ParseFinancialSummary parses the report of ReportsFinSummary
+func ParseFinancialSummary(data string) (*FinancialSummary, error) {
+	fs := &FinancialSummary{}
+	if err := xml.Unmarshal([]byte(data), fs); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", REPORT_FIN_SUMMARY, err)
+	}
+	return fs, nil
+}
+
+/*
+   #########################################################################
+   ################## ReportSnapshot
+   #########################################################################
+*/
+
+// CompanySnapshot is the report of ReportSnapshot
+type CompanySnapshot struct {
+	XMLName     xml.Name               `xml:"ReportSnapshot"`
+	CoIDs       FundamentalIDs         `xml:"CoIDs>CoID"`
+	Issues      []FundamentalIssue     `xml:"Issues>Issue"`
+	GeneralInfo FundamentalGeneralInfo `xml:"CoGeneralInfo"`
+	Texts       []struct {
+		Type         string `xml:"Type,attr"`
+		LastModified string `xml:"lastModified,attr"`
+		Value        string `xml:",chardata"`
+	} `xml:"TextInfo>Text"`
+	Contact struct {
+		LastUpdated   string   `xml:"lastUpdated,attr"`
+		StreetAddress []string `xml:"streetAddress"`
+		City          string   `xml:"city"`
+		StateRegion   string   `xml:"state-region"`
+		PostalCode    string   `xml:"postalCode"`
+		Country       struct {
+			Code string `xml:"code,attr"`
+			Name string `xml:",chardata"`
+		} `xml:"country"`
+		Phones []struct {
+			Type             string `xml:"type,attr"`
+			CountryPhoneCode string `xml:"countryPhoneCode"`
+			AreaCode         string `xml:"areaCode"`
+			Number           string `xml:"number"`
+		} `xml:"phone>phone"`
+	} `xml:"contactInfo"`
+	WebSite    string               `xml:"webLinks>webSite"`
+	EMail      string               `xml:"webLinks>eMail"`
+	Industries []SnapshotIndustry   `xml:"peerInfo>IndustryInfo>Industry"`
+	Officers   []SnapshotOfficer    `xml:"officers>officer"`
+	Ratios     SnapshotRatios       `xml:"Ratios"`
+	Forecast   SnapshotForecastData `xml:"ForecastData"`
+}
+
+// SnapshotIndustry is an industry classification of the company, Type is TRBC, NAICS or SIC etc
+type SnapshotIndustry struct {
+	Type     string `xml:"type,attr"`
+	Order    int    `xml:"order,attr"`
+	Reported int    `xml:"reported,attr"`
+	Code     string `xml:"code,attr"`
+	Mnem     string `xml:"mnem,attr"`
+	Name     string `xml:",chardata"`
+}
+
+// SnapshotOfficer is an officer of the company
+type SnapshotOfficer struct {
+	Rank      int    `xml:"rank,attr"`
+	Since     string `xml:"since,attr"`
+	FirstName string `xml:"firstName"`
+	MI        string `xml:"mI"`
+	LastName  string `xml:"lastName"`
+	Age       string `xml:"age"`
+	Title     struct {
+		StartYear  string `xml:"startYear,attr"`
+		StartMonth string `xml:"startMonth,attr"`
+		StartDay   string `xml:"startDay,attr"`
+		Value      string `xml:",chardata"`
+	} `xml:"title"`
+}
+
+// SnapshotRatio is a ratio of the snapshot, Type is N (number), D (date) or S (string)
+type SnapshotRatio struct {
+	FieldName string `xml:"FieldName,attr"`
+	Type      string `xml:"Type,attr"`
+	Value     string `xml:",chardata"`
+}
+
+// Float returns the Value as float64
+func (r SnapshotRatio) Float() (float64, error) {
+	return strconv.ParseFloat(strings.TrimSpace(r.Value), 64)
+}
+
+// SnapshotRatios is the Ratios of the snapshot grouped by ID, such as "Price and Volume" or "Income Statement"
+type SnapshotRatios struct {
+	PriceCurrency       string `xml:"PriceCurrency,attr"`
+	ReportingCurrency   string `xml:"ReportingCurrency,attr"`
+	ExchangeRate        string `xml:"ExchangeRate,attr"`
+	LatestAvailableDate string `xml:"LatestAvailableDate,attr"`
+	Groups              []struct {
+		ID     string          `xml:"ID,attr"`
+		Ratios []SnapshotRatio `xml:"Ratio"`
+	} `xml:"Group"`
+}
+
+// SnapshotForecastData is the consensus forecast of the snapshot
+type SnapshotForecastData struct {
+	ConsensusType         string `xml:"ConsensusType,attr"`
+	CurFiscalYear         int    `xml:"CurFiscalYear,attr"`
+	CurFiscalYearEndMonth int    `xml:"CurFiscalYearEndMonth,attr"`
+	CurInterimEndCalYear  int    `xml:"CurInterimEndCal_Year,attr"`
+	CurInterimEndMonth    int    `xml:"CurInterimEndMonth,attr"`
+	EarningsBasis         string `xml:"EarningsBasis,attr"`
+	Ratios                []struct {
+		FieldName string `xml:"FieldName,attr"`
+		Type      string `xml:"Type,attr"`
+		Values    []struct {
+			PeriodType string `xml:"PeriodType,attr"`
+			Value      string `xml:",chardata"`
+		} `xml:"Value"`
+	} `xml:"Ratio"`
+}
+
+// CompanyName returns the name of the company
+func (s *CompanySnapshot) CompanyName() string {
+	return s.CoIDs.Get("CompanyName")
+}
+
+// Text returns the text of typ, such as "Business Summary" or "Financial Summary"
+func (s *CompanySnapshot) Text(typ string) string {
+	for _, t := range s.Texts {
+		if t.Type == typ {
+			return strings.TrimSpace(t.Value)
+		}
+	}
+	return ""
+}
+
+// Ratio returns the ratio of fieldName in any group, such as NPRICE, MKTCAP or PEEXCLXOR
+func (s *CompanySnapshot) Ratio(fieldName string) (SnapshotRatio, bool) {
+	for _, g := range s.Ratios.Groups {
+		for _, r := range g.Ratios {
+			if r.FieldName == fieldName {
+				return r, true
+			}
+		}
+	}
+	return SnapshotRatio{}, false
+}
+
+// ParseCompanySnapshot parses the report of ReportSnapshot
+func ParseCompanySnapshot(data string) (*CompanySnapshot, error) {
+	s := &CompanySnapshot{}
+	if err := xml.Unmarshal([]byte(data), s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", REPORT_SNAPSHOT, err)
+	}
+	return s, nil
+}
+
+/*
+   #########################################################################
+   ################## ReportsFinStatements
+   #########################################################################
+*/
+
+// FinancialStatements is the report of ReportsFinStatements
+type FinancialStatements struct {
+	XMLName       xml.Name               `xml:"ReportFinancialStatements"`
+	CoIDs         FundamentalIDs         `xml:"CoIDs>CoID"`
+	Issues        []FundamentalIssue     `xml:"Issues>Issue"`
+	GeneralInfo   FundamentalGeneralInfo `xml:"CoGeneralInfo"`
+	StatementInfo struct {
+		COAType             FundamentalCode `xml:"COAType"`
+		BalanceSheetDisplay FundamentalCode `xml:"BalanceSheetDisplay"`
+		CashFlowMethod      FundamentalCode `xml:"CashFlowMethod"`
+	} `xml:"StatementInfo"`
+	COAMap         []COAMapItem   `xml:"FinancialStatements>COAMap>mapItem"`
+	AnnualPeriods  []FiscalPeriod `xml:"FinancialStatements>AnnualPeriods>FiscalPeriod"`
+	InterimPeriods []FiscalPeriod `xml:"FinancialStatements>InterimPeriods>FiscalPeriod"`
+}
+
+// COAMapItem maps the coaCode of the line items to its name, such as SREV to Revenue
+type COAMapItem struct {
+	COAItem       string `xml:"coaItem,attr"`
+	StatementType string `xml:"statementType,attr"`
+	LineID        int    `xml:"lineID,attr"`
+	Precision     int    `xml:"precision,attr"`
+	Name          string `xml:",chardata"`
+}
+
+// FiscalPeriod is the statements of a fiscal period, FiscalPeriodNumber is only for the interim periods
+type FiscalPeriod struct {
+	Type               string               `xml:"Type,attr"`
+	EndDate            string               `xml:"EndDate,attr"`
+	FiscalYear         int                  `xml:"FiscalYear,attr"`
+	FiscalPeriodNumber int                  `xml:"FiscalPeriodNumber,attr"`
+	Statements         []FinancialStatement `xml:"Statement"`
+}
+
+// FinancialStatement is a statement of FiscalPeriod, Type is INC, BAL or CAS
+type FinancialStatement struct {
+	Type   string `xml:"Type,attr"`
+	Header struct {
+		PeriodLength   int             `xml:"PeriodLength"`
+		PeriodType     FundamentalCode `xml:"periodType"`
+		UpdateType     FundamentalCode `xml:"UpdateType"`
+		StatementDate  string          `xml:"StatementDate"`
+		AuditorName    FundamentalCode `xml:"AuditorName"`
+		AuditorOpinion FundamentalCode `xml:"AuditorOpinion"`
+		Source         struct {
+			Date  string `xml:"Date,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"Source"`
+	} `xml:"FPHeader"`
+	LineItems []struct {
+		COACode string  `xml:"coaCode,attr"`
+		Value   float64 `xml:",chardata"`
+	} `xml:"lineItem"`
+}
+
+// Statement returns the statement of typ, INC, BAL or CAS
+func (fp FiscalPeriod) Statement(typ string) (FinancialStatement, bool) {
+	for _, s := range fp.Statements {
+		if s.Type == typ {
+			return s, true
+		}
+	}
+	return FinancialStatement{}, false
+}
+
+// Value returns the value of the line item of coaCode
+func (s FinancialStatement) Value(coaCode string) (float64, bool) {
+	for _, item := range s.LineItems {
+		if item.COACode == coaCode {
+			return item.Value, true
+		}
+	}
+	return 0, false
+}
+
+// FinancialValue is a value of a line item in a fiscal period
+type FinancialValue struct {
+	EndDate            string
+	FiscalYear         int
+	FiscalPeriodNumber int
+	Value              float64
+}
+
+// LineName returns the name of coaCode in the COAMap
+func (fs *FinancialStatements) LineName(coaCode string) string {
+	for _, item := range fs.COAMap {
+		if item.COAItem == coaCode {
+			return strings.TrimSpace(item.Name)
+		}
+	}
+	return ""
+}
+
+// Periods returns the AnnualPeriods if annual, otherwise the InterimPeriods
+func (fs *FinancialStatements) Periods(annual bool) []FiscalPeriod {
+	if annual {
+		return fs.AnnualPeriods
+	}
+	return fs.InterimPeriods
+}
+
+// Series returns the values of coaCode in the statement of typ by period, in the order of the report, which is the latest first
+func (fs *FinancialStatements) Series(annual bool, typ string, coaCode string) []FinancialValue {
+	var values []FinancialValue
+	for _, fp := range fs.Periods(annual) {
+		s, ok := fp.Statement(typ)
+		if !ok {
+			continue
+		}
+		if v, ok := s.Value(coaCode); ok {
+			values = append(values, FinancialValue{fp.EndDate, fp.FiscalYear, fp.FiscalPeriodNumber, v})
+		}
+	}
+	return values
+}
+
+// ParseFinancialStatements parses the report of ReportsFinStatements
+func ParseFinancialStatements(data string) (*FinancialStatements, error) {
+	fs := &FinancialStatements{}
+	if err := xml.Unmarshal([]byte(data), fs); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", REPORT_FIN_STATEMENTS, err)
+	}
+	return fs, nil
+}
+
+/*
+   #########################################################################
+   ################## RESC
+   #########################################################################
+*/
+
+// AnalystEstimates is the report of RESC
+type AnalystEstimates struct {
+	XMLName xml.Name       `xml:"REarnEstCons"`
+	CoIDs   FundamentalIDs `xml:"Company>CoIDs>CoID"`
+	SecIDs  []struct {
+		Type  string `xml:"type,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"Company>SecIds>SecId"`
+	Actuals     []EstimateActual    `xml:"Actuals>FYActual"`
+	Estimates   []EstimateFY        `xml:"ConsEstimates>FYEstimate"`
+	NPEstimates []EstimateNonPeriod `xml:"ConsEstimates>NPEstimate"`
+}
+
+// EstimateActual is the actual values of an estimate type, such as EPS or REVENUE
+type EstimateActual struct {
+	Type    string `xml:"type,attr"`
+	Unit    string `xml:"unit,attr"`
+	Periods []struct {
+		PeriodType string `xml:"periodType,attr"`
+		FYear      int    `xml:"fYear,attr"`
+		EndMonth   int    `xml:"endMonth,attr"`
+		FPeriod    int    `xml:"fPeriod,attr"`
+		Values     []struct {
+			Updated string  `xml:"updated,attr"`
+			Value   float64 `xml:",chardata"`
+		} `xml:"ActValue"`
+	} `xml:"FYPeriod"`
+}
+
+// EstimateFY is the consensus of an estimate type by fiscal period
+type EstimateFY struct {
+	Type    string `xml:"type,attr"`
+	Unit    string `xml:"unit,attr"`
+	Periods []struct {
+		PeriodType string              `xml:"periodType,attr"`
+		FYear      int                 `xml:"fYear,attr"`
+		EndMonth   int                 `xml:"endMonth,attr"`
+		FPeriod    int                 `xml:"fPeriod,attr"`
+		Consensus  []EstimateConsensus `xml:"ConsEstimate"`
+	} `xml:"FYPeriod"`
+}
+
+// EstimateNonPeriod is the consensus of an estimate type without period, such as the target price
+type EstimateNonPeriod struct {
+	Type      string              `xml:"type,attr"`
+	Unit      string              `xml:"unit,attr"`
+	Consensus []EstimateConsensus `xml:"ConsEstimate"`
+}
+
+// EstimateConsensus is a statistic of the estimates, such as Mean, Median, High, Low or NumOfEst,
+// the values are keyed by dateType, such as CURR or 1WA (1 week ago)
+type EstimateConsensus struct {
+	Type   string `xml:"type,attr"`
+	Values []struct {
+		DateType string  `xml:"dateType,attr"`
+		Value    float64 `xml:",chardata"`
+	} `xml:"ConsValue"`
+}
+
+// Value returns the value of dateType
+func (ec EstimateConsensus) Value(dateType string) (float64, bool) {
+	for _, v := range ec.Values {
+		if v.DateType == dateType {
+			return v.Value, true
+		}
+	}
+	return 0, false
+}
+
+func consensusValue(consensus []EstimateConsensus, stat string) (float64, bool) {
+	for _, c := range consensus {
+		if c.Type == stat {
+			return c.Value(ESTIMATE_DATE_CURRENT)
+		}
+	}
+	return 0, false
+}
+
+// Consensus returns the current consensus stat of the estimate typ in the fiscal year, periodType is A or Q
+func (ae *AnalystEstimates) Consensus(typ string, periodType string, fYear int, fPeriod int, stat string) (float64, bool) {
+	for _, e := range ae.Estimates {
+		if e.Type != typ {
+			continue
+		}
+		for _, p := range e.Periods {
+			if p.PeriodType == periodType && p.FYear == fYear && p.FPeriod == fPeriod {
+				return consensusValue(p.Consensus, stat)
+			}
+		}
+	}
+	return 0, false
+}
+
+// NonPeriodConsensus returns the current consensus stat of the estimate typ without period, such as TargetPrice
+func (ae *AnalystEstimates) NonPeriodConsensus(typ string, stat string) (float64, bool) {
+	for _, e := range ae.NPEstimates {
+		if e.Type == typ {
+			return consensusValue(e.Consensus, stat)
+		}
+	}
+	return 0, false
+}
+
+// ParseAnalystEstimates parses the report of RESC
+func ParseAnalystEstimates(data string) (*AnalystEstimates, error) {
+	ae := &AnalystEstimates{}
+	if err := xml.Unmarshal([]byte(data), ae); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", REPORT_ANALYST_ESTIMATES, err)
+	}
+	return ae, nil
+}
+
+/*
+   #########################################################################
+   ################## ReportsOwnership
+   #########################################################################
+*/
+
+// Ownership is the report of ReportsOwnership
+type Ownership struct {
+	ISIN        string `xml:"ISIN"`
+	FloatShares struct {
+		AsOfDate string  `xml:"asofDate,attr"`
+		Value    float64 `xml:",chardata"`
+	} `xml:"floatShares"`
+	Owners []OwnershipHolder `xml:"Owner"`
+}
+
+// OwnershipHolder is a holder of Ownership
+type OwnershipHolder struct {
+	OwnerID  string  `xml:"ownerId,attr"`
+	Type     string  `xml:"type"`
+	Name     string  `xml:"name"`
+	Quantity float64 `xml:"quantity"`
+	Currency string  `xml:"currency"`
+	Time     string  `xml:"time"`
+}
+
+// ParseOwnership parses the report of ReportsOwnership
+func ParseOwnership(data string) (*Ownership, error) {
+	o := &Ownership{}
+	if err := xml.Unmarshal([]byte(data), o); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", REPORT_OWNERSHIP, err)
+	}
+	return o, nil
+}
+
+/*
+   #########################################################################
+   ################## Request
+   #########################################################################
+*/
+
+// ParseFundamentalData parses data of reportType into *FinancialSummary, *CompanySnapshot, *FinancialStatements,
+// *AnalystEstimates or *Ownership.
+func ParseFundamentalData(reportType string, data string) (interface{}, error) {
+	switch reportType {
+	case REPORT_FIN_SUMMARY:
+		return ParseFinancialSummary(data)
+	case REPORT_SNAPSHOT:
+		return ParseCompanySnapshot(data)
+	case REPORT_FIN_STATEMENTS:
+		return ParseFinancialStatements(data)
+	case REPORT_ANALYST_ESTIMATES:
+		return ParseAnalystEstimates(data)
+	case REPORT_OWNERSHIP:
+		return ParseOwnership(data)
+	}
+	return nil, fmt.Errorf("unsupported report type %s", reportType)
+}
+
+// FetchFundamentalData requests the fundamental data of reportType and waits for the XML
+func (ic *IbClient) FetchFundamentalData(ctx context.Context, contract *Contract, reportType string) (string, error) {
+	if !ic.IsConnected() {
+		return "", NOT_CONNECTED
+	}
+
+	reqID := ic.GetReqID()
+	var data string
+	err := ic.request(ctx, reqID, func() { ic.ReqFundamentalData(reqID, contract, reportType, nil) }, func(msg interface{}) bool {
+		if m, ok := msg.(*fundamentalDataMsg); ok {
+			data = m.data
+			return true
+		}
+		return false
+	})
+
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		ic.CancelFundamentalData(reqID)
+	}
+
+	return data, err
+}
+
+// FetchFundamentalReport requests the fundamental data of reportType and parses it by ParseFundamentalData
+func (ic *IbClient) FetchFundamentalReport(ctx context.Context, contract *Contract, reportType string) (interface{}, error) {
+	data, err := ic.FetchFundamentalData(ctx, contract, reportType)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFundamentalData(reportType, data)
+}
+
+// FetchFinancialSummary requests and parses the report of ReportsFinSummary
+func (ic *IbClient) FetchFinancialSummary(ctx context.Context, contract *Contract) (*FinancialSummary, error) {
+	data, err := ic.FetchFundamentalData(ctx, contract, REPORT_FIN_SUMMARY)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFinancialSummary(data)
+}
+
+// FetchCompanySnapshot requests and parses the report of ReportSnapshot
+func (ic *IbClient) FetchCompanySnapshot(ctx context.Context, contract *Contract) (*CompanySnapshot, error) {
+	data, err := ic.FetchFundamentalData(ctx, contract, REPORT_SNAPSHOT)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCompanySnapshot(data)
+}
+
+// FetchFinancialStatements requests and parses the report of ReportsFinStatements
+func (ic *IbClient) FetchFinancialStatements(ctx context.Context, contract *Contract) (*FinancialStatements, error) {
+	data, err := ic.FetchFundamentalData(ctx, contract, REPORT_FIN_STATEMENTS)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFinancialStatements(data)
+}
+
+// FetchAnalystEstimates requests and parses the report of RESC
+func (ic *IbClient) FetchAnalystEstimates(ctx context.Context, contract *Contract) (*AnalystEstimates, error) {
+	data, err := ic.FetchFundamentalData(ctx, contract, REPORT_ANALYST_ESTIMATES)
+	if err != nil {
+		return nil, err
+	}
+	return ParseAnalystEstimates(data)
+}
+
+// FetchOwnership requests and parses the report of ReportsOwnership
+func (ic *IbClient) FetchOwnership(ctx context.Context, contract *Contract) (*Ownership, error) {
+	data, err := ic.FetchFundamentalData(ctx, contract, REPORT_OWNERSHIP)
+	if err != nil {
+		return nil, err
+	}
+	return ParseOwnership(data)
+}
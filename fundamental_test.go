@@ -0,0 +1,180 @@
+package ibapi
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func readFundamentalFixture(t *testing.T, reportType string) string {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "fundamental", reportType+".xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestParseFinancialSummary(t *testing.T) {
+	fs, err := ParseFinancialSummary(readFundamentalFixture(t, REPORT_FIN_SUMMARY))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fs.TotalRevenues.Currency != "USD" || len(fs.TotalRevenues.Items) != 3 {
+		t.Fatalf("unexpected revenues: %+v", fs.TotalRevenues)
+	}
+	quarters := fs.EPSs.ByPeriod("3M")
+	if len(quarters) != 2 || quarters[0].AsOfDate != "2020-06-30" || quarters[0].Value != 0.645 {
+		t.Errorf("unexpected quarterly EPS: %+v", quarters)
+	}
+	if len(fs.Dividends) != 2 || fs.Dividends[0].ExDate != "2020-08-07" || fs.Dividends[0].Value != 0.82 {
+		t.Errorf("unexpected dividends: %+v", fs.Dividends)
+	}
+}
+
+func TestParseCompanySnapshot(t *testing.T) {
+	s, err := ParseCompanySnapshot(readFundamentalFixture(t, REPORT_SNAPSHOT))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.CompanyName() != "Apple Inc." || s.Issues[0].IDs.Get("Ticker") != "AAPL" || s.Issues[0].MostRecentSplit.Ratio != 4 {
+		t.Errorf("unexpected ids: %+v, %+v", s.CoIDs, s.Issues)
+	}
+	if s.GeneralInfo.Employees.Value != 137000 || s.GeneralInfo.SharesOut.TotalFloat != 4270470000 {
+		t.Errorf("unexpected general info: %+v", s.GeneralInfo)
+	}
+	if s.Text("Business Summary") == "" || s.Contact.City != "CUPERTINO" || s.Contact.Phones[0].AreaCode != "408" || s.WebSite != "https://www.apple.com/" {
+		t.Errorf("unexpected company info: %+v", s.Contact)
+	}
+	if len(s.Industries) != 3 || s.Industries[0].Name != "Phones & Smart Phones" {
+		t.Errorf("unexpected industries: %+v", s.Industries)
+	}
+	if len(s.Officers) != 2 || s.Officers[0].LastName != "Cook" {
+		t.Errorf("unexpected officers: %+v", s.Officers)
+	}
+
+	r, ok := s.Ratio("MKTCAP")
+	if !ok {
+		t.Fatal("MKTCAP is not found")
+	}
+	if v, err := r.Float(); err != nil || v != 1900317 {
+		t.Errorf("unexpected MKTCAP %v, %v", v, err)
+	}
+	if len(s.Forecast.Ratios) != 2 || s.Forecast.CurFiscalYear != 2020 || s.Forecast.Ratios[1].Values[0].Value != "408.78730" {
+		t.Errorf("unexpected forecast: %+v", s.Forecast)
+	}
+}
+
+func TestParseFinancialStatements(t *testing.T) {
+	fs, err := ParseFinancialStatements(readFundamentalFixture(t, REPORT_FIN_STATEMENTS))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fs.LineName("SREV") != "Revenue" || fs.StatementInfo.COAType.Code != "INDU" {
+		t.Errorf("unexpected statement info: %+v", fs.StatementInfo)
+	}
+
+	revenues := fs.Series(true, STATEMENT_INCOME, "SREV")
+	if len(revenues) != 2 || revenues[0].FiscalYear != 2019 || revenues[0].Value != 260174 || revenues[1].Value != 265595 {
+		t.Errorf("unexpected annual revenues: %+v", revenues)
+	}
+	if assets := fs.Series(true, STATEMENT_BALANCE_SHEET, "ATOT"); len(assets) != 1 || assets[0].Value != 338516 {
+		t.Errorf("unexpected total assets: %+v", assets)
+	}
+
+	interim := fs.Periods(false)
+	if len(interim) != 1 || interim[0].FiscalPeriodNumber != 3 {
+		t.Fatalf("unexpected interim periods: %+v", interim)
+	}
+	inc, ok := interim[0].Statement(STATEMENT_INCOME)
+	if !ok || inc.Header.PeriodLength != 13 || inc.Header.Source.Value != "10-Q" {
+		t.Errorf("unexpected interim income statement: %+v", inc)
+	}
+	if v, ok := inc.Value("NINC"); !ok || v != 11253 {
+		t.Errorf("unexpected net income %v", v)
+	}
+}
+
+func TestParseAnalystEstimates(t *testing.T) {
+	ae, err := ParseAnalystEstimates(readFundamentalFixture(t, REPORT_ANALYST_ESTIMATES))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ae.CoIDs.Get("CompanyName") != "Apple Inc." || len(ae.SecIDs) != 2 {
+		t.Errorf("unexpected company: %+v", ae.CoIDs)
+	}
+	if len(ae.Actuals) != 1 || ae.Actuals[0].Periods[0].Values[0].Value != 11.89 {
+		t.Errorf("unexpected actuals: %+v", ae.Actuals)
+	}
+	if v, ok := ae.Consensus("EPS", ESTIMATE_PERIOD_ANNUAL, 2020, 0, CONSENSUS_MEAN); !ok || v != 12.86871 {
+		t.Errorf("unexpected annual EPS mean %v", v)
+	}
+	if v, ok := ae.Consensus("EPS", ESTIMATE_PERIOD_QUARTER, 2020, 4, CONSENSUS_MEAN); !ok || v != 2.79 {
+		t.Errorf("unexpected quarterly EPS mean %v", v)
+	}
+	if v, ok := ae.Consensus("EPS", ESTIMATE_PERIOD_ANNUAL, 2020, 0, CONSENSUS_NUM_OF_ESTIMATE); !ok || v != 39 {
+		t.Errorf("unexpected number of estimates %v", v)
+	}
+	if v, ok := ae.NonPeriodConsensus("TargetPrice", CONSENSUS_HIGH); !ok || v != 530 {
+		t.Errorf("unexpected target price %v", v)
+	}
+}
+
+func TestParseOwnership(t *testing.T) {
+	o, err := ParseOwnership(readFundamentalFixture(t, REPORT_OWNERSHIP))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if o.ISIN != "US0378331005" || o.FloatShares.Value != 4270470000 {
+		t.Errorf("unexpected ownership: %+v", o)
+	}
+	if len(o.Owners) != 2 || o.Owners[0].OwnerID != "1263000" || o.Owners[0].Quantity != 335283446 {
+		t.Errorf("unexpected owners: %+v", o.Owners)
+	}
+}
+
+func TestFetchFundamentalReport(t *testing.T) {
+	ic := newTestClient(func(ic *IbClient, req []byte) {
+		fields := splitMsgBytes(req[4:])
+		if fieldInt(fields[0]) != mREQ_FUNDAMENTAL_DATA {
+			return
+		}
+		reqID := fieldInt(fields[2])
+		reportType := decodeString(fields[10])
+		if reportType == REPORT_OWNERSHIP {
+			ic.dispatcher.Error(reqID, 430, "We are sorry, but fundamentals data for the security specified is not available.")
+			return
+		}
+		ic.dispatcher.FundamentalData(reqID, readFundamentalFixture(t, reportType))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	aapl := &Contract{Symbol: "AAPL", SecurityType: "STK", Exchange: "SMART", Currency: "USD"}
+
+	fs, err := ic.FetchFinancialStatements(ctx, aapl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fs.CoIDs.Get("CompanyName") != "Apple Inc." {
+		t.Errorf("unexpected statements: %+v", fs.CoIDs)
+	}
+
+	report, err := ic.FetchFundamentalReport(ctx, aapl, REPORT_ANALYST_ESTIMATES)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := report.(*AnalystEstimates); !ok {
+		t.Errorf("unexpected report %T", report)
+	}
+
+	if _, err := ic.FetchOwnership(ctx, aapl); err == nil {
+		t.Error("the error of the request should be returned")
+	}
+}
@@ -0,0 +1,323 @@
+/*
+futures contains FutureChain, the expiry chain of a future, the rules to pick the active contract of it,
+and StitchContinuous to build the continuous series from the bars of the contracts.
+*/
+
+package ibapi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// FutureContract is a contract in FutureChain, Expiry is the last trade time
+type FutureContract struct {
+	Details *ContractDetails
+	Expiry  time.Time
+}
+
+func (fc FutureContract) String() string {
+	return fmt.Sprintf("FutureContract<ConID: %d, LocalSymbol: %s, Expiry: %s>",
+		fc.Details.Contract.ContractID, fc.Details.Contract.LocalSymbol, fc.Expiry.Format("20060102 15:04:05 MST"))
+}
+
+// FutureChain is the contracts of a future sorted by Expiry
+type FutureChain []FutureContract
+
+// futureExpiry returns the last trade time from RealExpirationDate or Contract.Expiry, LastTradeTime and TimezoneID
+func futureExpiry(cd *ContractDetails) (time.Time, error) {
+	loc := time.UTC
+	if cd.TimezoneID != "" {
+		if tz, err := LoadTWSLocation(cd.TimezoneID); err == nil {
+			loc = tz
+		}
+	}
+
+	date := cd.RealExpirationDate
+	if date == "" {
+		date = cd.Contract.Expiry
+	}
+	// the Expiry of ContractDetails could be "yyyymmdd hh:mm zone" on some TWS
+	if fields := strings.Fields(date); len(fields) > 0 {
+		date = fields[0]
+	}
+
+	var day time.Time
+	var err error
+	switch len(date) {
+	case 8:
+		day, err = time.ParseInLocation("20060102", date, loc)
+	case 6:
+		// only the contract month is known, regard the last day of the month as the expiry
+		day, err = time.ParseInLocation("200601", date, loc)
+		day = day.AddDate(0, 1, -1)
+	default:
+		err = fmt.Errorf("invalid expiry %q of %d", date, cd.Contract.ContractID)
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if lastTradeTime, err := time.Parse("15:04:05", cd.LastTradeTime); err == nil {
+		return time.Date(day.Year(), day.Month(), day.Day(), lastTradeTime.Hour(), lastTradeTime.Minute(), lastTradeTime.Second(), 0, loc), nil
+	} else if lastTradeTime, err := time.Parse("15:04", cd.LastTradeTime); err == nil {
+		return time.Date(day.Year(), day.Month(), day.Day(), lastTradeTime.Hour(), lastTradeTime.Minute(), 0, 0, loc), nil
+	}
+
+	return day.AddDate(0, 0, 1).Add(-time.Second), nil
+}
+
+// NewFutureChain sorts the details of a future by the expiry, the ones without a valid expiry are dropped
+func NewFutureChain(details []*ContractDetails) FutureChain {
+	chain := make(FutureChain, 0, len(details))
+	for _, cd := range details {
+		expiry, err := futureExpiry(cd)
+		if err != nil {
+			log.Warn("skip the future without valid expiry", zap.Int64("conID", cd.Contract.ContractID), zap.Error(err))
+			continue
+		}
+		chain = append(chain, FutureContract{cd, expiry})
+	}
+
+	sort.SliceStable(chain, func(i, j int) bool { return chain[i].Expiry.Before(chain[j].Expiry) })
+	return chain
+}
+
+// FetchFutureChain requests the contracts of the future by ReqContractDetails and returns the chain.
+/*
+The SecurityType of future is FUT or CONTFUT, CONTFUT returns the current front contract only.
+Set future.IncludeExpired to get the expired contracts as well, which are needed by the continuous series.
+*/
+func (ic *IbClient) FetchFutureChain(ctx context.Context, future *Contract) (FutureChain, error) {
+	details, err := ic.FetchContractDetails(ctx, future)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFutureChain(details), nil
+}
+
+// Live returns the contracts not expired at t
+func (fc FutureChain) Live(t time.Time) FutureChain {
+	i := sort.Search(len(fc), func(i int) bool { return fc[i].Expiry.After(t) })
+	return fc[i:]
+}
+
+// Front returns the first contract not expired at t
+func (fc FutureChain) Front(t time.Time) (FutureContract, bool) {
+	live := fc.Live(t)
+	if len(live) == 0 {
+		return FutureContract{}, false
+	}
+	return live[0], true
+}
+
+// ActiveByDays returns the active contract at t, which is rolled days before the expiry
+func (fc FutureChain) ActiveByDays(t time.Time, days int) (FutureContract, bool) {
+	for _, c := range fc {
+		if c.Expiry.AddDate(0, 0, -days).After(t) {
+			return c, true
+		}
+	}
+	return FutureContract{}, false
+}
+
+// ActiveByCrossover returns the active contract at t by the volume or open interest of the contracts, keyed by conID.
+// Starting from the front, it is rolled to the next contract once the metric of the next one exceeds the current one.
+func (fc FutureChain) ActiveByCrossover(t time.Time, metrics map[int64]float64) (FutureContract, bool) {
+	live := fc.Live(t)
+	if len(live) == 0 {
+		return FutureContract{}, false
+	}
+
+	i := 0
+	for i+1 < len(live) && metrics[live[i+1].Details.Contract.ContractID] > metrics[live[i].Details.Contract.ContractID] {
+		i++
+	}
+	return live[i], true
+}
+
+// RollDatesByDays returns the roll dates between the adjacent contracts, which are days before the expiry
+func (fc FutureChain) RollDatesByDays(days int) []time.Time {
+	if len(fc) == 0 {
+		return nil
+	}
+
+	rollDates := make([]time.Time, 0, len(fc)-1)
+	for _, c := range fc[:len(fc)-1] {
+		rollDates = append(rollDates, c.Expiry.AddDate(0, 0, -days))
+	}
+	return rollDates
+}
+
+// AdjustMethod is the adjustment of StitchContinuous
+type AdjustMethod int
+
+const (
+	ADJUST_NONE  AdjustMethod = iota // the raw prices, with gaps at the rolls
+	ADJUST_BACK                      // the bars before a roll are shifted by the price difference at the roll
+	ADJUST_RATIO                     // the bars before a roll are scaled by the price ratio at the roll
+)
+
+// FutureSeries is the bars of a contract of FutureChain
+type FutureSeries struct {
+	Contract FutureContract
+	Bars     []BarData
+}
+
+// VolumeCrossoverRollDates returns the roll dates between the adjacent series,
+// which are the first bars where the volume of the next contract exceeds the current one.
+// The expiry of the current contract is used if it never happens.
+func VolumeCrossoverRollDates(series []FutureSeries) []time.Time {
+	if len(series) == 0 {
+		return nil
+	}
+
+	rollDates := make([]time.Time, 0, len(series)-1)
+	for i := 0; i+1 < len(series); i++ {
+		rollDate := series[i].Contract.Expiry
+		volumes := make(map[string]Decimal, len(series[i].Bars))
+		for _, bar := range series[i].Bars {
+			volumes[bar.Date] = bar.Volume
+		}
+
+		loc := series[i+1].Contract.Expiry.Location()
+		for _, bar := range series[i+1].Bars {
+			if volume, ok := volumes[bar.Date]; ok && bar.Volume.Cmp(volume) > 0 {
+				if t, err := bar.TimeIn(loc); err == nil {
+					rollDate = t
+					break
+				}
+			}
+		}
+		rollDates = append(rollDates, rollDate)
+	}
+	return rollDates
+}
+
+// StitchContinuous stitches the bars of series into a continuous series.
+/*
+series must be sorted by the expiry, and rollDates[i] is the time rolling from series[i] to series[i+1],
+the bars of series[i] in [rollDates[i-1], rollDates[i]) are taken.
+The adjustment is measured by the closes of the two contracts at the last bar of series[i] before the roll,
+so both of them must have a bar with the same Date there.
+The bar dates are parsed in the time zone of the contract's Expiry, which should match the formatDate of the bars.
+*/
+func StitchContinuous(series []FutureSeries, rollDates []time.Time, method AdjustMethod) ([]BarData, error) {
+	if len(series) == 0 {
+		return nil, nil
+	}
+	if len(rollDates) != len(series)-1 {
+		return nil, fmt.Errorf("%d roll dates are expected for %d series, got %d", len(series)-1, len(series), len(rollDates))
+	}
+
+	var stitched []BarData
+	for i, s := range series {
+		loc := s.Contract.Expiry.Location()
+		var from, to time.Time
+		if i > 0 {
+			from = rollDates[i-1]
+		}
+		if i < len(rollDates) {
+			to = rollDates[i]
+		}
+
+		var taken []BarData
+		for _, bar := range s.Bars {
+			t, err := bar.TimeIn(loc)
+			if err != nil {
+				return nil, err
+			}
+			if (!from.IsZero() && t.Before(from)) || (!to.IsZero() && !t.Before(to)) {
+				continue
+			}
+			taken = append(taken, bar)
+		}
+
+		if i > 0 && method != ADJUST_NONE && len(stitched) > 0 {
+			// the reference bar is the last stitched bar, which is the last bar of the previous series before the roll
+			ref := stitched[len(stitched)-1]
+			refClose, ok := s.closeAt(ref.Date)
+			if !ok {
+				return nil, fmt.Errorf("no bar of %s at %s to adjust the roll", s.Contract, ref.Date)
+			}
+			oldClose, ok := series[i-1].closeAt(ref.Date)
+			if !ok {
+				return nil, fmt.Errorf("no bar of %s at %s to adjust the roll", series[i-1].Contract, ref.Date)
+			}
+
+			switch method {
+			case ADJUST_BACK:
+				delta := refClose - oldClose
+				for j := range stitched {
+					shiftBar(&stitched[j], delta)
+				}
+			case ADJUST_RATIO:
+				if oldClose == 0 {
+					return nil, fmt.Errorf("zero close of %s at %s to adjust the roll", series[i-1].Contract, ref.Date)
+				}
+				ratio := refClose / oldClose
+				for j := range stitched {
+					scaleBar(&stitched[j], ratio)
+				}
+			}
+		}
+
+		stitched = append(stitched, taken...)
+	}
+
+	return stitched, nil
+}
+
+// closeAt returns the raw close of the bar at date
+func (s FutureSeries) closeAt(date string) (float64, bool) {
+	for _, bar := range s.Bars {
+		if bar.Date == date {
+			return bar.Close, true
+		}
+	}
+	return 0, false
+}
+
+func shiftBar(bar *BarData, delta float64) {
+	bar.Open += delta
+	bar.High += delta
+	bar.Low += delta
+	bar.Close += delta
+	bar.Average += delta
+}
+
+func scaleBar(bar *BarData, ratio float64) {
+	bar.Open *= ratio
+	bar.High *= ratio
+	bar.Low *= ratio
+	bar.Close *= ratio
+	bar.Average *= ratio
+}
+
+// FetchContinuousBars requests the bars of each contract of chain ending at its expiry, and stitches them by StitchContinuous.
+// See ReqHistoricalData for duration, barSize, whatToShow and useRTH, which are the same for all the contracts.
+func (ic *IbClient) FetchContinuousBars(ctx context.Context, chain FutureChain, rollDates []time.Time, duration string, barSize string, whatToShow string, useRTH bool, method AdjustMethod) ([]BarData, error) {
+	series := make([]FutureSeries, 0, len(chain))
+	for _, c := range chain {
+		endDateTime := ""
+		if c.Expiry.Before(time.Now()) {
+			endDateTime = FormatIBTime(c.Expiry)
+		}
+
+		contract := c.Details.Contract
+		contract.IncludeExpired = true
+		bars, err := ic.FetchHistoricalData(ctx, &contract, endDateTime, duration, barSize, whatToShow, useRTH, 1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the bars of %s: %w", c, err)
+		}
+		series = append(series, FutureSeries{c, bars})
+	}
+
+	return StitchContinuous(series, rollDates, method)
+}
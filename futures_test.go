@@ -0,0 +1,159 @@
+package ibapi
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func testFuture(conID int64, localSymbol, expiry string) *ContractDetails {
+	return &ContractDetails{
+		Contract: Contract{
+			ContractID: conID, Symbol: "ES", SecurityType: "FUT", Exchange: "CME",
+			Currency: "USD", LocalSymbol: localSymbol, Expiry: expiry,
+		},
+		RealExpirationDate: expiry,
+		LastTradeTime:      "09:30:00",
+		TimezoneID:         "US/Central",
+	}
+}
+
+func TestFutureChain(t *testing.T) {
+	chain := NewFutureChain([]*ContractDetails{
+		testFuture(3, "ESU4", "20240920"),
+		testFuture(1, "ESH4", "20240315"),
+		testFuture(2, "ESM4", "20240621"),
+		{Contract: Contract{ContractID: 4}},
+	})
+	if len(chain) != 3 {
+		t.Fatalf("unexpected chain: %v", chain)
+	}
+	for i, c := range chain {
+		if c.Details.Contract.ContractID != int64(i+1) {
+			t.Fatalf("chain is not sorted by expiry: %v", chain)
+		}
+	}
+
+	loc, _ := LoadTWSLocation("US/Central")
+	if want := time.Date(2024, 3, 15, 9, 30, 0, 0, loc); !chain[0].Expiry.Equal(want) {
+		t.Errorf("unexpected expiry %s, want %s", chain[0].Expiry, want)
+	}
+
+	at := time.Date(2024, 3, 10, 0, 0, 0, 0, loc)
+	if front, ok := chain.Front(at); !ok || front.Details.Contract.LocalSymbol != "ESH4" {
+		t.Errorf("unexpected front: %v", front)
+	}
+	if active, ok := chain.ActiveByDays(at, 8); !ok || active.Details.Contract.LocalSymbol != "ESM4" {
+		t.Errorf("unexpected active by days: %v", active)
+	}
+	if active, ok := chain.ActiveByCrossover(at, map[int64]float64{1: 100, 2: 200, 3: 10}); !ok || active.Details.Contract.LocalSymbol != "ESM4" {
+		t.Errorf("unexpected active by crossover: %v", active)
+	}
+	if _, ok := chain.Front(time.Date(2025, 1, 1, 0, 0, 0, 0, loc)); ok {
+		t.Error("no contract should be live after the last expiry")
+	}
+	if rolls := chain.RollDatesByDays(8); len(rolls) != 2 || rolls[0].Day() != 7 {
+		t.Errorf("unexpected roll dates: %v", rolls)
+	}
+}
+
+func TestStitchContinuous(t *testing.T) {
+	chain := NewFutureChain([]*ContractDetails{
+		testFuture(1, "ESH4", "20240315"),
+		testFuture(2, "ESM4", "20240621"),
+	})
+	series := []FutureSeries{
+		{chain[0], []BarData{
+			{Date: "20240311", Close: 100, Volume: DecimalFromInt(500)},
+			{Date: "20240312", Close: 102, Volume: DecimalFromInt(400)},
+			{Date: "20240313", Close: 104, Volume: DecimalFromInt(100)},
+		}},
+		{chain[1], []BarData{
+			{Date: "20240311", Close: 110, Volume: DecimalFromInt(200)},
+			{Date: "20240312", Close: 112, Volume: DecimalFromInt(300)},
+			{Date: "20240313", Close: 115, Volume: DecimalFromInt(600)},
+			{Date: "20240314", Close: 116, Volume: DecimalFromInt(700)},
+		}},
+	}
+
+	rolls := VolumeCrossoverRollDates(series)
+	if len(rolls) != 1 || rolls[0].Format("20060102") != "20240313" {
+		t.Fatalf("unexpected roll dates: %v", rolls)
+	}
+
+	closes := func(bars []BarData) []float64 {
+		var cs []float64
+		for _, b := range bars {
+			cs = append(cs, b.Close)
+		}
+		return cs
+	}
+
+	cases := []struct {
+		method AdjustMethod
+		want   []float64
+	}{
+		{ADJUST_NONE, []float64{100, 102, 115, 116}},
+		{ADJUST_BACK, []float64{110, 112, 115, 116}},
+		{ADJUST_RATIO, []float64{100 * 112.0 / 102, 112, 115, 116}},
+	}
+	for _, c := range cases {
+		bars, err := StitchContinuous(series, rolls, c.method)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := closes(bars)
+		if len(got) != len(c.want) {
+			t.Fatalf("method %d: unexpected bars %v", c.method, got)
+		}
+		for i := range got {
+			if math.Abs(got[i]-c.want[i]) > 1e-9 {
+				t.Errorf("method %d: unexpected closes %v, want %v", c.method, got, c.want)
+				break
+			}
+		}
+	}
+	if series[0].Bars[0].Close != 100 {
+		t.Error("the bars of series are modified")
+	}
+
+	if _, err := StitchContinuous(series, nil, ADJUST_BACK); err == nil {
+		t.Error("mismatched roll dates should fail")
+	}
+}
+
+func TestFetchContinuousBars(t *testing.T) {
+	ic := newTestClient(func(ic *IbClient, req []byte) {
+		fields := splitMsgBytes(req[4:])
+		if fieldInt(fields[0]) != mREQ_HISTORICAL_DATA {
+			return
+		}
+		reqID := fieldInt(fields[1])
+		bars := map[int64][]BarData{
+			1: {{Date: "20240311", Close: 100}, {Date: "20240312", Close: 102}},
+			2: {{Date: "20240312", Close: 112}, {Date: "20240313", Close: 115}},
+		}[fieldInt(fields[2])]
+		for i := range bars {
+			ic.dispatcher.HistoricalData(reqID, &bars[i])
+		}
+		ic.dispatcher.HistoricalDataEnd(reqID, "", "")
+	})
+
+	chain := NewFutureChain([]*ContractDetails{
+		testFuture(1, "ESH4", "20240315"),
+		testFuture(2, "ESM4", "20240621"),
+	})
+	loc, _ := LoadTWSLocation("US/Central")
+	rolls := []time.Time{time.Date(2024, 3, 13, 0, 0, 0, 0, loc)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	bars, err := ic.FetchContinuousBars(ctx, chain, rolls, "1 M", "1 day", "TRADES", false, ADJUST_BACK)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bars) != 3 || bars[0].Close != 110 || bars[2].Close != 115 {
+		t.Errorf("unexpected bars: %v", bars)
+	}
+}
@@ -0,0 +1,35 @@
+//go:build gofuzz
+// +build gofuzz
+
+/*
+fuzz configures the go-fuzz build of the harnesses for the framing and the decoding of the msgs from TWS.
+
+	go-fuzz-build -func FuzzInterpret
+	go-fuzz -bin ibapi-fuzz.zip -workdir testdata/fuzz/interpret
+
+The exported harnesses only wrap those in fuzz_harness.go, which is built without the tag so that TestFuzzCorpus runs them on the corpus.
+The seed corpus is in testdata/fuzz/<harness>/corpus, it is generated by
+go test -run TestFuzzSeeds -update-corpus from the msg layouts in fuzz_test.go.
+*/
+package ibapi
+
+import (
+	"go.uber.org/zap"
+)
+
+func init() {
+	// the wrapper logs every callback, which slows the fuzzer down
+	log = zap.NewNop()
+}
+
+// FuzzFrame is the go-fuzz entry of fuzzFrame
+func FuzzFrame(data []byte) int { return fuzzFrame(data) }
+
+// FuzzInterpret is the go-fuzz entry of fuzzInterpret
+func FuzzInterpret(data []byte) int { return fuzzInterpret(data) }
+
+// FuzzProcessors is the go-fuzz entry of fuzzProcessors
+func FuzzProcessors(data []byte) int { return fuzzProcessors(data) }
+
+// FuzzMsgBuffer is the go-fuzz entry of fuzzMsgBuffer
+func FuzzMsgBuffer(data []byte) int { return fuzzMsgBuffer(data) }
@@ -0,0 +1,116 @@
+/*
+fuzz_harness contains the harnesses of go-fuzz, whose exported entries are in fuzz.go. They are built without the gofuzz tag,
+so that the tests could run them on the seed corpus.
+*/
+
+package ibapi
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// fuzzFrame splits data into msgs by scanFields and the msgs into fields by splitMsgBytes
+func fuzzFrame(data []byte) int {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 4096), MAX_MSG_LEN)
+	scanner.Split(scanFields)
+
+	n := 0
+	for scanner.Scan() {
+		msg := scanner.Bytes()
+		if fields := splitMsgBytes(msg); len(fields) != bytes.Count(msg, []byte{fieldSplit}) {
+			panic(fmt.Sprintf("%d fields are split from %q", len(fields), msg))
+		}
+		n++
+	}
+
+	if n == 0 {
+		return 0
+	}
+	return 1
+}
+
+// fuzzVersion picks the server version in [MIN_CLIENT_VER, MAX_CLIENT_VER] by b
+func fuzzVersion(b byte) Version {
+	return MIN_CLIENT_VER + Version(b)%(MAX_CLIENT_VER-MIN_CLIENT_VER+1)
+}
+
+// fuzzInterpret decodes data[1:] as a msg, data[0] picks the server version by fuzzVersion
+func fuzzInterpret(data []byte) int {
+	if len(data) < 2 {
+		return -1
+	}
+
+	return decodeFuzzMsg(fuzzVersion(data[0]), data[1:])
+}
+
+// fuzzProcessors decodes data[2:] by the processer picked by data[0] in the order of msg ID, data[1] picks the server version,
+// so that every processer is reached even if the fuzzer has not found its msg ID
+func fuzzProcessors(data []byte) int {
+	if len(data) < 2 {
+		return -1
+	}
+
+	ids := fuzzMsgIDs()
+	msg := makeMsgBytes(ids[int(data[0])%len(ids)])[4:]
+	return decodeFuzzMsg(fuzzVersion(data[1]), append(msg, data[2:]...))
+}
+
+// fuzzMsgBuffer reads the fields of data by MsgBuffer, the fields written back by makeMsgBytes should be the same bytes
+func fuzzMsgBuffer(data []byte) int {
+	msgBuf := NewMsgBuffer(data)
+	var fields []interface{}
+	for msgBuf.Len() > 0 {
+		f := msgBuf.readString()
+		if msgBuf.Err() != nil {
+			break
+		}
+		fields = append(fields, f)
+	}
+
+	if len(fields) == 0 {
+		return 0
+	}
+
+	msg := makeMsgBytes(fields...)[4:]
+	if !bytes.HasPrefix(data, msg) {
+		panic(fmt.Sprintf("%q is written back as %q", data, msg))
+	}
+	return 1
+}
+
+// newFuzzDecoder makes the decoder of the server version with the default Wrapper
+func newFuzzDecoder(version Version) *ibDecoder {
+	d := &ibDecoder{wrapper: &Wrapper{}}
+	d.setVersion(version)
+	d.setmsgID2process()
+	return d
+}
+
+// decodeFuzzMsg panics on the panic while decoding, the malformed msgs are fine
+func decodeFuzzMsg(version Version, msg []byte) int {
+	if err := newFuzzDecoder(version).interpret(msg); err != nil {
+		if errors.Is(err, errDecodePanic) {
+			panic(err)
+		}
+		return 0
+	}
+	return 1
+}
+
+// fuzzMsgIDs returns the msg IDs of the processers in ascending order
+func fuzzMsgIDs() []IN {
+	d := &ibDecoder{}
+	d.setmsgID2process()
+
+	ids := make([]IN, 0, len(d.msgID2process))
+	for id := range d.msgID2process {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
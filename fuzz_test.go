@@ -0,0 +1,238 @@
+package ibapi
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+var updateCorpus = flag.Bool("update-corpus", false, "write the seed corpus of the fuzz harnesses into testdata/fuzz")
+
+// fuzzSeedVersion is the server version the seeds are laid out for
+const fuzzSeedVersion Version = 151
+
+// fuzzSeed is the layout of a msg from TWS, fields follow the msg ID
+type fuzzSeed struct {
+	name   string
+	msgID  IN
+	fields []interface{}
+}
+
+var fuzzSeeds = []fuzzSeed{
+	{"tick_price", mTICK_PRICE, []interface{}{6, 1, BID, 23403.5, 3, 0}},
+	{"tick_size", mTICK_SIZE, []interface{}{6, 1, VOLUME, 2983}},
+	{"order_status", mORDER_STATUS, []interface{}{7, "Filled", 100, 0, 187.5, 1801234567, 0, 187.5, 1, "", 0}},
+	{"error", mERR_MSG, []interface{}{2, -1, 2104, "Market data farm connection is OK:hfarm"}},
+	{"acct_value", mACCT_VALUE, []interface{}{2, "NetLiquidationByCurrency", "-1105612", "HKD", "DU1382837"}},
+	{"portfolio_value", mPORTFOLIO_VALUE, []interface{}{8, 265598, "AAPL", "STK", "", 0, "", "", "NASDAQ", "USD", "AAPL", "NMS",
+		100, 187.5, 18750, 150.2, 3730, 0, "DU1382837"}},
+	{"acct_update_time", mACCT_UPDATE_TIME, []interface{}{1, "16:20"}},
+	{"next_valid_id", mNEXT_VALID_ID, []interface{}{1, 1}},
+	{"contract_data", mCONTRACT_DATA, []interface{}{8, 1, "AAPL", "STK", "", 0, "", "SMART", "USD", "AAPL", "NMS", "NMS", 265598, 0.01, 100, "",
+		"ACTIVETIM,AD,ADJUST,ALERT,ALGO,ALLOC,AVGCOST,BASKET,LMT,MKT,STP", "SMART,AMEX,NYSE,CBOE,ISLAND", 1, 0, "APPLE INC", "NASDAQ",
+		"", "Technology", "Computers", "Computers", "US/Eastern", "20200527:0400-20200527:2000;20200528:0400-20200528:2000",
+		"20200527:0930-20200527:1600;20200528:0930-20200528:1600", "", "", 1, "ISIN", "US0378331005", 1, "", "", "26,26,26", ""}},
+	{"contract_data_end", mCONTRACT_DATA_END, []interface{}{1, 1}},
+	{"execution_data", mEXECUTION_DATA, []interface{}{1, 7, 265598, "AAPL", "STK", "", 0, "", "", "ISLAND", "USD", "AAPL", "NMS",
+		"0000e0d5.5ecd3a1a.01.01", "20200527  09:30:01", "DU1382837", "ISLAND", "BOT", 100, 187.5, 1801234567, 0, 0, 100, 187.5, "", "", "", "", 1}},
+	{"market_depth", mMARKET_DEPTH, []interface{}{1, 1, 0, 0, 1, 23403, 8}},
+	{"market_depth_l2", mMARKET_DEPTH_L2, []interface{}{1, 3, 0, "HKFE", 1, 1, 23403, 8, 1}},
+	{"managed_accts", mMANAGED_ACCTS, []interface{}{1, "DU1382837"}},
+	{"historical_data", mHISTORICAL_DATA, []interface{}{1, "20200526  09:15:00", "20200526  16:30:00", 2,
+		"20200526  09:15:00", 23500, 23520, 23480, 23510, 1520, 23502.3, 320,
+		"20200526  09:16:00", 23510, 23530, 23505, 23525, 980, 23517.8, 211}},
+	{"historical_data_update", mHISTORICAL_DATA_UPDATE, []interface{}{2, 209, "20200526  16:20:00", 23403, 23404, 23406, 23400, 23403.43816254417, 283}},
+	{"tick_option_computation", mTICK_OPTION_COMPUTATION, []interface{}{6, 1, MODEL_OPTION, 0.25, 0.55, 5.2, 0, 0.03, 0.1, -0.05, 187.5}},
+	{"tick_generic", mTICK_GENERIC, []interface{}{6, 1, HALTED, 0}},
+	{"tick_string", mTICK_STRING, []interface{}{6, 1, LAST_TIMESTAMP, "1590508800"}},
+	{"current_time", mCURRENT_TIME, []interface{}{1, 1590508800}},
+	{"real_time_bars", mREAL_TIME_BARS, []interface{}{3, 1, 1590508800, 23403, 23406, 23400, 23404, 25, 23403.2, 12}},
+	{"acct_download_end", mACCT_DOWNLOAD_END, []interface{}{1, "DU1382837"}},
+	{"market_data_type", mMARKET_DATA_TYPE, []interface{}{1, 1, 3}},
+	{"commission_report", mCOMMISSION_REPORT, []interface{}{1, "0000e0d5.5ecd3a1a.01.01", 1.0, "USD", "1.7976931348623157E308", "1.7976931348623157E308", ""}},
+	{"position_data", mPOSITION_DATA, []interface{}{3, "DU1382837", 265598, "AAPL", "STK", "", 0, "", "", "NASDAQ", "USD", "AAPL", "NMS", 100, 150.2}},
+	{"position_end", mPOSITION_END, []interface{}{1}},
+	{"account_summary", mACCOUNT_SUMMARY, []interface{}{1, 9001, "DU1382837", "NetLiquidation", "1105612.35", "HKD"}},
+	{"account_summary_end", mACCOUNT_SUMMARY_END, []interface{}{1, 9001}},
+	{"security_definition_option_parameter", mSECURITY_DEFINITION_OPTION_PARAMETER, []interface{}{1, "SMART", 265598, "AAPL", "100",
+		2, "20200619", "20200717", 3, 180, 185, 190}},
+	{"security_definition_option_parameter_end", mSECURITY_DEFINITION_OPTION_PARAMETER_END, []interface{}{1}},
+	{"symbol_samples", mSYMBOL_SAMPLES, []interface{}{1, 1, 265598, "AAPL", "STK", "NASDAQ", "USD", 2, "CFD", "OPT"}},
+	{"tick_req_params", mTICK_REQ_PARAMS, []interface{}{1, 0.01, "9c0001", 3}},
+	{"head_timestamp", mHEAD_TIMESTAMP, []interface{}{1, "19801212  14:30:00"}},
+	{"tick_news", mTICK_NEWS, []interface{}{1, 1590508800000, "BZ", "BZ$12345", "{A:800015:L:en:K:n/a:C:0.6}!Apple shares rise", "A:800015:L:en:K:n/a:C:0.6"}},
+	{"news_providers", mNEWS_PROVIDERS, []interface{}{3, "BRFG", "Briefing.com General Market Columns", "BZ", "Benzinga Pro", "DJNL", "Dow Jones Newsletters"}},
+	{"market_rule", mMARKET_RULE, []interface{}{26, 1, 0, 0.01}},
+	{"pnl", mPNL, []interface{}{1, 123.4, 56.7, 0}},
+	{"pnl_single", mPNL_SINGLE, []interface{}{1, 100, 12.5, 373, 0, 18750}},
+	{"historical_ticks", mHISTORICAL_TICKS, []interface{}{1, 2, 1590508800, "", 187.5, 100, 1590508801, "", 187.45, 200, 1}},
+	{"historical_ticks_last", mHISTORICAL_TICKS_LAST, []interface{}{1, 1, 1590508800, 0, 187.5, 100, "ISLAND", " T", 1}},
+	{"tick_by_tick_last", mTICK_BY_TICK, []interface{}{1, 1, 1590508800, 187.5, 100, 0, "ISLAND", ""}},
+	{"tick_by_tick_bid_ask", mTICK_BY_TICK, []interface{}{1, 3, 1590508800, 187.4, 187.5, 300, 200, 0}},
+	{"wsh_meta_data", mWSH_META_DATA, []interface{}{1, `{"validated":true,"data":{"metadata":{"filters":[]}}}`}},
+	{"historical_schedule", mHISTORICAL_SCHEDULE, []interface{}{1, "20220302-09:30:00", "20220304-16:00:00", "US/Eastern",
+		2, "20220302-09:30:00", "20220302-16:00:00", "20220302", "20220303-09:30:00", "20220303-16:00:00", "20220303"}},
+	{"user_info", mUSER_INFO, []interface{}{1, "IBKR"}},
+}
+
+// msg returns the msg bytes of the seed without the size header
+func (s fuzzSeed) msg() []byte {
+	return makeMsgBytes(append([]interface{}{s.msgID}, s.fields...)...)[4:]
+}
+
+// TestFuzzSeeds checks that the seeds are laid out exactly, they are decoded, but not without the last field
+func TestFuzzSeeds(t *testing.T) {
+	d := newFuzzDecoder(fuzzSeedVersion)
+	for _, s := range fuzzSeeds {
+		msg := s.msg()
+		if err := d.interpret(msg); err != nil {
+			t.Errorf("%s: %v", s.name, err)
+		}
+
+		truncated := msg[:bytes.LastIndexByte(msg[:len(msg)-1], fieldSplit)+1]
+		if err := d.interpret(truncated); err == nil {
+			t.Errorf("%s: the msg without the last field should fail", s.name)
+		}
+	}
+
+	if *updateCorpus {
+		writeFuzzCorpus(t)
+	}
+}
+
+func writeFuzzCorpus(t *testing.T) {
+	write := func(harness string, name string, data []byte) {
+		dir := filepath.Join("testdata", "fuzz", harness, "corpus")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids := fuzzMsgIDs()
+	index := make(map[IN]byte, len(ids))
+	for i, id := range ids {
+		index[id] = byte(i)
+		write("processors", fmt.Sprintf("msg_%d", id), []byte{byte(i), byte(fuzzSeedVersion - MIN_CLIENT_VER)})
+	}
+
+	var frames []byte
+	for _, s := range fuzzSeeds {
+		msg := s.msg()
+		frame := makeMsgBytes(append([]interface{}{s.msgID}, s.fields...)...)
+		frames = append(frames, frame...)
+
+		write("interpret", s.name, append([]byte{byte(fuzzSeedVersion - MIN_CLIENT_VER)}, msg...))
+		write("processors", s.name, append([]byte{index[s.msgID], byte(fuzzSeedVersion - MIN_CLIENT_VER)}, msg[bytes.IndexByte(msg, fieldSplit)+1:]...))
+		write("frame", s.name, frame)
+		write("msgbuffer", s.name, msg)
+	}
+	write("frame", "all", frames)
+}
+
+// TestFuzzCorpus runs the fuzz harnesses on their corpus, which must not panic in the decoder
+func TestFuzzCorpus(t *testing.T) {
+	harnesses := map[string]func([]byte) int{
+		"frame":      fuzzFrame,
+		"interpret":  fuzzInterpret,
+		"processors": fuzzProcessors,
+		"msgbuffer":  fuzzMsgBuffer,
+	}
+	for harness, fuzz := range harnesses {
+		files, err := filepath.Glob(filepath.Join("testdata", "fuzz", harness, "corpus", "*"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) == 0 {
+			t.Fatalf("no corpus of %s", harness)
+		}
+
+		for _, f := range files {
+			data, err := ioutil.ReadFile(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Errorf("%s: %v", f, r)
+					}
+				}()
+				fuzz(data)
+			}()
+		}
+	}
+}
+
+// TestMsgRoundTrip checks that the fields made by makeMsgBytes are read back by MsgBuffer
+func TestMsgRoundTrip(t *testing.T) {
+	roundTrip := func(i int64, f float64, s string, b bool) bool {
+		s = strings.ReplaceAll(s, string(fieldSplit), "")
+		msgBuf := NewMsgBuffer(makeMsgBytes(i, f, s, b, handleEmpty(UNSETINT), handleEmpty(UNSETFLOAT))[4:])
+
+		ok := msgBuf.readInt() == i &&
+			msgBuf.readFloat() == f &&
+			msgBuf.readString() == s &&
+			msgBuf.readBool() == b &&
+			msgBuf.readIntCheckUnset() == UNSETINT &&
+			msgBuf.readFloatCheckUnset() == UNSETFLOAT
+
+		return ok && msgBuf.Err() == nil && msgBuf.Len() == 0
+	}
+	if err := quick.Check(roundTrip, nil); err != nil {
+		t.Error(err)
+	}
+
+	for _, f := range []float64{0, -0.5, 1e-7, 1e6, 23403.43816254417, math.MaxFloat32, UNSETFLOAT} {
+		if !roundTrip(1, f, "", false) {
+			t.Errorf("failed to round trip %v", f)
+		}
+	}
+}
+
+// TestFrameRoundTrip checks that the msgs made by makeMsgBytes are split back by scanFields and splitMsgBytes
+func TestFrameRoundTrip(t *testing.T) {
+	roundTrip := func(msgs [][]string) bool {
+		var data []byte
+		for _, fields := range msgs {
+			fs := make([]interface{}, len(fields))
+			for i, f := range fields {
+				fs[i] = strings.ReplaceAll(f, string(fieldSplit), "")
+			}
+			data = append(data, makeMsgBytes(fs...)...)
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 4096), MAX_MSG_LEN)
+		scanner.Split(scanFields)
+
+		n := 0
+		for ; scanner.Scan(); n++ {
+			fields := splitMsgBytes(scanner.Bytes())
+			if len(fields) != len(msgs[n]) {
+				return false
+			}
+			for i, f := range fields {
+				if string(f) != strings.ReplaceAll(msgs[n][i], string(fieldSplit), "") {
+					return false
+				}
+			}
+		}
+
+		return scanner.Err() == nil && n == len(msgs)
+	}
+	if err := quick.Check(roundTrip, nil); err != nil {
+		t.Error(err)
+	}
+}
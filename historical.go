@@ -0,0 +1,92 @@
+package ibapi
+
+import (
+	"context"
+	"time"
+)
+
+// FetchHistoricalData requests the historical bars and waits for HistoricalDataEnd, see ReqHistoricalData for the params.
+// keepUpToDate is not supported, and the callbacks of the request are not delivered to the wrapper.
+func (ic *IbClient) FetchHistoricalData(ctx context.Context, contract *Contract, endDateTime string, duration string, barSize string, whatToShow string, useRTH bool, formatDate int) ([]BarData, error) {
+	if !ic.IsConnected() {
+		return nil, NOT_CONNECTED
+	}
+
+	reqID := ic.GetReqID()
+	bars := []BarData{}
+	err := ic.request(ctx, reqID, func() {
+		ic.ReqHistoricalData(reqID, contract, endDateTime, duration, barSize, whatToShow, useRTH, formatDate, false, nil)
+	}, func(msg interface{}) bool {
+		switch m := msg.(type) {
+		case *historicalDataMsg:
+			bars = append(bars, *m.bar)
+		case *historicalDataEndMsg:
+			return true
+		}
+		return false
+	})
+
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		ic.CancelHistoricalData(reqID)
+	}
+
+	return bars, err
+}
+
+// HistoricalSchedule is the trading sessions of a contract, see ReqHistoricalSchedule.
+/*
+The times are "yyyymmdd-hh:mm:ss" in TimeZone, such as "20220302-09:30:00" in "US/Eastern",
+use Location and HistoricalSession.Start/End to get them as time.Time.
+Unlike ContractDetails.TradingHours, which only covers the coming days, the sessions are the ones in the past,
+so that they are the authoritative boundaries of the historical bars, including the holidays and early closes.
+*/
+type HistoricalSchedule struct {
+	StartDateTime string
+	EndDateTime   string
+	TimeZone      string
+	Sessions      []HistoricalSession
+}
+
+// Location loads the TimeZone of the schedule
+func (s HistoricalSchedule) Location() (*time.Location, error) {
+	return LoadTWSLocation(s.TimeZone)
+}
+
+// scheduleTimeLayout is the layout of the times of the historical schedule
+const scheduleTimeLayout = "20060102-15:04:05"
+
+// Start returns StartDateTime in loc, which should be the Location of the HistoricalSchedule
+func (h HistoricalSession) Start(loc *time.Location) (time.Time, error) {
+	return time.ParseInLocation(scheduleTimeLayout, h.StartDateTime, loc)
+}
+
+// End returns EndDateTime in loc, which should be the Location of the HistoricalSchedule
+func (h HistoricalSession) End(loc *time.Location) (time.Time, error) {
+	return time.ParseInLocation(scheduleTimeLayout, h.EndDateTime, loc)
+}
+
+// FetchHistoricalSchedule requests the trading sessions and waits for the HistoricalSchedule, see ReqHistoricalSchedule for the params.
+// The callback of the request is not delivered to the wrapper.
+func (ic *IbClient) FetchHistoricalSchedule(ctx context.Context, contract *Contract, endDateTime string, duration string, barSize string, useRTH bool) (*HistoricalSchedule, error) {
+	if !ic.IsConnected() {
+		return nil, NOT_CONNECTED
+	}
+
+	reqID := ic.GetReqID()
+	var schedule *HistoricalSchedule
+	err := ic.request(ctx, reqID, func() {
+		ic.ReqHistoricalSchedule(reqID, contract, endDateTime, duration, barSize, useRTH)
+	}, func(msg interface{}) bool {
+		if m, ok := msg.(*historicalScheduleMsg); ok {
+			schedule = &m.schedule
+			return true
+		}
+		return false
+	})
+
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		ic.CancelHistoricalData(reqID)
+	}
+
+	return schedule, err
+}
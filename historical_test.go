@@ -0,0 +1,59 @@
+package ibapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchHistoricalSchedule(t *testing.T) {
+	ic := newTestClient(func(ic *IbClient, req []byte) {
+		fields := splitMsgBytes(req[4:])
+		if fieldInt(fields[0]) != mREQ_HISTORICAL_DATA || string(fields[19]) != "SCHEDULE" {
+			t.Errorf("unexpected request %q", fields)
+			return
+		}
+		ic.dispatcher.HistoricalSchedule(fieldInt(fields[1]), "20220302-09:30:00", "20220303-16:00:00", "US/Eastern", []HistoricalSession{
+			{"20220302-09:30:00", "20220302-16:00:00", "20220302"},
+			{"20220303-09:30:00", "20220303-13:00:00", "20220303"},
+		})
+	})
+	ic.serverVersion = MAX_CLIENT_VER
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	aapl := &Contract{ContractID: 265598, Symbol: "AAPL", SecurityType: "STK", Exchange: "SMART", Currency: "USD"}
+	schedule, err := ic.FetchHistoricalSchedule(ctx, aapl, "20220304 00:00:00", "2 D", "1 day", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schedule.Sessions) != 2 || schedule.Sessions[1].RefDate != "20220303" {
+		t.Fatalf("unexpected schedule %+v", schedule)
+	}
+
+	loc, err := schedule.Location()
+	if err != nil {
+		t.Fatal(err)
+	}
+	end, err := schedule.Sessions[1].End(loc)
+	if err != nil || !end.Equal(time.Date(2022, 3, 3, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("the early close should be 13:00 EST, got %v, %v", end, err)
+	}
+}
+
+func TestFetchHistoricalScheduleUpdateTWS(t *testing.T) {
+	ic := newTestClient(func(ic *IbClient, req []byte) {
+		t.Errorf("the request should not be sent to the older server")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := ic.FetchHistoricalSchedule(ctx, &Contract{ContractID: 265598}, "", "1 M", "1 day", true)
+	if !errors.Is(err, UPDATE_TWS) || !strings.Contains(err.Error(), "historical schedule") {
+		t.Errorf("expect the error of UPDATE_TWS, got %v", err)
+	}
+}
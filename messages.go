@@ -0,0 +1,3719 @@
+package ibapi
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Message is a msg from TWS decoded into a struct.
+/*
+Every IN msg has its own XxxMsg struct, such as TickPriceMsg and OrderStatusMsg. The decoder decodes the msg into the struct
+and then dispatches it to the IbWrapper, so a recorder, a bridge or a test could decode the raw msg bytes by the same code
+and get the same values as the wrapper.
+*/
+type Message interface {
+	// MsgID returns the IN msg ID of the msg
+	MsgID() IN
+	// Decode decodes the msg bytes without the size header, the first field of which is the msg ID, by the server version
+	Decode(version Version, msgBytes []byte) error
+	// Dispatch calls back the wrapper with the decoded fields, some msgs are dispatched to more than one callback
+	Dispatch(w IbWrapper)
+
+	decode(serverVersion Version, msgBuf *MsgBuffer)
+}
+
+// NewMessage returns an empty Message of the msg ID, or nil if the msg ID is unknown
+func NewMessage(msgID IN) Message {
+	if newMsg, ok := newMessages[msgID]; ok {
+		return newMsg()
+	}
+	return nil
+}
+
+// DecodeMessage decodes the msg bytes without the size header into the Message of its msg ID.
+/*
+It returns *DecodeError if the msg is malformed, and a nil Message without error if the msg ID is unknown.
+*/
+func DecodeMessage(version Version, msgBytes []byte) (Message, error) {
+	msgBuf := NewMsgBuffer(msgBytes)
+	msgID := msgBuf.readInt()
+	if msgBuf.err != nil {
+		return nil, &DecodeError{MsgID: NO_VALID_ID, MsgBytes: msgBytes, Err: msgBuf.err}
+	}
+
+	m := NewMessage(msgID)
+	if m == nil {
+		return nil, nil
+	}
+
+	decodeFields(m, version, msgBuf)
+	if msgBuf.err != nil {
+		return nil, &DecodeError{MsgID: msgID, MsgBytes: msgBytes, Err: msgBuf.err}
+	}
+	return m, nil
+}
+
+// decodeMessage is the Decode of every Message, it checks the msg ID before decoding the rest of the fields into m
+func decodeMessage(m Message, version Version, msgBytes []byte) error {
+	msgBuf := NewMsgBuffer(msgBytes)
+	msgID := msgBuf.readInt()
+	if msgBuf.err != nil {
+		return &DecodeError{MsgID: NO_VALID_ID, MsgBytes: msgBytes, Err: msgBuf.err}
+	}
+	if msgID != m.MsgID() {
+		return &DecodeError{MsgID: msgID, MsgBytes: msgBytes, Err: fmt.Errorf("msg ID %d is not %T's %d", msgID, m, m.MsgID())}
+	}
+
+	decodeFields(m, version, msgBuf)
+	if msgBuf.err != nil {
+		return &DecodeError{MsgID: msgID, MsgBytes: msgBytes, Err: msgBuf.err}
+	}
+	return nil
+}
+
+func decodeBarTime(bar *BarData, loc *time.Location) {
+	t, err := BarTime(bar.Date, loc)
+	if err != nil {
+		log.Error("failed to decode the bar date", zap.String("date", bar.Date), zap.Error(err))
+	}
+	bar.Time = t
+}
+
+// newMessages is the constructors of the Message by msg ID
+var newMessages = map[IN]func() Message{
+	mTICK_PRICE:                           func() Message { return new(TickPriceMsg) },
+	mTICK_SIZE:                            func() Message { return new(TickSizeMsg) },
+	mORDER_STATUS:                         func() Message { return new(OrderStatusMsg) },
+	mERR_MSG:                              func() Message { return new(ErrorMsg) },
+	mOPEN_ORDER:                           func() Message { return new(OpenOrderMsg) },
+	mACCT_VALUE:                           func() Message { return new(AcctValueMsg) },
+	mPORTFOLIO_VALUE:                      func() Message { return new(PortfolioValueMsg) },
+	mACCT_UPDATE_TIME:                     func() Message { return new(AcctUpdateTimeMsg) },
+	mNEXT_VALID_ID:                        func() Message { return new(NextValidIDMsg) },
+	mCONTRACT_DATA:                        func() Message { return new(ContractDataMsg) },
+	mEXECUTION_DATA:                       func() Message { return new(ExecutionDataMsg) },
+	mMARKET_DEPTH:                         func() Message { return new(MarketDepthMsg) },
+	mMARKET_DEPTH_L2:                      func() Message { return new(MarketDepthL2Msg) },
+	mNEWS_BULLETINS:                       func() Message { return new(NewsBulletinsMsg) },
+	mMANAGED_ACCTS:                        func() Message { return new(ManagedAcctsMsg) },
+	mRECEIVE_FA:                           func() Message { return new(ReceiveFAMsg) },
+	mHISTORICAL_DATA:                      func() Message { return new(HistoricalDataMsg) },
+	mHISTORICAL_DATA_UPDATE:               func() Message { return new(HistoricalDataUpdateMsg) },
+	mBOND_CONTRACT_DATA:                   func() Message { return new(BondContractDataMsg) },
+	mSCANNER_PARAMETERS:                   func() Message { return new(ScannerParametersMsg) },
+	mSCANNER_DATA:                         func() Message { return new(ScannerDataMsg) },
+	mTICK_OPTION_COMPUTATION:              func() Message { return new(TickOptionComputationMsg) },
+	mTICK_GENERIC:                         func() Message { return new(TickGenericMsg) },
+	mTICK_STRING:                          func() Message { return new(TickStringMsg) },
+	mTICK_EFP:                             func() Message { return new(TickEFPMsg) },
+	mCURRENT_TIME:                         func() Message { return new(CurrentTimeMsg) },
+	mREAL_TIME_BARS:                       func() Message { return new(RealTimeBarsMsg) },
+	mFUNDAMENTAL_DATA:                     func() Message { return new(FundamentalDataMsg) },
+	mCONTRACT_DATA_END:                    func() Message { return new(ContractDataEndMsg) },
+	mACCT_DOWNLOAD_END:                    func() Message { return new(AcctDownloadEndMsg) },
+	mOPEN_ORDER_END:                       func() Message { return new(OpenOrderEndMsg) },
+	mEXECUTION_DATA_END:                   func() Message { return new(ExecutionDataEndMsg) },
+	mDELTA_NEUTRAL_VALIDATION:             func() Message { return new(DeltaNeutralValidationMsg) },
+	mTICK_SNAPSHOT_END:                    func() Message { return new(TickSnapshotEndMsg) },
+	mMARKET_DATA_TYPE:                     func() Message { return new(MarketDataTypeMsg) },
+	mCOMMISSION_REPORT:                    func() Message { return new(CommissionReportMsg) },
+	mPOSITION_DATA:                        func() Message { return new(PositionDataMsg) },
+	mPOSITION_END:                         func() Message { return new(PositionEndMsg) },
+	mACCOUNT_SUMMARY:                      func() Message { return new(AccountSummaryMsg) },
+	mACCOUNT_SUMMARY_END:                  func() Message { return new(AccountSummaryEndMsg) },
+	mVERIFY_MESSAGE_API:                   func() Message { return new(VerifyMessageAPIMsg) },
+	mVERIFY_COMPLETED:                     func() Message { return new(VerifyCompletedMsg) },
+	mDISPLAY_GROUP_LIST:                   func() Message { return new(DisplayGroupListMsg) },
+	mDISPLAY_GROUP_UPDATED:                func() Message { return new(DisplayGroupUpdatedMsg) },
+	mVERIFY_AND_AUTH_MESSAGE_API:          func() Message { return new(VerifyAndAuthMessageAPIMsg) },
+	mVERIFY_AND_AUTH_COMPLETED:            func() Message { return new(VerifyAndAuthCompletedMsg) },
+	mPOSITION_MULTI:                       func() Message { return new(PositionMultiMsg) },
+	mPOSITION_MULTI_END:                   func() Message { return new(PositionMultiEndMsg) },
+	mACCOUNT_UPDATE_MULTI:                 func() Message { return new(AccountUpdateMultiMsg) },
+	mACCOUNT_UPDATE_MULTI_END:             func() Message { return new(AccountUpdateMultiEndMsg) },
+	mSECURITY_DEFINITION_OPTION_PARAMETER: func() Message { return new(SecurityDefinitionOptionParameterMsg) },
+	mSECURITY_DEFINITION_OPTION_PARAMETER_END: func() Message { return new(SecurityDefinitionOptionParameterEndMsg) },
+	mSOFT_DOLLAR_TIERS:                        func() Message { return new(SoftDollarTiersMsg) },
+	mFAMILY_CODES:                             func() Message { return new(FamilyCodesMsg) },
+	mSYMBOL_SAMPLES:                           func() Message { return new(SymbolSamplesMsg) },
+	mSMART_COMPONENTS:                         func() Message { return new(SmartComponentsMsg) },
+	mTICK_REQ_PARAMS:                          func() Message { return new(TickReqParamsMsg) },
+	mMKT_DEPTH_EXCHANGES:                      func() Message { return new(MktDepthExchangesMsg) },
+	mHEAD_TIMESTAMP:                           func() Message { return new(HeadTimestampMsg) },
+	mTICK_NEWS:                                func() Message { return new(TickNewsMsg) },
+	mNEWS_PROVIDERS:                           func() Message { return new(NewsProvidersMsg) },
+	mNEWS_ARTICLE:                             func() Message { return new(NewsArticleMsg) },
+	mHISTORICAL_NEWS:                          func() Message { return new(HistoricalNewsMsg) },
+	mHISTORICAL_NEWS_END:                      func() Message { return new(HistoricalNewsEndMsg) },
+	mHISTOGRAM_DATA:                           func() Message { return new(HistogramDataMsg) },
+	mREROUTE_MKT_DATA_REQ:                     func() Message { return new(RerouteMktDataReqMsg) },
+	mREROUTE_MKT_DEPTH_REQ:                    func() Message { return new(RerouteMktDepthReqMsg) },
+	mMARKET_RULE:                              func() Message { return new(MarketRuleMsg) },
+	mPNL:                                      func() Message { return new(PnLMsg) },
+	mPNL_SINGLE:                               func() Message { return new(PnLSingleMsg) },
+	mHISTORICAL_TICKS:                         func() Message { return new(HistoricalTicksMsg) },
+	mHISTORICAL_TICKS_BID_ASK:                 func() Message { return new(HistoricalTicksBidAskMsg) },
+	mHISTORICAL_TICKS_LAST:                    func() Message { return new(HistoricalTicksLastMsg) },
+	mTICK_BY_TICK:                             func() Message { return new(TickByTickMsg) },
+	mORDER_BOUND:                              func() Message { return new(OrderBoundMsg) },
+	mCOMPLETED_ORDER:                          func() Message { return new(CompletedOrderMsg) },
+	mCOMPLETED_ORDERS_END:                     func() Message { return new(CompletedOrdersEndMsg) },
+	mREPLACE_FA_END:                           func() Message { return new(ReplaceFAEndMsg) },
+	mWSH_META_DATA:                            func() Message { return new(WshMetaDataMsg) },
+	mWSH_EVENT_DATA:                           func() Message { return new(WshEventDataMsg) },
+	mHISTORICAL_SCHEDULE:                      func() Message { return new(HistoricalScheduleMsg) },
+	mUSER_INFO:                                func() Message { return new(UserInfoMsg) },
+}
+
+// TickPriceMsg is the TICK_PRICE msg, which is dispatched to TickPrice and then TickSize of the size tick type if Size is set
+type TickPriceMsg struct {
+	ReqID    int64
+	TickType int64
+	Price    float64
+	Size     Decimal
+	Attrib   TickAttrib
+}
+
+func (m *TickPriceMsg) MsgID() IN { return mTICK_PRICE }
+
+func (m *TickPriceMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *TickPriceMsg) Dispatch(w IbWrapper) {
+	w.TickPrice(m.ReqID, m.TickType, m.Price, m.Attrib)
+
+	var sizeTickType int64
+	switch m.TickType {
+	case BID:
+		sizeTickType = BID_SIZE
+	case ASK:
+		sizeTickType = ASK_SIZE
+	case LAST:
+		sizeTickType = LAST_SIZE
+	case DELAYED_BID:
+		sizeTickType = DELAYED_BID_SIZE
+	case DELAYED_ASK:
+		sizeTickType = DELAYED_ASK_SIZE
+	case DELAYED_LAST:
+		sizeTickType = DELAYED_LAST_SIZE
+	default:
+		sizeTickType = NOT_SET
+	}
+
+	if sizeTickType != NOT_SET {
+		w.TickSize(m.ReqID, sizeTickType, m.Size)
+	}
+}
+
+func (m *TickPriceMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	tickType := msgBuf.readInt()
+	price := msgBuf.readFloat()
+	size := msgBuf.readDecimal()
+	attrMask := msgBuf.readInt()
+
+	attrib := TickAttrib{}
+	attrib.CanAutoExecute = attrMask == 1
+
+	if serverVersion >= mMIN_SERVER_VER_PAST_LIMIT {
+		attrib.CanAutoExecute = attrMask&0x1 != 0
+		attrib.PastLimit = attrMask&0x2 != 0
+		if serverVersion >= mMIN_SERVER_VER_PRE_OPEN_BID_ASK {
+			attrib.PreOpen = attrMask&0x4 != 0
+		}
+	}
+	*m = TickPriceMsg{ReqID: reqID, TickType: tickType, Price: price, Size: size, Attrib: attrib}
+}
+
+// TickSizeMsg is the TICK_SIZE msg, which is dispatched to TickSize
+type TickSizeMsg struct {
+	ReqID    int64
+	TickType int64
+	Size     Decimal
+}
+
+func (m *TickSizeMsg) MsgID() IN { return mTICK_SIZE }
+
+func (m *TickSizeMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *TickSizeMsg) Dispatch(w IbWrapper) {
+	w.TickSize(m.ReqID, m.TickType, m.Size)
+}
+
+func (m *TickSizeMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	tickType := msgBuf.readInt()
+	size := msgBuf.readDecimal()
+	*m = TickSizeMsg{ReqID: reqID, TickType: tickType, Size: size}
+}
+
+// OrderStatusMsg is the ORDER_STATUS msg, which is dispatched to OrderStatus
+type OrderStatusMsg struct {
+	OrderID       int64
+	Status        string
+	Filled        Decimal
+	Remaining     Decimal
+	AvgFillPrice  float64
+	PermID        int64
+	ParentID      int64
+	LastFillPrice float64
+	ClientID      int64
+	WhyHeld       string
+	MktCapPrice   float64
+}
+
+func (m *OrderStatusMsg) MsgID() IN { return mORDER_STATUS }
+
+func (m *OrderStatusMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *OrderStatusMsg) Dispatch(w IbWrapper) {
+	w.OrderStatus(m.OrderID, m.Status, m.Filled, m.Remaining, m.AvgFillPrice, m.PermID, m.ParentID, m.LastFillPrice, m.ClientID, m.WhyHeld, m.MktCapPrice)
+}
+
+func (m *OrderStatusMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	if serverVersion < mMIN_SERVER_VER_MARKET_CAP_PRICE {
+		_ = msgBuf.readString()
+	}
+	orderID := msgBuf.readInt()
+	status := msgBuf.readString()
+
+	filled := msgBuf.readDecimal()
+
+	remaining := msgBuf.readDecimal()
+
+	avgFilledPrice := msgBuf.readFloat()
+
+	permID := msgBuf.readInt()
+	parentID := msgBuf.readInt()
+	lastFillPrice := msgBuf.readFloat()
+	clientID := msgBuf.readInt()
+	whyHeld := msgBuf.readString()
+
+	mktCapPrice := 0.0
+	if serverVersion >= mMIN_SERVER_VER_MARKET_CAP_PRICE {
+		mktCapPrice = msgBuf.readFloat()
+	}
+	*m = OrderStatusMsg{
+		OrderID:       orderID,
+		Status:        status,
+		Filled:        filled,
+		Remaining:     remaining,
+		AvgFillPrice:  avgFilledPrice,
+		PermID:        permID,
+		ParentID:      parentID,
+		LastFillPrice: lastFillPrice,
+		ClientID:      clientID,
+		WhyHeld:       whyHeld,
+		MktCapPrice:   mktCapPrice,
+	}
+}
+
+// ErrorMsg is the ERR_MSG msg, which is dispatched to Error,
+// or ErrorWithAdvancedOrderReject if there is AdvancedOrderRejectJSON and the wrapper is an AdvancedOrderRejecter
+type ErrorMsg struct {
+	ReqID                   int64
+	ErrCode                 int64
+	ErrString               string
+	AdvancedOrderRejectJSON string
+}
+
+func (m *ErrorMsg) MsgID() IN { return mERR_MSG }
+
+func (m *ErrorMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *ErrorMsg) Dispatch(w IbWrapper) {
+	if r, ok := w.(AdvancedOrderRejecter); ok && m.AdvancedOrderRejectJSON != "" {
+		r.ErrorWithAdvancedOrderReject(m.ReqID, m.ErrCode, m.ErrString, m.AdvancedOrderRejectJSON)
+		return
+	}
+	w.Error(m.ReqID, m.ErrCode, m.ErrString)
+}
+
+func (m *ErrorMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	errorCode := msgBuf.readInt()
+	errorString := msgBuf.readString()
+
+	var advancedOrderRejectJSON string
+	if serverVersion >= mMIN_SERVER_VER_ADVANCED_ORDER_REJECT {
+		advancedOrderRejectJSON = msgBuf.readString()
+	}
+	*m = ErrorMsg{ReqID: reqID, ErrCode: errorCode, ErrString: errorString, AdvancedOrderRejectJSON: advancedOrderRejectJSON}
+}
+
+// OpenOrderMsg is the OPEN_ORDER msg, which is dispatched to OpenOrder
+type OpenOrderMsg struct {
+	OrderID    int64
+	Contract   *Contract
+	Order      *Order
+	OrderState *OrderState
+}
+
+func (m *OpenOrderMsg) MsgID() IN { return mOPEN_ORDER }
+
+func (m *OpenOrderMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *OpenOrderMsg) Dispatch(w IbWrapper) {
+	w.OpenOrder(m.OrderID, m.Contract, m.Order, m.OrderState)
+}
+
+func (m *OpenOrderMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+
+	var version int64
+	if serverVersion < mMIN_SERVER_VER_ORDER_CONTAINER {
+		version = msgBuf.readInt()
+	} else {
+		version = int64(serverVersion)
+	}
+
+	o := &Order{}
+	o.OrderID = msgBuf.readInt()
+
+	c := &Contract{}
+
+	// read contract fields
+	c.ContractID = msgBuf.readInt()
+	c.Symbol = msgBuf.readString()
+	c.SecurityType = msgBuf.readString()
+	c.Expiry = msgBuf.readString()
+	c.Strike = msgBuf.readFloat()
+	c.Right = msgBuf.readString()
+	if version >= 32 {
+		c.Multiplier = msgBuf.readString()
+	}
+	c.Exchange = msgBuf.readString()
+	c.Currency = msgBuf.readString()
+	c.LocalSymbol = msgBuf.readString()
+	if version >= 32 {
+		c.TradingClass = msgBuf.readString()
+	}
+
+	// read order fields
+	o.Action = msgBuf.readString()
+	o.TotalQuantity = msgBuf.readDecimal()
+	o.OrderType = msgBuf.readString()
+	if version < 29 {
+		o.LimitPrice = msgBuf.readFloat()
+	} else {
+		o.LimitPrice = msgBuf.readFloatCheckUnset()
+	}
+	if version < 30 {
+		o.AuxPrice = msgBuf.readFloat()
+	} else {
+		o.AuxPrice = msgBuf.readFloatCheckUnset()
+	}
+	o.TIF = msgBuf.readString()
+	o.OCAGroup = msgBuf.readString()
+	o.Account = msgBuf.readString()
+	o.OpenClose = msgBuf.readString()
+	o.Origin = msgBuf.readInt()
+	o.OrderRef = msgBuf.readString()
+	o.ClientID = msgBuf.readInt()
+	o.PermID = msgBuf.readInt()
+	o.OutsideRTH = msgBuf.readBool()
+	o.Hidden = msgBuf.readBool()
+	o.DiscretionaryAmount = msgBuf.readFloat()
+	o.GoodAfterTime = msgBuf.readString()
+	_ = msgBuf.readString() // skip sharesAllocation
+
+	// FAParams
+	o.FAGroup = msgBuf.readString()
+	o.FAMethod = msgBuf.readString()
+	o.FAPercentage = msgBuf.readString()
+	o.FAProfile = msgBuf.readString()
+	// ---------
+	if serverVersion >= mMIN_SERVER_VER_MODELS_SUPPORT {
+		o.ModelCode = msgBuf.readString()
+	}
+	o.GoodTillDate = msgBuf.readString()
+	o.Rule80A = msgBuf.readString()
+	o.PercentOffset = msgBuf.readFloatCheckUnset() //show_unset
+	o.SettlingFirm = msgBuf.readString()
+
+	// ShortSaleParams
+	o.ShortSaleSlot = msgBuf.readInt()
+	o.DesignatedLocation = msgBuf.readString()
+	if serverVersion == mMIN_SERVER_VER_SSHORTX_OLD {
+		_ = msgBuf.readString()
+	} else if version >= 23 {
+		o.ExemptCode = msgBuf.readInt()
+	}
+	// ----------
+	o.AuctionStrategy = msgBuf.readInt()
+
+	// BoxOrderParams
+	o.StartingPrice = msgBuf.readFloatCheckUnset() //show_unset
+	o.StockRefPrice = msgBuf.readFloatCheckUnset() //show_unset
+	o.Delta = msgBuf.readFloatCheckUnset()         //show_unset
+	// ----------
+
+	// PegToStkOrVolOrderParams
+	o.StockRangeLower = msgBuf.readFloatCheckUnset() //show_unset
+	o.StockRangeUpper = msgBuf.readFloatCheckUnset() //show_unset
+	// ----------
+
+	o.DisplaySize = msgBuf.readInt()
+	o.BlockOrder = msgBuf.readBool()
+	o.SweepToFill = msgBuf.readBool()
+	o.AllOrNone = msgBuf.readBool()
+	o.MinQty = msgBuf.readIntCheckUnset() //show_unset
+	o.OCAType = msgBuf.readInt()
+	o.ETradeOnly = msgBuf.readBool()
+	o.FirmQuoteOnly = msgBuf.readBool()
+	o.NBBOPriceCap = msgBuf.readFloatCheckUnset() //show_unset
+	o.ParentID = msgBuf.readInt()
+	o.TriggerMethod = msgBuf.readInt()
+
+	// VolOrderParams
+	o.Volatility = msgBuf.readFloatCheckUnset() //show_unset
+	o.VolatilityType = msgBuf.readInt()
+	o.DeltaNeutralOrderType = msgBuf.readString()
+	o.DeltaNeutralAuxPrice = msgBuf.readFloatCheckUnset() //show_unset
+	if version >= 27 && o.DeltaNeutralOrderType != "" {
+		o.DeltaNeutralContractID = msgBuf.readInt()
+		o.DeltaNeutralSettlingFirm = msgBuf.readString()
+		o.DeltaNeutralClearingAccount = msgBuf.readString()
+		o.DeltaNeutralClearingIntent = msgBuf.readString()
+	}
+	if version >= 31 && o.DeltaNeutralOrderType != "" {
+		o.DeltaNeutralOpenClose = msgBuf.readString()
+		o.DeltaNeutralShortSale = msgBuf.readBool()
+		o.DeltaNeutralShortSaleSlot = msgBuf.readInt()
+		o.DeltaNeutralDesignatedLocation = msgBuf.readString()
+	}
+	o.ContinuousUpdate = msgBuf.readBool()
+	o.ReferencePriceType = msgBuf.readInt()
+	// ---------
+
+	// TrailParams
+	o.TrailStopPrice = msgBuf.readFloatCheckUnset()
+	if version >= 30 {
+		o.TrailingPercent = msgBuf.readFloatCheckUnset() //show_unset
+	}
+	// ----------
+
+	// BasisPoints
+	o.BasisPoints = msgBuf.readFloatCheckUnset()
+	o.BasisPointsType = msgBuf.readIntCheckUnset()
+	// ----------
+
+	// ComboLegs
+	c.ComboLegsDescription = msgBuf.readString()
+	if version >= 29 {
+		{
+			n := msgBuf.readInt()
+			c.ComboLegs = make([]ComboLeg, 0, msgBuf.capacity(n))
+			for ; n > 0 && msgBuf.err == nil; n-- {
+				comboleg := ComboLeg{}
+				comboleg.ContractID = msgBuf.readInt()
+				comboleg.Ratio = msgBuf.readInt()
+				comboleg.Action = msgBuf.readString()
+				comboleg.Exchange = msgBuf.readString()
+				comboleg.OpenClose = msgBuf.readInt()
+				comboleg.ShortSaleSlot = msgBuf.readInt()
+				comboleg.DesignatedLocation = msgBuf.readString()
+				comboleg.ExemptCode = msgBuf.readInt()
+				c.ComboLegs = append(c.ComboLegs, comboleg)
+			}
+		}
+
+		{
+			n := msgBuf.readInt()
+			o.OrderComboLegs = make([]OrderComboLeg, 0, msgBuf.capacity(n))
+			for ; n > 0 && msgBuf.err == nil; n-- {
+				orderComboLeg := OrderComboLeg{}
+				orderComboLeg.Price = msgBuf.readFloatCheckUnset()
+				o.OrderComboLegs = append(o.OrderComboLegs, orderComboLeg)
+			}
+		}
+
+	}
+	if version >= 26 {
+		n := msgBuf.readInt()
+		o.SmartComboRoutingParams = make([]TagValue, 0, msgBuf.capacity(n))
+		for ; n > 0 && msgBuf.err == nil; n-- {
+			tagValue := TagValue{}
+			tagValue.Tag = msgBuf.readString()
+			tagValue.Value = msgBuf.readString()
+			o.SmartComboRoutingParams = append(o.SmartComboRoutingParams, tagValue)
+		}
+	}
+	// ----------
+
+	// ScaleOrderParams
+	if version >= 20 {
+		o.ScaleInitLevelSize = msgBuf.readIntCheckUnset() //show_unset
+		o.ScaleSubsLevelSize = msgBuf.readIntCheckUnset() //show_unset
+	} else {
+		o.NotSuppScaleNumComponents = msgBuf.readIntCheckUnset()
+		o.ScaleInitLevelSize = msgBuf.readIntCheckUnset()
+	}
+	o.ScalePriceIncrement = msgBuf.readFloatCheckUnset()
+	if version >= 28 && o.ScalePriceIncrement != UNSETFLOAT && o.ScalePriceIncrement > 0.0 {
+		o.ScalePriceAdjustValue = msgBuf.readFloatCheckUnset()
+		o.ScalePriceAdjustInterval = msgBuf.readIntCheckUnset()
+		o.ScaleProfitOffset = msgBuf.readFloatCheckUnset()
+		o.ScaleAutoReset = msgBuf.readBool()
+		o.ScaleInitPosition = msgBuf.readIntCheckUnset()
+		o.ScaleInitFillQty = msgBuf.readIntCheckUnset()
+		o.ScaleRandomPercent = msgBuf.readBool()
+	}
+	// ----------
+
+	if version >= 24 {
+		o.HedgeType = msgBuf.readString()
+		if o.HedgeType != "" {
+			o.HedgeParam = msgBuf.readString()
+		}
+	}
+
+	if version >= 25 {
+		o.OptOutSmartRouting = msgBuf.readBool()
+	}
+
+	// ClearingParams
+	o.ClearingAccount = msgBuf.readString()
+	o.ClearingIntent = msgBuf.readString()
+	// ----------
+
+	if version >= 22 {
+		o.NotHeld = msgBuf.readBool()
+	}
+
+	// DeltaNeutral
+	if version >= 20 {
+		deltaNeutralContractPresent := msgBuf.readBool()
+		if deltaNeutralContractPresent {
+			c.DeltaNeutralContract = new(DeltaNeutralContract)
+			c.DeltaNeutralContract.ContractID = msgBuf.readInt()
+			c.DeltaNeutralContract.Delta = msgBuf.readFloat()
+			c.DeltaNeutralContract.Price = msgBuf.readFloat()
+		}
+	}
+	// ----------
+
+	// AlgoParams
+	if version >= 21 {
+		o.AlgoStrategy = msgBuf.readString()
+		if o.AlgoStrategy != "" {
+			n := msgBuf.readInt()
+			o.AlgoParams = make([]TagValue, 0, msgBuf.capacity(n))
+			for ; n > 0 && msgBuf.err == nil; n-- {
+				tagValue := TagValue{}
+				tagValue.Tag = msgBuf.readString()
+				tagValue.Value = msgBuf.readString()
+				o.AlgoParams = append(o.AlgoParams, tagValue)
+			}
+		}
+	}
+	// ----------
+
+	if version >= 33 {
+		o.Solictied = msgBuf.readBool()
+	}
+
+	orderState := &OrderState{}
+
+	// WhatIfInfoAndCommission
+	o.WhatIf = msgBuf.readBool()
+	orderState.Status = msgBuf.readString()
+	if serverVersion >= mMIN_SERVER_VER_WHAT_IF_EXT_FIELDS {
+		orderState.InitialMarginBefore = msgBuf.readString()
+		orderState.MaintenanceMarginBefore = msgBuf.readString()
+		orderState.EquityWithLoanBefore = msgBuf.readString()
+		orderState.InitialMarginChange = msgBuf.readString()
+		orderState.MaintenanceMarginChange = msgBuf.readString()
+		orderState.EquityWithLoanChange = msgBuf.readString()
+	}
+
+	orderState.InitialMarginAfter = msgBuf.readString()
+	orderState.MaintenanceMarginAfter = msgBuf.readString()
+	orderState.EquityWithLoanAfter = msgBuf.readString()
+
+	orderState.Commission = msgBuf.readFloatCheckUnset()
+	orderState.MinCommission = msgBuf.readFloatCheckUnset()
+	orderState.MaxCommission = msgBuf.readFloatCheckUnset()
+	orderState.CommissionCurrency = msgBuf.readString()
+	orderState.WarningText = msgBuf.readString()
+	// ----------
+
+	// VolRandomizeFlags
+	if version >= 34 {
+		o.RandomizeSize = msgBuf.readBool()
+		o.RandomizePrice = msgBuf.readBool()
+	}
+	// ----------
+
+	if serverVersion >= mMIN_SERVER_VER_PEGGED_TO_BENCHMARK {
+		// PegToBenchParams
+		if o.OrderType == "PEG BENCH" {
+			o.ReferenceContractID = msgBuf.readInt()
+			o.IsPeggedChangeAmountDecrease = msgBuf.readBool()
+			o.PeggedChangeAmount = msgBuf.readFloat()
+			o.ReferenceChangeAmount = msgBuf.readFloat()
+			o.ReferenceExchangeID = msgBuf.readString()
+		}
+		// ----------
+
+		// Conditions
+		n := msgBuf.readInt()
+		o.Conditions = make([]OrderConditioner, 0, msgBuf.capacity(n))
+		if n > 0 {
+			for ; n > 0 && msgBuf.err == nil; n-- {
+				conditionType := msgBuf.readInt()
+				cond, _ := InitOrderCondition(conditionType)
+				cond.decode(msgBuf)
+
+				o.Conditions = append(o.Conditions, cond)
+			}
+			o.ConditionsIgnoreRth = msgBuf.readBool()
+			o.ConditionsCancelOrder = msgBuf.readBool()
+		}
+		// ----------
+
+		// AdjustedOrderParams
+		o.AdjustedOrderType = msgBuf.readString()
+		o.TriggerPrice = msgBuf.readFloat()
+		o.TrailStopPrice = msgBuf.readFloat()
+		o.LimitPriceOffset = msgBuf.readFloat()
+		o.AdjustedStopPrice = msgBuf.readFloat()
+		o.AdjustedStopLimitPrice = msgBuf.readFloat()
+		o.AdjustedTrailingAmount = msgBuf.readFloat()
+		o.AdjustableTrailingUnit = msgBuf.readInt()
+		// ----------
+	}
+
+	// SoftDollarTier
+	if serverVersion >= mMIN_SERVER_VER_SOFT_DOLLAR_TIER {
+		name := msgBuf.readString()
+		value := msgBuf.readString()
+		displayName := msgBuf.readString()
+		o.SoftDollarTier = SoftDollarTier{name, value, displayName}
+	}
+	// ----------
+
+	if serverVersion >= mMIN_SERVER_VER_CASH_QTY {
+		o.CashQty = msgBuf.readFloat()
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_AUTO_PRICE_FOR_HEDGE {
+		o.DontUseAutoPriceForHedge = msgBuf.readBool()
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_ORDER_CONTAINER {
+		o.IsOmsContainer = msgBuf.readBool()
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_D_PEG_ORDERS {
+		o.DiscretionaryUpToLimitPrice = msgBuf.readBool()
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_PRICE_MGMT_ALGO {
+		o.UsePriceMgmtAlgo = msgBuf.readBool()
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_DURATION {
+		o.Duration = msgBuf.readIntCheckUnset()
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_POST_TO_ATS {
+		o.PostToAts = msgBuf.readIntCheckUnset()
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_AUTO_CANCEL_PARENT {
+		o.AutoCancelParent = msgBuf.readBool()
+	}
+
+	decodePegBestPegMidOrderAttributes(serverVersion, msgBuf, o)
+	*m = OpenOrderMsg{
+		OrderID:    o.OrderID,
+		Contract:   c,
+		Order:      o,
+		OrderState: orderState,
+	}
+}
+
+// decodePegBestPegMidOrderAttributes decodes the attributes of PEG BEST and PEG MID orders at the end of OPEN_ORDER and COMPLETED_ORDER
+func decodePegBestPegMidOrderAttributes(serverVersion Version, msgBuf *MsgBuffer, o *Order) {
+	if serverVersion >= mMIN_SERVER_VER_PEGBEST_PEGMID_OFFSETS {
+		o.MinTradeQty = msgBuf.readIntCheckUnset()
+		o.MinCompeteSize = msgBuf.readIntCheckUnset()
+		o.CompeteAgainstBestOffset = msgBuf.readFloatCheckUnset()
+		o.MidOffsetAtWhole = msgBuf.readFloatCheckUnset()
+		o.MidOffsetAtHalf = msgBuf.readFloatCheckUnset()
+	}
+}
+
+// AcctValueMsg is the ACCT_VALUE msg, which is dispatched to UpdateAccountValue
+type AcctValueMsg struct {
+	Tag      string
+	Val      string
+	Currency string
+	AccName  string
+}
+
+func (m *AcctValueMsg) MsgID() IN { return mACCT_VALUE }
+
+func (m *AcctValueMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *AcctValueMsg) Dispatch(w IbWrapper) {
+	w.UpdateAccountValue(m.Tag, m.Val, m.Currency, m.AccName)
+}
+
+func (m *AcctValueMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	tag := msgBuf.readString()
+	val := msgBuf.readString()
+	currency := msgBuf.readString()
+	accName := msgBuf.readString()
+	*m = AcctValueMsg{
+		Tag:      tag,
+		Val:      val,
+		Currency: currency,
+		AccName:  accName,
+	}
+}
+
+// PortfolioValueMsg is the PORTFOLIO_VALUE msg, which is dispatched to UpdatePortfolio
+type PortfolioValueMsg struct {
+	Contract      *Contract
+	Position      Decimal
+	MarketPrice   float64
+	MarketValue   float64
+	AverageCost   float64
+	UnrealizedPNL float64
+	RealizedPNL   float64
+	AccName       string
+}
+
+func (m *PortfolioValueMsg) MsgID() IN { return mPORTFOLIO_VALUE }
+
+func (m *PortfolioValueMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *PortfolioValueMsg) Dispatch(w IbWrapper) {
+	w.UpdatePortfolio(m.Contract, m.Position, m.MarketPrice, m.MarketValue, m.AverageCost, m.UnrealizedPNL, m.RealizedPNL, m.AccName)
+}
+
+func (m *PortfolioValueMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	v := msgBuf.readInt()
+
+	c := &Contract{}
+	c.ContractID = msgBuf.readInt()
+	c.Symbol = msgBuf.readString()
+	c.SecurityType = msgBuf.readString()
+	c.Expiry = msgBuf.readString()
+	c.Strike = msgBuf.readFloat()
+	c.Right = msgBuf.readString()
+	if v >= 7 {
+		c.Multiplier = msgBuf.readString()
+		c.PrimaryExchange = msgBuf.readString()
+	}
+	c.Currency = msgBuf.readString()
+	c.LocalSymbol = msgBuf.readString()
+	if v >= 8 {
+		c.TradingClass = msgBuf.readString()
+	}
+	position := msgBuf.readDecimal()
+	marketPrice := msgBuf.readFloat()
+	marketValue := msgBuf.readFloat()
+	averageCost := msgBuf.readFloat()
+	unrealizedPNL := msgBuf.readFloat()
+	realizedPNL := msgBuf.readFloat()
+	accName := msgBuf.readString()
+	if v == 6 && serverVersion == 39 {
+		c.PrimaryExchange = msgBuf.readString()
+	}
+	*m = PortfolioValueMsg{
+		Contract:      c,
+		Position:      position,
+		MarketPrice:   marketPrice,
+		MarketValue:   marketValue,
+		AverageCost:   averageCost,
+		UnrealizedPNL: unrealizedPNL,
+		RealizedPNL:   realizedPNL,
+		AccName:       accName,
+	}
+}
+
+// AcctUpdateTimeMsg is the ACCT_UPDATE_TIME msg, which is dispatched to UpdateAccountTime
+type AcctUpdateTimeMsg struct {
+	Time time.Time
+}
+
+func (m *AcctUpdateTimeMsg) MsgID() IN { return mACCT_UPDATE_TIME }
+
+func (m *AcctUpdateTimeMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *AcctUpdateTimeMsg) Dispatch(w IbWrapper) {
+	w.UpdateAccountTime(m.Time)
+}
+
+func (m *AcctUpdateTimeMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	ts := msgBuf.readString()
+	// the time stamp is hh:mm in the time zone of TWS login
+	hm, err := time.Parse("15:04", ts)
+	if err != nil {
+		log.Error("failed to parse account time", zap.String("time", ts), zap.Error(err))
+	}
+	today := time.Now()
+	t := time.Date(today.Year(), today.Month(), today.Day(), hm.Hour(), hm.Minute(), 0, 0, time.Local)
+	*m = AcctUpdateTimeMsg{Time: t}
+}
+
+// NextValidIDMsg is the NEXT_VALID_ID msg, which is dispatched to NextValidID
+type NextValidIDMsg struct {
+	ReqID int64
+}
+
+func (m *NextValidIDMsg) MsgID() IN { return mNEXT_VALID_ID }
+
+func (m *NextValidIDMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *NextValidIDMsg) Dispatch(w IbWrapper) {
+	w.NextValidID(m.ReqID)
+}
+
+func (m *NextValidIDMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	*m = NextValidIDMsg{ReqID: reqID}
+}
+
+// ContractDataMsg is the CONTRACT_DATA msg, which is dispatched to ContractDetails
+type ContractDataMsg struct {
+	ReqID           int64
+	ContractDetails *ContractDetails
+}
+
+func (m *ContractDataMsg) MsgID() IN { return mCONTRACT_DATA }
+
+func (m *ContractDataMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *ContractDataMsg) Dispatch(w IbWrapper) {
+	w.ContractDetails(m.ReqID, m.ContractDetails)
+}
+
+func (m *ContractDataMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	v := msgBuf.readInt()
+	var reqID int64 = -1
+	if v >= 3 {
+		reqID = msgBuf.readInt()
+	}
+
+	cd := ContractDetails{}
+	cd.Contract = Contract{}
+	cd.Contract.Symbol = msgBuf.readString()
+	cd.Contract.SecurityType = msgBuf.readString()
+
+	lastTradeDateOrContractMonth := msgBuf.readString()
+	if lastTradeDateOrContractMonth != "" {
+		splitted := strings.Split(lastTradeDateOrContractMonth, " ")
+		l := len(splitted)
+
+		if l > 0 {
+			cd.Contract.Expiry = splitted[0]
+		}
+		if l > 1 {
+			cd.LastTradeTime = splitted[1]
+		}
+	}
+
+	cd.Contract.Strike = msgBuf.readFloat()
+	cd.Contract.Right = msgBuf.readString()
+	cd.Contract.Exchange = msgBuf.readString()
+	cd.Contract.Currency = msgBuf.readString()
+	cd.Contract.LocalSymbol = msgBuf.readString()
+	cd.MarketName = msgBuf.readString()
+	cd.Contract.TradingClass = msgBuf.readString()
+	cd.Contract.ContractID = msgBuf.readInt()
+	cd.MinTick = msgBuf.readFloat()
+	if serverVersion >= mMIN_SERVER_VER_MD_SIZE_MULTIPLIER && serverVersion < mMIN_SERVER_VER_SIZE_RULES {
+		cd.MdSizeMultiplier = msgBuf.readInt()
+	}
+	cd.Contract.Multiplier = msgBuf.readString()
+	cd.OrderTypes = msgBuf.readString()
+	cd.ValidExchanges = msgBuf.readString()
+	cd.PriceMagnifier = msgBuf.readInt()
+	if v >= 4 {
+		cd.UnderContractID = msgBuf.readInt()
+	}
+	if v >= 5 {
+		if serverVersion >= mMIN_SERVER_VER_ENCODE_MSG_ASCII7 {
+			cd.LongName = msgBuf.readString() // FIXME: unicode-escape
+		} else {
+			cd.LongName = msgBuf.readString()
+		}
+
+		cd.Contract.PrimaryExchange = msgBuf.readString()
+	}
+	if v >= 6 {
+		cd.ContractMonth = msgBuf.readString()
+		cd.Industry = msgBuf.readString()
+		cd.Category = msgBuf.readString()
+		cd.Subcategory = msgBuf.readString()
+		cd.TimezoneID = msgBuf.readString()
+		cd.TradingHours = msgBuf.readString()
+		cd.LiquidHours = msgBuf.readString()
+	}
+	if v >= 8 {
+		cd.EVRule = msgBuf.readString()
+		cd.EVMultiplier = msgBuf.readInt()
+	}
+	if v >= 7 {
+		n := msgBuf.readInt()
+		cd.SecurityIDList = make([]TagValue, 0, msgBuf.capacity(n))
+		for ; n > 0 && msgBuf.err == nil; n-- {
+			tagValue := TagValue{}
+			tagValue.Tag = msgBuf.readString()
+			tagValue.Value = msgBuf.readString()
+			cd.SecurityIDList = append(cd.SecurityIDList, tagValue)
+		}
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_AGG_GROUP {
+		cd.AggGroup = msgBuf.readInt()
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_UNDERLYING_INFO {
+		cd.UnderSymbol = msgBuf.readString()
+		cd.UnderSecurityType = msgBuf.readString()
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_MARKET_RULES {
+		cd.MarketRuleIDs = msgBuf.readString()
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_REAL_EXPIRATION_DATE {
+		cd.RealExpirationDate = msgBuf.readString()
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_STOCK_TYPE {
+		cd.StockType = msgBuf.readString()
+	}
+
+	if serverVersion == mMIN_SERVER_VER_FRACTIONAL_SIZE_SUPPORT {
+		_ = msgBuf.readDecimal() // sizeMinTick, replaced by the size rules
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_SIZE_RULES {
+		cd.MinSize = msgBuf.readDecimal()
+		cd.SizeIncrement = msgBuf.readDecimal()
+		cd.SuggestedSizeIncrement = msgBuf.readDecimal()
+	}
+	*m = ContractDataMsg{ReqID: reqID, ContractDetails: &cd}
+}
+
+// ExecutionDataMsg is the EXECUTION_DATA msg, which is dispatched to ExecDetails
+type ExecutionDataMsg struct {
+	ReqID     int64
+	Contract  *Contract
+	Execution *Execution
+}
+
+func (m *ExecutionDataMsg) MsgID() IN { return mEXECUTION_DATA }
+
+func (m *ExecutionDataMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *ExecutionDataMsg) Dispatch(w IbWrapper) {
+	w.ExecDetails(m.ReqID, m.Contract, m.Execution)
+}
+
+func (m *ExecutionDataMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	var v int64
+	if serverVersion < mMIN_SERVER_VER_LAST_LIQUIDITY {
+		v = msgBuf.readInt()
+	} else {
+		v = int64(serverVersion)
+	}
+
+	var reqID int64 = -1
+	if v >= 7 {
+		reqID = msgBuf.readInt()
+	}
+
+	orderID := msgBuf.readInt()
+
+	// read contact fields
+	c := Contract{}
+	c.ContractID = msgBuf.readInt()
+	c.Symbol = msgBuf.readString()
+	c.SecurityType = msgBuf.readString()
+	c.Expiry = msgBuf.readString()
+	c.Strike = msgBuf.readFloat()
+	c.Right = msgBuf.readString()
+	if v >= 9 {
+		c.Multiplier = msgBuf.readString()
+	}
+	c.Exchange = msgBuf.readString()
+	c.Currency = msgBuf.readString()
+	c.LocalSymbol = msgBuf.readString()
+	if v >= 10 {
+		c.TradingClass = msgBuf.readString()
+	}
+
+	// read execution fields
+	e := Execution{}
+	e.OrderID = orderID
+	e.ExecID = msgBuf.readString()
+	e.Time = msgBuf.readString()
+	e.AccountCode = msgBuf.readString()
+	e.Exchange = msgBuf.readString()
+	e.Side = msgBuf.readString()
+	e.Shares = msgBuf.readDecimal()
+	e.Price = msgBuf.readFloat()
+	e.PermID = msgBuf.readInt()
+	e.ClientID = msgBuf.readInt()
+	e.Liquidation = msgBuf.readInt()
+	if v >= 6 {
+		e.CumQty = msgBuf.readDecimal()
+		e.AveragePrice = msgBuf.readFloat()
+	}
+	if v >= 8 {
+		e.OrderRef = msgBuf.readString()
+	}
+	if v >= 9 {
+		e.EVRule = msgBuf.readString()
+		e.EVMultiplier = msgBuf.readFloat()
+	}
+	if serverVersion >= mMIN_SERVER_VER_MODELS_SUPPORT {
+		e.ModelCode = msgBuf.readString()
+	}
+	if serverVersion >= mMIN_SERVER_VER_LAST_LIQUIDITY {
+		e.LastLiquidity = msgBuf.readInt()
+	}
+	*m = ExecutionDataMsg{ReqID: reqID, Contract: &c, Execution: &e}
+}
+
+// MarketDepthMsg is the MARKET_DEPTH msg, which is dispatched to UpdateMktDepth
+type MarketDepthMsg struct {
+	ReqID     int64
+	Position  int64
+	Operation int64
+	Side      int64
+	Price     float64
+	Size      Decimal
+}
+
+func (m *MarketDepthMsg) MsgID() IN { return mMARKET_DEPTH }
+
+func (m *MarketDepthMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *MarketDepthMsg) Dispatch(w IbWrapper) {
+	w.UpdateMktDepth(m.ReqID, m.Position, m.Operation, m.Side, m.Price, m.Size)
+}
+
+func (m *MarketDepthMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	position := msgBuf.readInt()
+	operation := msgBuf.readInt()
+	side := msgBuf.readInt()
+	price := msgBuf.readFloat()
+	size := msgBuf.readDecimal()
+	*m = MarketDepthMsg{
+		ReqID:     reqID,
+		Position:  position,
+		Operation: operation,
+		Side:      side,
+		Price:     price,
+		Size:      size,
+	}
+}
+
+// MarketDepthL2Msg is the MARKET_DEPTH_L2 msg, which is dispatched to UpdateMktDepthL2
+type MarketDepthL2Msg struct {
+	ReqID        int64
+	Position     int64
+	MarketMaker  string
+	Operation    int64
+	Side         int64
+	Price        float64
+	Size         Decimal
+	IsSmartDepth bool
+}
+
+func (m *MarketDepthL2Msg) MsgID() IN { return mMARKET_DEPTH_L2 }
+
+func (m *MarketDepthL2Msg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *MarketDepthL2Msg) Dispatch(w IbWrapper) {
+	w.UpdateMktDepthL2(m.ReqID, m.Position, m.MarketMaker, m.Operation, m.Side, m.Price, m.Size, m.IsSmartDepth)
+}
+
+func (m *MarketDepthL2Msg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	position := msgBuf.readInt()
+	marketMaker := msgBuf.readString()
+	operation := msgBuf.readInt()
+	side := msgBuf.readInt()
+	price := msgBuf.readFloat()
+	size := msgBuf.readDecimal()
+	isSmartDepth := msgBuf.readBool()
+	*m = MarketDepthL2Msg{
+		ReqID:        reqID,
+		Position:     position,
+		MarketMaker:  marketMaker,
+		Operation:    operation,
+		Side:         side,
+		Price:        price,
+		Size:         size,
+		IsSmartDepth: isSmartDepth,
+	}
+}
+
+// NewsBulletinsMsg is the NEWS_BULLETINS msg, which is dispatched to UpdateNewsBulletin
+type NewsBulletinsMsg struct {
+	BulletinID     int64
+	MsgType        int64
+	NewsMessage    string
+	OriginExchange string
+}
+
+func (m *NewsBulletinsMsg) MsgID() IN { return mNEWS_BULLETINS }
+
+func (m *NewsBulletinsMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *NewsBulletinsMsg) Dispatch(w IbWrapper) {
+	w.UpdateNewsBulletin(m.BulletinID, m.MsgType, m.NewsMessage, m.OriginExchange)
+}
+
+func (m *NewsBulletinsMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	msgID := msgBuf.readInt()
+	msgType := msgBuf.readInt()
+	newsMessage := msgBuf.readString()
+	originExch := msgBuf.readString()
+	*m = NewsBulletinsMsg{
+		BulletinID:     msgID,
+		MsgType:        msgType,
+		NewsMessage:    newsMessage,
+		OriginExchange: originExch,
+	}
+}
+
+// ManagedAcctsMsg is the MANAGED_ACCTS msg, which is dispatched to ManagedAccounts
+type ManagedAcctsMsg struct {
+	AccountsList []string
+}
+
+func (m *ManagedAcctsMsg) MsgID() IN { return mMANAGED_ACCTS }
+
+func (m *ManagedAcctsMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *ManagedAcctsMsg) Dispatch(w IbWrapper) {
+	w.ManagedAccounts(m.AccountsList)
+}
+
+func (m *ManagedAcctsMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	accNames := msgBuf.readString()
+	accsList := strings.Split(accNames, ",")
+	*m = ManagedAcctsMsg{AccountsList: accsList}
+}
+
+// ReceiveFAMsg is the RECEIVE_FA msg, which is dispatched to ReceiveFA
+type ReceiveFAMsg struct {
+	FaData int64
+	Cxml   string
+}
+
+func (m *ReceiveFAMsg) MsgID() IN { return mRECEIVE_FA }
+
+func (m *ReceiveFAMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *ReceiveFAMsg) Dispatch(w IbWrapper) {
+	w.ReceiveFA(m.FaData, m.Cxml)
+}
+
+func (m *ReceiveFAMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	faData := msgBuf.readInt()
+	cxml := msgBuf.readString()
+	*m = ReceiveFAMsg{FaData: faData, Cxml: cxml}
+}
+
+// HistoricalDataMsg is the HISTORICAL_DATA msg, which is dispatched to HistoricalData bar by bar and then HistoricalDataEnd
+type HistoricalDataMsg struct {
+	ReqID        int64
+	StartDateStr string
+	EndDateStr   string
+	Bars         []*BarData
+}
+
+func (m *HistoricalDataMsg) MsgID() IN { return mHISTORICAL_DATA }
+
+func (m *HistoricalDataMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *HistoricalDataMsg) Dispatch(w IbWrapper) {
+	for _, bar := range m.Bars {
+		w.HistoricalData(m.ReqID, bar)
+	}
+	w.HistoricalDataEnd(m.ReqID, m.StartDateStr, m.EndDateStr)
+}
+
+func (m *HistoricalDataMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	if serverVersion < mMIN_SERVER_VER_SYNT_REALTIME_BARS {
+		_ = msgBuf.readString()
+	}
+
+	reqID := msgBuf.readInt()
+	startDateStr := msgBuf.readString()
+	endDateStr := msgBuf.readString()
+
+	n := msgBuf.readInt()
+	bars := make([]*BarData, 0, msgBuf.capacity(n))
+	for ; n > 0 && msgBuf.err == nil; n-- {
+		bar := &BarData{}
+		bar.Date = msgBuf.readString()
+		bar.Open = msgBuf.readFloat()
+		bar.High = msgBuf.readFloat()
+		bar.Low = msgBuf.readFloat()
+		bar.Close = msgBuf.readFloat()
+		bar.Volume = msgBuf.readDecimal()
+		bar.Average = msgBuf.readFloat()
+		if serverVersion < mMIN_SERVER_VER_SYNT_REALTIME_BARS {
+			_ = msgBuf.readString()
+		}
+		bar.BarCount = msgBuf.readInt()
+		decodeBarTime(bar, msgBuf.loc)
+		bars = append(bars, bar)
+	}
+	*m = HistoricalDataMsg{ReqID: reqID, StartDateStr: startDateStr, EndDateStr: endDateStr, Bars: bars}
+}
+
+// HistoricalDataUpdateMsg is the HISTORICAL_DATA_UPDATE msg, which is dispatched to HistoricalDataUpdate
+type HistoricalDataUpdateMsg struct {
+	ReqID int64
+	Bar   *BarData
+}
+
+func (m *HistoricalDataUpdateMsg) MsgID() IN { return mHISTORICAL_DATA_UPDATE }
+
+func (m *HistoricalDataUpdateMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *HistoricalDataUpdateMsg) Dispatch(w IbWrapper) {
+	w.HistoricalDataUpdate(m.ReqID, m.Bar)
+}
+
+func (m *HistoricalDataUpdateMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+	bar := &BarData{}
+	bar.BarCount = msgBuf.readInt()
+	bar.Date = msgBuf.readString()
+	bar.Open = msgBuf.readFloat()
+	bar.Close = msgBuf.readFloat()
+	bar.High = msgBuf.readFloat()
+	bar.Low = msgBuf.readFloat()
+	bar.Average = msgBuf.readFloat()
+	bar.Volume = msgBuf.readDecimal()
+	decodeBarTime(bar, msgBuf.loc)
+	*m = HistoricalDataUpdateMsg{ReqID: reqID, Bar: bar}
+}
+
+// BondContractDataMsg is the BOND_CONTRACT_DATA msg, which is dispatched to BondContractDetails
+type BondContractDataMsg struct {
+	ReqID           int64
+	ContractDetails *ContractDetails
+}
+
+func (m *BondContractDataMsg) MsgID() IN { return mBOND_CONTRACT_DATA }
+
+func (m *BondContractDataMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *BondContractDataMsg) Dispatch(w IbWrapper) {
+	w.BondContractDetails(m.ReqID, m.ContractDetails)
+}
+
+func (m *BondContractDataMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	v := msgBuf.readInt()
+
+	var reqID int64 = -1
+
+	if v >= 3 {
+		reqID = msgBuf.readInt()
+	}
+
+	c := &ContractDetails{}
+	c.Contract.Symbol = msgBuf.readString()
+	c.Contract.SecurityType = msgBuf.readString()
+	c.Cusip = msgBuf.readString()
+	c.Coupon = msgBuf.readInt()
+
+	// fallthrough does not check the next case, so the parts are checked one by one
+	splittedExpiry := strings.Split(msgBuf.readString(), " ")
+	s := len(splittedExpiry)
+	if s > 0 {
+		c.Maturity = splittedExpiry[0]
+	}
+	if s > 1 {
+		c.LastTradeTime = splittedExpiry[1]
+	}
+	if s > 2 {
+		c.TimezoneID = splittedExpiry[2]
+	}
+
+	c.IssueDate = msgBuf.readString()
+	c.Ratings = msgBuf.readString()
+	c.BondType = msgBuf.readString()
+	c.CouponType = msgBuf.readString()
+	c.Convertible = msgBuf.readBool()
+	c.Callable = msgBuf.readBool()
+	c.Putable = msgBuf.readBool()
+	c.DescAppend = msgBuf.readString()
+	c.Contract.Exchange = msgBuf.readString()
+	c.Contract.Currency = msgBuf.readString()
+	c.MarketName = msgBuf.readString()
+	c.Contract.TradingClass = msgBuf.readString()
+	c.Contract.ContractID = msgBuf.readInt()
+	c.MinTick = msgBuf.readFloat()
+
+	if serverVersion >= mMIN_SERVER_VER_MD_SIZE_MULTIPLIER && serverVersion < mMIN_SERVER_VER_SIZE_RULES {
+		c.MdSizeMultiplier = msgBuf.readInt()
+	}
+
+	c.OrderTypes = msgBuf.readString()
+	c.ValidExchanges = msgBuf.readString()
+	c.NextOptionDate = msgBuf.readString()
+	c.NextOptionType = msgBuf.readString()
+	c.NextOptionPartial = msgBuf.readBool()
+	c.Notes = msgBuf.readString()
+
+	if v >= 4 {
+		c.LongName = msgBuf.readString()
+	}
+
+	if v >= 6 {
+		c.EVRule = msgBuf.readString()
+		c.EVMultiplier = msgBuf.readInt()
+	}
+
+	if v >= 5 {
+		n := msgBuf.readInt()
+		c.SecurityIDList = make([]TagValue, 0, msgBuf.capacity(n))
+		for ; n > 0 && msgBuf.err == nil; n-- {
+			tagValue := TagValue{}
+			tagValue.Tag = msgBuf.readString()
+			tagValue.Value = msgBuf.readString()
+			c.SecurityIDList = append(c.SecurityIDList, tagValue)
+		}
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_AGG_GROUP {
+		c.AggGroup = msgBuf.readInt()
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_MARKET_RULES {
+		c.MarketRuleIDs = msgBuf.readString()
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_SIZE_RULES {
+		c.MinSize = msgBuf.readDecimal()
+		c.SizeIncrement = msgBuf.readDecimal()
+		c.SuggestedSizeIncrement = msgBuf.readDecimal()
+	}
+	*m = BondContractDataMsg{ReqID: reqID, ContractDetails: c}
+}
+
+// ScannerParametersMsg is the SCANNER_PARAMETERS msg, which is dispatched to ScannerParameters
+type ScannerParametersMsg struct {
+	XML string
+}
+
+func (m *ScannerParametersMsg) MsgID() IN { return mSCANNER_PARAMETERS }
+
+func (m *ScannerParametersMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *ScannerParametersMsg) Dispatch(w IbWrapper) {
+	w.ScannerParameters(m.XML)
+}
+
+func (m *ScannerParametersMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	xml := msgBuf.readString()
+	*m = ScannerParametersMsg{XML: xml}
+}
+
+// ScannerDataMsg is the SCANNER_DATA msg, which is dispatched to ScannerData item by item and then ScannerDataEnd
+type ScannerDataMsg struct {
+	ReqID int64
+	Items []ScanData
+}
+
+func (m *ScannerDataMsg) MsgID() IN { return mSCANNER_DATA }
+
+func (m *ScannerDataMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *ScannerDataMsg) Dispatch(w IbWrapper) {
+	for i := range m.Items {
+		sd := &m.Items[i]
+		w.ScannerData(m.ReqID, sd.Rank, &sd.ContractDetails, sd.Distance, sd.Benchmark, sd.Projection, sd.Legs)
+	}
+	w.ScannerDataEnd(m.ReqID)
+}
+
+func (m *ScannerDataMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+
+	n := msgBuf.readInt()
+	items := make([]ScanData, 0, msgBuf.capacity(n))
+	for ; n > 0 && msgBuf.err == nil; n-- {
+		sd := ScanData{}
+		sd.Rank = msgBuf.readInt()
+		sd.ContractDetails.Contract.ContractID = msgBuf.readInt()
+		sd.ContractDetails.Contract.Symbol = msgBuf.readString()
+		sd.ContractDetails.Contract.SecurityType = msgBuf.readString()
+		sd.ContractDetails.Contract.Expiry = msgBuf.readString()
+		sd.ContractDetails.Contract.Strike = msgBuf.readFloat()
+		sd.ContractDetails.Contract.Right = msgBuf.readString()
+		sd.ContractDetails.Contract.Exchange = msgBuf.readString()
+		sd.ContractDetails.Contract.Currency = msgBuf.readString()
+		sd.ContractDetails.Contract.LocalSymbol = msgBuf.readString()
+		sd.ContractDetails.MarketName = msgBuf.readString()
+		sd.ContractDetails.Contract.TradingClass = msgBuf.readString()
+		sd.Distance = msgBuf.readString()
+		sd.Benchmark = msgBuf.readString()
+		sd.Projection = msgBuf.readString()
+		sd.Legs = msgBuf.readString()
+		items = append(items, sd)
+	}
+	*m = ScannerDataMsg{ReqID: reqID, Items: items}
+}
+
+// TickOptionComputationMsg is the TICK_OPTION_COMPUTATION msg, which is dispatched to TickOptionComputation
+/*
+void tickOptionComputation	(
+int 	tickerId, 	-- 	the request's unique identifier.
+int 	field, 		-- Specifies the type of option computation. Pass the field value into TickType.getField(int tickType) to retrieve the field description. For example, a field value of 13 will map to modelOptComp, etc. 10 = Bid 11 = Ask 12 = Las
+int 	tickAttrib,	-- 	0 - return based, 1- price based.
+double 	impliedVolatility,
+double 	delta,
+double 	optPrice,
+double 	pvDividend,
+double 	gamma,
+double 	vega,
+double 	theta,
+double 	undPrice
+)
+*/
+type TickOptionComputationMsg struct {
+	ReqID      int64
+	TickType   int64
+	TickAttrib int64
+	ImpliedVol float64
+	Delta      float64
+	OptPrice   float64
+	PvDividend float64
+	Gamma      float64
+	Vega       float64
+	Theta      float64
+	UndPrice   float64
+}
+
+func (m *TickOptionComputationMsg) MsgID() IN { return mTICK_OPTION_COMPUTATION }
+
+func (m *TickOptionComputationMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *TickOptionComputationMsg) Dispatch(w IbWrapper) {
+	w.TickOptionComputation(m.ReqID, m.TickType, m.TickAttrib, m.ImpliedVol, m.Delta, m.OptPrice, m.PvDividend, m.Gamma, m.Vega, m.Theta, m.UndPrice)
+}
+
+func (m *TickOptionComputationMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	optPrice := UNSETFLOAT
+	pvDividend := UNSETFLOAT
+	gamma := UNSETFLOAT
+	vega := UNSETFLOAT
+	theta := UNSETFLOAT
+	undPrice := UNSETFLOAT
+
+	var v int64
+	if serverVersion < mMIN_SERVER_VER_PRICE_BASED_VOLATILITY {
+		v = msgBuf.readInt()
+	} else {
+		v = int64(serverVersion)
+	}
+
+	reqID := msgBuf.readInt()    // tickerId
+	tickType := msgBuf.readInt() // field
+
+	var tickAttrib int64
+	if serverVersion >= mMIN_SERVER_VER_PRICE_BASED_VOLATILITY {
+		tickAttrib = msgBuf.readInt() // tickAtrib
+	}
+
+	impliedVol := msgBuf.readFloat()
+	delta := msgBuf.readFloat()
+
+	if v >= 6 || tickType == MODEL_OPTION || tickType == DELAYED_MODEL_OPTION {
+		optPrice = msgBuf.readFloat()
+		pvDividend = msgBuf.readFloat()
+	}
+
+	if v >= 6 {
+		gamma = msgBuf.readFloat()
+		vega = msgBuf.readFloat()
+		theta = msgBuf.readFloat()
+		undPrice = msgBuf.readFloat()
+
+	}
+
+	switch {
+	case impliedVol < 0:
+		impliedVol = UNSETFLOAT
+		fallthrough
+	case delta == -2:
+		delta = UNSETFLOAT
+		fallthrough
+	case optPrice == -1:
+		optPrice = UNSETFLOAT
+		fallthrough
+	case pvDividend == -1:
+		pvDividend = UNSETFLOAT
+		fallthrough
+	case gamma == -2:
+		gamma = UNSETFLOAT
+		fallthrough
+	case vega == -2:
+		vega = UNSETFLOAT
+		fallthrough
+	case theta == -2:
+		theta = UNSETFLOAT
+		fallthrough
+	case undPrice == -1:
+		undPrice = UNSETFLOAT
+	}
+	*m = TickOptionComputationMsg{
+		ReqID:      reqID,
+		TickType:   tickType,
+		TickAttrib: tickAttrib,
+		ImpliedVol: impliedVol,
+		Delta:      delta,
+		OptPrice:   optPrice,
+		PvDividend: pvDividend,
+		Gamma:      gamma,
+		Vega:       vega,
+		Theta:      theta,
+		UndPrice:   undPrice,
+	}
+}
+
+// TickGenericMsg is the TICK_GENERIC msg, which is dispatched to TickGeneric
+type TickGenericMsg struct {
+	ReqID    int64
+	TickType int64
+	Value    float64
+}
+
+func (m *TickGenericMsg) MsgID() IN { return mTICK_GENERIC }
+
+func (m *TickGenericMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *TickGenericMsg) Dispatch(w IbWrapper) {
+	w.TickGeneric(m.ReqID, m.TickType, m.Value)
+}
+
+func (m *TickGenericMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	tickType := msgBuf.readInt()
+	value := msgBuf.readFloat()
+	*m = TickGenericMsg{ReqID: reqID, TickType: tickType, Value: value}
+}
+
+// TickStringMsg is the TICK_STRING msg, which is dispatched to TickString
+type TickStringMsg struct {
+	ReqID    int64
+	TickType int64
+	Value    string
+}
+
+func (m *TickStringMsg) MsgID() IN { return mTICK_STRING }
+
+func (m *TickStringMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *TickStringMsg) Dispatch(w IbWrapper) {
+	w.TickString(m.ReqID, m.TickType, m.Value)
+}
+
+func (m *TickStringMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	tickType := msgBuf.readInt()
+	value := msgBuf.readString()
+	*m = TickStringMsg{ReqID: reqID, TickType: tickType, Value: value}
+}
+
+// TickEFPMsg is the TICK_EFP msg, which is dispatched to TickEFP
+type TickEFPMsg struct {
+	ReqID                    int64
+	TickType                 int64
+	BasisPoints              float64
+	FormattedBasisPoints     string
+	TotalDividends           float64
+	HoldDays                 int64
+	FutureLastTradeDate      string
+	DividendImpact           float64
+	DividendsToLastTradeDate float64
+}
+
+func (m *TickEFPMsg) MsgID() IN { return mTICK_EFP }
+
+func (m *TickEFPMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *TickEFPMsg) Dispatch(w IbWrapper) {
+	w.TickEFP(m.ReqID, m.TickType, m.BasisPoints, m.FormattedBasisPoints, m.TotalDividends, m.HoldDays, m.FutureLastTradeDate, m.DividendImpact, m.DividendsToLastTradeDate)
+}
+
+func (m *TickEFPMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	tickType := msgBuf.readInt()
+	basisPoints := msgBuf.readFloat()
+	formattedBasisPoints := msgBuf.readString()
+	totalDividends := msgBuf.readFloat()
+	holdDays := msgBuf.readInt()
+	futureLastTradeDate := msgBuf.readString()
+	dividendImpact := msgBuf.readFloat()
+	dividendsToLastTradeDate := msgBuf.readFloat()
+	*m = TickEFPMsg{
+		ReqID:                    reqID,
+		TickType:                 tickType,
+		BasisPoints:              basisPoints,
+		FormattedBasisPoints:     formattedBasisPoints,
+		TotalDividends:           totalDividends,
+		HoldDays:                 holdDays,
+		FutureLastTradeDate:      futureLastTradeDate,
+		DividendImpact:           dividendImpact,
+		DividendsToLastTradeDate: dividendsToLastTradeDate,
+	}
+}
+
+// CurrentTimeMsg is the CURRENT_TIME msg, which is dispatched to CurrentTime
+type CurrentTimeMsg struct {
+	Time time.Time
+}
+
+func (m *CurrentTimeMsg) MsgID() IN { return mCURRENT_TIME }
+
+func (m *CurrentTimeMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *CurrentTimeMsg) Dispatch(w IbWrapper) {
+	w.CurrentTime(m.Time)
+}
+
+func (m *CurrentTimeMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	ts := msgBuf.readInt()
+	t := time.Unix(ts, 0)
+	*m = CurrentTimeMsg{Time: t}
+}
+
+// RealTimeBarsMsg is the REAL_TIME_BARS msg, which is dispatched to RealtimeBar
+type RealTimeBarsMsg struct {
+	ReqID  int64
+	Time   int64
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume Decimal
+	Wap    float64
+	Count  int64
+}
+
+func (m *RealTimeBarsMsg) MsgID() IN { return mREAL_TIME_BARS }
+
+func (m *RealTimeBarsMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *RealTimeBarsMsg) Dispatch(w IbWrapper) {
+	w.RealtimeBar(m.ReqID, m.Time, m.Open, m.High, m.Low, m.Close, m.Volume, m.Wap, m.Count)
+}
+
+func (m *RealTimeBarsMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+
+	rtb := &RealTimeBar{}
+	rtb.Time = msgBuf.readInt()
+	rtb.Open = msgBuf.readFloat()
+	rtb.High = msgBuf.readFloat()
+	rtb.Low = msgBuf.readFloat()
+	rtb.Close = msgBuf.readFloat()
+	rtb.Volume = msgBuf.readDecimal()
+	rtb.Wap = msgBuf.readFloat()
+	rtb.Count = msgBuf.readInt()
+	*m = RealTimeBarsMsg{
+		ReqID:  reqID,
+		Time:   rtb.Time,
+		Open:   rtb.Open,
+		High:   rtb.High,
+		Low:    rtb.Low,
+		Close:  rtb.Close,
+		Volume: rtb.Volume,
+		Wap:    rtb.Wap,
+		Count:  rtb.Count,
+	}
+}
+
+// FundamentalDataMsg is the FUNDAMENTAL_DATA msg, which is dispatched to FundamentalData
+type FundamentalDataMsg struct {
+	ReqID int64
+	Data  string
+}
+
+func (m *FundamentalDataMsg) MsgID() IN { return mFUNDAMENTAL_DATA }
+
+func (m *FundamentalDataMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *FundamentalDataMsg) Dispatch(w IbWrapper) {
+	w.FundamentalData(m.ReqID, m.Data)
+}
+
+func (m *FundamentalDataMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	data := msgBuf.readString()
+	*m = FundamentalDataMsg{ReqID: reqID, Data: data}
+}
+
+// ContractDataEndMsg is the CONTRACT_DATA_END msg, which is dispatched to ContractDetailsEnd
+type ContractDataEndMsg struct {
+	ReqID int64
+}
+
+func (m *ContractDataEndMsg) MsgID() IN { return mCONTRACT_DATA_END }
+
+func (m *ContractDataEndMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *ContractDataEndMsg) Dispatch(w IbWrapper) {
+	w.ContractDetailsEnd(m.ReqID)
+}
+
+func (m *ContractDataEndMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	*m = ContractDataEndMsg{ReqID: reqID}
+}
+
+// AcctDownloadEndMsg is the ACCT_DOWNLOAD_END msg, which is dispatched to AccountDownloadEnd
+type AcctDownloadEndMsg struct {
+	AccName string
+}
+
+func (m *AcctDownloadEndMsg) MsgID() IN { return mACCT_DOWNLOAD_END }
+
+func (m *AcctDownloadEndMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *AcctDownloadEndMsg) Dispatch(w IbWrapper) {
+	w.AccountDownloadEnd(m.AccName)
+}
+
+func (m *AcctDownloadEndMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	accName := msgBuf.readString()
+	*m = AcctDownloadEndMsg{AccName: accName}
+}
+
+// OpenOrderEndMsg is the OPEN_ORDER_END msg, which is dispatched to OpenOrderEnd
+type OpenOrderEndMsg struct {
+}
+
+func (m *OpenOrderEndMsg) MsgID() IN { return mOPEN_ORDER_END }
+
+func (m *OpenOrderEndMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *OpenOrderEndMsg) Dispatch(w IbWrapper) {
+	w.OpenOrderEnd()
+}
+
+func (m *OpenOrderEndMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+
+}
+
+// ExecutionDataEndMsg is the EXECUTION_DATA_END msg, which is dispatched to ExecDetailsEnd
+type ExecutionDataEndMsg struct {
+	ReqID int64
+}
+
+func (m *ExecutionDataEndMsg) MsgID() IN { return mEXECUTION_DATA_END }
+
+func (m *ExecutionDataEndMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *ExecutionDataEndMsg) Dispatch(w IbWrapper) {
+	w.ExecDetailsEnd(m.ReqID)
+}
+
+func (m *ExecutionDataEndMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	*m = ExecutionDataEndMsg{ReqID: reqID}
+}
+
+// DeltaNeutralValidationMsg is the DELTA_NEUTRAL_VALIDATION msg, which is dispatched to DeltaNeutralValidation
+type DeltaNeutralValidationMsg struct {
+	ReqID                int64
+	DeltaNeutralContract DeltaNeutralContract
+}
+
+func (m *DeltaNeutralValidationMsg) MsgID() IN { return mDELTA_NEUTRAL_VALIDATION }
+
+func (m *DeltaNeutralValidationMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *DeltaNeutralValidationMsg) Dispatch(w IbWrapper) {
+	w.DeltaNeutralValidation(m.ReqID, m.DeltaNeutralContract)
+}
+
+func (m *DeltaNeutralValidationMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	deltaNeutralContract := DeltaNeutralContract{}
+
+	deltaNeutralContract.ContractID = msgBuf.readInt()
+	deltaNeutralContract.Delta = msgBuf.readFloat()
+	deltaNeutralContract.Price = msgBuf.readFloat()
+	*m = DeltaNeutralValidationMsg{ReqID: reqID, DeltaNeutralContract: deltaNeutralContract}
+}
+
+// TickSnapshotEndMsg is the TICK_SNAPSHOT_END msg, which is dispatched to TickSnapshotEnd
+type TickSnapshotEndMsg struct {
+	ReqID int64
+}
+
+func (m *TickSnapshotEndMsg) MsgID() IN { return mTICK_SNAPSHOT_END }
+
+func (m *TickSnapshotEndMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *TickSnapshotEndMsg) Dispatch(w IbWrapper) {
+	w.TickSnapshotEnd(m.ReqID)
+}
+
+func (m *TickSnapshotEndMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	*m = TickSnapshotEndMsg{ReqID: reqID}
+}
+
+// MarketDataTypeMsg is the MARKET_DATA_TYPE msg, which is dispatched to MarketDataType
+type MarketDataTypeMsg struct {
+	ReqID          int64
+	MarketDataType int64
+}
+
+func (m *MarketDataTypeMsg) MsgID() IN { return mMARKET_DATA_TYPE }
+
+func (m *MarketDataTypeMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *MarketDataTypeMsg) Dispatch(w IbWrapper) {
+	w.MarketDataType(m.ReqID, m.MarketDataType)
+}
+
+func (m *MarketDataTypeMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	marketDataType := msgBuf.readInt()
+	*m = MarketDataTypeMsg{ReqID: reqID, MarketDataType: marketDataType}
+}
+
+// CommissionReportMsg is the COMMISSION_REPORT msg, which is dispatched to CommissionReport
+type CommissionReportMsg struct {
+	CommissionReport CommissionReport
+}
+
+func (m *CommissionReportMsg) MsgID() IN { return mCOMMISSION_REPORT }
+
+func (m *CommissionReportMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *CommissionReportMsg) Dispatch(w IbWrapper) {
+	w.CommissionReport(m.CommissionReport)
+}
+
+func (m *CommissionReportMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	cr := CommissionReport{}
+	cr.ExecID = msgBuf.readString()
+	cr.Commission = msgBuf.readFloat()
+	cr.Currency = msgBuf.readString()
+	cr.RealizedPNL = msgBuf.readFloat()
+	cr.Yield = msgBuf.readFloat()
+	cr.YieldRedemptionDate = msgBuf.readInt()
+	*m = CommissionReportMsg{CommissionReport: cr}
+}
+
+// PositionDataMsg is the POSITION_DATA msg, which is dispatched to Position
+type PositionDataMsg struct {
+	Account  string
+	Contract *Contract
+	Position Decimal
+	AvgCost  float64
+}
+
+func (m *PositionDataMsg) MsgID() IN { return mPOSITION_DATA }
+
+func (m *PositionDataMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *PositionDataMsg) Dispatch(w IbWrapper) {
+	w.Position(m.Account, m.Contract, m.Position, m.AvgCost)
+}
+
+func (m *PositionDataMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	v := msgBuf.readInt()
+	acc := msgBuf.readString()
+
+	// read contract fields
+	c := new(Contract)
+	c.ContractID = msgBuf.readInt()
+	c.Symbol = msgBuf.readString()
+	c.SecurityType = msgBuf.readString()
+	c.Expiry = msgBuf.readString()
+	c.Strike = msgBuf.readFloat()
+	c.Right = msgBuf.readString()
+	c.Multiplier = msgBuf.readString()
+	c.Exchange = msgBuf.readString()
+	c.Currency = msgBuf.readString()
+	c.LocalSymbol = msgBuf.readString()
+	if v >= 2 {
+		c.TradingClass = msgBuf.readString()
+	}
+
+	p := msgBuf.readDecimal()
+
+	var avgCost float64
+	if v >= 3 {
+		avgCost = msgBuf.readFloat()
+	}
+	*m = PositionDataMsg{
+		Account:  acc,
+		Contract: c,
+		Position: p,
+		AvgCost:  avgCost,
+	}
+}
+
+// PositionEndMsg is the POSITION_END msg, which is dispatched to PositionEnd
+type PositionEndMsg struct {
+}
+
+func (m *PositionEndMsg) MsgID() IN { return mPOSITION_END }
+
+func (m *PositionEndMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *PositionEndMsg) Dispatch(w IbWrapper) {
+	w.PositionEnd()
+}
+
+func (m *PositionEndMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+}
+
+// AccountSummaryMsg is the ACCOUNT_SUMMARY msg, which is dispatched to AccountSummary
+type AccountSummaryMsg struct {
+	ReqID    int64
+	Account  string
+	Tag      string
+	Value    string
+	Currency string
+}
+
+func (m *AccountSummaryMsg) MsgID() IN { return mACCOUNT_SUMMARY }
+
+func (m *AccountSummaryMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *AccountSummaryMsg) Dispatch(w IbWrapper) {
+	w.AccountSummary(m.ReqID, m.Account, m.Tag, m.Value, m.Currency)
+}
+
+func (m *AccountSummaryMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	account := msgBuf.readString()
+	tag := msgBuf.readString()
+	value := msgBuf.readString()
+	currency := msgBuf.readString()
+	*m = AccountSummaryMsg{
+		ReqID:    reqID,
+		Account:  account,
+		Tag:      tag,
+		Value:    value,
+		Currency: currency,
+	}
+}
+
+// AccountSummaryEndMsg is the ACCOUNT_SUMMARY_END msg, which is dispatched to AccountSummaryEnd
+type AccountSummaryEndMsg struct {
+	ReqID int64
+}
+
+func (m *AccountSummaryEndMsg) MsgID() IN { return mACCOUNT_SUMMARY_END }
+
+func (m *AccountSummaryEndMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *AccountSummaryEndMsg) Dispatch(w IbWrapper) {
+	w.AccountSummaryEnd(m.ReqID)
+}
+
+func (m *AccountSummaryEndMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	*m = AccountSummaryEndMsg{ReqID: reqID}
+}
+
+// VerifyMessageAPIMsg is the VERIFY_MESSAGE_API msg, which is dispatched to VerifyMessageAPI
+type VerifyMessageAPIMsg struct {
+	ApiData string
+}
+
+func (m *VerifyMessageAPIMsg) MsgID() IN { return mVERIFY_MESSAGE_API }
+
+func (m *VerifyMessageAPIMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *VerifyMessageAPIMsg) Dispatch(w IbWrapper) {
+	w.VerifyMessageAPI(m.ApiData)
+}
+
+func (m *VerifyMessageAPIMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	// Deprecated Function: keep it temporarily, not know how it works
+	_ = msgBuf.readString()
+	apiData := msgBuf.readString()
+	*m = VerifyMessageAPIMsg{ApiData: apiData}
+}
+
+// VerifyCompletedMsg is the VERIFY_COMPLETED msg, which is dispatched to VerifyCompleted
+type VerifyCompletedMsg struct {
+	IsSuccessful bool
+	ErrText      string
+}
+
+func (m *VerifyCompletedMsg) MsgID() IN { return mVERIFY_COMPLETED }
+
+func (m *VerifyCompletedMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *VerifyCompletedMsg) Dispatch(w IbWrapper) {
+	w.VerifyCompleted(m.IsSuccessful, m.ErrText)
+}
+
+func (m *VerifyCompletedMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	isSuccessful := msgBuf.readBool()
+	err := msgBuf.readString()
+	*m = VerifyCompletedMsg{IsSuccessful: isSuccessful, ErrText: err}
+}
+
+// DisplayGroupListMsg is the DISPLAY_GROUP_LIST msg, which is dispatched to DisplayGroupList
+type DisplayGroupListMsg struct {
+	ReqID  int64
+	Groups string
+}
+
+func (m *DisplayGroupListMsg) MsgID() IN { return mDISPLAY_GROUP_LIST }
+
+func (m *DisplayGroupListMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *DisplayGroupListMsg) Dispatch(w IbWrapper) {
+	w.DisplayGroupList(m.ReqID, m.Groups)
+}
+
+func (m *DisplayGroupListMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	groups := msgBuf.readString()
+	*m = DisplayGroupListMsg{ReqID: reqID, Groups: groups}
+}
+
+// DisplayGroupUpdatedMsg is the DISPLAY_GROUP_UPDATED msg, which is dispatched to DisplayGroupUpdated
+type DisplayGroupUpdatedMsg struct {
+	ReqID        int64
+	ContractInfo string
+}
+
+func (m *DisplayGroupUpdatedMsg) MsgID() IN { return mDISPLAY_GROUP_UPDATED }
+
+func (m *DisplayGroupUpdatedMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *DisplayGroupUpdatedMsg) Dispatch(w IbWrapper) {
+	w.DisplayGroupUpdated(m.ReqID, m.ContractInfo)
+}
+
+func (m *DisplayGroupUpdatedMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	contractInfo := msgBuf.readString()
+	*m = DisplayGroupUpdatedMsg{ReqID: reqID, ContractInfo: contractInfo}
+}
+
+// VerifyAndAuthMessageAPIMsg is the VERIFY_AND_AUTH_MESSAGE_API msg, which is dispatched to VerifyAndAuthMessageAPI
+type VerifyAndAuthMessageAPIMsg struct {
+	ApiData      string
+	XyzChallange string
+}
+
+func (m *VerifyAndAuthMessageAPIMsg) MsgID() IN { return mVERIFY_AND_AUTH_MESSAGE_API }
+
+func (m *VerifyAndAuthMessageAPIMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *VerifyAndAuthMessageAPIMsg) Dispatch(w IbWrapper) {
+	w.VerifyAndAuthMessageAPI(m.ApiData, m.XyzChallange)
+}
+
+func (m *VerifyAndAuthMessageAPIMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	apiData := msgBuf.readString()
+	xyzChallange := msgBuf.readString()
+	*m = VerifyAndAuthMessageAPIMsg{ApiData: apiData, XyzChallange: xyzChallange}
+}
+
+// VerifyAndAuthCompletedMsg is the VERIFY_AND_AUTH_COMPLETED msg, which is dispatched to VerifyAndAuthCompleted
+type VerifyAndAuthCompletedMsg struct {
+	IsSuccessful bool
+	ErrText      string
+}
+
+func (m *VerifyAndAuthCompletedMsg) MsgID() IN { return mVERIFY_AND_AUTH_COMPLETED }
+
+func (m *VerifyAndAuthCompletedMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *VerifyAndAuthCompletedMsg) Dispatch(w IbWrapper) {
+	w.VerifyAndAuthCompleted(m.IsSuccessful, m.ErrText)
+}
+
+func (m *VerifyAndAuthCompletedMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	isSuccessful := msgBuf.readBool()
+	err := msgBuf.readString()
+	*m = VerifyAndAuthCompletedMsg{IsSuccessful: isSuccessful, ErrText: err}
+}
+
+// PositionMultiMsg is the POSITION_MULTI msg, which is dispatched to PositionMulti
+type PositionMultiMsg struct {
+	ReqID     int64
+	Account   string
+	ModelCode string
+	Contract  *Contract
+	Position  Decimal
+	AvgCost   float64
+}
+
+func (m *PositionMultiMsg) MsgID() IN { return mPOSITION_MULTI }
+
+func (m *PositionMultiMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *PositionMultiMsg) Dispatch(w IbWrapper) {
+	w.PositionMulti(m.ReqID, m.Account, m.ModelCode, m.Contract, m.Position, m.AvgCost)
+}
+
+func (m *PositionMultiMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	acc := msgBuf.readString()
+
+	// read contract fields
+	c := new(Contract)
+	c.ContractID = msgBuf.readInt()
+	c.Symbol = msgBuf.readString()
+	c.SecurityType = msgBuf.readString()
+	c.Expiry = msgBuf.readString()
+	c.Strike = msgBuf.readFloat()
+	c.Right = msgBuf.readString()
+	c.Multiplier = msgBuf.readString()
+	c.Exchange = msgBuf.readString()
+	c.Currency = msgBuf.readString()
+	c.LocalSymbol = msgBuf.readString()
+	c.TradingClass = msgBuf.readString()
+
+	p := msgBuf.readDecimal()
+	avgCost := msgBuf.readFloat()
+	modelCode := msgBuf.readString()
+	*m = PositionMultiMsg{
+		ReqID:     reqID,
+		Account:   acc,
+		ModelCode: modelCode,
+		Contract:  c,
+		Position:  p,
+		AvgCost:   avgCost,
+	}
+}
+
+// PositionMultiEndMsg is the POSITION_MULTI_END msg, which is dispatched to PositionMultiEnd
+type PositionMultiEndMsg struct {
+	ReqID int64
+}
+
+func (m *PositionMultiEndMsg) MsgID() IN { return mPOSITION_MULTI_END }
+
+func (m *PositionMultiEndMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *PositionMultiEndMsg) Dispatch(w IbWrapper) {
+	w.PositionMultiEnd(m.ReqID)
+}
+
+func (m *PositionMultiEndMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	*m = PositionMultiEndMsg{ReqID: reqID}
+}
+
+// AccountUpdateMultiMsg is the ACCOUNT_UPDATE_MULTI msg, which is dispatched to AccountUpdateMulti
+type AccountUpdateMultiMsg struct {
+	ReqID     int64
+	Account   string
+	ModelCode string
+	Tag       string
+	Value     string
+	Currency  string
+}
+
+func (m *AccountUpdateMultiMsg) MsgID() IN { return mACCOUNT_UPDATE_MULTI }
+
+func (m *AccountUpdateMultiMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *AccountUpdateMultiMsg) Dispatch(w IbWrapper) {
+	w.AccountUpdateMulti(m.ReqID, m.Account, m.ModelCode, m.Tag, m.Value, m.Currency)
+}
+
+func (m *AccountUpdateMultiMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	acc := msgBuf.readString()
+	modelCode := msgBuf.readString()
+	tag := msgBuf.readString()
+	val := msgBuf.readString()
+	currency := msgBuf.readString()
+	*m = AccountUpdateMultiMsg{
+		ReqID:     reqID,
+		Account:   acc,
+		ModelCode: modelCode,
+		Tag:       tag,
+		Value:     val,
+		Currency:  currency,
+	}
+}
+
+// AccountUpdateMultiEndMsg is the ACCOUNT_UPDATE_MULTI_END msg, which is dispatched to AccountUpdateMultiEnd
+type AccountUpdateMultiEndMsg struct {
+	ReqID int64
+}
+
+func (m *AccountUpdateMultiEndMsg) MsgID() IN { return mACCOUNT_UPDATE_MULTI_END }
+
+func (m *AccountUpdateMultiEndMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *AccountUpdateMultiEndMsg) Dispatch(w IbWrapper) {
+	w.AccountUpdateMultiEnd(m.ReqID)
+}
+
+func (m *AccountUpdateMultiEndMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	_ = msgBuf.readString()
+	reqID := msgBuf.readInt()
+	*m = AccountUpdateMultiEndMsg{ReqID: reqID}
+}
+
+// SecurityDefinitionOptionParameterMsg is the SECURITY_DEFINITION_OPTION_PARAMETER msg, which is dispatched to SecurityDefinitionOptionParameter
+type SecurityDefinitionOptionParameterMsg struct {
+	ReqID                int64
+	Exchange             string
+	UnderlyingContractID int64
+	TradingClass         string
+	Multiplier           string
+	Expirations          []string
+	Strikes              []float64
+}
+
+func (m *SecurityDefinitionOptionParameterMsg) MsgID() IN {
+	return mSECURITY_DEFINITION_OPTION_PARAMETER
+}
+
+func (m *SecurityDefinitionOptionParameterMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *SecurityDefinitionOptionParameterMsg) Dispatch(w IbWrapper) {
+	w.SecurityDefinitionOptionParameter(m.ReqID, m.Exchange, m.UnderlyingContractID, m.TradingClass, m.Multiplier, m.Expirations, m.Strikes)
+}
+
+func (m *SecurityDefinitionOptionParameterMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+	exchange := msgBuf.readString()
+	underlyingContractID := msgBuf.readInt()
+	tradingClass := msgBuf.readString()
+	multiplier := msgBuf.readString()
+
+	expN := msgBuf.readInt()
+	expirations := make([]string, 0, msgBuf.capacity(expN))
+	for ; expN > 0 && msgBuf.err == nil; expN-- {
+		expiration := msgBuf.readString()
+		expirations = append(expirations, expiration)
+	}
+
+	strikeN := msgBuf.readInt()
+	strikes := make([]float64, 0, msgBuf.capacity(strikeN))
+	for ; strikeN > 0 && msgBuf.err == nil; strikeN-- {
+		strike := msgBuf.readFloat()
+		strikes = append(strikes, strike)
+	}
+	*m = SecurityDefinitionOptionParameterMsg{
+		ReqID:                reqID,
+		Exchange:             exchange,
+		UnderlyingContractID: underlyingContractID,
+		TradingClass:         tradingClass,
+		Multiplier:           multiplier,
+		Expirations:          expirations,
+		Strikes:              strikes,
+	}
+}
+
+// SecurityDefinitionOptionParameterEndMsg is the SECURITY_DEFINITION_OPTION_PARAMETER_END msg, which is dispatched to SecurityDefinitionOptionParameterEnd
+type SecurityDefinitionOptionParameterEndMsg struct {
+	ReqID int64
+}
+
+func (m *SecurityDefinitionOptionParameterEndMsg) MsgID() IN {
+	return mSECURITY_DEFINITION_OPTION_PARAMETER_END
+}
+
+func (m *SecurityDefinitionOptionParameterEndMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *SecurityDefinitionOptionParameterEndMsg) Dispatch(w IbWrapper) {
+	w.SecurityDefinitionOptionParameterEnd(m.ReqID)
+}
+
+func (m *SecurityDefinitionOptionParameterEndMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+	*m = SecurityDefinitionOptionParameterEndMsg{ReqID: reqID}
+}
+
+// SoftDollarTiersMsg is the SOFT_DOLLAR_TIERS msg, which is dispatched to SoftDollarTiers
+type SoftDollarTiersMsg struct {
+	ReqID int64
+	Tiers []SoftDollarTier
+}
+
+func (m *SoftDollarTiersMsg) MsgID() IN { return mSOFT_DOLLAR_TIERS }
+
+func (m *SoftDollarTiersMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *SoftDollarTiersMsg) Dispatch(w IbWrapper) {
+	w.SoftDollarTiers(m.ReqID, m.Tiers)
+}
+
+func (m *SoftDollarTiersMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+
+	n := msgBuf.readInt()
+	tiers := make([]SoftDollarTier, 0, msgBuf.capacity(n))
+	for ; n > 0 && msgBuf.err == nil; n-- {
+		tier := SoftDollarTier{}
+		tier.Name = msgBuf.readString()
+		tier.Value = msgBuf.readString()
+		tier.DisplayName = msgBuf.readString()
+		tiers = append(tiers, tier)
+	}
+	*m = SoftDollarTiersMsg{ReqID: reqID, Tiers: tiers}
+}
+
+// FamilyCodesMsg is the FAMILY_CODES msg, which is dispatched to FamilyCodes
+type FamilyCodesMsg struct {
+	FamilyCodes []FamilyCode
+}
+
+func (m *FamilyCodesMsg) MsgID() IN { return mFAMILY_CODES }
+
+func (m *FamilyCodesMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *FamilyCodesMsg) Dispatch(w IbWrapper) {
+	w.FamilyCodes(m.FamilyCodes)
+}
+
+func (m *FamilyCodesMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	n := msgBuf.readInt()
+	familyCodes := make([]FamilyCode, 0, msgBuf.capacity(n))
+	for ; n > 0 && msgBuf.err == nil; n-- {
+		familyCode := FamilyCode{}
+		familyCode.AccountID = msgBuf.readString()
+		familyCode.FamilyCode = msgBuf.readString()
+		familyCodes = append(familyCodes, familyCode)
+	}
+	*m = FamilyCodesMsg{FamilyCodes: familyCodes}
+}
+
+// SymbolSamplesMsg is the SYMBOL_SAMPLES msg, which is dispatched to SymbolSamples
+type SymbolSamplesMsg struct {
+	ReqID                int64
+	ContractDescriptions []ContractDescription
+}
+
+func (m *SymbolSamplesMsg) MsgID() IN { return mSYMBOL_SAMPLES }
+
+func (m *SymbolSamplesMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *SymbolSamplesMsg) Dispatch(w IbWrapper) {
+	w.SymbolSamples(m.ReqID, m.ContractDescriptions)
+}
+
+func (m *SymbolSamplesMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+
+	n := msgBuf.readInt()
+	contractDescriptions := make([]ContractDescription, 0, msgBuf.capacity(n))
+	for ; n > 0 && msgBuf.err == nil; n-- {
+		cd := ContractDescription{}
+		cd.Contract.ContractID = msgBuf.readInt()
+		cd.Contract.Symbol = msgBuf.readString()
+		cd.Contract.SecurityType = msgBuf.readString()
+		cd.Contract.PrimaryExchange = msgBuf.readString()
+		cd.Contract.Currency = msgBuf.readString()
+
+		sdtN := msgBuf.readInt()
+		cd.DerivativeSecTypes = make([]string, 0, msgBuf.capacity(sdtN))
+		for ; sdtN > 0 && msgBuf.err == nil; sdtN-- {
+			derivativeSecType := msgBuf.readString()
+			cd.DerivativeSecTypes = append(cd.DerivativeSecTypes, derivativeSecType)
+		}
+		contractDescriptions = append(contractDescriptions, cd)
+	}
+	*m = SymbolSamplesMsg{ReqID: reqID, ContractDescriptions: contractDescriptions}
+}
+
+// SmartComponentsMsg is the SMART_COMPONENTS msg, which is dispatched to SmartComponents
+type SmartComponentsMsg struct {
+	ReqID           int64
+	SmartComponents []SmartComponent
+}
+
+func (m *SmartComponentsMsg) MsgID() IN { return mSMART_COMPONENTS }
+
+func (m *SmartComponentsMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *SmartComponentsMsg) Dispatch(w IbWrapper) {
+	w.SmartComponents(m.ReqID, m.SmartComponents)
+}
+
+func (m *SmartComponentsMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+
+	n := msgBuf.readInt()
+	smartComponents := make([]SmartComponent, 0, msgBuf.capacity(n))
+	for ; n > 0 && msgBuf.err == nil; n-- {
+		smartComponent := SmartComponent{}
+		smartComponent.BitNumber = msgBuf.readInt()
+		smartComponent.Exchange = msgBuf.readString()
+		smartComponent.ExchangeLetter = msgBuf.readString()
+		smartComponents = append(smartComponents, smartComponent)
+	}
+	*m = SmartComponentsMsg{ReqID: reqID, SmartComponents: smartComponents}
+}
+
+// TickReqParamsMsg is the TICK_REQ_PARAMS msg, which is dispatched to TickReqParams
+type TickReqParamsMsg struct {
+	TickerID            int64
+	MinTick             float64
+	BboExchange         string
+	SnapshotPermissions int64
+}
+
+func (m *TickReqParamsMsg) MsgID() IN { return mTICK_REQ_PARAMS }
+
+func (m *TickReqParamsMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *TickReqParamsMsg) Dispatch(w IbWrapper) {
+	w.TickReqParams(m.TickerID, m.MinTick, m.BboExchange, m.SnapshotPermissions)
+}
+
+func (m *TickReqParamsMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	tickerID := msgBuf.readInt()
+	minTick := msgBuf.readFloat()
+	bboExchange := msgBuf.readString()
+	snapshotPermissions := msgBuf.readInt()
+	*m = TickReqParamsMsg{
+		TickerID:            tickerID,
+		MinTick:             minTick,
+		BboExchange:         bboExchange,
+		SnapshotPermissions: snapshotPermissions,
+	}
+}
+
+// MktDepthExchangesMsg is the MKT_DEPTH_EXCHANGES msg, which is dispatched to MktDepthExchanges
+type MktDepthExchangesMsg struct {
+	DepthMktDataDescriptions []DepthMktDataDescription
+}
+
+func (m *MktDepthExchangesMsg) MsgID() IN { return mMKT_DEPTH_EXCHANGES }
+
+func (m *MktDepthExchangesMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *MktDepthExchangesMsg) Dispatch(w IbWrapper) {
+	w.MktDepthExchanges(m.DepthMktDataDescriptions)
+}
+
+func (m *MktDepthExchangesMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	n := msgBuf.readInt()
+	depthMktDataDescriptions := make([]DepthMktDataDescription, 0, msgBuf.capacity(n))
+	for ; n > 0 && msgBuf.err == nil; n-- {
+		desc := DepthMktDataDescription{}
+		desc.Exchange = msgBuf.readString()
+		desc.SecurityType = msgBuf.readString()
+		if serverVersion >= mMIN_SERVER_VER_SERVICE_DATA_TYPE {
+			desc.ListingExchange = msgBuf.readString()
+			desc.SecurityType = msgBuf.readString()
+			desc.AggGroup = msgBuf.readInt()
+		} else {
+			_ = msgBuf.readString()
+		}
+
+		depthMktDataDescriptions = append(depthMktDataDescriptions, desc)
+	}
+	*m = MktDepthExchangesMsg{DepthMktDataDescriptions: depthMktDataDescriptions}
+}
+
+// HeadTimestampMsg is the HEAD_TIMESTAMP msg, which is dispatched to HeadTimestamp
+type HeadTimestampMsg struct {
+	ReqID         int64
+	HeadTimestamp string
+}
+
+func (m *HeadTimestampMsg) MsgID() IN { return mHEAD_TIMESTAMP }
+
+func (m *HeadTimestampMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *HeadTimestampMsg) Dispatch(w IbWrapper) {
+	w.HeadTimestamp(m.ReqID, m.HeadTimestamp)
+}
+
+func (m *HeadTimestampMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+	headTimestamp := msgBuf.readString()
+	*m = HeadTimestampMsg{ReqID: reqID, HeadTimestamp: headTimestamp}
+}
+
+// TickNewsMsg is the TICK_NEWS msg, which is dispatched to TickNews
+type TickNewsMsg struct {
+	TickerID     int64
+	TimeStamp    int64
+	ProviderCode string
+	ArticleID    string
+	Headline     string
+	ExtraData    string
+}
+
+func (m *TickNewsMsg) MsgID() IN { return mTICK_NEWS }
+
+func (m *TickNewsMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *TickNewsMsg) Dispatch(w IbWrapper) {
+	w.TickNews(m.TickerID, m.TimeStamp, m.ProviderCode, m.ArticleID, m.Headline, m.ExtraData)
+}
+
+func (m *TickNewsMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	tickerID := msgBuf.readInt()
+	timeStamp := msgBuf.readInt()
+	providerCode := msgBuf.readString()
+	articleID := msgBuf.readString()
+	headline := msgBuf.readString()
+	extraData := msgBuf.readString()
+	*m = TickNewsMsg{
+		TickerID:     tickerID,
+		TimeStamp:    timeStamp,
+		ProviderCode: providerCode,
+		ArticleID:    articleID,
+		Headline:     headline,
+		ExtraData:    extraData,
+	}
+}
+
+// NewsProvidersMsg is the NEWS_PROVIDERS msg, which is dispatched to NewsProviders
+type NewsProvidersMsg struct {
+	NewsProviders []NewsProvider
+}
+
+func (m *NewsProvidersMsg) MsgID() IN { return mNEWS_PROVIDERS }
+
+func (m *NewsProvidersMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *NewsProvidersMsg) Dispatch(w IbWrapper) {
+	w.NewsProviders(m.NewsProviders)
+}
+
+func (m *NewsProvidersMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	n := msgBuf.readInt()
+	newsProviders := make([]NewsProvider, 0, msgBuf.capacity(n))
+	for ; n > 0 && msgBuf.err == nil; n-- {
+		provider := NewsProvider{}
+		provider.Code = msgBuf.readString()
+		provider.Name = msgBuf.readString()
+		newsProviders = append(newsProviders, provider)
+	}
+	*m = NewsProvidersMsg{NewsProviders: newsProviders}
+}
+
+// NewsArticleMsg is the NEWS_ARTICLE msg, which is dispatched to NewsArticle
+type NewsArticleMsg struct {
+	ReqID       int64
+	ArticleType int64
+	ArticleText string
+}
+
+func (m *NewsArticleMsg) MsgID() IN { return mNEWS_ARTICLE }
+
+func (m *NewsArticleMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *NewsArticleMsg) Dispatch(w IbWrapper) {
+	w.NewsArticle(m.ReqID, m.ArticleType, m.ArticleText)
+}
+
+func (m *NewsArticleMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+	articleType := msgBuf.readInt()
+	articleText := msgBuf.readString()
+	*m = NewsArticleMsg{ReqID: reqID, ArticleType: articleType, ArticleText: articleText}
+}
+
+// HistoricalNewsMsg is the HISTORICAL_NEWS msg, which is dispatched to HistoricalNews
+type HistoricalNewsMsg struct {
+	ReqID        int64
+	Time         string
+	ProviderCode string
+	ArticleID    string
+	Headline     string
+}
+
+func (m *HistoricalNewsMsg) MsgID() IN { return mHISTORICAL_NEWS }
+
+func (m *HistoricalNewsMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *HistoricalNewsMsg) Dispatch(w IbWrapper) {
+	w.HistoricalNews(m.ReqID, m.Time, m.ProviderCode, m.ArticleID, m.Headline)
+}
+
+func (m *HistoricalNewsMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+	time := msgBuf.readString()
+	providerCode := msgBuf.readString()
+	articleID := msgBuf.readString()
+	headline := msgBuf.readString()
+	*m = HistoricalNewsMsg{
+		ReqID:        reqID,
+		Time:         time,
+		ProviderCode: providerCode,
+		ArticleID:    articleID,
+		Headline:     headline,
+	}
+}
+
+// HistoricalNewsEndMsg is the HISTORICAL_NEWS_END msg, which is dispatched to HistoricalNewsEnd
+type HistoricalNewsEndMsg struct {
+	ReqID   int64
+	HasMore bool
+}
+
+func (m *HistoricalNewsEndMsg) MsgID() IN { return mHISTORICAL_NEWS_END }
+
+func (m *HistoricalNewsEndMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *HistoricalNewsEndMsg) Dispatch(w IbWrapper) {
+	w.HistoricalNewsEnd(m.ReqID, m.HasMore)
+}
+
+func (m *HistoricalNewsEndMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+	hasMore := msgBuf.readBool()
+	*m = HistoricalNewsEndMsg{ReqID: reqID, HasMore: hasMore}
+}
+
+// HistogramDataMsg is the HISTOGRAM_DATA msg, which is dispatched to HistogramData
+type HistogramDataMsg struct {
+	ReqID     int64
+	Histogram []HistogramData
+}
+
+func (m *HistogramDataMsg) MsgID() IN { return mHISTOGRAM_DATA }
+
+func (m *HistogramDataMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *HistogramDataMsg) Dispatch(w IbWrapper) {
+	w.HistogramData(m.ReqID, m.Histogram)
+}
+
+func (m *HistogramDataMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+
+	n := msgBuf.readInt()
+	histogram := make([]HistogramData, 0, msgBuf.capacity(n))
+	for ; n > 0 && msgBuf.err == nil; n-- {
+		p := HistogramData{}
+		p.Price = msgBuf.readFloat()
+		p.Count = msgBuf.readDecimal()
+		histogram = append(histogram, p)
+	}
+	*m = HistogramDataMsg{ReqID: reqID, Histogram: histogram}
+}
+
+// RerouteMktDataReqMsg is the REROUTE_MKT_DATA_REQ msg, which is dispatched to RerouteMktDataReq
+type RerouteMktDataReqMsg struct {
+	ReqID      int64
+	ContractID int64
+	Exchange   string
+}
+
+func (m *RerouteMktDataReqMsg) MsgID() IN { return mREROUTE_MKT_DATA_REQ }
+
+func (m *RerouteMktDataReqMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *RerouteMktDataReqMsg) Dispatch(w IbWrapper) {
+	w.RerouteMktDataReq(m.ReqID, m.ContractID, m.Exchange)
+}
+
+func (m *RerouteMktDataReqMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+	contractID := msgBuf.readInt()
+	exchange := msgBuf.readString()
+	*m = RerouteMktDataReqMsg{ReqID: reqID, ContractID: contractID, Exchange: exchange}
+}
+
+// RerouteMktDepthReqMsg is the REROUTE_MKT_DEPTH_REQ msg, which is dispatched to RerouteMktDepthReq
+type RerouteMktDepthReqMsg struct {
+	ReqID      int64
+	ContractID int64
+	Exchange   string
+}
+
+func (m *RerouteMktDepthReqMsg) MsgID() IN { return mREROUTE_MKT_DEPTH_REQ }
+
+func (m *RerouteMktDepthReqMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *RerouteMktDepthReqMsg) Dispatch(w IbWrapper) {
+	w.RerouteMktDepthReq(m.ReqID, m.ContractID, m.Exchange)
+}
+
+func (m *RerouteMktDepthReqMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+	contractID := msgBuf.readInt()
+	exchange := msgBuf.readString()
+	*m = RerouteMktDepthReqMsg{ReqID: reqID, ContractID: contractID, Exchange: exchange}
+}
+
+// MarketRuleMsg is the MARKET_RULE msg, which is dispatched to MarketRule
+type MarketRuleMsg struct {
+	MarketRuleID    int64
+	PriceIncrements []PriceIncrement
+}
+
+func (m *MarketRuleMsg) MsgID() IN { return mMARKET_RULE }
+
+func (m *MarketRuleMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *MarketRuleMsg) Dispatch(w IbWrapper) {
+	w.MarketRule(m.MarketRuleID, m.PriceIncrements)
+}
+
+func (m *MarketRuleMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	marketRuleID := msgBuf.readInt()
+
+	n := msgBuf.readInt()
+	priceIncrements := make([]PriceIncrement, 0, msgBuf.capacity(n))
+	for ; n > 0 && msgBuf.err == nil; n-- {
+		priceInc := PriceIncrement{}
+		priceInc.LowEdge = msgBuf.readFloat()
+		priceInc.Increment = msgBuf.readFloat()
+		priceIncrements = append(priceIncrements, priceInc)
+	}
+	*m = MarketRuleMsg{MarketRuleID: marketRuleID, PriceIncrements: priceIncrements}
+}
+
+// PnLMsg is the PNL msg, which is dispatched to Pnl
+type PnLMsg struct {
+	ReqID         int64
+	DailyPnL      float64
+	UnrealizedPnL float64
+	RealizedPnL   float64
+}
+
+func (m *PnLMsg) MsgID() IN { return mPNL }
+
+func (m *PnLMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *PnLMsg) Dispatch(w IbWrapper) {
+	w.Pnl(m.ReqID, m.DailyPnL, m.UnrealizedPnL, m.RealizedPnL)
+}
+
+func (m *PnLMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+	dailyPnL := msgBuf.readFloat()
+	var unrealizedPnL float64
+	var realizedPnL float64
+
+	if serverVersion >= mMIN_SERVER_VER_UNREALIZED_PNL {
+		unrealizedPnL = msgBuf.readFloat()
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_REALIZED_PNL {
+		realizedPnL = msgBuf.readFloat()
+	}
+	*m = PnLMsg{
+		ReqID:         reqID,
+		DailyPnL:      dailyPnL,
+		UnrealizedPnL: unrealizedPnL,
+		RealizedPnL:   realizedPnL,
+	}
+}
+
+// PnLSingleMsg is the PNL_SINGLE msg, which is dispatched to PnlSingle
+type PnLSingleMsg struct {
+	ReqID         int64
+	Position      Decimal
+	DailyPnL      float64
+	UnrealizedPnL float64
+	RealizedPnL   float64
+	Value         float64
+}
+
+func (m *PnLSingleMsg) MsgID() IN { return mPNL_SINGLE }
+
+func (m *PnLSingleMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *PnLSingleMsg) Dispatch(w IbWrapper) {
+	w.PnlSingle(m.ReqID, m.Position, m.DailyPnL, m.UnrealizedPnL, m.RealizedPnL, m.Value)
+}
+
+func (m *PnLSingleMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+	position := msgBuf.readDecimal()
+	dailyPnL := msgBuf.readFloat()
+	var unrealizedPnL float64
+	var realizedPnL float64
+
+	if serverVersion >= mMIN_SERVER_VER_UNREALIZED_PNL {
+		unrealizedPnL = msgBuf.readFloat()
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_REALIZED_PNL {
+		realizedPnL = msgBuf.readFloat()
+	}
+
+	value := msgBuf.readFloat()
+	*m = PnLSingleMsg{
+		ReqID:         reqID,
+		Position:      position,
+		DailyPnL:      dailyPnL,
+		UnrealizedPnL: unrealizedPnL,
+		RealizedPnL:   realizedPnL,
+		Value:         value,
+	}
+}
+
+// HistoricalTicksMsg is the HISTORICAL_TICKS msg, which is dispatched to HistoricalTicks
+type HistoricalTicksMsg struct {
+	ReqID int64
+	Ticks []HistoricalTick
+	Done  bool
+}
+
+func (m *HistoricalTicksMsg) MsgID() IN { return mHISTORICAL_TICKS }
+
+func (m *HistoricalTicksMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *HistoricalTicksMsg) Dispatch(w IbWrapper) {
+	w.HistoricalTicks(m.ReqID, m.Ticks, m.Done)
+}
+
+func (m *HistoricalTicksMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+
+	n := msgBuf.readInt()
+	ticks := make([]HistoricalTick, 0, msgBuf.capacity(n))
+	for ; n > 0 && msgBuf.err == nil; n-- {
+		historicalTick := HistoricalTick{}
+		historicalTick.Time = msgBuf.readInt()
+		_ = msgBuf.readString()
+		historicalTick.Price = msgBuf.readFloat()
+		historicalTick.Size = msgBuf.readDecimal()
+		ticks = append(ticks, historicalTick)
+	}
+
+	done := msgBuf.readBool()
+	*m = HistoricalTicksMsg{ReqID: reqID, Ticks: ticks, Done: done}
+}
+
+// HistoricalTicksBidAskMsg is the HISTORICAL_TICKS_BID_ASK msg, which is dispatched to HistoricalTicksBidAsk
+type HistoricalTicksBidAskMsg struct {
+	ReqID int64
+	Ticks []HistoricalTickBidAsk
+	Done  bool
+}
+
+func (m *HistoricalTicksBidAskMsg) MsgID() IN { return mHISTORICAL_TICKS_BID_ASK }
+
+func (m *HistoricalTicksBidAskMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *HistoricalTicksBidAskMsg) Dispatch(w IbWrapper) {
+	w.HistoricalTicksBidAsk(m.ReqID, m.Ticks, m.Done)
+}
+
+func (m *HistoricalTicksBidAskMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+
+	n := msgBuf.readInt()
+	ticks := make([]HistoricalTickBidAsk, 0, msgBuf.capacity(n))
+	for ; n > 0 && msgBuf.err == nil; n-- {
+		historicalTickBidAsk := HistoricalTickBidAsk{}
+		historicalTickBidAsk.Time = msgBuf.readInt()
+
+		mask := msgBuf.readInt()
+		tickAttribBidAsk := TickAttribBidAsk{}
+		tickAttribBidAsk.AskPastHigh = mask&1 != 0
+		tickAttribBidAsk.BidPastLow = mask&2 != 0
+
+		historicalTickBidAsk.TickAttirbBidAsk = tickAttribBidAsk
+		historicalTickBidAsk.PriceBid = msgBuf.readFloat()
+		historicalTickBidAsk.PriceAsk = msgBuf.readFloat()
+		historicalTickBidAsk.SizeBid = msgBuf.readDecimal()
+		historicalTickBidAsk.SizeAsk = msgBuf.readDecimal()
+		ticks = append(ticks, historicalTickBidAsk)
+	}
+
+	done := msgBuf.readBool()
+	*m = HistoricalTicksBidAskMsg{ReqID: reqID, Ticks: ticks, Done: done}
+}
+
+// HistoricalTicksLastMsg is the HISTORICAL_TICKS_LAST msg, which is dispatched to HistoricalTicksLast
+type HistoricalTicksLastMsg struct {
+	ReqID int64
+	Ticks []HistoricalTickLast
+	Done  bool
+}
+
+func (m *HistoricalTicksLastMsg) MsgID() IN { return mHISTORICAL_TICKS_LAST }
+
+func (m *HistoricalTicksLastMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *HistoricalTicksLastMsg) Dispatch(w IbWrapper) {
+	w.HistoricalTicksLast(m.ReqID, m.Ticks, m.Done)
+}
+
+func (m *HistoricalTicksLastMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+
+	n := msgBuf.readInt()
+	ticks := make([]HistoricalTickLast, 0, msgBuf.capacity(n))
+	for ; n > 0 && msgBuf.err == nil; n-- {
+		historicalTickLast := HistoricalTickLast{}
+		historicalTickLast.Time = msgBuf.readInt()
+
+		mask := msgBuf.readInt()
+		tickAttribLast := TickAttribLast{}
+		tickAttribLast.PastLimit = mask&1 != 0
+		tickAttribLast.Unreported = mask&2 != 0
+
+		historicalTickLast.TickAttribLast = tickAttribLast
+		historicalTickLast.Price = msgBuf.readFloat()
+		historicalTickLast.Size = msgBuf.readDecimal()
+		historicalTickLast.Exchange = msgBuf.readString()
+		historicalTickLast.SpecialConditions = msgBuf.readString()
+		ticks = append(ticks, historicalTickLast)
+	}
+
+	done := msgBuf.readBool()
+	*m = HistoricalTicksLastMsg{ReqID: reqID, Ticks: ticks, Done: done}
+}
+
+// TickByTickMsg is the TICK_BY_TICK msg, which is dispatched by TickType:
+// 1(Last) and 2(AllLast) to TickByTickAllLast, 3(BidAsk) to TickByTickBidAsk and 4(MidPoint) to TickByTickMidPoint.
+// Only the fields of the TickType are set.
+type TickByTickMsg struct {
+	ReqID    int64
+	TickType int64
+	Time     int64
+
+	// Last and AllLast
+	Price             float64
+	Size              Decimal
+	TickAttribLast    TickAttribLast
+	Exchange          string
+	SpecialConditions string
+
+	// BidAsk
+	BidPrice         float64
+	AskPrice         float64
+	BidSize          Decimal
+	AskSize          Decimal
+	TickAttribBidAsk TickAttribBidAsk
+
+	// MidPoint
+	MidPoint float64
+}
+
+func (m *TickByTickMsg) MsgID() IN { return mTICK_BY_TICK }
+
+func (m *TickByTickMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *TickByTickMsg) Dispatch(w IbWrapper) {
+	switch m.TickType {
+	case 1, 2:
+		w.TickByTickAllLast(m.ReqID, m.TickType, m.Time, m.Price, m.Size, m.TickAttribLast, m.Exchange, m.SpecialConditions)
+	case 3:
+		w.TickByTickBidAsk(m.ReqID, m.Time, m.BidPrice, m.AskPrice, m.BidSize, m.AskSize, m.TickAttribBidAsk)
+	case 4:
+		w.TickByTickMidPoint(m.ReqID, m.Time, m.MidPoint)
+	}
+}
+
+func (m *TickByTickMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	*m = TickByTickMsg{}
+	m.ReqID = msgBuf.readInt()
+	m.TickType = msgBuf.readInt()
+	m.Time = msgBuf.readInt()
+
+	switch m.TickType {
+	case 0:
+	case 1, 2:
+		m.Price = msgBuf.readFloat()
+		m.Size = msgBuf.readDecimal()
+
+		mask := msgBuf.readInt()
+		m.TickAttribLast.PastLimit = mask&1 != 0
+		m.TickAttribLast.Unreported = mask&2 != 0
+
+		m.Exchange = msgBuf.readString()
+		m.SpecialConditions = msgBuf.readString()
+	case 3:
+		m.BidPrice = msgBuf.readFloat()
+		m.AskPrice = msgBuf.readFloat()
+		m.BidSize = msgBuf.readDecimal()
+		m.AskSize = msgBuf.readDecimal()
+
+		mask := msgBuf.readInt()
+		m.TickAttribBidAsk.BidPastLow = mask&1 != 0
+		m.TickAttribBidAsk.AskPastHigh = mask&2 != 0
+	case 4:
+		m.MidPoint = msgBuf.readFloat()
+	}
+}
+
+// OrderBoundMsg is the ORDER_BOUND msg, which is dispatched to OrderBound
+type OrderBoundMsg struct {
+	ReqID       int64
+	ApiClientID int64
+	ApiOrderID  int64
+}
+
+func (m *OrderBoundMsg) MsgID() IN { return mORDER_BOUND }
+
+func (m *OrderBoundMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *OrderBoundMsg) Dispatch(w IbWrapper) {
+	w.OrderBound(m.ReqID, m.ApiClientID, m.ApiOrderID)
+}
+
+func (m *OrderBoundMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+	apiClientID := msgBuf.readInt()
+	apiOrderID := msgBuf.readInt()
+	*m = OrderBoundMsg{ReqID: reqID, ApiClientID: apiClientID, ApiOrderID: apiOrderID}
+}
+
+// CompletedOrderMsg is the COMPLETED_ORDER msg, which is dispatched to CompletedOrder
+type CompletedOrderMsg struct {
+	Contract   *Contract
+	Order      *Order
+	OrderState *OrderState
+}
+
+func (m *CompletedOrderMsg) MsgID() IN { return mCOMPLETED_ORDER }
+
+func (m *CompletedOrderMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *CompletedOrderMsg) Dispatch(w IbWrapper) {
+	w.CompletedOrder(m.Contract, m.Order, m.OrderState)
+}
+
+func (m *CompletedOrderMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	o := &Order{}
+	c := &Contract{}
+	orderState := &OrderState{}
+
+	version := UNSETINT
+
+	c.ContractID = msgBuf.readInt()
+	c.Symbol = msgBuf.readString()
+	c.SecurityType = msgBuf.readString()
+	c.Expiry = msgBuf.readString()
+	c.Strike = msgBuf.readFloat()
+	c.Right = msgBuf.readString()
+
+	if serverVersion >= 32 {
+		c.Multiplier = msgBuf.readString()
+	}
+
+	c.Exchange = msgBuf.readString()
+	c.Currency = msgBuf.readString()
+	c.LocalSymbol = msgBuf.readString()
+
+	if serverVersion >= 32 {
+		c.TradingClass = msgBuf.readString()
+	}
+
+	o.Action = msgBuf.readString()
+	o.TotalQuantity = msgBuf.readDecimal()
+
+	o.OrderType = msgBuf.readString()
+	if version < 29 {
+		o.LimitPrice = msgBuf.readFloat()
+	} else {
+		o.LimitPrice = msgBuf.readFloatCheckUnset()
+	}
+
+	if version < 30 {
+		o.AuxPrice = msgBuf.readFloat()
+	} else {
+		o.AuxPrice = msgBuf.readFloatCheckUnset()
+	}
+
+	o.TIF = msgBuf.readString()
+	o.OCAGroup = msgBuf.readString()
+	o.Account = msgBuf.readString()
+	o.OpenClose = msgBuf.readString()
+
+	o.Origin = msgBuf.readInt()
+
+	o.OrderRef = msgBuf.readString()
+	o.PermID = msgBuf.readInt()
+
+	o.OutsideRTH = msgBuf.readBool()
+	o.Hidden = msgBuf.readBool()
+	o.DiscretionaryAmount = msgBuf.readFloat()
+	o.GoodAfterTime = msgBuf.readString()
+
+	o.FAGroup = msgBuf.readString()
+	o.FAMethod = msgBuf.readString()
+	o.FAPercentage = msgBuf.readString()
+	o.FAProfile = msgBuf.readString()
+
+	if serverVersion >= mMIN_SERVER_VER_MODELS_SUPPORT {
+		o.ModelCode = msgBuf.readString()
+	}
+
+	o.GoodTillDate = msgBuf.readString()
+
+	o.Rule80A = msgBuf.readString()
+	o.PercentOffset = msgBuf.readFloatCheckUnset() //show_unset
+	o.SettlingFirm = msgBuf.readString()
+
+	//ShortSaleParams
+	o.ShortSaleSlot = msgBuf.readInt()
+	o.DesignatedLocation = msgBuf.readString()
+
+	if serverVersion == mMIN_SERVER_VER_SSHORTX_OLD {
+		_ = msgBuf.readString()
+	} else if version >= 23 {
+		o.ExemptCode = msgBuf.readInt()
+	}
+
+	//BoxOrderParams
+	o.StartingPrice = msgBuf.readFloatCheckUnset() //show_unset
+	o.StockRefPrice = msgBuf.readFloatCheckUnset() //show_unset
+	o.Delta = msgBuf.readFloatCheckUnset()         //show_unset
+
+	//PegToStkOrVolOrderParams
+	o.StockRangeLower = msgBuf.readFloatCheckUnset() //show_unset
+	o.StockRangeUpper = msgBuf.readFloatCheckUnset() //show_unset
+
+	o.DisplaySize = msgBuf.readInt()
+	o.SweepToFill = msgBuf.readBool()
+	o.AllOrNone = msgBuf.readBool()
+	o.MinQty = msgBuf.readIntCheckUnset() //show_unset
+	o.OCAType = msgBuf.readInt()
+	o.TriggerMethod = msgBuf.readInt()
+
+	//VolOrderParams
+	o.Volatility = msgBuf.readFloatCheckUnset() //show_unset
+	o.VolatilityType = msgBuf.readInt()
+	o.DeltaNeutralOrderType = msgBuf.readString()
+	o.DeltaNeutralAuxPrice = msgBuf.readFloatCheckUnset()
+
+	if version >= 27 && o.DeltaNeutralOrderType != "" {
+		o.DeltaNeutralContractID = msgBuf.readInt()
+	}
+
+	if version >= 31 && o.DeltaNeutralOrderType != "" {
+		o.DeltaNeutralShortSale = msgBuf.readBool()
+		o.DeltaNeutralShortSaleSlot = msgBuf.readInt()
+		o.DeltaNeutralDesignatedLocation = msgBuf.readString()
+	}
+
+	o.ContinuousUpdate = msgBuf.readBool()
+	o.ReferencePriceType = msgBuf.readInt()
+
+	//TrailParams
+	o.TrailStopPrice = msgBuf.readFloatCheckUnset()
+	if version >= 30 {
+		o.TrailingPercent = msgBuf.readFloatCheckUnset() //show_unset
+	}
+
+	//ComboLegs
+	c.ComboLegsDescription = msgBuf.readString()
+	if version >= 29 {
+		combolegN := msgBuf.readInt()
+		c.ComboLegs = make([]ComboLeg, 0, msgBuf.capacity(combolegN))
+		for ; combolegN > 0 && msgBuf.err == nil; combolegN-- {
+			// fmt.Println("comboLegsCount:", comboLegsCount)
+			comboleg := ComboLeg{}
+			comboleg.ContractID = msgBuf.readInt()
+			comboleg.Ratio = msgBuf.readInt()
+			comboleg.Action = msgBuf.readString()
+			comboleg.Exchange = msgBuf.readString()
+			comboleg.OpenClose = msgBuf.readInt()
+			comboleg.ShortSaleSlot = msgBuf.readInt()
+			comboleg.DesignatedLocation = msgBuf.readString()
+			comboleg.ExemptCode = msgBuf.readInt()
+			c.ComboLegs = append(c.ComboLegs, comboleg)
+		}
+
+		orderComboLegN := msgBuf.readInt()
+		o.OrderComboLegs = make([]OrderComboLeg, 0, msgBuf.capacity(orderComboLegN))
+		for ; orderComboLegN > 0 && msgBuf.err == nil; orderComboLegN-- {
+			orderComboLeg := OrderComboLeg{}
+			orderComboLeg.Price = msgBuf.readFloatCheckUnset()
+			o.OrderComboLegs = append(o.OrderComboLegs, orderComboLeg)
+		}
+	}
+
+	//SmartComboRoutingParams
+	if version >= 26 {
+		n := msgBuf.readInt()
+		o.SmartComboRoutingParams = make([]TagValue, 0, msgBuf.capacity(n))
+		for ; n > 0 && msgBuf.err == nil; n-- {
+			tagValue := TagValue{}
+			tagValue.Tag = msgBuf.readString()
+			tagValue.Value = msgBuf.readString()
+			o.SmartComboRoutingParams = append(o.SmartComboRoutingParams, tagValue)
+		}
+	}
+
+	//ScaleOrderParams
+	if version >= 20 {
+		o.ScaleInitLevelSize = msgBuf.readIntCheckUnset() //show_unset
+		o.ScaleSubsLevelSize = msgBuf.readIntCheckUnset() //show_unset
+	} else {
+		o.NotSuppScaleNumComponents = msgBuf.readIntCheckUnset()
+		o.ScaleInitLevelSize = msgBuf.readIntCheckUnset()
+	}
+
+	o.ScalePriceIncrement = msgBuf.readFloatCheckUnset()
+
+	if version >= 28 && o.ScalePriceIncrement != UNSETFLOAT && o.ScalePriceIncrement > 0.0 {
+		o.ScalePriceAdjustValue = msgBuf.readFloatCheckUnset()
+		o.ScalePriceAdjustInterval = msgBuf.readIntCheckUnset()
+		o.ScaleProfitOffset = msgBuf.readFloatCheckUnset()
+		o.ScaleAutoReset = msgBuf.readBool()
+		o.ScaleInitPosition = msgBuf.readIntCheckUnset()
+		o.ScaleInitFillQty = msgBuf.readIntCheckUnset()
+		o.ScaleRandomPercent = msgBuf.readBool()
+	}
+
+	//HedgeParams
+	if version >= 24 {
+		o.HedgeType = msgBuf.readString()
+		if o.HedgeType != "" {
+			o.HedgeParam = msgBuf.readString()
+		}
+	}
+
+	o.ClearingAccount = msgBuf.readString()
+	o.ClearingIntent = msgBuf.readString()
+
+	if version >= 22 {
+		o.NotHeld = msgBuf.readBool()
+	}
+
+	// DeltaNeutral
+	if version >= 20 {
+		deltaNeutralContractPresent := msgBuf.readBool()
+		if deltaNeutralContractPresent {
+			c.DeltaNeutralContract = new(DeltaNeutralContract)
+			c.DeltaNeutralContract.ContractID = msgBuf.readInt()
+			c.DeltaNeutralContract.Delta = msgBuf.readFloat()
+			c.DeltaNeutralContract.Price = msgBuf.readFloat()
+		}
+	}
+
+	if version >= 21 {
+		o.AlgoStrategy = msgBuf.readString()
+		if o.AlgoStrategy != "" {
+			n := msgBuf.readInt()
+			o.AlgoParams = make([]TagValue, 0, msgBuf.capacity(n))
+			for ; n > 0 && msgBuf.err == nil; n-- {
+				tagValue := TagValue{}
+				tagValue.Tag = msgBuf.readString()
+				tagValue.Value = msgBuf.readString()
+				o.AlgoParams = append(o.AlgoParams, tagValue)
+			}
+		}
+	}
+
+	if version >= 33 {
+		o.Solictied = msgBuf.readBool()
+	}
+
+	orderState.Status = msgBuf.readString()
+
+	// VolRandomizeFlags
+	if version >= 34 {
+		o.RandomizeSize = msgBuf.readBool()
+		o.RandomizePrice = msgBuf.readBool()
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_PEGGED_TO_BENCHMARK {
+		// PegToBenchParams
+		if o.OrderType == "PEG BENCH" {
+			o.ReferenceContractID = msgBuf.readInt()
+			o.IsPeggedChangeAmountDecrease = msgBuf.readBool()
+			o.PeggedChangeAmount = msgBuf.readFloat()
+			o.ReferenceChangeAmount = msgBuf.readFloat()
+			o.ReferenceExchangeID = msgBuf.readString()
+		}
+
+		// Conditions
+		if n := msgBuf.readInt(); n > 0 {
+			o.Conditions = make([]OrderConditioner, 0, msgBuf.capacity(n))
+			for ; n > 0 && msgBuf.err == nil; n-- {
+				conditionType := msgBuf.readInt()
+				cond, _ := InitOrderCondition(conditionType)
+				cond.decode(msgBuf)
+
+				o.Conditions = append(o.Conditions, cond)
+			}
+			o.ConditionsIgnoreRth = msgBuf.readBool()
+			o.ConditionsCancelOrder = msgBuf.readBool()
+		}
+	}
+
+	// StopPriceAndLmtPriceOffset
+	o.TrailStopPrice = msgBuf.readFloat()
+	o.LimitPriceOffset = msgBuf.readFloat()
+
+	if serverVersion >= mMIN_SERVER_VER_CASH_QTY {
+		o.CashQty = msgBuf.readFloat()
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_AUTO_PRICE_FOR_HEDGE {
+		o.DontUseAutoPriceForHedge = msgBuf.readBool()
+	}
+
+	if serverVersion >= mMIN_SERVER_VER_ORDER_CONTAINER {
+		o.IsOmsContainer = msgBuf.readBool()
+	}
+
+	o.AutoCancelDate = msgBuf.readString()
+	o.FilledQuantity = msgBuf.readDecimal()
+	o.RefFuturesConID = msgBuf.readInt()
+	o.AutoCancelParent = msgBuf.readBool()
+	o.Shareholder = msgBuf.readString()
+	o.ImbalanceOnly = msgBuf.readBool()
+	o.RouteMarketableToBbo = msgBuf.readBool()
+	o.ParentPermID = msgBuf.readInt()
+
+	orderState.CompletedTime = msgBuf.readString()
+	orderState.CompletedStatus = msgBuf.readString()
+
+	decodePegBestPegMidOrderAttributes(serverVersion, msgBuf, o)
+	*m = CompletedOrderMsg{Contract: c, Order: o, OrderState: orderState}
+}
+
+// CompletedOrdersEndMsg is the COMPLETED_ORDERS_END msg, which is dispatched to CompletedOrdersEnd
+type CompletedOrdersEndMsg struct {
+}
+
+func (m *CompletedOrdersEndMsg) MsgID() IN { return mCOMPLETED_ORDERS_END }
+
+func (m *CompletedOrdersEndMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *CompletedOrdersEndMsg) Dispatch(w IbWrapper) {
+	w.CompletedOrdersEnd()
+}
+
+func (m *CompletedOrdersEndMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	if msgBuf.err != nil {
+		return
+	}
+}
+
+// ReplaceFAEndMsg is the REPLACE_FA_END msg, which is dispatched to ReplaceFAEnd
+type ReplaceFAEndMsg struct {
+	ReqID int64
+	Text  string
+}
+
+func (m *ReplaceFAEndMsg) MsgID() IN { return mREPLACE_FA_END }
+
+func (m *ReplaceFAEndMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *ReplaceFAEndMsg) Dispatch(w IbWrapper) {
+	w.ReplaceFAEnd(m.ReqID, m.Text)
+}
+
+func (m *ReplaceFAEndMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+	text := msgBuf.readString()
+	*m = ReplaceFAEndMsg{ReqID: reqID, Text: text}
+}
+
+// WshMetaDataMsg is the WSH_META_DATA msg, which is dispatched to WshMetaData
+type WshMetaDataMsg struct {
+	ReqID    int64
+	DataJSON string
+}
+
+func (m *WshMetaDataMsg) MsgID() IN { return mWSH_META_DATA }
+
+func (m *WshMetaDataMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *WshMetaDataMsg) Dispatch(w IbWrapper) {
+	w.WshMetaData(m.ReqID, m.DataJSON)
+}
+
+func (m *WshMetaDataMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+	dataJson := msgBuf.readString()
+	*m = WshMetaDataMsg{ReqID: reqID, DataJSON: dataJson}
+}
+
+// WshEventDataMsg is the WSH_EVENT_DATA msg, which is dispatched to WshEventData
+type WshEventDataMsg struct {
+	ReqID    int64
+	DataJSON string
+}
+
+func (m *WshEventDataMsg) MsgID() IN { return mWSH_EVENT_DATA }
+
+func (m *WshEventDataMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *WshEventDataMsg) Dispatch(w IbWrapper) {
+	w.WshEventData(m.ReqID, m.DataJSON)
+}
+
+func (m *WshEventDataMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+	dataJson := msgBuf.readString()
+	*m = WshEventDataMsg{ReqID: reqID, DataJSON: dataJson}
+}
+
+// HistoricalScheduleMsg is the HISTORICAL_SCHEDULE msg, which is dispatched to HistoricalSchedule
+type HistoricalScheduleMsg struct {
+	ReqID         int64
+	StartDateTime string
+	EndDateTime   string
+	TimeZone      string
+	Sessions      []HistoricalSession
+}
+
+func (m *HistoricalScheduleMsg) MsgID() IN { return mHISTORICAL_SCHEDULE }
+
+func (m *HistoricalScheduleMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *HistoricalScheduleMsg) Dispatch(w IbWrapper) {
+	w.HistoricalSchedule(m.ReqID, m.StartDateTime, m.EndDateTime, m.TimeZone, m.Sessions)
+}
+
+func (m *HistoricalScheduleMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+	startDateTime := msgBuf.readString()
+	endDateTime := msgBuf.readString()
+	timeZone := msgBuf.readString()
+
+	n := msgBuf.readInt()
+	sessions := make([]HistoricalSession, 0, msgBuf.capacity(n))
+	for ; n > 0 && msgBuf.err == nil; n-- {
+		session := HistoricalSession{}
+		session.StartDateTime = msgBuf.readString()
+		session.EndDateTime = msgBuf.readString()
+		session.RefDate = msgBuf.readString()
+		sessions = append(sessions, session)
+	}
+	*m = HistoricalScheduleMsg{ReqID: reqID, StartDateTime: startDateTime, EndDateTime: endDateTime, TimeZone: timeZone, Sessions: sessions}
+}
+
+// UserInfoMsg is the USER_INFO msg, which is dispatched to UserInfo
+type UserInfoMsg struct {
+	ReqID           int64
+	WhiteBrandingID string
+}
+
+func (m *UserInfoMsg) MsgID() IN { return mUSER_INFO }
+
+func (m *UserInfoMsg) Decode(version Version, msgBytes []byte) error {
+	return decodeMessage(m, version, msgBytes)
+}
+
+func (m *UserInfoMsg) Dispatch(w IbWrapper) {
+	w.UserInfo(m.ReqID, m.WhiteBrandingID)
+}
+
+func (m *UserInfoMsg) decode(serverVersion Version, msgBuf *MsgBuffer) {
+	reqID := msgBuf.readInt()
+	whiteBrandingID := msgBuf.readString()
+	*m = UserInfoMsg{ReqID: reqID, WhiteBrandingID: whiteBrandingID}
+}
@@ -0,0 +1,135 @@
+package ibapi
+
+import (
+	"errors"
+	"testing"
+)
+
+type tickWrapper struct {
+	Wrapper
+	prices []float64
+	sizes  []Decimal
+}
+
+func (w *tickWrapper) TickPrice(reqID int64, tickType int64, price float64, attrib TickAttrib) {
+	w.prices = append(w.prices, price)
+}
+
+func (w *tickWrapper) TickSize(reqID int64, tickType int64, size Decimal) {
+	w.sizes = append(w.sizes, size)
+}
+
+func TestNewMessage(t *testing.T) {
+	for msgID := range newMessages {
+		if m := NewMessage(msgID); m == nil || m.MsgID() != msgID {
+			t.Errorf("the Message of msg ID %d is %T", msgID, m)
+		}
+	}
+
+	if m := NewMessage(-1); m != nil {
+		t.Errorf("unknown msg ID should have no Message, got %T", m)
+	}
+}
+
+func TestDecodeMessage(t *testing.T) {
+	for _, s := range fuzzSeeds {
+		m, err := DecodeMessage(fuzzSeedVersion, s.msg())
+		if err != nil {
+			t.Errorf("%s: %v", s.name, err)
+			continue
+		}
+		if m.MsgID() != s.msgID {
+			t.Errorf("%s: decoded as %T", s.name, m)
+		}
+	}
+
+	m, err := DecodeMessage(fuzzSeedVersion, makeMsgBytes(mORDER_STATUS, 7, "Filled", 100, 0, 187.5, 1801234567, 0, 187.5, 1, "", 0)[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := OrderStatusMsg{OrderID: 7, Status: "Filled", Filled: DecimalFromInt(100), AvgFillPrice: 187.5, PermID: 1801234567, LastFillPrice: 187.5, ClientID: 1}
+	if got, ok := m.(*OrderStatusMsg); !ok || *got != want {
+		t.Errorf("unexpected order status %+v", m)
+	}
+
+	if m, err := DecodeMessage(fuzzSeedVersion, makeMsgBytes(9999, 1)[4:]); m != nil || err != nil {
+		t.Errorf("unknown msg ID should be skipped, got %T, %v", m, err)
+	}
+
+	var decodeErr *DecodeError
+	if _, err := DecodeMessage(fuzzSeedVersion, makeMsgBytes(mORDER_STATUS, 7, "Filled")[4:]); !errors.As(err, &decodeErr) || decodeErr.MsgID != mORDER_STATUS {
+		t.Errorf("truncated msg should be DecodeError, got %v", err)
+	}
+}
+
+func TestMessageDecode(t *testing.T) {
+	msg := makeMsgBytes(mTICK_PRICE, 6, 1, BID, 23403.5, 3, 0)[4:]
+
+	var tick TickPriceMsg
+	if err := tick.Decode(fuzzSeedVersion, msg); err != nil {
+		t.Fatal(err)
+	}
+	if tick.ReqID != 1 || tick.TickType != BID || tick.Price != 23403.5 || tick.Size != DecimalFromInt(3) {
+		t.Errorf("unexpected tick price %+v", tick)
+	}
+
+	if err := new(TickSizeMsg).Decode(fuzzSeedVersion, msg); err == nil {
+		t.Error("TICK_PRICE should not be decoded as TickSizeMsg")
+	}
+
+	w := &tickWrapper{}
+	tick.Dispatch(w)
+	if len(w.prices) != 1 || w.prices[0] != 23403.5 || len(w.sizes) != 1 || w.sizes[0] != DecimalFromInt(3) {
+		t.Errorf("TickPriceMsg should be dispatched to TickPrice and TickSize, got %v %v", w.prices, w.sizes)
+	}
+
+	w = &tickWrapper{}
+	d := &ibDecoder{wrapper: w}
+	d.setVersion(fuzzSeedVersion)
+	d.setmsgID2process()
+	if err := d.interpret(msg); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.prices) != 1 || len(w.sizes) != 1 {
+		t.Errorf("the decoder should dispatch the same callbacks, got %v %v", w.prices, w.sizes)
+	}
+}
+
+type rejectWrapper struct {
+	Wrapper
+	errors  []int64
+	rejects []string
+}
+
+func (w *rejectWrapper) Error(reqID int64, errCode int64, errString string) {
+	w.errors = append(w.errors, errCode)
+}
+
+func (w *rejectWrapper) ErrorWithAdvancedOrderReject(reqID int64, errCode int64, errString string, advancedOrderRejectJSON string) {
+	w.rejects = append(w.rejects, advancedOrderRejectJSON)
+}
+
+func TestDecodeAdvancedOrderReject(t *testing.T) {
+	reject := `{"rejectReason":"size"}`
+	msg := makeMsgBytes(mERR_MSG, 2, 7, 201, "Order rejected", reject)[4:]
+
+	m, err := DecodeMessage(mMIN_SERVER_VER_ADVANCED_ORDER_REJECT, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, ok := m.(*ErrorMsg); !ok || e.AdvancedOrderRejectJSON != reject {
+		t.Fatalf("unexpected error msg %+v", m)
+	}
+
+	w := &rejectWrapper{}
+	m.Dispatch(newDispatcher(w))
+	m.Dispatch(&tickWrapper{})
+	(&ErrorMsg{ReqID: 7, ErrCode: 202, ErrString: "Order Canceled"}).Dispatch(w)
+	if len(w.rejects) != 1 || w.rejects[0] != reject || len(w.errors) != 1 || w.errors[0] != 202 {
+		t.Errorf("the advanced order reject should be dispatched to ErrorWithAdvancedOrderReject, got %v %v", w.rejects, w.errors)
+	}
+
+	if _, err := DecodeMessage(fuzzSeedVersion, msg); err != nil {
+		t.Errorf("the older server version should ignore the extra field, got %v", err)
+	}
+}
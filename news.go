@@ -0,0 +1,331 @@
+/*
+news contains NewsClient, which lists the news providers, streams the headlines, pages the historical news
+and decodes the articles, and NewsItem parsed from the headlines and their extraData.
+*/
+
+package ibapi
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// the articleType of NewsArticle
+const (
+	NEWS_ARTICLE_TEXT   int64 = 0 // plain text or html
+	NEWS_ARTICLE_BINARY int64 = 1 // base64 encoded binary, such as pdf
+)
+
+// NEWS_GENERIC_TICKS is the generic tick list of the news headlines, mdoff turns off the market data of the contract
+const NEWS_GENERIC_TICKS = "mdoff,292"
+
+// MAX_HISTORICAL_NEWS is the max totalResults of ReqHistoricalNews
+const MAX_HISTORICAL_NEWS = 300
+
+// NewsItem is a headline from TickNews or HistoricalNews.
+/*
+Headline is stripped of the metadata prefix such as "{A:800015:L:en:K:n/a:C:0.6}",
+which is parsed into ExtraData as the extraData of TickNews.
+Language, Sentiment and Confidence are taken from ExtraData, L, K and C, UNSETFLOAT if absent or n/a.
+*/
+type NewsItem struct {
+	Time         time.Time
+	ProviderCode string
+	ArticleID    string
+	Headline     string
+	Language     string
+	Sentiment    float64
+	Confidence   float64
+	ExtraData    map[string]string
+}
+
+func (n NewsItem) String() string {
+	return fmt.Sprintf("NewsItem<Time: %s, ProviderCode: %s, ArticleID: %s, Headline: %s>",
+		n.Time.Format("2006-01-02 15:04:05 MST"),
+		n.ProviderCode,
+		n.ArticleID,
+		n.Headline)
+}
+
+// ParseNewsExtraData parses the key/values of extraData, such as "A:800015:L:en:K:n/a:C:0.6"
+func ParseNewsExtraData(extraData string) map[string]string {
+	extraData = strings.Trim(strings.TrimSpace(extraData), "{}")
+	if extraData == "" {
+		return map[string]string{}
+	}
+
+	fields := strings.Split(extraData, ":")
+	kvs := make(map[string]string, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		kvs[fields[i]] = fields[i+1]
+	}
+	return kvs
+}
+
+// NewNewsItem creates the NewsItem of a headline, extraData is merged with the metadata prefix of the headline
+func NewNewsItem(t time.Time, providerCode string, articleID string, headline string, extraData string) NewsItem {
+	kvs := ParseNewsExtraData(extraData)
+	if strings.HasPrefix(headline, "{") {
+		if i := strings.Index(headline, "}"); i > 0 {
+			for k, v := range ParseNewsExtraData(headline[:i+1]) {
+				if _, ok := kvs[k]; !ok {
+					kvs[k] = v
+				}
+			}
+			headline = strings.TrimPrefix(headline[i+1:], "!")
+		}
+	}
+
+	return NewsItem{
+		Time:         t,
+		ProviderCode: providerCode,
+		ArticleID:    articleID,
+		Headline:     strings.TrimSpace(headline),
+		Language:     kvs["L"],
+		Sentiment:    parseNewsFloat(kvs["K"]),
+		Confidence:   parseNewsFloat(kvs["C"]),
+		ExtraData:    kvs,
+	}
+}
+
+func parseNewsFloat(s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return UNSETFLOAT
+	}
+	return f
+}
+
+// NewsArticle is the article of ReqNewsArticle, Binary is decoded from the base64 Text if Type is NEWS_ARTICLE_BINARY
+type NewsArticle struct {
+	Type   int64
+	Text   string
+	Binary []byte
+}
+
+// IsBinary reports whether the article is binary, such as pdf
+func (a *NewsArticle) IsBinary() bool {
+	return a.Type == NEWS_ARTICLE_BINARY
+}
+
+// NewNewsArticle decodes the article of NewsArticle
+func NewNewsArticle(articleType int64, articleText string) (*NewsArticle, error) {
+	article := &NewsArticle{Type: articleType, Text: articleText}
+	if articleType == NEWS_ARTICLE_BINARY {
+		b, err := base64.StdEncoding.DecodeString(articleText)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the binary article: %w", err)
+		}
+		article.Binary = b
+	}
+	return article, nil
+}
+
+// NewsClient requests the news by IbClient.
+/*
+Location is the time zone of the times of the historical news, which is the time zone of TWS login.
+It is nil by default, which means IbClient.Location of the current connection.
+*/
+type NewsClient struct {
+	ic       *IbClient
+	Location *time.Location
+}
+
+// NewNewsClient creates a NewsClient of ic
+func NewNewsClient(ic *IbClient) *NewsClient {
+	return &NewsClient{ic: ic}
+}
+
+// location is Location, or the time zone of TWS login if it is nil
+func (nc *NewsClient) location() *time.Location {
+	if nc.Location != nil {
+		return nc.Location
+	}
+	return nc.ic.Location()
+}
+
+// newsProvidersWaiter observes NewsProviders for Providers
+type newsProvidersWaiter struct {
+	ch chan []NewsProvider
+}
+
+func (w *newsProvidersWaiter) NewsProviders(newsProviders []NewsProvider) {
+	select {
+	case w.ch <- newsProviders:
+	default:
+	}
+}
+
+// Providers requests the news providers subscribed
+func (nc *NewsClient) Providers(ctx context.Context) ([]NewsProvider, error) {
+	if !nc.ic.IsConnected() {
+		return nil, NOT_CONNECTED
+	}
+
+	w := &newsProvidersWaiter{make(chan []NewsProvider, 1)}
+	nc.ic.AddObserver(w)
+	defer nc.ic.RemoveObserver(w)
+
+	nc.ic.ReqNewsProviders()
+
+	select {
+	case providers := <-w.ch:
+		return providers, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// SubscribeBroadTape subscribes the headlines of all the contracts from the provider, such as BRFG, BZ or DJNL.
+// f is called on the decoder goroutine, it should not block. The subscription is canceled by the returned func.
+func (nc *NewsClient) SubscribeBroadTape(providerCode string, f func(NewsItem)) (cancel func(), err error) {
+	contract := &Contract{
+		Symbol:       providerCode + ":" + providerCode + "_ALL",
+		SecurityType: "NEWS",
+		Exchange:     providerCode,
+	}
+	return nc.subscribe(contract, NEWS_GENERIC_TICKS, f)
+}
+
+// SubscribeContract subscribes the headlines of the contract from the providers, such as BRFG, BZ or DJNL.
+// f is called on the decoder goroutine, it should not block. The subscription is canceled by the returned func.
+func (nc *NewsClient) SubscribeContract(contract *Contract, providerCodes []string, f func(NewsItem)) (cancel func(), err error) {
+	if len(providerCodes) == 0 {
+		return nil, fmt.Errorf("no news provider")
+	}
+	return nc.subscribe(contract, NEWS_GENERIC_TICKS+":"+strings.Join(providerCodes, "+"), f)
+}
+
+func (nc *NewsClient) subscribe(contract *Contract, genericTickList string, f func(NewsItem)) (func(), error) {
+	if !nc.ic.IsConnected() {
+		return nil, NOT_CONNECTED
+	}
+
+	reqID := nc.ic.GetReqID()
+	nc.ic.dispatcher.register(nc.ic.dispatcher.handlers, reqID, func(msg interface{}) {
+		switch m := msg.(type) {
+		case *tickNewsMsg:
+			f(NewNewsItem(time.Unix(0, m.timeStamp*int64(time.Millisecond)), m.providerCode, m.articleID, m.headline, m.extraData))
+		case *errorMsg:
+			log.Warn("news subscription error", zap.Int64("reqID", reqID), zap.Int64("errCode", m.code), zap.String("errString", m.msg))
+		}
+	})
+	nc.ic.ReqMktData(reqID, contract, genericTickList, false, false, nil)
+
+	return func() {
+		nc.ic.CancelMktData(reqID)
+		nc.ic.dispatcher.mute(nc.ic.dispatcher.handlers, reqID, muteDuration)
+	}, nil
+}
+
+// formatNewsTime formats t as "yyyy-MM-dd HH:mm:ss.0" for ReqHistoricalNews, the zero time is ""
+func (nc *NewsClient) formatNewsTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(nc.location()).Format("2006-01-02 15:04:05") + ".0"
+}
+
+// parseNewsTime parses the time of HistoricalNews, "yyyy-MM-dd HH:mm:ss.0"
+func (nc *NewsClient) parseNewsTime(s string) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02 15:04:05", strings.TrimSuffix(strings.TrimSpace(s), ".0"), nc.location())
+}
+
+// HistoricalNews requests the headlines of the contract from the providers in (start, end], newest first.
+/*
+It pages ReqHistoricalNews by MAX_HISTORICAL_NEWS until hasMore is false,
+moving the end of the next page to the oldest headline of the last one,
+limit stops it once the number of the headlines reaches it, 0 means no limit.
+It fails with the headlines so far if a page of hasMore adds no new headline, as the paging could not move on.
+*/
+func (nc *NewsClient) HistoricalNews(ctx context.Context, conID int64, providerCodes []string, start time.Time, end time.Time, limit int) ([]NewsItem, error) {
+	var items []NewsItem
+	seen := make(map[string]bool)
+	providers := strings.Join(providerCodes, "+")
+
+	for {
+		page, hasMore, err := nc.historicalNewsPage(ctx, conID, providers, start, end)
+		if err != nil {
+			return items, err
+		}
+
+		added := 0
+		for _, item := range page {
+			if seen[item.ProviderCode+item.ArticleID] {
+				continue
+			}
+			seen[item.ProviderCode+item.ArticleID] = true
+			items = append(items, item)
+			added++
+			if limit > 0 && len(items) >= limit {
+				return items, nil
+			}
+			if end.IsZero() || item.Time.Before(end) {
+				end = item.Time
+			}
+		}
+
+		if !hasMore {
+			return items, nil
+		}
+		if added == 0 {
+			return items, fmt.Errorf("historical news has more before %s, but the page adds no new headline", nc.formatNewsTime(end))
+		}
+	}
+}
+
+func (nc *NewsClient) historicalNewsPage(ctx context.Context, conID int64, providers string, start time.Time, end time.Time) ([]NewsItem, bool, error) {
+	if !nc.ic.IsConnected() {
+		return nil, false, NOT_CONNECTED
+	}
+
+	reqID := nc.ic.GetReqID()
+	var items []NewsItem
+	var hasMore bool
+	err := nc.ic.request(ctx, reqID, func() {
+		nc.ic.ReqHistoricalNews(reqID, conID, providers, nc.formatNewsTime(start), nc.formatNewsTime(end), MAX_HISTORICAL_NEWS, nil)
+	}, func(msg interface{}) bool {
+		switch m := msg.(type) {
+		case *historicalNewsMsg:
+			t, err := nc.parseNewsTime(m.time)
+			if err != nil {
+				log.Warn("failed to parse the time of historical news", zap.String("time", m.time), zap.Error(err))
+			}
+			items = append(items, NewNewsItem(t, m.providerCode, m.articleID, m.headline, ""))
+		case *historicalNewsEndMsg:
+			hasMore = m.hasMore
+			return true
+		}
+		return false
+	})
+
+	return items, hasMore, err
+}
+
+// Article requests and decodes the article
+func (nc *NewsClient) Article(ctx context.Context, providerCode string, articleID string) (*NewsArticle, error) {
+	if !nc.ic.IsConnected() {
+		return nil, NOT_CONNECTED
+	}
+
+	reqID := nc.ic.GetReqID()
+	var article *newsArticleMsg
+	err := nc.ic.request(ctx, reqID, func() { nc.ic.ReqNewsArticle(reqID, providerCode, articleID, nil) }, func(msg interface{}) bool {
+		if m, ok := msg.(*newsArticleMsg); ok {
+			article = m
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewNewsArticle(article.articleType, article.articleText)
+}
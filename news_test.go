@@ -0,0 +1,149 @@
+package ibapi
+
+import (
+	"context"
+	"encoding/base64"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewsItem(t *testing.T) {
+	item := NewNewsItem(time.Unix(0, 0), "BRFG", "BRFG$0a1b", "{A:800015:L:en:K:-0.96:C:0.9999}!Apple beats estimates", "")
+	if item.Headline != "Apple beats estimates" || item.Language != "en" || item.Sentiment != -0.96 || item.Confidence != 0.9999 || item.ExtraData["A"] != "800015" {
+		t.Errorf("unexpected item: %+v", item)
+	}
+
+	item = NewNewsItem(time.Unix(0, 0), "BZ", "BZ$1", "Plain headline", "A:800015:L:de:K:n/a:C:0.5")
+	if item.Headline != "Plain headline" || item.Language != "de" || item.Sentiment != UNSETFLOAT || item.Confidence != 0.5 {
+		t.Errorf("unexpected item: %+v", item)
+	}
+
+	pdf := []byte("%PDF-1.4")
+	article, err := NewNewsArticle(NEWS_ARTICLE_BINARY, base64.StdEncoding.EncodeToString(pdf))
+	if err != nil || !article.IsBinary() || string(article.Binary) != string(pdf) {
+		t.Errorf("unexpected binary article: %+v, %v", article, err)
+	}
+	if _, err := NewNewsArticle(NEWS_ARTICLE_BINARY, "not base64!"); err == nil {
+		t.Error("invalid base64 should fail")
+	}
+	if article, err := NewNewsArticle(NEWS_ARTICLE_TEXT, "<p>text</p>"); err != nil || article.IsBinary() || article.Text != "<p>text</p>" {
+		t.Errorf("unexpected text article: %+v, %v", article, err)
+	}
+}
+
+func TestNewsClient(t *testing.T) {
+	// 5 headlines, 2 on each page, each page overlapping the last one at its end
+	headlines := []string{"2020-08-07 10:05:00.0", "2020-08-07 10:04:00.0", "2020-08-07 10:03:00.0", "2020-08-07 10:02:00.0", "2020-08-07 10:01:00.0"}
+	var mu sync.Mutex
+	var ends []string
+
+	ic := newTestClient(func(ic *IbClient, req []byte) {
+		fields := splitMsgBytes(req[4:])
+		switch fieldInt(fields[0]) {
+		case mREQ_NEWS_PROVIDERS:
+			ic.dispatcher.NewsProviders([]NewsProvider{{Code: "BRFG", Name: "Briefing.com General Market Columns"}})
+		case mREQ_MKT_DATA:
+			reqID := fieldInt(fields[2])
+			ic.dispatcher.TickNews(reqID, 1596794700000, "BRFG", "BRFG$1", "{A:800015:L:en:K:n/a:C:0.6}Market news", "")
+		case mREQ_NEWS_ARTICLE:
+			ic.dispatcher.NewsArticle(fieldInt(fields[1]), NEWS_ARTICLE_TEXT, "article")
+		case mREQ_HISTORICAL_NEWS:
+			reqID := fieldInt(fields[1])
+			end := decodeString(fields[5])
+			mu.Lock()
+			ends = append(ends, end)
+			mu.Unlock()
+
+			n := 0
+			for i, h := range headlines {
+				if end != "" && h > end {
+					continue
+				}
+				ic.dispatcher.HistoricalNews(reqID, h, "BRFG", "BRFG$"+h, "headline")
+				if n++; n == 2 {
+					ic.dispatcher.HistoricalNewsEnd(reqID, i < len(headlines)-1)
+					return
+				}
+			}
+			ic.dispatcher.HistoricalNewsEnd(reqID, false)
+		}
+	})
+
+	nc := NewNewsClient(ic)
+	nc.Location = time.UTC
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	providers, err := nc.Providers(ctx)
+	if err != nil || len(providers) != 1 || providers[0].Code != "BRFG" {
+		t.Fatalf("unexpected providers: %v, %v", providers, err)
+	}
+
+	items := make(chan NewsItem, 1)
+	stop, err := nc.SubscribeBroadTape("BRFG", func(item NewsItem) { items <- item })
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case item := <-items:
+		if item.Headline != "Market news" || item.Time.Unix() != 1596794700 {
+			t.Errorf("unexpected tick news: %+v", item)
+		}
+	case <-ctx.Done():
+		t.Fatal("no tick news")
+	}
+	stop()
+
+	news, err := nc.HistoricalNews(ctx, 265598, []string{"BRFG"}, time.Time{}, time.Time{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(news) != len(headlines) {
+		t.Fatalf("unexpected historical news: %v", news)
+	}
+	for i, item := range news {
+		if item.Time.Format("2006-01-02 15:04:05") != headlines[i][:19] {
+			t.Errorf("unexpected historical news %d: %s", i, item)
+		}
+	}
+	mu.Lock()
+	if len(ends) < 3 || ends[0] != "" || ends[1] != "2020-08-07 10:04:00.0" {
+		t.Errorf("unexpected pages: %v", ends)
+	}
+	mu.Unlock()
+
+	if limited, err := nc.HistoricalNews(ctx, 265598, []string{"BRFG"}, time.Time{}, time.Time{}, 3); err != nil || len(limited) != 3 {
+		t.Errorf("unexpected limited news: %v, %v", limited, err)
+	}
+
+	article, err := nc.Article(ctx, "BRFG", "BRFG$1")
+	if err != nil || article.Text != "article" {
+		t.Errorf("unexpected article: %+v, %v", article, err)
+	}
+}
+
+func TestNewsClientStuckPaging(t *testing.T) {
+	ic := newTestClient(func(ic *IbClient, req []byte) {
+		fields := splitMsgBytes(req[4:])
+		if fieldInt(fields[0]) == mREQ_HISTORICAL_NEWS {
+			reqID := fieldInt(fields[1])
+			ic.dispatcher.HistoricalNews(reqID, "2020-08-07 10:05:00.0", "BRFG", "BRFG$1", "headline")
+			ic.dispatcher.HistoricalNewsEnd(reqID, true)
+		}
+	})
+	ic.connTime = "20200807 10:00:00 Hongkong Standard Time"
+
+	nc := NewNewsClient(ic)
+	if nc.location().String() != "Asia/Hong_Kong" {
+		t.Errorf("the location should be that of TWS login, got %s", nc.location())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	news, err := nc.HistoricalNews(ctx, 265598, []string{"BRFG"}, time.Time{}, time.Time{}, 0)
+	if err == nil || len(news) != 1 {
+		t.Errorf("the stuck paging should fail, got %v, %v", news, err)
+	}
+}
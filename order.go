@@ -2,6 +2,7 @@ package ibapi
 
 import (
 	"fmt"
+	"math"
 )
 
 const (
@@ -10,6 +11,9 @@ const (
 	UNKNOWN
 )
 
+// COMPETE_AGAINST_BEST_OFFSET_UP_TO_MID is the CompeteAgainstBestOffset of PEG BEST order to compete up to the mid
+var COMPETE_AGAINST_BEST_OFFSET_UP_TO_MID = math.Inf(1)
+
 const (
 	AUCTION_UNSET int64 = iota
 	AUCTION_MATCH
@@ -23,7 +27,7 @@ type Order struct {
 	ClientID                      int64
 	PermID                        int64
 	Action                        string
-	TotalQuantity                 float64
+	TotalQuantity                 Decimal
 	OrderType                     string
 	LimitPrice                    float64 `default:"UNSETFLOAT"`
 	AuxPrice                      float64 `default:"UNSETFLOAT"`
@@ -189,7 +193,7 @@ type Order struct {
 	DiscretionaryUpToLimitPrice bool
 
 	AutoCancelDate       string
-	FilledQuantity       float64 `default:"UNSETFLOAT"`
+	FilledQuantity       Decimal `default:"UNSETDECIMAL"`
 	RefFuturesConID      int64
 	AutoCancelParent     bool
 	Shareholder          string
@@ -201,11 +205,21 @@ type Order struct {
 	Duration         int64 `default:"UNSETINT"`
 	PostToAts        int64 `default:"UNSETINT"`
 
+	AdvancedErrorOverride string
+	ManualOrderTime       string
+
+	//-----PEG BEST and PEG MID orders--------
+	MinTradeQty              int64   `default:"UNSETINT"`
+	MinCompeteSize           int64   `default:"UNSETINT"`
+	CompeteAgainstBestOffset float64 `default:"UNSETFLOAT"`
+	MidOffsetAtWhole         float64 `default:"UNSETFLOAT"`
+	MidOffsetAtHalf          float64 `default:"UNSETFLOAT"`
+
 	SoftDollarTier SoftDollarTier
 }
 
 func (o Order) String() string {
-	s := fmt.Sprintf("Order<OrderID: %d, ClientID: %d, PermID: %d> -- <%s %s %.2f@%f %s> --",
+	s := fmt.Sprintf("Order<OrderID: %d, ClientID: %d, PermID: %d> -- <%s %s %s@%f %s> --",
 		o.OrderID,
 		o.ClientID,
 		o.PermID,
@@ -325,10 +339,16 @@ func NewOrder() *Order {
 	order.LimitPriceOffset = UNSETFLOAT
 
 	order.CashQty = UNSETFLOAT
-	order.FilledQuantity = UNSETFLOAT
+	order.FilledQuantity = UNSETDECIMAL
 	order.Duration = UNSETINT
 	order.PostToAts = UNSETINT
 
+	order.MinTradeQty = UNSETINT
+	order.MinCompeteSize = UNSETINT
+	order.CompeteAgainstBestOffset = UNSETFLOAT
+	order.MidOffsetAtWhole = UNSETFLOAT
+	order.MidOffsetAtHalf = UNSETFLOAT
+
 	return order
 }
 
@@ -338,7 +358,7 @@ func NewLimitOrder(action string, lmtPrice float64, quantity float64) *Order {
 	o.OrderType = "LMT"
 	o.Action = action
 	o.LimitPrice = lmtPrice
-	o.TotalQuantity = quantity
+	o.TotalQuantity = DecimalFromFloat(quantity)
 
 	return o
 }
@@ -348,7 +368,7 @@ func NewMarketOrder(action string, quantity float64) *Order {
 	o := NewOrder()
 	o.OrderType = "MKT"
 	o.Action = action
-	o.TotalQuantity = quantity
+	o.TotalQuantity = DecimalFromFloat(quantity)
 
 	return o
 }
@@ -0,0 +1,293 @@
+/*
+pnl contains PnLTracker, which keeps a ReqPnLSingle subscription for every position of a PositionBook
+and aggregates their PnL by account.
+*/
+
+package ibapi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PnL is the daily, unrealized and realized PnL, the values unknown yet are UNSETFLOAT
+type PnL struct {
+	DailyPnL      float64
+	UnrealizedPnL float64
+	RealizedPnL   float64
+}
+
+func newPnL() PnL {
+	return PnL{UNSETFLOAT, UNSETFLOAT, UNSETFLOAT}
+}
+
+func (p PnL) String() string {
+	return fmt.Sprintf("PnL<Daily: %v, Unrealized: %v, Realized: %v>", p.DailyPnL, p.UnrealizedPnL, p.RealizedPnL)
+}
+
+// add sums the values which are set, a sum stays UNSETFLOAT until any of its values is set
+func (p *PnL) add(o PnL) {
+	addSet := func(sum *float64, v float64) {
+		if v == UNSETFLOAT {
+			return
+		}
+		if *sum == UNSETFLOAT {
+			*sum = 0
+		}
+		*sum += v
+	}
+	addSet(&p.DailyPnL, o.DailyPnL)
+	addSet(&p.UnrealizedPnL, o.UnrealizedPnL)
+	addSet(&p.RealizedPnL, o.RealizedPnL)
+}
+
+// PositionPnL is the PnL of a position reported by PnlSingle
+type PositionPnL struct {
+	PositionKey
+	PnL
+	Position  Decimal
+	Value     float64
+	UpdatedAt time.Time // zero until the first PnlSingle
+}
+
+func (p PositionPnL) String() string {
+	return fmt.Sprintf("PositionPnL<Account: %s, ModelCode: %s, ConID: %d, Position: %s, Value: %v, %s>",
+		p.Account, p.ModelCode, p.ContractID, p.Position, p.Value, p.PnL)
+}
+
+// PnLSummary is the PnL of all the tracked positions at Time
+type PnLSummary struct {
+	Time      time.Time
+	Total     PnL
+	Value     float64
+	Accounts  map[string]PnL
+	Positions []PositionPnL
+}
+
+// PnLTracker subscribes PnlSingle for the positions of a PositionBook.
+/*
+ReqPnLSingle is issued with a new reqID when a position is opened,
+and CancelPnLSingle when it is closed, the PnlSingle of them are not delivered to the wrapper.
+The position changes are queued to the goroutine of the tracker, so that the decoder is never blocked by the requests.
+The PositionBook must be fed by the same IbClient, see PositionBook.
+*/
+type PnLTracker struct {
+	ic          *IbClient
+	book        *PositionBook
+	mu          sync.RWMutex
+	reqIDs      map[PositionKey]int64
+	pnls        map[int64]*PositionPnL // keyed by reqID
+	unsubscribe func()
+	quit        chan struct{}
+	stopped     chan struct{}
+}
+
+// pnlOp is a subscription change queued to the goroutine of PnLTracker
+type pnlOp struct {
+	key  PositionKey
+	open bool
+}
+
+// NewPnLTracker create a PnLTracker of the positions of book
+func NewPnLTracker(ic *IbClient, book *PositionBook) *PnLTracker {
+	return &PnLTracker{
+		ic:     ic,
+		book:   book,
+		reqIDs: make(map[PositionKey]int64),
+		pnls:   make(map[int64]*PositionPnL),
+	}
+}
+
+// Start subscribes the PnL of the current positions and watches the PositionBook for the new ones
+func (t *PnLTracker) Start() {
+	t.mu.Lock()
+	if t.unsubscribe != nil {
+		t.mu.Unlock()
+		return
+	}
+	ops := newMailbox()
+	t.quit = make(chan struct{})
+	t.stopped = make(chan struct{})
+	t.unsubscribe = t.book.Subscribe(func(c PositionChange) {
+		switch c.Kind {
+		case POSITION_OPENED:
+			ops.put(pnlOp{c.Curr.PositionKey, true})
+		case POSITION_CLOSED:
+			ops.put(pnlOp{c.Prev.PositionKey, false})
+		}
+	})
+	go t.run(ops, t.quit, t.stopped)
+	t.mu.Unlock()
+
+	for key := range t.book.Snapshot() {
+		ops.put(pnlOp{key, true})
+	}
+}
+
+// Stop cancels all the PnL subscriptions and stops watching the PositionBook
+func (t *PnLTracker) Stop() {
+	t.mu.Lock()
+	unsubscribe, quit, stopped := t.unsubscribe, t.quit, t.stopped
+	t.unsubscribe = nil
+	t.mu.Unlock()
+
+	if unsubscribe != nil {
+		unsubscribe()
+		close(quit)
+		<-stopped
+	}
+
+	t.mu.RLock()
+	keys := make([]PositionKey, 0, len(t.reqIDs))
+	for key := range t.reqIDs {
+		keys = append(keys, key)
+	}
+	t.mu.RUnlock()
+
+	for _, key := range keys {
+		t.cancel(key)
+	}
+}
+
+// run applies the queued pnlOps until quit is closed
+func (t *PnLTracker) run(ops *mailbox, quit <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+	for {
+		select {
+		case <-ops.notify:
+			for _, op := range ops.take() {
+				if op := op.(pnlOp); op.open {
+					t.subscribe(op.key)
+				} else {
+					t.cancel(op.key)
+				}
+			}
+		case <-quit:
+			return
+		}
+	}
+}
+
+func (t *PnLTracker) subscribe(key PositionKey) {
+	t.mu.Lock()
+	if _, ok := t.reqIDs[key]; ok {
+		t.mu.Unlock()
+		return
+	}
+	reqID := t.ic.GetReqID()
+	t.reqIDs[key] = reqID
+	t.pnls[reqID] = &PositionPnL{PositionKey: key, PnL: newPnL(), Value: UNSETFLOAT}
+	t.mu.Unlock()
+
+	t.ic.dispatcher.register(t.ic.dispatcher.handlers, reqID, func(msg interface{}) { t.handle(reqID, msg) })
+	t.ic.ReqPnLSingle(reqID, key.Account, key.ModelCode, key.ContractID)
+}
+
+func (t *PnLTracker) cancel(key PositionKey) {
+	t.mu.Lock()
+	reqID, ok := t.reqIDs[key]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	delete(t.reqIDs, key)
+	delete(t.pnls, reqID)
+	t.mu.Unlock()
+
+	t.ic.CancelPnLSingle(reqID)
+	t.ic.dispatcher.mute(t.ic.dispatcher.handlers, reqID, muteDuration)
+}
+
+func (t *PnLTracker) handle(reqID int64, msg interface{}) {
+	switch m := msg.(type) {
+	case *pnlSingleMsg:
+		t.mu.Lock()
+		if p, ok := t.pnls[reqID]; ok {
+			p.Position = m.position
+			p.PnL = PnL{m.dailyPnL, m.unrealizedPnL, m.realizedPnL}
+			p.Value = m.value
+			p.UpdatedAt = time.Now()
+		}
+		t.mu.Unlock()
+	case *errorMsg:
+		log.Warn("pnl single error", zap.Int64("reqID", reqID), zap.Int64("errCode", m.code), zap.String("errString", m.msg))
+	}
+}
+
+// Get returns the PnL of the position of key
+func (t *PnLTracker) Get(key PositionKey) (PositionPnL, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	reqID, ok := t.reqIDs[key]
+	if !ok {
+		return PositionPnL{}, false
+	}
+	return *t.pnls[reqID], true
+}
+
+// Summary aggregates the PnL of the tracked positions, the UNSETFLOAT values are skipped
+func (t *PnLTracker) Summary() PnLSummary {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	summary := PnLSummary{
+		Time:      time.Now(),
+		Total:     newPnL(),
+		Value:     UNSETFLOAT,
+		Accounts:  make(map[string]PnL),
+		Positions: make([]PositionPnL, 0, len(t.pnls)),
+	}
+
+	for _, p := range t.pnls {
+		summary.Positions = append(summary.Positions, *p)
+		summary.Total.add(p.PnL)
+
+		acctPnL, ok := summary.Accounts[p.Account]
+		if !ok {
+			acctPnL = newPnL()
+		}
+		acctPnL.add(p.PnL)
+		summary.Accounts[p.Account] = acctPnL
+
+		if p.Value != UNSETFLOAT {
+			if summary.Value == UNSETFLOAT {
+				summary.Value = 0
+			}
+			summary.Value += p.Value
+		}
+	}
+
+	sort.Slice(summary.Positions, func(i, j int) bool {
+		pi, pj := summary.Positions[i], summary.Positions[j]
+		if pi.Account != pj.Account {
+			return pi.Account < pj.Account
+		}
+		if pi.ModelCode != pj.ModelCode {
+			return pi.ModelCode < pj.ModelCode
+		}
+		return pi.ContractID < pj.ContractID
+	})
+
+	return summary
+}
+
+// RunSummaries calls f with the Summary every interval until ctx is done
+func (t *PnLTracker) RunSummaries(ctx context.Context, interval time.Duration, f func(PnLSummary)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			f(t.Summary())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
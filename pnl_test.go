@@ -0,0 +1,104 @@
+package ibapi
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPnLTracker(t *testing.T) {
+	var mu sync.Mutex
+	subscribed := map[int64]int64{} // reqID -> conID
+	canceled := map[int64]bool{}
+
+	ic := newTestClient(func(ic *IbClient, req []byte) {
+		fields := splitMsgBytes(req[4:])
+		mu.Lock()
+		defer mu.Unlock()
+		switch fieldInt(fields[0]) {
+		case mREQ_PNL_SINGLE:
+			subscribed[fieldInt(fields[1])] = fieldInt(fields[4])
+		case mCANCEL_PNL_SINGLE:
+			canceled[fieldInt(fields[1])] = true
+		}
+	})
+	ic.serverVersion = mMIN_SERVER_VER_PNL
+	book := NewPositionBook()
+	ic.AddObserver(book)
+
+	aapl := &Contract{ContractID: 265598}
+	msft := &Contract{ContractID: 272093}
+	ic.wrapper.Position("DU001", aapl, DecimalFromInt(100), 150)
+
+	tracker := NewPnLTracker(ic, book)
+	tracker.Start()
+	ic.wrapper.Position("DU002", msft, DecimalFromInt(10), 300)
+
+	reqIDs := func() map[int64]int64 {
+		mu.Lock()
+		defer mu.Unlock()
+		ids := map[int64]int64{}
+		for reqID, conID := range subscribed {
+			ids[conID] = reqID
+		}
+		return ids
+	}
+	waitFor(t, func() bool { return len(reqIDs()) == 2 })
+
+	ids := reqIDs()
+	ic.wrapper.PnlSingle(ids[aapl.ContractID], DecimalFromInt(100), 50, 1000, 0, 16000)
+	ic.wrapper.PnlSingle(ids[msft.ContractID], DecimalFromInt(10), -20, UNSETFLOAT, 5, 3000)
+
+	summary := tracker.Summary()
+	if summary.Total.DailyPnL != 30 || summary.Total.UnrealizedPnL != 1000 || summary.Total.RealizedPnL != 5 || summary.Value != 19000 {
+		t.Errorf("unexpected total: %s, value: %v", summary.Total, summary.Value)
+	}
+	if acct := summary.Accounts["DU002"]; acct.UnrealizedPnL != UNSETFLOAT || acct.DailyPnL != -20 {
+		t.Errorf("unexpected account pnl: %s", acct)
+	}
+
+	ic.wrapper.Position("DU002", msft, DecimalFromInt(0), 0)
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return canceled[ids[msft.ContractID]]
+	})
+	if _, ok := tracker.Get(PositionKey{"DU002", "", msft.ContractID}); ok {
+		t.Error("closed position is still tracked")
+	}
+
+	tracker.Stop()
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return canceled[ids[aapl.ContractID]]
+	})
+}
+
+func TestPnLTrackerNotBlockingDecoder(t *testing.T) {
+	release := make(chan struct{})
+	ic := newTestClient(func(ic *IbClient, req []byte) { <-release })
+	ic.serverVersion = mMIN_SERVER_VER_PNL
+	book := NewPositionBook()
+	ic.AddObserver(book)
+
+	tracker := NewPnLTracker(ic, book)
+	tracker.Start()
+
+	// the requests are stuck while TWS is not reading, the positions still go through
+	done := make(chan struct{})
+	go func() {
+		for conID := int64(1); conID <= 3*int64(cap(ic.reqChan)); conID++ {
+			ic.wrapper.Position("DU001", &Contract{ContractID: conID}, DecimalFromInt(1), 1)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the position callbacks are blocked by ReqPnLSingle")
+	}
+
+	close(release)
+	tracker.Stop()
+}
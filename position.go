@@ -0,0 +1,363 @@
+/*
+position contains PositionBook, which merges Position, PositionMulti and UpdatePortfolio
+into one book keyed by (account, modelCode, conID).
+*/
+
+package ibapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// PositionKey identifies a position in PositionBook, ModelCode is "" for the positions not in a model
+type PositionKey struct {
+	Account    string
+	ModelCode  string
+	ContractID int64
+}
+
+// PositionEntry is a position in PositionBook.
+// MarketPrice, MarketValue and UnrealizedPnL are UNSETFLOAT until it is marked by UpdatePortfolio or PositionBook.Mark,
+// RealizedPnL is only known from UpdatePortfolio.
+type PositionEntry struct {
+	PositionKey
+	Contract      Contract
+	Position      Decimal
+	AvgCost       float64 // including the multiplier, as reported by TWS
+	MarketPrice   float64
+	MarketValue   float64
+	UnrealizedPnL float64
+	RealizedPnL   float64
+	MarkedAt      time.Time
+}
+
+func (p PositionEntry) String() string {
+	return fmt.Sprintf("PositionEntry<Account: %s, ModelCode: %s, ConID: %d, Symbol: %s, Position: %v, AvgCost: %v, MarketPrice: %v, MarketValue: %v, UnrealizedPnL: %v>",
+		p.Account, p.ModelCode, p.ContractID, p.Contract.Symbol, p.Position, p.AvgCost, p.MarketPrice, p.MarketValue, p.UnrealizedPnL)
+}
+
+// mark updates the market fields with price
+func (p *PositionEntry) mark(price float64, at time.Time) {
+	multiplier := 1.0
+	if p.Contract.Multiplier != "" {
+		if m, err := strconv.ParseFloat(p.Contract.Multiplier, 64); err == nil && m != 0 {
+			multiplier = m
+		}
+	}
+
+	p.MarketPrice = price
+	position := p.Position.Float64()
+	p.MarketValue = position * price * multiplier
+	p.UnrealizedPnL = p.MarketValue - position*p.AvgCost
+	p.MarkedAt = at
+}
+
+// PositionSnapshot is a copy of the positions in PositionBook
+type PositionSnapshot map[PositionKey]PositionEntry
+
+// PositionChangeKind ...
+type PositionChangeKind int
+
+const (
+	POSITION_OPENED PositionChangeKind = iota
+	POSITION_CHANGED
+	POSITION_CLOSED
+	POSITION_LOAD_END // all the subscriptions of the book have ended their loads, see PositionBook
+)
+
+// PositionChange is a change of the quantity or average cost of a position.
+// Prev is empty when POSITION_OPENED, Curr is empty when POSITION_CLOSED, both are empty when POSITION_LOAD_END.
+type PositionChange struct {
+	Kind PositionChangeKind
+	Prev PositionEntry
+	Curr PositionEntry
+}
+
+// Diff returns the changes from old to new, marks are not regarded as changes
+func (s PositionSnapshot) Diff(old PositionSnapshot) []PositionChange {
+	changes := []PositionChange{}
+	for key, curr := range s {
+		prev, ok := old[key]
+		switch {
+		case !ok:
+			changes = append(changes, PositionChange{Kind: POSITION_OPENED, Curr: curr})
+		case prev.Position != curr.Position || prev.AvgCost != curr.AvgCost:
+			changes = append(changes, PositionChange{Kind: POSITION_CHANGED, Prev: prev, Curr: curr})
+		}
+	}
+
+	for key, prev := range old {
+		if _, ok := s[key]; !ok {
+			changes = append(changes, PositionChange{Kind: POSITION_CLOSED, Prev: prev})
+		}
+	}
+
+	return changes
+}
+
+// PositionBook keeps the positions across accounts and model codes.
+/*
+It is an observer of IbClient, add it by IbClient.AddObserver, then subscribe any of
+	ReqPositions -> Position, PositionEnd
+	ReqPositionsMulti -> PositionMulti, PositionMultiEnd
+	ReqAccountUpdates -> UpdatePortfolio, AccountDownloadEnd
+by the methods of the book with the same names. The initial load is completed once all of them have ended,
+the ends of the subscriptions made by IbClient directly are not waited for, though their positions are kept as well.
+A position is removed once its quantity becomes zero.
+All the methods are safe for concurrent use.
+*/
+type PositionBook struct {
+	mu          sync.RWMutex
+	positions   map[PositionKey]*PositionEntry
+	sources     map[positionSource]bool // the subscriptions of the book, true once ended
+	loaded      chan struct{}           // closed when the initial load is completed
+	isLoaded    bool
+	subscribers map[int]func(PositionChange)
+	subSeq      int
+}
+
+// positionSource is a subscription feeding PositionBook, reqID is of ReqPositionsMulti and account is of ReqAccountUpdates
+type positionSource struct {
+	msgID   OUT
+	reqID   int64
+	account string
+}
+
+// NewPositionBook create an empty PositionBook
+func NewPositionBook() *PositionBook {
+	return &PositionBook{
+		positions:   make(map[PositionKey]*PositionEntry),
+		sources:     make(map[positionSource]bool),
+		loaded:      make(chan struct{}),
+		subscribers: make(map[int]func(PositionChange)),
+	}
+}
+
+// ReqPositions subscribes the positions of all the accounts by ic, the load ends with PositionEnd
+func (pb *PositionBook) ReqPositions(ic *IbClient) {
+	pb.expect(positionSource{msgID: mREQ_POSITIONS})
+	ic.ReqPositions()
+}
+
+// ReqPositionsMulti subscribes the positions of the account and model by ic, the load ends with PositionMultiEnd of reqID
+func (pb *PositionBook) ReqPositionsMulti(ic *IbClient, reqID int64, account string, modelCode string) {
+	pb.expect(positionSource{msgID: mREQ_POSITIONS_MULTI, reqID: reqID})
+	ic.ReqPositionsMulti(reqID, account, modelCode)
+}
+
+// ReqAccountUpdates subscribes the portfolio of the account by ic, the load ends with AccountDownloadEnd of the account.
+// accName could be "" for the single account, whose AccountDownloadEnd of any name ends the load.
+func (pb *PositionBook) ReqAccountUpdates(ic *IbClient, accName string) {
+	pb.expect(positionSource{msgID: mREQ_ACCT_DATA, account: accName})
+	ic.ReqAccountUpdates(true, accName)
+}
+
+// expect adds the subscription of the book, which must end before the load is completed
+func (pb *PositionBook) expect(source positionSource) {
+	pb.mu.Lock()
+	pb.sources[source] = false
+	pb.mu.Unlock()
+}
+
+// Subscribe registers f to be called on every PositionChange, it is called in the decoder goroutine and must not block.
+// Call the returned func to unsubscribe.
+func (pb *PositionBook) Subscribe(f func(PositionChange)) (unsubscribe func()) {
+	pb.mu.Lock()
+	id := pb.subSeq
+	pb.subSeq++
+	pb.subscribers[id] = f
+	pb.mu.Unlock()
+
+	return func() {
+		pb.mu.Lock()
+		delete(pb.subscribers, id)
+		pb.mu.Unlock()
+	}
+}
+
+// Loaded reports whether the initial load is completed
+func (pb *PositionBook) Loaded() bool {
+	pb.mu.RLock()
+	defer pb.mu.RUnlock()
+	return pb.isLoaded
+}
+
+// WaitLoaded blocks until the initial load is completed, or ctx is done
+func (pb *PositionBook) WaitLoaded(ctx context.Context) error {
+	select {
+	case <-pb.loaded:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Get returns the position of key
+func (pb *PositionBook) Get(key PositionKey) (PositionEntry, bool) {
+	pb.mu.RLock()
+	defer pb.mu.RUnlock()
+
+	p, ok := pb.positions[key]
+	if !ok {
+		return PositionEntry{}, false
+	}
+	return *p, true
+}
+
+// Snapshot returns a copy of all the positions
+func (pb *PositionBook) Snapshot() PositionSnapshot {
+	pb.mu.RLock()
+	defer pb.mu.RUnlock()
+
+	snapshot := make(PositionSnapshot, len(pb.positions))
+	for key, p := range pb.positions {
+		snapshot[key] = *p
+	}
+	return snapshot
+}
+
+// Mark updates MarketPrice, MarketValue and UnrealizedPnL of all the positions of conID with the live price
+func (pb *PositionBook) Mark(conID int64, price float64) {
+	now := time.Now()
+
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	for key, p := range pb.positions {
+		if key.ContractID == conID {
+			p.mark(price, now)
+		}
+	}
+}
+
+// update sets the position of key and notifies the subscribers, apply could override the market fields
+func (pb *PositionBook) update(key PositionKey, contract *Contract, position Decimal, avgCost float64, apply func(p *PositionEntry)) {
+	pb.mu.Lock()
+	p, existed := pb.positions[key]
+	var prev PositionEntry
+	if existed {
+		prev = *p
+	}
+
+	var change PositionChange
+	switch {
+	case position.IsZero() && !existed:
+		pb.mu.Unlock()
+		return
+	case position.IsZero():
+		delete(pb.positions, key)
+		change = PositionChange{Kind: POSITION_CLOSED, Prev: prev}
+	default:
+		if !existed {
+			p = &PositionEntry{PositionKey: key, MarketPrice: UNSETFLOAT, MarketValue: UNSETFLOAT, UnrealizedPnL: UNSETFLOAT, RealizedPnL: UNSETFLOAT}
+			pb.positions[key] = p
+		}
+		if contract != nil {
+			p.Contract = *contract
+		}
+		p.Position = position
+		p.AvgCost = avgCost
+		if apply != nil {
+			apply(p)
+		} else if p.MarketPrice != UNSETFLOAT {
+			p.mark(p.MarketPrice, p.MarkedAt)
+		}
+
+		switch {
+		case !existed:
+			change = PositionChange{Kind: POSITION_OPENED, Curr: *p}
+		case prev.Position != p.Position || prev.AvgCost != p.AvgCost:
+			change = PositionChange{Kind: POSITION_CHANGED, Prev: prev, Curr: *p}
+		default:
+			pb.mu.Unlock()
+			return
+		}
+	}
+	subscribers := pb.subscriberList()
+	pb.mu.Unlock()
+
+	for _, f := range subscribers {
+		f(change)
+	}
+}
+
+// loadEnd marks the subscription of the book ended, and completes the load if all of them have ended
+func (pb *PositionBook) loadEnd(source positionSource) {
+	pb.mu.Lock()
+	if ended, ok := pb.sources[source]; !ok || ended {
+		pb.mu.Unlock()
+		return
+	}
+	pb.sources[source] = true
+	for _, ended := range pb.sources {
+		if !ended {
+			pb.mu.Unlock()
+			return
+		}
+	}
+
+	if !pb.isLoaded {
+		pb.isLoaded = true
+		close(pb.loaded)
+	}
+	subscribers := pb.subscriberList()
+	pb.mu.Unlock()
+
+	for _, f := range subscribers {
+		f(PositionChange{Kind: POSITION_LOAD_END})
+	}
+}
+
+// subscriberList must be called with mu locked
+func (pb *PositionBook) subscriberList() []func(PositionChange) {
+	subscribers := make([]func(PositionChange), 0, len(pb.subscribers))
+	for _, f := range pb.subscribers {
+		subscribers = append(subscribers, f)
+	}
+	return subscribers
+}
+
+func (pb *PositionBook) Position(account string, contract *Contract, position Decimal, avgCost float64) {
+	pb.update(PositionKey{account, "", contract.ContractID}, contract, position, avgCost, nil)
+}
+
+func (pb *PositionBook) PositionEnd() {
+	pb.loadEnd(positionSource{msgID: mREQ_POSITIONS})
+}
+
+func (pb *PositionBook) PositionMulti(reqID int64, account string, modelCode string, contract *Contract, position Decimal, avgCost float64) {
+	pb.update(PositionKey{account, modelCode, contract.ContractID}, contract, position, avgCost, nil)
+}
+
+func (pb *PositionBook) PositionMultiEnd(reqID int64) {
+	pb.loadEnd(positionSource{msgID: mREQ_POSITIONS_MULTI, reqID: reqID})
+}
+
+func (pb *PositionBook) UpdatePortfolio(contract *Contract, position Decimal, marketPrice float64, marketValue float64, averageCost float64, unrealizedPNL float64, realizedPNL float64, accName string) {
+	key := PositionKey{accName, "", contract.ContractID}
+	pb.update(key, nil, position, averageCost, func(p *PositionEntry) {
+		// the contract of UpdatePortfolio lacks the exchange, keep the one from Position if any
+		if p.Contract.ContractID == 0 {
+			p.Contract = *contract
+		}
+		p.MarketPrice = marketPrice
+		p.MarketValue = marketValue
+		p.UnrealizedPnL = unrealizedPNL
+		p.RealizedPnL = realizedPNL
+		p.MarkedAt = time.Now()
+	})
+}
+
+func (pb *PositionBook) AccountDownloadEnd(accName string) {
+	source := positionSource{msgID: mREQ_ACCT_DATA, account: accName}
+	pb.mu.RLock()
+	if _, ok := pb.sources[source]; !ok {
+		source.account = "" // the single account requested without the name
+	}
+	pb.mu.RUnlock()
+	pb.loadEnd(source)
+}
@@ -0,0 +1,64 @@
+package ibapi
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPositionBook(t *testing.T) {
+	ic := newTestClient(func(ic *IbClient, req []byte) {})
+	book := NewPositionBook()
+	ic.AddObserver(book)
+	book.ReqPositions(ic)
+	book.ReqPositionsMulti(ic, 1, "DU001", "MODEL")
+
+	aapl := &Contract{ContractID: 265598, Symbol: "AAPL", SecurityType: "STK", Exchange: "NASDAQ", Currency: "USD"}
+	es := &Contract{ContractID: 495512563, Symbol: "ES", SecurityType: "FUT", Multiplier: "50", Currency: "USD"}
+
+	ic.wrapper.Position("DU001", aapl, DecimalFromInt(100), 150)
+	ic.wrapper.PositionMulti(1, "DU001", "MODEL", aapl, DecimalFromInt(10), 140)
+	ic.wrapper.Position("DU001", es, DecimalFromInt(-2), 225000)
+	ic.wrapper.PositionMultiEnd(2)
+	ic.wrapper.AccountDownloadEnd("DU001")
+	ic.wrapper.PositionEnd()
+	if book.Loaded() {
+		t.Fatal("loaded before PositionMultiEnd of the book")
+	}
+	ic.wrapper.PositionMultiEnd(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := book.WaitLoaded(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	before := book.Snapshot()
+	if len(before) != 3 {
+		t.Fatalf("unexpected positions: %v", before)
+	}
+
+	book.Mark(es.ContractID, 4400)
+	esPos, _ := book.Get(PositionKey{"DU001", "", es.ContractID})
+	if esPos.MarketValue != -2*4400*50 || esPos.UnrealizedPnL != -2*4400*50+2*225000 {
+		t.Errorf("unexpected mark: %s", esPos)
+	}
+
+	ic.wrapper.UpdatePortfolio(&Contract{ContractID: aapl.ContractID, Symbol: "AAPL"}, DecimalFromInt(100), 160, 16000, 150, 1000, 0, "DU001")
+	aaplPos, _ := book.Get(PositionKey{"DU001", "", aapl.ContractID})
+	if aaplPos.UnrealizedPnL != 1000 || aaplPos.Contract.Exchange != "NASDAQ" {
+		t.Errorf("unexpected portfolio update: %s", aaplPos)
+	}
+
+	ic.wrapper.Position("DU001", es, DecimalFromInt(0), 0)
+	ic.wrapper.PositionMulti(1, "DU001", "MODEL", aapl, DecimalFromInt(20), 145)
+
+	changes := book.Snapshot().Diff(before)
+	kinds := map[PositionChangeKind]int{}
+	for _, c := range changes {
+		kinds[c.Kind]++
+	}
+	if len(changes) != 2 || kinds[POSITION_CLOSED] != 1 || kinds[POSITION_CHANGED] != 1 {
+		t.Errorf("unexpected diff: %+v", changes)
+	}
+}
@@ -0,0 +1,101 @@
+package ibapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+// MarginImpact is the margin and commission preview of an order, parsed from the OrderState of a what-if order.
+// Values which TWS did not send are UNSETFLOAT.
+type MarginImpact struct {
+	InitMarginBefore     float64
+	InitMarginChange     float64
+	InitMarginAfter      float64
+	MaintMarginBefore    float64
+	MaintMarginChange    float64
+	MaintMarginAfter     float64
+	EquityWithLoanBefore float64
+	EquityWithLoanChange float64
+	EquityWithLoanAfter  float64
+	Commission           float64
+	MinCommission        float64
+	MaxCommission        float64
+	CommissionCurrency   string
+	WarningText          string
+}
+
+func (m MarginImpact) String() string {
+	return fmt.Sprintf("MarginImpact<InitMargin: %v%+v=%v, MaintMargin: %v%+v=%v, EquityWithLoan: %v%+v=%v, Commission: %v[%v-%v]%s, Warning: %s>",
+		m.InitMarginBefore, m.InitMarginChange, m.InitMarginAfter,
+		m.MaintMarginBefore, m.MaintMarginChange, m.MaintMarginAfter,
+		m.EquityWithLoanBefore, m.EquityWithLoanChange, m.EquityWithLoanAfter,
+		m.Commission, m.MinCommission, m.MaxCommission, m.CommissionCurrency,
+		m.WarningText)
+}
+
+// NewMarginImpact parses the what-if fields of the OrderState
+func NewMarginImpact(orderState *OrderState) *MarginImpact {
+	return &MarginImpact{
+		InitMarginBefore:     parseMarginValue(orderState.InitialMarginBefore),
+		InitMarginChange:     parseMarginValue(orderState.InitialMarginChange),
+		InitMarginAfter:      parseMarginValue(orderState.InitialMarginAfter),
+		MaintMarginBefore:    parseMarginValue(orderState.MaintenanceMarginBefore),
+		MaintMarginChange:    parseMarginValue(orderState.MaintenanceMarginChange),
+		MaintMarginAfter:     parseMarginValue(orderState.MaintenanceMarginAfter),
+		EquityWithLoanBefore: parseMarginValue(orderState.EquityWithLoanBefore),
+		EquityWithLoanChange: parseMarginValue(orderState.EquityWithLoanChange),
+		EquityWithLoanAfter:  parseMarginValue(orderState.EquityWithLoanAfter),
+		Commission:           orderState.Commission,
+		MinCommission:        orderState.MinCommission,
+		MaxCommission:        orderState.MaxCommission,
+		CommissionCurrency:   orderState.CommissionCurrency,
+		WarningText:          orderState.WarningText,
+	}
+}
+
+// parseMarginValue parse the margin string of OrderState, "" and the unset value of TWS become UNSETFLOAT
+func parseMarginValue(s string) float64 {
+	if s == "" {
+		return UNSETFLOAT
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		log.Warn("failed to parse margin value", zap.String("value", s), zap.Error(err))
+		return UNSETFLOAT
+	}
+
+	return f
+}
+
+// PreviewOrder get the margin impact and commission of the order without placing it.
+/*
+A copy of the order is sent as a what-if order with orderID, and the result is parsed from the OrderState of its OpenOrder callback.
+The callbacks of orderID are never delivered to the wrapper for a while, so that it would not be tracked as a live order,
+thus orderID must be an unused id from the NextValidID sequence, which is never reused for placing a real order.
+
+It returns ctx.Err() if ctx is done before TWS responds,
+or the IbError reported by TWS if the what-if order is rejected.
+*/
+func (ic *IbClient) PreviewOrder(ctx context.Context, orderID int64, contract *Contract, order *Order) (*MarginImpact, error) {
+	if !ic.IsConnected() {
+		return nil, NOT_CONNECTED
+	}
+
+	whatIfOrder := *order
+	whatIfOrder.WhatIf = true
+
+	var impact *MarginImpact
+	err := ic.requestOrder(ctx, orderID, func() { ic.PlaceOrder(orderID, contract, &whatIfOrder) }, func(msg interface{}) bool {
+		if m, ok := msg.(*openOrderMsg); ok {
+			impact = NewMarginImpact(m.orderState)
+			return true
+		}
+		return false
+	})
+
+	return impact, err
+}
@@ -0,0 +1,208 @@
+package ibapi
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fieldInt decodes the int field of a req, a malformed one is decoded as UNSETINT
+func fieldInt(field []byte) int64 {
+	i, err := decodeInt(field)
+	if err != nil {
+		return UNSETINT
+	}
+	return i
+}
+
+// newTestClient create a connected IbClient without socket, reqs are passed to respond instead of TWS
+func newTestClient(respond func(ic *IbClient, req []byte)) *IbClient {
+	ic := NewIbClient(new(Wrapper))
+	ic.setConnState(CONNECTED)
+	ic.serverVersion = 151
+	ic.decoder.setVersion(ic.serverVersion)
+	ic.decoder.setmsgID2process()
+
+	go func() {
+		for req := range ic.reqChan {
+			respond(ic, req)
+		}
+	}()
+
+	return ic
+}
+
+// waitFor polls cond until it is true, or fails the test after a second
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPreviewOrder(t *testing.T) {
+	ic := newTestClient(func(ic *IbClient, req []byte) {
+		fields := splitMsgBytes(req[4:])
+		orderID := fieldInt(fields[1])
+		ic.dispatcher.Error(orderID, 2109, "Order Event Warning")
+		ic.dispatcher.OpenOrder(orderID, &Contract{}, &Order{WhatIf: true}, &OrderState{
+			InitialMarginBefore: "1000",
+			InitialMarginChange: "250.5",
+			InitialMarginAfter:  "1250.5",
+			Commission:          UNSETFLOAT,
+			MinCommission:       1,
+			MaxCommission:       2,
+		})
+	})
+
+	order := NewLimitOrder("BUY", 100, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	impact, err := ic.PreviewOrder(ctx, 100, &Contract{Symbol: "AAPL", SecurityType: "STK"}, order)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if impact.InitMarginChange != 250.5 || impact.InitMarginAfter != 1250.5 || impact.MaintMarginAfter != UNSETFLOAT {
+		t.Errorf("unexpected margin impact: %s", impact)
+	}
+
+	if order.WhatIf {
+		t.Error("the order of caller should not be changed")
+	}
+}
+
+func TestPreviewOrderRejected(t *testing.T) {
+	ic := newTestClient(func(ic *IbClient, req []byte) {
+		fields := splitMsgBytes(req[4:])
+		ic.dispatcher.Error(fieldInt(fields[1]), 201, "Order rejected")
+	})
+
+	_, err := ic.PreviewOrder(context.Background(), 100, &Contract{}, NewMarketOrder("SELL", 1))
+	if ie, ok := err.(IbError); !ok || ie.code != 201 {
+		t.Errorf("expected rejection, got %v", err)
+	}
+}
+
+func TestPreviewOrderTimeout(t *testing.T) {
+	ic := newTestClient(func(ic *IbClient, req []byte) {})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := ic.PreviewOrder(ctx, 100, &Contract{}, NewMarketOrder("SELL", 1)); err != context.DeadlineExceeded {
+		t.Errorf("expected timeout, got %v", err)
+	}
+}
+
+// orderEventWrapper sends the orderIDs of OrderStatus and Error to orderIDs
+type orderEventWrapper struct {
+	Wrapper
+	orderIDs chan int64
+}
+
+func (w *orderEventWrapper) OrderStatus(orderID int64, status string, filled Decimal, remaining Decimal, avgFillPrice float64, permID int64, parentID int64, lastFillPrice float64, clientID int64, whyHeld string, mktCapPrice float64) {
+	w.orderIDs <- orderID
+}
+
+func (w *orderEventWrapper) Error(reqID int64, errCode int64, errString string) {
+	w.orderIDs <- reqID
+}
+
+func TestOrderAndRequestIDs(t *testing.T) {
+	w := &orderEventWrapper{orderIDs: make(chan int64, 10)}
+	ic := newTestClient(func(ic *IbClient, req []byte) {
+		if fieldInt(splitMsgBytes(req[4:])[0]) == mREQ_CURRENT_TIME {
+			ic.dispatcher.Error(7, 2109, "Order Event Warning")
+		}
+	})
+	ic.SetWrapper(w)
+
+	// the request of reqID 7 is done, its late callbacks are muted
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	ic.request(ctx, 7, ic.ReqCurrentTime, func(interface{}) bool { return false })
+
+	// the live order 7 is not taken by the muted request
+	ic.PlaceOrder(7, &Contract{Symbol: "AAPL", SecurityType: "STK", Exchange: "SMART", Currency: "USD"}, NewLimitOrder("BUY", 100, 1))
+	ic.dispatcher.OrderStatus(7, "Submitted", Decimal{}, DecimalFromInt(100), 0, 1, 0, 0, 0, "", 0)
+	ic.dispatcher.Error(7, 399, "Order Message")
+	for i := 0; i < 2; i++ {
+		select {
+		case orderID := <-w.orderIDs:
+			if orderID != 7 {
+				t.Errorf("unexpected orderID %d", orderID)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("the callbacks of the live order should reach the wrapper")
+		}
+	}
+}
+
+func TestPlaceOrderAfterPreview(t *testing.T) {
+	w := &orderEventWrapper{orderIDs: make(chan int64, 10)}
+	ic := newTestClient(func(ic *IbClient, req []byte) {
+		fields := splitMsgBytes(req[4:])
+		switch fieldInt(fields[0]) {
+		case mPLACE_ORDER:
+			ic.dispatcher.OpenOrder(fieldInt(fields[1]), &Contract{}, &Order{WhatIf: true}, &OrderState{})
+		case mREQ_CURRENT_TIME:
+			ic.dispatcher.Error(100, 321, "Error validating request")
+		}
+	})
+	ic.SetWrapper(w)
+
+	// the previewed orderID 100 is muted
+	if _, err := ic.PreviewOrder(context.Background(), 100, &Contract{}, NewMarketOrder("SELL", 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the request of reqID 100 is not shadowed by the muted preview
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := ic.request(ctx, 100, ic.ReqCurrentTime, func(interface{}) bool { return false })
+	if ie, ok := err.(IbError); !ok || ie.code != 321 {
+		t.Fatalf("expected the error of the request, got %v", err)
+	}
+
+	// neither is the live order 100, until its terminal status
+	ic.dispatcher.trackOrder(100)
+	ic.dispatcher.OrderStatus(100, "Filled", DecimalFromInt(1), Decimal{}, 0, 1, 0, 0, 0, "", 0)
+	select {
+	case orderID := <-w.orderIDs:
+		if orderID != 100 {
+			t.Errorf("unexpected orderID %d", orderID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the callbacks of the live order should reach the wrapper")
+	}
+	if ic.dispatcher.errorHandler(100) != nil || len(ic.dispatcher.placedOrders) != 0 {
+		t.Error("the filled order should be untracked")
+	}
+}
+
+func TestMuteExpiry(t *testing.T) {
+	d := newDispatcher(new(Wrapper))
+	d.mute(d.handlers, 1, 10*time.Millisecond)
+
+	// the id claimed by a new request is not unregistered by the expired mute
+	var got []interface{}
+	d.register(d.handlers, 1, func(msg interface{}) { got = append(got, msg) })
+	time.Sleep(20 * time.Millisecond)
+	d.ContractDetailsEnd(1)
+	if len(got) != 1 {
+		t.Errorf("the live handler should receive the callback, got %v", got)
+	}
+
+	d.mute(d.handlers, 2, 10*time.Millisecond)
+	d.mute(d.handlers, 2, time.Hour)
+	time.Sleep(20 * time.Millisecond)
+	if d.handler(2) == nil {
+		t.Error("the id muted again should not be unregistered by the former mute")
+	}
+}
@@ -0,0 +1,397 @@
+/*
+resolver contains ContractResolver, which qualifies half-specified contracts by ReqContractDetails
+and caches the ContractDetails in memory and optionally on disk.
+*/
+
+package ibapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultResolverInterval is the default minimum interval between the ReqContractDetails of ContractResolver
+	DefaultResolverInterval = 25 * time.Millisecond
+	// DefaultResolverInFlight is the default max number of ReqContractDetails waiting for the response in QualifyAll
+	DefaultResolverInFlight = 10
+)
+
+var (
+	// ErrNoContractMatch is returned when no contract matches the spec
+	ErrNoContractMatch = errors.New("no contract matches")
+	// ErrAmbiguousContract is returned when more than one contract match the spec, see AmbiguousContractError
+	ErrAmbiguousContract = errors.New("ambiguous contract")
+)
+
+// AmbiguousContractError is returned when more than one contract match the spec, errors.Is(err, ErrAmbiguousContract) is true
+type AmbiguousContractError struct {
+	Spec       Contract
+	Candidates []*ContractDetails
+}
+
+func (e *AmbiguousContractError) Error() string {
+	candidates := make([]string, 0, len(e.Candidates))
+	for _, cd := range e.Candidates {
+		candidates = append(candidates, fmt.Sprintf("%d(%s@%s)", cd.Contract.ContractID, cd.Contract.LocalSymbol, cd.Contract.PrimaryExchange))
+	}
+	return fmt.Sprintf("%s: %s matches %d contracts: %s", ErrAmbiguousContract, contractSpecKey(&e.Spec), len(e.Candidates), strings.Join(candidates, ", "))
+}
+
+func (e *AmbiguousContractError) Is(target error) bool {
+	return target == ErrAmbiguousContract
+}
+
+// FetchContractDetails requests the ContractDetails of all the contracts matching contract and waits for ContractDetailsEnd.
+// The callbacks of the request are not delivered to the wrapper.
+func (ic *IbClient) FetchContractDetails(ctx context.Context, contract *Contract) ([]*ContractDetails, error) {
+	if !ic.IsConnected() {
+		return nil, NOT_CONNECTED
+	}
+
+	reqID := ic.GetReqID()
+	details := []*ContractDetails{}
+	err := ic.request(ctx, reqID, func() { ic.ReqContractDetails(reqID, contract) }, func(msg interface{}) bool {
+		switch m := msg.(type) {
+		case *contractDetailsMsg:
+			details = append(details, m.conDetails)
+		case *contractDetailsEndMsg:
+			return true
+		}
+		return false
+	})
+
+	if errors.Is(err, NO_SECURITY_DEFINITION) {
+		return details, fmt.Errorf("%w: %s", ErrNoContractMatch, err)
+	}
+
+	return details, err
+}
+
+// contractSpecKey is the cache key of the spec of contract
+func contractSpecKey(c *Contract) string {
+	return strings.Join([]string{
+		strconv.FormatInt(c.ContractID, 10),
+		c.Symbol,
+		c.SecurityType,
+		c.Expiry,
+		strconv.FormatFloat(c.Strike, 'f', -1, 64),
+		c.Right,
+		c.Multiplier,
+		c.Exchange,
+		c.PrimaryExchange,
+		c.Currency,
+		c.LocalSymbol,
+		c.TradingClass,
+		c.SecurityIDType,
+		c.SecurityID,
+	}, "|")
+}
+
+// contractResolverCache is the format of the cache file
+type contractResolverCache struct {
+	Details []*ContractDetails `json:"details"`
+	Specs   map[string]int64   `json:"specs"`
+}
+
+// ContractResolver qualifies half-specified contracts.
+/*
+The ContractDetails are cached by conID, and the conID is cached by the spec of the contract,
+so the same spec is requested only once.
+If cacheFile is set, the cache is loaded from it on creation, and saved to it after every new resolution.
+The ContractDetails returned are copies, changing them does not affect the cache.
+Interval and InFlight pace the ReqContractDetails, change them before use.
+*/
+type ContractResolver struct {
+	ic        *IbClient
+	cacheFile string
+	Interval  time.Duration
+	InFlight  int
+
+	mu      sync.RWMutex
+	byConID map[int64]*ContractDetails // never changed once cached
+	bySpec  map[string]int64
+	saveMu  sync.Mutex // serializes Save, which writes the same tmp file
+
+	paceMu   sync.Mutex
+	nextSend time.Time
+}
+
+// NewContractResolver create a ContractResolver, cacheFile could be "" to disable the persistence
+func NewContractResolver(ic *IbClient, cacheFile string) (*ContractResolver, error) {
+	r := &ContractResolver{
+		ic:        ic,
+		cacheFile: cacheFile,
+		Interval:  DefaultResolverInterval,
+		InFlight:  DefaultResolverInFlight,
+		byConID:   make(map[int64]*ContractDetails),
+		bySpec:    make(map[string]int64),
+	}
+
+	if cacheFile != "" {
+		if err := r.load(); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+func (r *ContractResolver) load() error {
+	b, err := ioutil.ReadFile(r.cacheFile)
+	if err != nil {
+		return err
+	}
+
+	cache := contractResolverCache{}
+	if err := json.Unmarshal(b, &cache); err != nil {
+		return fmt.Errorf("invalid contract cache %s: %w", r.cacheFile, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, cd := range cache.Details {
+		r.byConID[cd.Contract.ContractID] = cd
+	}
+	for spec, conID := range cache.Specs {
+		if _, ok := r.byConID[conID]; ok {
+			r.bySpec[spec] = conID
+		}
+	}
+	return nil
+}
+
+// Save writes the cache to cacheFile, it does nothing if cacheFile is ""
+func (r *ContractResolver) Save() error {
+	if r.cacheFile == "" {
+		return nil
+	}
+
+	r.saveMu.Lock()
+	defer r.saveMu.Unlock()
+
+	r.mu.RLock()
+	cache := contractResolverCache{
+		Details: make([]*ContractDetails, 0, len(r.byConID)),
+		Specs:   make(map[string]int64, len(r.bySpec)),
+	}
+	for _, cd := range r.byConID {
+		cache.Details = append(cache.Details, cd)
+	}
+	for spec, conID := range r.bySpec {
+		cache.Specs[spec] = conID
+	}
+	b, err := json.Marshal(cache)
+	r.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmp := r.cacheFile + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.cacheFile)
+}
+
+// Lookup returns the cached ContractDetails of conID
+func (r *ContractResolver) Lookup(conID int64) (*ContractDetails, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cd, ok := r.byConID[conID]
+	if !ok {
+		return nil, false
+	}
+	return copyContractDetails(cd), true
+}
+
+// copyContractDetails copies cd with its slices and DeltaNeutralContract
+func copyContractDetails(cd *ContractDetails) *ContractDetails {
+	c := *cd
+	if cd.SecurityIDList != nil {
+		c.SecurityIDList = append([]TagValue(nil), cd.SecurityIDList...)
+	}
+	if cd.Contract.ComboLegs != nil {
+		c.Contract.ComboLegs = append([]ComboLeg(nil), cd.Contract.ComboLegs...)
+	}
+	if cd.Contract.DeltaNeutralContract != nil {
+		dnc := *cd.Contract.DeltaNeutralContract
+		c.Contract.DeltaNeutralContract = &dnc
+	}
+	return &c
+}
+
+// Resolve returns the ContractDetails of the unique contract matching contract.
+// It returns ErrNoContractMatch or *AmbiguousContractError if the spec does not identify a single contract.
+func (r *ContractResolver) Resolve(ctx context.Context, contract *Contract) (*ContractDetails, error) {
+	cd, fresh, err := r.resolve(ctx, contract)
+	if err != nil {
+		return nil, err
+	}
+
+	if fresh {
+		if err := r.Save(); err != nil {
+			log.Warn("failed to save contract cache", zap.String("file", r.cacheFile), zap.Error(err))
+		}
+	}
+	return cd, nil
+}
+
+// resolve returns fresh true if cd is not from the cache
+func (r *ContractResolver) resolve(ctx context.Context, contract *Contract) (cd *ContractDetails, fresh bool, err error) {
+	spec := contractSpecKey(contract)
+
+	r.mu.RLock()
+	conID, ok := r.bySpec[spec]
+	if !ok && contract.ContractID != 0 {
+		conID, ok = contract.ContractID, true
+	}
+	cd, cached := r.byConID[conID]
+	r.mu.RUnlock()
+	if ok && cached {
+		return copyContractDetails(cd), false, nil
+	}
+
+	if err := r.pace(ctx); err != nil {
+		return nil, false, err
+	}
+
+	details, err := r.ic.FetchContractDetails(ctx, contract)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch len(details) {
+	case 0:
+		return nil, false, fmt.Errorf("%w: %s", ErrNoContractMatch, spec)
+	case 1:
+		cd = details[0]
+	default:
+		return nil, false, &AmbiguousContractError{Spec: *contract, Candidates: details}
+	}
+
+	r.mu.Lock()
+	r.byConID[cd.Contract.ContractID] = cd
+	r.bySpec[spec] = cd.Contract.ContractID
+	r.mu.Unlock()
+
+	return copyContractDetails(cd), true, nil
+}
+
+// pace blocks until the next ReqContractDetails is allowed
+func (r *ContractResolver) pace(ctx context.Context) error {
+	r.paceMu.Lock()
+	now := time.Now()
+	sendAt := r.nextSend
+	if sendAt.Before(now) {
+		sendAt = now
+	}
+	r.nextSend = sendAt.Add(r.Interval)
+	r.paceMu.Unlock()
+
+	wait := time.Until(sendAt)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Qualify resolves contract and fills in its ContractID, LocalSymbol, TradingClass and PrimaryExchange,
+// the empty Exchange, Currency, Expiry and Multiplier are filled too.
+func (r *ContractResolver) Qualify(ctx context.Context, contract *Contract) error {
+	cd, err := r.Resolve(ctx, contract)
+	if err != nil {
+		return err
+	}
+
+	fillQualified(contract, &cd.Contract)
+	return nil
+}
+
+func fillQualified(contract *Contract, qualified *Contract) {
+	contract.ContractID = qualified.ContractID
+	contract.LocalSymbol = qualified.LocalSymbol
+	contract.TradingClass = qualified.TradingClass
+	contract.PrimaryExchange = qualified.PrimaryExchange
+
+	fillEmpty := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+	fillEmpty(&contract.Exchange, qualified.Exchange)
+	fillEmpty(&contract.Currency, qualified.Currency)
+	fillEmpty(&contract.Expiry, qualified.Expiry)
+	fillEmpty(&contract.Multiplier, qualified.Multiplier)
+}
+
+// QualifyAll qualifies contracts concurrently within the pacing of Interval and InFlight.
+// The error of contracts[i] is errs[i], errs is nil if all of them are qualified.
+func (r *ContractResolver) QualifyAll(ctx context.Context, contracts []*Contract) (errs []error) {
+	results := make([]error, len(contracts))
+	inFlight := r.InFlight
+	if inFlight <= 0 {
+		inFlight = 1
+	}
+
+	sem := make(chan struct{}, inFlight)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	anyFresh := false
+
+	for i, contract := range contracts {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, contract *Contract) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			cd, isFresh, err := r.resolve(ctx, contract)
+			if err != nil {
+				results[i] = err
+				return
+			}
+			if isFresh {
+				mu.Lock()
+				anyFresh = true
+				mu.Unlock()
+			}
+			fillQualified(contract, &cd.Contract)
+		}(i, contract)
+	}
+	wg.Wait()
+
+	if anyFresh {
+		if err := r.Save(); err != nil {
+			log.Warn("failed to save contract cache", zap.String("file", r.cacheFile), zap.Error(err))
+		}
+	}
+
+	for _, err := range results {
+		if err != nil {
+			return results
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package ibapi
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestContractResolver(t *testing.T) {
+	var requests int64
+	ic := newTestClient(func(ic *IbClient, req []byte) {
+		fields := splitMsgBytes(req[4:])
+		if fieldInt(fields[0]) != mREQ_CONTRACT_DATA {
+			return
+		}
+		atomic.AddInt64(&requests, 1)
+
+		reqID := fieldInt(fields[2])
+		symbol := decodeString(fields[4])
+		switch symbol {
+		case "AAPL":
+			ic.dispatcher.ContractDetails(reqID, &ContractDetails{Contract: Contract{
+				ContractID: 265598, Symbol: "AAPL", SecurityType: "STK", Exchange: "SMART",
+				PrimaryExchange: "NASDAQ", Currency: "USD", LocalSymbol: "AAPL", TradingClass: "NMS",
+			}})
+		case "AMBIG":
+			ic.dispatcher.ContractDetails(reqID, &ContractDetails{Contract: Contract{ContractID: 1, Symbol: symbol}})
+			ic.dispatcher.ContractDetails(reqID, &ContractDetails{Contract: Contract{ContractID: 2, Symbol: symbol}})
+		default:
+			ic.dispatcher.Error(reqID, 200, "No security definition has been found for the request")
+			return
+		}
+		ic.dispatcher.ContractDetailsEnd(reqID)
+	})
+
+	dir, err := ioutil.TempDir("", "ibapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cacheFile := filepath.Join(dir, "contracts.json")
+
+	resolver, err := NewContractResolver(ic, cacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver.Interval = time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	aapl := &Contract{Symbol: "AAPL", SecurityType: "STK", Exchange: "SMART", Currency: "USD"}
+	if err := resolver.Qualify(ctx, aapl); err != nil {
+		t.Fatal(err)
+	}
+	if aapl.ContractID != 265598 || aapl.PrimaryExchange != "NASDAQ" || aapl.TradingClass != "NMS" || aapl.Exchange != "SMART" {
+		t.Errorf("unexpected qualified contract: %s", aapl)
+	}
+
+	contracts := []*Contract{
+		{Symbol: "AAPL", SecurityType: "STK", Exchange: "SMART", Currency: "USD"},
+		{Symbol: "AMBIG", SecurityType: "STK"},
+		{Symbol: "NONE", SecurityType: "STK"},
+	}
+	errs := resolver.QualifyAll(ctx, contracts)
+	if errs == nil || errs[0] != nil || !errors.Is(errs[1], ErrAmbiguousContract) || !errors.Is(errs[2], ErrNoContractMatch) {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if n := atomic.LoadInt64(&requests); n != 3 {
+		t.Errorf("the cached spec is requested again, %d requests", n)
+	}
+
+	reloaded, err := NewContractResolver(ic, cacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cd, ok := reloaded.Lookup(265598); !ok || cd.Contract.LocalSymbol != "AAPL" {
+		t.Errorf("cache is not persisted: %v", cd)
+	}
+	if _, err := reloaded.Resolve(ctx, &Contract{Symbol: "AAPL", SecurityType: "STK", Exchange: "SMART", Currency: "USD"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt64(&requests); n != 3 {
+		t.Errorf("the persisted spec is requested again, %d requests", n)
+	}
+
+	cd, _ := reloaded.Lookup(265598)
+	cd.Contract.LocalSymbol = "CHANGED"
+	if cd, _ := reloaded.Lookup(265598); cd.Contract.LocalSymbol != "AAPL" {
+		t.Errorf("the cache is changed by the caller: %v", cd)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := reloaded.Save(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -1,6 +1,13 @@
 package ibapi
 
-import "fmt"
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
 
 // ScanData is the data retureed by IB, which matches the ScannerSubscription
 type ScanData struct {
@@ -71,3 +78,476 @@ func NewScannerSubscription() *ScannerSubscription {
 
 	return scannerSubscription
 }
+
+// SCANNER_MAX_ROWS is the max NumberOfRows of a scanner subscription
+const SCANNER_MAX_ROWS = 50
+
+// the types of ScannerFilterField
+const (
+	SCANNER_FIELD_DOUBLE = "scanner.filter.DoubleField"
+	SCANNER_FIELD_INT    = "scanner.filter.IntField"
+	SCANNER_FIELD_STRING = "scanner.filter.StringField"
+	SCANNER_FIELD_DATE   = "scanner.filter.DateField"
+	SCANNER_FIELD_COMBO  = "scanner.filter.ComboField"
+	SCANNER_FIELD_BOOL   = "scanner.filter.BooleanField"
+)
+
+/*
+   #########################################################################
+   ################## Scanner Parameters
+   #########################################################################
+*/
+
+// ScannerCatalog is the catalogue of instruments, locations, scan codes and filters parsed from the xml of ScannerParameters
+type ScannerCatalog struct {
+	Instruments []ScannerInstrument `xml:"InstrumentList>Instrument"`
+	Locations   []ScannerLocation   `xml:"LocationTree>Location"`
+	ScanTypes   []ScannerScanType   `xml:"ScanTypeList>ScanType"`
+	Filters     []ScannerFilter     `xml:"-"`
+
+	instruments map[string]*ScannerInstrument
+	locations   map[string]*ScannerLocation
+	scanTypes   map[string]*ScannerScanType
+	fields      map[string]*ScannerFilterField
+	fieldFilter map[string]*ScannerFilter
+}
+
+// ScannerInstrument is the Instrument of a scanner subscription, such as STK or FUT.US
+type ScannerInstrument struct {
+	Name      string `xml:"name"`
+	Type      string `xml:"type"`
+	FilterIDs string `xml:"filters"`
+	Group     string `xml:"group"`
+	ShortName string `xml:"shortName"`
+}
+
+// Filters returns the ids of the filters available for the instrument
+func (i ScannerInstrument) Filters() []string {
+	return splitScannerList(i.FilterIDs)
+}
+
+// ScannerLocation is the LocationCode of a scanner subscription, such as STK.US.MAJOR, Children are the sub locations
+type ScannerLocation struct {
+	DisplayName   string            `xml:"displayName"`
+	LocationCode  string            `xml:"locationCode"`
+	InstrumentIDs string            `xml:"instruments"`
+	RouteExchange string            `xml:"routeExchange"`
+	DelayedOnly   bool              `xml:"delayedOnly"`
+	Children      []ScannerLocation `xml:"LocationTree>Location"`
+}
+
+// Instruments returns the instrument types of the location
+func (l ScannerLocation) Instruments() []string {
+	return splitScannerList(l.InstrumentIDs)
+}
+
+// ScannerScanType is the ScanCode of a scanner subscription, such as TOP_PERC_GAIN
+type ScannerScanType struct {
+	DisplayName     string `xml:"displayName"`
+	ScanCode        string `xml:"scanCode"`
+	InstrumentIDs   string `xml:"instruments"`
+	AbsoluteColumns bool   `xml:"absoluteColumns"`
+	SupportsSorting bool   `xml:"supportsSorting"`
+	RespSizeLimit   int64  `xml:"respSizeLimit"`
+	SearchDefault   bool   `xml:"searchDefault"`
+	Access          string `xml:"access"`
+}
+
+// Instruments returns the instrument types the scan code supports
+func (s ScannerScanType) Instruments() []string {
+	return splitScannerList(s.InstrumentIDs)
+}
+
+// ScannerFilter is a filter of the FilterList, Kind is the element name of it, such as RangeFilter or SimpleFilter.
+// The tags of the filter options are the Codes of its Fields, such as priceAbove and priceBelow of the PRICE filter.
+type ScannerFilter struct {
+	XMLName  xml.Name
+	ID       string               `xml:"id,attr"`
+	Category string               `xml:"category"`
+	Access   string               `xml:"access"`
+	Fields   []ScannerFilterField `xml:"AbstractField"`
+}
+
+// Kind returns the kind of the filter, such as RangeFilter or SimpleFilter
+func (f ScannerFilter) Kind() string {
+	return f.XMLName.Local
+}
+
+// ScannerFilterField is a field of ScannerFilter, Type is one of SCANNER_FIELD_*
+type ScannerFilterField struct {
+	Type              string              `xml:"type,attr"`
+	Code              string              `xml:"code"`
+	DisplayName       string              `xml:"displayName"`
+	Tooltip           string              `xml:"tooltip"`
+	Abbrev            string              `xml:"abbrev"`
+	DontAllowNegative bool                `xml:"dontAllowNegative"`
+	MinValue          string              `xml:"minValue"`
+	MaxValue          string              `xml:"maxValue"`
+	ComboValues       []ScannerComboValue `xml:"ComboValues>ComboValue"`
+}
+
+// ScannerComboValue is an option of SCANNER_FIELD_COMBO
+type ScannerComboValue struct {
+	Code        string `xml:"code"`
+	DisplayName string `xml:"displayName"`
+	Default     bool   `xml:"default"`
+}
+
+func splitScannerList(s string) []string {
+	var l []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			l = append(l, v)
+		}
+	}
+	return l
+}
+
+func containsScannerItem(l []string, s string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseScannerParameters parses the xml of ScannerParameters into ScannerCatalog
+func ParseScannerParameters(xmlData string) (*ScannerCatalog, error) {
+	// the filters are of different element names, such as RangeFilter and SimpleFilter
+	var doc struct {
+		*ScannerCatalog
+		FilterList struct {
+			Filters []ScannerFilter `xml:",any"`
+		}
+	}
+	c := &ScannerCatalog{}
+	doc.ScannerCatalog = c
+	if err := xml.Unmarshal([]byte(xmlData), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse the scanner parameters: %w", err)
+	}
+	c.Filters = doc.FilterList.Filters
+
+	c.instruments = make(map[string]*ScannerInstrument, len(c.Instruments))
+	for i := range c.Instruments {
+		c.instruments[c.Instruments[i].Type] = &c.Instruments[i]
+	}
+
+	c.locations = make(map[string]*ScannerLocation)
+	var walk func(locations []ScannerLocation)
+	walk = func(locations []ScannerLocation) {
+		for i := range locations {
+			c.locations[locations[i].LocationCode] = &locations[i]
+			walk(locations[i].Children)
+		}
+	}
+	walk(c.Locations)
+
+	c.scanTypes = make(map[string]*ScannerScanType, len(c.ScanTypes))
+	for i := range c.ScanTypes {
+		c.scanTypes[c.ScanTypes[i].ScanCode] = &c.ScanTypes[i]
+	}
+
+	c.fields = make(map[string]*ScannerFilterField)
+	c.fieldFilter = make(map[string]*ScannerFilter)
+	for i := range c.Filters {
+		f := &c.Filters[i]
+		for j := range f.Fields {
+			c.fields[f.Fields[j].Code] = &f.Fields[j]
+			c.fieldFilter[f.Fields[j].Code] = f
+		}
+	}
+
+	return c, nil
+}
+
+// Instrument returns the instrument of the type
+func (c *ScannerCatalog) Instrument(instrumentType string) (*ScannerInstrument, bool) {
+	i, ok := c.instruments[instrumentType]
+	return i, ok
+}
+
+// Location returns the location of the code, the sub locations included
+func (c *ScannerCatalog) Location(locationCode string) (*ScannerLocation, bool) {
+	l, ok := c.locations[locationCode]
+	return l, ok
+}
+
+// ScanType returns the scan type of the code
+func (c *ScannerCatalog) ScanType(scanCode string) (*ScannerScanType, bool) {
+	s, ok := c.scanTypes[scanCode]
+	return s, ok
+}
+
+// FilterField returns the filter field of the code, which is the tag of the filter options, and the filter it belongs to
+func (c *ScannerCatalog) FilterField(code string) (*ScannerFilterField, *ScannerFilter, bool) {
+	f, ok := c.fields[code]
+	if !ok {
+		return nil, nil, false
+	}
+	return f, c.fieldFilter[code], true
+}
+
+// LocationsOf returns the locations of the instrument type, the sub locations included
+func (c *ScannerCatalog) LocationsOf(instrumentType string) []*ScannerLocation {
+	var locations []*ScannerLocation
+	var walk func(l []ScannerLocation)
+	walk = func(l []ScannerLocation) {
+		for i := range l {
+			if containsScannerItem(l[i].Instruments(), instrumentType) {
+				locations = append(locations, &l[i])
+			}
+			walk(l[i].Children)
+		}
+	}
+	walk(c.Locations)
+	return locations
+}
+
+// ScanTypesOf returns the scan types supporting the instrument type
+func (c *ScannerCatalog) ScanTypesOf(instrumentType string) []*ScannerScanType {
+	var scanTypes []*ScannerScanType
+	for i := range c.ScanTypes {
+		if containsScannerItem(c.ScanTypes[i].Instruments(), instrumentType) {
+			scanTypes = append(scanTypes, &c.ScanTypes[i])
+		}
+	}
+	return scanTypes
+}
+
+// FiltersOf returns the filters available for the instrument type
+func (c *ScannerCatalog) FiltersOf(instrumentType string) []*ScannerFilter {
+	instrument, ok := c.instruments[instrumentType]
+	if !ok {
+		return nil
+	}
+
+	ids := instrument.Filters()
+	var filters []*ScannerFilter
+	for i := range c.Filters {
+		if containsScannerItem(ids, c.Filters[i].ID) {
+			filters = append(filters, &c.Filters[i])
+		}
+	}
+	return filters
+}
+
+// SearchScanTypes returns the scan types whose code or display name contains the keyword, case-insensitively
+func (c *ScannerCatalog) SearchScanTypes(keyword string) []*ScannerScanType {
+	keyword = strings.ToLower(keyword)
+	var scanTypes []*ScannerScanType
+	for i := range c.ScanTypes {
+		s := &c.ScanTypes[i]
+		if strings.Contains(strings.ToLower(s.ScanCode), keyword) || strings.Contains(strings.ToLower(s.DisplayName), keyword) {
+			scanTypes = append(scanTypes, s)
+		}
+	}
+	return scanTypes
+}
+
+// SearchFilterFields returns the filter fields whose code or display name contains the keyword, case-insensitively
+func (c *ScannerCatalog) SearchFilterFields(keyword string) []*ScannerFilterField {
+	keyword = strings.ToLower(keyword)
+	var fields []*ScannerFilterField
+	for i := range c.Filters {
+		for j := range c.Filters[i].Fields {
+			f := &c.Filters[i].Fields[j]
+			if strings.Contains(strings.ToLower(f.Code), keyword) || strings.Contains(strings.ToLower(f.DisplayName), keyword) {
+				fields = append(fields, f)
+			}
+		}
+	}
+	return fields
+}
+
+// ScannerValidationError lists the problems of a scanner subscription found by ScannerCatalog.Validate
+type ScannerValidationError struct {
+	Problems []string
+}
+
+func (e *ScannerValidationError) Error() string {
+	return "invalid scanner subscription: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks the subscription and the filter options against the catalogue, it returns *ScannerValidationError if invalid.
+/*
+The instrument, location and scan code must be in the catalogue, and the location and the scan code must support the instrument.
+The tags of the filter options must be the codes of the filter fields available for the instrument,
+and the values must match the types of the fields.
+*/
+func (c *ScannerCatalog) Validate(subscription *ScannerSubscription, filterOptions []TagValue) error {
+	var problems []string
+
+	if subscription.NumberOfRows > SCANNER_MAX_ROWS {
+		problems = append(problems, fmt.Sprintf("numberOfRows %d is more than %d", subscription.NumberOfRows, SCANNER_MAX_ROWS))
+	}
+
+	instrument, ok := c.Instrument(subscription.Instrument)
+	if !ok {
+		problems = append(problems, fmt.Sprintf("unknown instrument %q", subscription.Instrument))
+	}
+
+	if location, ok := c.Location(subscription.LocationCode); !ok {
+		problems = append(problems, fmt.Sprintf("unknown location code %q", subscription.LocationCode))
+	} else if instrument != nil && !containsScannerItem(location.Instruments(), instrument.Type) {
+		problems = append(problems, fmt.Sprintf("location code %q does not support instrument %q", location.LocationCode, instrument.Type))
+	}
+
+	if scanType, ok := c.ScanType(subscription.ScanCode); !ok {
+		problems = append(problems, fmt.Sprintf("unknown scan code %q", subscription.ScanCode))
+	} else if instrument != nil && !containsScannerItem(scanType.Instruments(), instrument.Type) {
+		problems = append(problems, fmt.Sprintf("scan code %q does not support instrument %q", scanType.ScanCode, instrument.Type))
+	}
+
+	for _, tv := range filterOptions {
+		field, filter, ok := c.FilterField(tv.Tag)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown filter %q", tv.Tag))
+			continue
+		}
+		if instrument != nil && !containsScannerItem(instrument.Filters(), filter.ID) {
+			problems = append(problems, fmt.Sprintf("filter %q is not available for instrument %q", tv.Tag, instrument.Type))
+		}
+		if err := field.check(tv.Value); err != nil {
+			problems = append(problems, fmt.Sprintf("filter %q: %v", tv.Tag, err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ScannerValidationError{problems}
+	}
+	return nil
+}
+
+// check checks that value matches the type of the field
+func (f *ScannerFilterField) check(value string) error {
+	switch f.Type {
+	case SCANNER_FIELD_DOUBLE:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		if f.DontAllowNegative && v < 0 {
+			return fmt.Errorf("%q is negative", value)
+		}
+	case SCANNER_FIELD_INT:
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+		if f.DontAllowNegative && v < 0 {
+			return fmt.Errorf("%q is negative", value)
+		}
+	case SCANNER_FIELD_BOOL:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+	case SCANNER_FIELD_COMBO:
+		for _, cv := range f.ComboValues {
+			if cv.Code == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q is not one of the combo values", value)
+	}
+	return nil
+}
+
+// ScannerSubscriptionBuilder builds a scanner subscription validated against ScannerCatalog
+type ScannerSubscriptionBuilder struct {
+	catalog       *ScannerCatalog
+	subscription  *ScannerSubscription
+	filterOptions []TagValue
+}
+
+// NewSubscription starts to build a scanner subscription of the instrument, location and scan code
+func (c *ScannerCatalog) NewSubscription(instrument string, locationCode string, scanCode string) *ScannerSubscriptionBuilder {
+	subscription := NewScannerSubscription()
+	subscription.Instrument = instrument
+	subscription.LocationCode = locationCode
+	subscription.ScanCode = scanCode
+
+	return &ScannerSubscriptionBuilder{catalog: c, subscription: subscription}
+}
+
+// Rows sets NumberOfRows, at most SCANNER_MAX_ROWS
+func (b *ScannerSubscriptionBuilder) Rows(n int64) *ScannerSubscriptionBuilder {
+	b.subscription.NumberOfRows = n
+	return b
+}
+
+// Filter adds the filter option of the filter field code, value is formatted by fmt, such as Filter("priceAbove", 5)
+func (b *ScannerSubscriptionBuilder) Filter(code string, value interface{}) *ScannerSubscriptionBuilder {
+	b.filterOptions = append(b.filterOptions, TagValue{Tag: code, Value: fmt.Sprint(value)})
+	return b
+}
+
+// Build validates and returns the subscription and the filter options for ReqScannerSubscription
+func (b *ScannerSubscriptionBuilder) Build() (*ScannerSubscription, []TagValue, error) {
+	if err := b.catalog.Validate(b.subscription, b.filterOptions); err != nil {
+		return nil, nil, err
+	}
+	return b.subscription, b.filterOptions, nil
+}
+
+// scannerParametersWaiter observes ScannerParameters for FetchScannerCatalog
+type scannerParametersWaiter struct {
+	ch chan string
+}
+
+func (w *scannerParametersWaiter) ScannerParameters(xml string) {
+	select {
+	case w.ch <- xml:
+	default:
+	}
+}
+
+// FetchScannerCatalog requests the scanner parameters and parses them into ScannerCatalog
+func (ic *IbClient) FetchScannerCatalog(ctx context.Context) (*ScannerCatalog, error) {
+	if !ic.IsConnected() {
+		return nil, NOT_CONNECTED
+	}
+
+	w := &scannerParametersWaiter{make(chan string, 1)}
+	ic.AddObserver(w)
+	defer ic.RemoveObserver(w)
+
+	ic.ReqScannerParameters()
+
+	select {
+	case xmlData := <-w.ch:
+		return ParseScannerParameters(xmlData)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// FetchScannerData subscribes the scanner, collects ScannerData until ScannerDataEnd and cancels the subscription.
+// The results are sorted by Rank.
+func (ic *IbClient) FetchScannerData(ctx context.Context, subscription *ScannerSubscription, filterOptions []TagValue) ([]ScanData, error) {
+	if !ic.IsConnected() {
+		return nil, NOT_CONNECTED
+	}
+
+	reqID := ic.GetReqID()
+	var results []ScanData
+	err := ic.request(ctx, reqID, func() { ic.ReqScannerSubscription(reqID, subscription, nil, filterOptions) }, func(msg interface{}) bool {
+		switch m := msg.(type) {
+		case *scannerDataMsg:
+			results = append(results, m.data)
+		case *scannerDataEndMsg:
+			return true
+		}
+		return false
+	})
+
+	if err == nil || err == context.Canceled || err == context.DeadlineExceeded {
+		ic.CancelScannerSubscription(reqID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Rank < results[j].Rank })
+	return results, nil
+}
@@ -0,0 +1,106 @@
+package ibapi
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func readScannerCatalog(t *testing.T) (string, *ScannerCatalog) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "scanner", "ScannerParameters.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := ParseScannerParameters(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data), c
+}
+
+func TestScannerCatalog(t *testing.T) {
+	_, c := readScannerCatalog(t)
+
+	if i, ok := c.Instrument("STK"); !ok || i.Name != "US Stocks" || len(i.Filters()) != 3 {
+		t.Errorf("unexpected instrument: %+v", i)
+	}
+	if l, ok := c.Location("STK.US.MAJOR"); !ok || l.DisplayName != "Listed/NASDAQ" {
+		t.Errorf("unexpected location: %+v", l)
+	}
+	if l := c.LocationsOf("FUT.US"); len(l) != 2 || l[1].LocationCode != "FUT.CME" {
+		t.Errorf("unexpected locations of FUT.US: %+v", l)
+	}
+	if s := c.ScanTypesOf("FUT.US"); len(s) != 1 || s[0].ScanCode != "TOP_PERC_GAIN" {
+		t.Errorf("unexpected scan types of FUT.US: %+v", s)
+	}
+	if f := c.FiltersOf("STK"); len(f) != 3 || f[2].Kind() != "SimpleFilter" {
+		t.Errorf("unexpected filters of STK: %+v", f)
+	}
+	if f, filter, ok := c.FilterField("stkTypes"); !ok || filter.ID != "STKTYPE" || f.Type != SCANNER_FIELD_COMBO || len(f.ComboValues) != 2 {
+		t.Errorf("unexpected filter field: %+v", f)
+	}
+	if s := c.SearchScanTypes("active"); len(s) != 1 || s[0].ScanCode != "MOST_ACTIVE" {
+		t.Errorf("unexpected search result: %+v", s)
+	}
+	if f := c.SearchFilterFields("price"); len(f) != 2 {
+		t.Errorf("unexpected search result: %+v", f)
+	}
+
+	sub, filters, err := c.NewSubscription("STK", "STK.US.MAJOR", "MOST_ACTIVE").Rows(10).Filter("priceAbove", 5).Filter("stkTypes", "CORP").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.NumberOfRows != 10 || sub.AbovePrice != UNSETFLOAT || len(filters) != 2 || filters[0].Value != "5" {
+		t.Errorf("unexpected subscription: %v, %v", sub, filters)
+	}
+
+	_, _, err = c.NewSubscription("FUT.US", "STK.US", "MOST_ACTIVE").Rows(100).Filter("volumeAbove", 1.5).Filter("priceAbove", -1).Filter("foo", 1).Build()
+	verr, ok := err.(*ScannerValidationError)
+	if !ok {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(verr.Problems) != 7 {
+		t.Errorf("unexpected problems: %v", verr.Problems)
+	}
+}
+
+func TestFetchScannerData(t *testing.T) {
+	xmlData, _ := readScannerCatalog(t)
+	ic := newTestClient(func(ic *IbClient, req []byte) {
+		fields := splitMsgBytes(req[4:])
+		switch fieldInt(fields[0]) {
+		case mREQ_SCANNER_PARAMETERS:
+			ic.dispatcher.ScannerParameters(xmlData)
+		case mREQ_SCANNER_SUBSCRIPTION:
+			reqID := fieldInt(fields[1])
+			for _, rank := range []int64{1, 0, 2} {
+				cd := &ContractDetails{}
+				cd.Contract.Symbol = string(rune('A' + rank))
+				ic.dispatcher.ScannerData(reqID, rank, cd, "", "", "", "")
+			}
+			ic.dispatcher.ScannerDataEnd(reqID)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	c, err := ic.FetchScannerCatalog(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, filters, err := c.NewSubscription("STK", "STK.US.MAJOR", "TOP_PERC_GAIN").Filter("priceAbove", 5).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := ic.FetchScannerData(ctx, sub, filters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || results[0].Rank != 0 || results[0].ContractDetails.Contract.Symbol != "A" || results[2].Rank != 2 {
+		t.Errorf("unexpected results: %v", results)
+	}
+}
@@ -0,0 +1,225 @@
+/*
+session contains TradingCalendar, the trading sessions parsed from the TradingHours or LiquidHours of ContractDetails.
+*/
+
+package ibapi
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Session is a trading session, Day is the yyyymmdd the session is listed under in the trading hours
+type Session struct {
+	Day   string
+	Start time.Time
+	End   time.Time
+}
+
+func (s Session) String() string {
+	return fmt.Sprintf("Session<%s: %s - %s>", s.Day, s.Start.Format("20060102 15:04"), s.End.Format("20060102 15:04 MST"))
+}
+
+// Contains reports whether t is in [Start, End)
+func (s Session) Contains(t time.Time) bool {
+	return !t.Before(s.Start) && t.Before(s.End)
+}
+
+// TradingCalendar is the sessions of a contract in its time zone.
+/*
+It only covers the days listed in the trading hours, which are usually a few days from the request,
+the methods answer as closed beyond them, use Covers to tell.
+IsOpen or SessionAt could be used to drop the bars or delay the orders outside the regular trading hours,
+with the calendar created by NewTradingCalendar(cd, true).
+*/
+type TradingCalendar struct {
+	Location   *time.Location
+	Sessions   []Session       // sorted by Start
+	ClosedDays map[string]bool // the days listed as CLOSED, yyyymmdd
+	first      time.Time
+	last       time.Time
+}
+
+// NewTradingCalendar parses the LiquidHours of cd if useRTH, otherwise the TradingHours, in the time zone of cd.TimezoneID
+func NewTradingCalendar(cd *ContractDetails, useRTH bool) (*TradingCalendar, error) {
+	loc, err := LoadTWSLocation(cd.TimezoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	if useRTH {
+		return ParseTradingHours(cd.LiquidHours, loc)
+	}
+	return ParseTradingHours(cd.TradingHours, loc)
+}
+
+// ParseTradingHours parses the TradingHours or LiquidHours of ContractDetails in loc.
+/*
+Both the formats are accepted:
+	20240101:CLOSED;20240102:0930-20240102:1600;20240103:1700-20240104:1600,20240104:1630-20240104:1700
+	20090507:0700-1830,1830-2330;20090508:CLOSED
+In the latter one, a session ending before its start ends on the next day.
+*/
+func ParseTradingHours(hours string, loc *time.Location) (*TradingCalendar, error) {
+	tc := &TradingCalendar{Location: loc, ClosedDays: make(map[string]bool)}
+
+	for _, item := range strings.Split(hours, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		i := strings.Index(item, ":")
+		if i != 8 {
+			return nil, fmt.Errorf("invalid trading hours %q", item)
+		}
+		day, ranges := item[:8], item[9:]
+		dayStart, err := time.ParseInLocation("20060102", day, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trading hours %q: %v", item, err)
+		}
+		tc.extend(dayStart, dayStart.AddDate(0, 0, 1))
+
+		if ranges == "CLOSED" {
+			tc.ClosedDays[day] = true
+			continue
+		}
+
+		for _, r := range strings.Split(ranges, ",") {
+			bounds := strings.Split(r, "-")
+			if len(bounds) != 2 {
+				return nil, fmt.Errorf("invalid trading hours %q: bad session %s", item, r)
+			}
+
+			start, err := parseSessionTime(bounds[0], dayStart)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trading hours %q: %v", item, err)
+			}
+			end, err := parseSessionTime(bounds[1], dayStart)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trading hours %q: %v", item, err)
+			}
+			if !strings.Contains(bounds[1], ":") && !end.After(start) {
+				end = end.AddDate(0, 0, 1)
+			}
+			if !end.After(start) {
+				return nil, fmt.Errorf("invalid trading hours %q: session %s ends before it starts", item, r)
+			}
+
+			tc.Sessions = append(tc.Sessions, Session{Day: day, Start: start, End: end})
+			tc.extend(start, end)
+		}
+	}
+
+	sort.Slice(tc.Sessions, func(i, j int) bool { return tc.Sessions[i].Start.Before(tc.Sessions[j].Start) })
+	return tc, nil
+}
+
+// parseSessionTime parses "hhmm" on the day of dayStart, or "yyyymmdd:hhmm"
+func parseSessionTime(s string, dayStart time.Time) (time.Time, error) {
+	if i := strings.Index(s, ":"); i >= 0 {
+		day, err := time.ParseInLocation("20060102", s[:i], dayStart.Location())
+		if err != nil {
+			return time.Time{}, err
+		}
+		dayStart, s = day, s[i+1:]
+	}
+
+	if len(s) != 4 {
+		return time.Time{}, fmt.Errorf("bad time %s", s)
+	}
+	hhmm, err := strconv.Atoi(s)
+	if err != nil || hhmm%100 >= 60 || hhmm > 2400 {
+		return time.Time{}, fmt.Errorf("bad time %s", s)
+	}
+
+	return time.Date(dayStart.Year(), dayStart.Month(), dayStart.Day(), hhmm/100, hhmm%100, 0, 0, dayStart.Location()), nil
+}
+
+func (tc *TradingCalendar) extend(start, end time.Time) {
+	if tc.first.IsZero() || start.Before(tc.first) {
+		tc.first = start
+	}
+	if end.After(tc.last) {
+		tc.last = end
+	}
+}
+
+// Covers reports whether t is in the days listed in the trading hours
+func (tc *TradingCalendar) Covers(t time.Time) bool {
+	return !t.Before(tc.first) && t.Before(tc.last)
+}
+
+// IsOpen reports whether t is in any session
+func (tc *TradingCalendar) IsOpen(t time.Time) bool {
+	_, ok := tc.SessionAt(t)
+	return ok
+}
+
+// SessionAt returns the session containing t
+func (tc *TradingCalendar) SessionAt(t time.Time) (Session, bool) {
+	i := sort.Search(len(tc.Sessions), func(i int) bool { return tc.Sessions[i].End.After(t) })
+	for ; i < len(tc.Sessions) && !tc.Sessions[i].Start.After(t); i++ {
+		if tc.Sessions[i].Contains(t) {
+			return tc.Sessions[i], true
+		}
+	}
+	return Session{}, false
+}
+
+// NextOpen returns the start of the first session after t, which is not contiguous with the session containing t
+func (tc *TradingCalendar) NextOpen(t time.Time) (time.Time, bool) {
+	if tc.IsOpen(t) {
+		t, _ = tc.NextClose(t)
+	}
+
+	for _, s := range tc.Sessions {
+		if !s.Start.Before(t) {
+			return s.Start.In(tc.Location), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// NextClose returns the end of the session containing t, or of the next session if closed at t.
+// The contiguous sessions, such as an overnight session followed by the day session, are regarded as one.
+func (tc *TradingCalendar) NextClose(t time.Time) (time.Time, bool) {
+	s, ok := tc.SessionAt(t)
+	if !ok {
+		open, ok := tc.NextOpen(t)
+		if !ok {
+			return time.Time{}, false
+		}
+		s, _ = tc.SessionAt(open)
+	}
+
+	end := s.End
+	for {
+		next, ok := tc.SessionAt(end)
+		if !ok {
+			break
+		}
+		end = next.End
+	}
+
+	return end.In(tc.Location), true
+}
+
+// SessionsOn returns the sessions listed under day, yyyymmdd
+func (tc *TradingCalendar) SessionsOn(day string) []Session {
+	var sessions []Session
+	for _, s := range tc.Sessions {
+		if s.Day == day {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions
+}
+
+// IsClosedDay reports whether day, yyyymmdd, is listed as CLOSED, such as the holidays and weekends
+func (tc *TradingCalendar) IsClosedDay(day string) bool {
+	return tc.ClosedDays[day]
+}
@@ -0,0 +1,82 @@
+package ibapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTradingCalendar(t *testing.T) {
+	cd := &ContractDetails{
+		TimezoneID:   "US/Central",
+		TradingHours: "20240101:CLOSED;20240102:1700-20240103:1600;20240103:1700-20240104:1600;20240104:1700-20240105:1600",
+		LiquidHours:  "20240101:CLOSED;20240102:0830-20240102:1200,20240102:1300-20240102:1500;20240103:0830-1500",
+	}
+
+	eth, err := NewTradingCalendar(cd, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rth, err := NewTradingCalendar(cd, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(s string) time.Time {
+		tm, err := time.ParseInLocation("20060102 15:04", s, eth.Location)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+
+	if !eth.IsClosedDay("20240101") || eth.IsOpen(at("20240101 12:00")) {
+		t.Error("holiday should be closed")
+	}
+	if !eth.IsOpen(at("20240102 23:00")) || eth.IsOpen(at("20240103 16:30")) {
+		t.Error("unexpected overnight session")
+	}
+	if close, ok := eth.NextClose(at("20240102 23:00")); !ok || !close.Equal(at("20240103 16:00")) {
+		t.Errorf("unexpected next close %s", close)
+	}
+	if open, ok := eth.NextOpen(at("20240103 16:30")); !ok || !open.Equal(at("20240103 17:00")) {
+		t.Errorf("unexpected next open %s", open)
+	}
+	if _, ok := eth.NextOpen(at("20240105 12:00")); ok {
+		t.Error("next open beyond the calendar should be unknown")
+	}
+
+	if len(rth.SessionsOn("20240102")) != 2 || rth.IsOpen(at("20240102 12:30")) || !rth.IsOpen(at("20240102 13:00")) {
+		t.Errorf("unexpected lunch break: %v", rth.Sessions)
+	}
+	if open, ok := rth.NextOpen(at("20240102 10:00")); !ok || !open.Equal(at("20240102 13:00")) {
+		t.Errorf("unexpected next open %s", open)
+	}
+	if close, ok := rth.NextClose(at("20240102 16:00")); !ok || !close.Equal(at("20240103 15:00")) {
+		t.Errorf("unexpected next close %s", close)
+	}
+
+	if _, err := ParseTradingHours("20240102:0930-", eth.Location); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestLoadTWSLocation(t *testing.T) {
+	for name, iana := range map[string]string{
+		"US/Eastern":                  "US/Eastern",
+		"EST (Eastern Standard Time)": "America/New_York",
+		"Hongkong Standard Time":      "Asia/Hong_Kong",
+		"MET":                         "Europe/Berlin",
+		"GMT":                         "GMT",
+		"CST (China Standard Time)":   "Asia/Shanghai",
+		"CST (Central Standard Time)": "America/Chicago",
+	} {
+		loc, err := LoadTWSLocation(name)
+		if err != nil || loc.String() != iana {
+			t.Errorf("%s: unexpected location %v, %v", name, loc, err)
+		}
+	}
+
+	if _, err := LoadTWSLocation("IST"); err == nil {
+		t.Error("the ambiguous IST should not be mapped")
+	}
+}
@@ -0,0 +1,142 @@
+/*
+shutdown contains Shutdown, which disconnects IbClient after the queued requests are sent.
+*/
+
+package ibapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// UnsentRequest is a request which was not sent to TWS before Shutdown disconnected, MsgBytes is the encoded request with the size prefix
+type UnsentRequest struct {
+	MsgID    int64
+	MsgBytes []byte
+}
+
+// newUnsentRequest decodes the msg ID of the encoded request, NO_VALID_ID if malformed
+func newUnsentRequest(msgBytes []byte) UnsentRequest {
+	r := UnsentRequest{MsgID: NO_VALID_ID, MsgBytes: msgBytes}
+	if len(msgBytes) > 4 {
+		if fields := splitMsgBytes(msgBytes[4:]); len(fields) > 0 {
+			if msgID, err := strconv.ParseInt(string(fields[0]), 10, 64); err == nil {
+				r.MsgID = msgID
+			}
+		}
+	}
+	return r
+}
+
+// ShutdownError is returned by Shutdown if any request is unsent, or the request queue is not flushed before ctx is done.
+// It unwraps to Err, such as context.DeadlineExceeded.
+type ShutdownError struct {
+	Unsent []UnsentRequest
+	Err    error
+}
+
+func (e *ShutdownError) Error() string {
+	msgIDs := make([]string, len(e.Unsent))
+	for i, r := range e.Unsent {
+		msgIDs[i] = fmt.Sprint(r.MsgID)
+	}
+
+	msg := fmt.Sprintf("shutdown with %d unsent requests [%s]", len(e.Unsent), strings.Join(msgIDs, " "))
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
+}
+
+// reqQueue is the encoded requests kept aside, such as the ones failed to be written
+type reqQueue struct {
+	mu   sync.Mutex
+	reqs [][]byte
+}
+
+func (q *reqQueue) add(req []byte) {
+	q.mu.Lock()
+	q.reqs = append(q.reqs, req)
+	q.mu.Unlock()
+}
+
+// take returns the requests and empties the queue
+func (q *reqQueue) take() [][]byte {
+	q.mu.Lock()
+	reqs := q.reqs
+	q.reqs = nil
+	q.mu.Unlock()
+	return reqs
+}
+
+// reqDone counts a request leaving reqChan, or rejected, and signals flushed if it is the last one while closing
+func (ic *IbClient) reqDone() {
+	if atomic.AddInt64(&ic.pendingReqs, -1) == 0 && atomic.LoadInt32(&ic.closing) == 1 {
+		select {
+		case ic.flushed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Shutdown disconnects IbClient gracefully.
+/*
+	1.cancel the active subscriptions if cancelSubscriptions, see Subscriptions
+	2.stop accepting new requests, which are reported by Error with NOT_CONNECTED
+	3.wait until the requests queued in reqChan are written to TWS, or ctx is done
+	4.Disconnect, which waits for the goroutines and fails the pending requests of the helpers with NOT_CONNECTED
+The orders held by PauseOrders, the requests failed to be written and the ones left in reqChan are never sent,
+they are returned by ShutdownError.
+*/
+func (ic *IbClient) Shutdown(ctx context.Context, cancelSubscriptions bool) error {
+	if !ic.IsConnected() {
+		return NOT_CONNECTED
+	}
+
+	if cancelSubscriptions {
+		ic.CancelSubscriptions()
+	}
+	atomic.StoreInt32(&ic.closing, 1)
+
+	var unsent []UnsentRequest
+	for _, o := range ic.orders.resume() {
+		unsent = append(unsent, newUnsentRequest(o.msg))
+	}
+
+	// reqDone signals flushed after closing is set, when the last pending request is done
+	var err error
+	if atomic.LoadInt64(&ic.pendingReqs) > 0 {
+		select {
+		case <-ic.flushed:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+
+	failed := ic.failedReqs.take()
+	left, derr := ic.disconnect()
+	for _, msgBytes := range append(failed, left...) {
+		unsent = append(unsent, newUnsentRequest(msgBytes))
+	}
+	if err == nil {
+		err = derr
+	}
+
+	if err != nil || len(unsent) > 0 {
+		log.Warn("shutdown with unsent requests", zap.Int("unsent", len(unsent)), zap.Error(err))
+		return &ShutdownError{Unsent: unsent, Err: err}
+	}
+
+	log.Info("shutdown")
+	return nil
+}
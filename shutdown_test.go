@@ -0,0 +1,160 @@
+package ibapi
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// connectFakeTWS connects ic to a fakeTWS, run starts the requester and the decoder
+func connectFakeTWS(t *testing.T, ic *IbClient, port int, run bool) {
+	t.Helper()
+	if err := ic.Connect("127.0.0.1", port, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := ic.HandShake(); err != nil {
+		t.Fatal(err)
+	}
+	if run {
+		if err := ic.Run(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	rec := &reqRecorder{}
+	port, stop := fakeTWS(t, rec.record)
+	defer stop()
+
+	ic := NewIbClient(new(Wrapper))
+	connectFakeTWS(t, ic, port, true)
+
+	aapl := &Contract{ContractID: 265598, Symbol: "AAPL", SecurityType: "STK", Exchange: "SMART", Currency: "USD"}
+	ic.ReqMktData(1, aapl, "", false, false, nil)
+	ic.ReqRealTimeBars(2, aapl, 5, "TRADES", true, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := ic.Shutdown(ctx, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if ic.IsConnected() || len(ic.Subscriptions()) != 0 {
+		t.Error("the client should be disconnected without subscriptions")
+	}
+	waitFor(t, func() bool { return rec.count() == 4 })
+	want := []int64{mREQ_MKT_DATA, mREQ_REAL_TIME_BARS, mCANCEL_MKT_DATA, mCANCEL_REAL_TIME_BARS}
+	for i, msgID := range want {
+		if got := rec.msgID(i); got != msgID {
+			t.Errorf("request %d: got msg %d, want %d", i, got, msgID)
+		}
+	}
+
+	if err := ic.Shutdown(ctx, false); err != NOT_CONNECTED {
+		t.Errorf("expect NOT_CONNECTED, got %v", err)
+	}
+}
+
+// errCodeWrapper sends the codes of Error to errCodes
+type errCodeWrapper struct {
+	Wrapper
+	errCodes chan int64
+}
+
+func (w *errCodeWrapper) Error(reqID int64, errCode int64, errString string) {
+	w.errCodes <- errCode
+}
+
+func TestShutdownUnsent(t *testing.T) {
+	port, stop := fakeTWS(t, nil)
+	defer stop()
+
+	w := &errCodeWrapper{errCodes: make(chan int64, 10)}
+	ic := NewIbClient(w)
+	connectFakeTWS(t, ic, port, false) // without the requester, the requests stay in reqChan
+
+	ic.ReqCurrentTime()
+	ic.PauseOrders()
+	ic.PlaceOrder(1, &Contract{Symbol: "AAPL", SecurityType: "STK", Exchange: "SMART", Currency: "USD"}, NewLimitOrder("BUY", 100, 1))
+
+	// the pending request of a helper fails on the disconnection
+	registered := make(chan struct{})
+	reqErr := make(chan error, 1)
+	go func() {
+		reqErr <- ic.request(context.Background(), 9, func() { close(registered) }, func(interface{}) bool { return false })
+	}()
+	<-registered
+
+	// the new requests are rejected while shutting down, then the shutdown is given up
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		for atomic.LoadInt32(&ic.closing) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		ic.ReqCurrentTime()
+		cancel()
+	}()
+	err := ic.Shutdown(ctx, false)
+
+	var se *ShutdownError
+	if !errors.As(err, &se) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expect ShutdownError of the cancellation, got %v", err)
+	}
+	if len(se.Unsent) != 2 || se.Unsent[0].MsgID != mPLACE_ORDER || se.Unsent[1].MsgID != mREQ_CURRENT_TIME {
+		t.Errorf("unexpected unsent requests %v", se)
+	}
+	if ic.OrdersPaused() || ic.IsConnected() {
+		t.Error("the client should be reset")
+	}
+	select {
+	case code := <-w.errCodes:
+		if code != NOT_CONNECTED.code {
+			t.Errorf("expect NOT_CONNECTED, got %d", code)
+		}
+	default:
+		t.Error("the request during the shutdown should be rejected")
+	}
+	select {
+	case err := <-reqErr:
+		if !errors.Is(err, NOT_CONNECTED) {
+			t.Errorf("expect NOT_CONNECTED, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("the pending request should fail")
+	}
+}
+
+func TestShutdownFailedWrite(t *testing.T) {
+	port, stop := fakeTWS(t, nil)
+	defer stop()
+
+	ic := NewIbClient(new(Wrapper))
+	connectFakeTWS(t, ic, port, false)
+	ic.ReqCurrentTime()
+
+	// the requester is started after the connection is lost, so the queued request fails to be written
+	go func() {
+		for atomic.LoadInt32(&ic.closing) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		ic.setConnState(DISCONNECTED)
+		ic.wg.Add(1)
+		go ic.goRequest()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := ic.Shutdown(ctx, false)
+
+	var se *ShutdownError
+	if !errors.As(err, &se) || se.Err != nil {
+		t.Fatalf("expect ShutdownError without the deadline, got %v", err)
+	}
+	if len(se.Unsent) != 1 || se.Unsent[0].MsgID != mREQ_CURRENT_TIME {
+		t.Errorf("unexpected unsent requests %v", se)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+subscriptions keeps the streaming market data requests of IbClient,
+so that they could be sent again after the data is lost, or canceled all at once.
+*/
+
+package ibapi
+
+import (
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// subscription is a streaming request, request sends it again with the same reqID and cancel cancels it
+type subscription struct {
+	request func()
+	cancel  func()
+}
+
+// subscriptionTable is the active subscriptions keyed by reqID, which is added by the Req and removed by the Cancel
+type subscriptionTable struct {
+	mu   sync.Mutex
+	subs map[int64]subscription
+}
+
+func (t *subscriptionTable) add(reqID int64, request func(), cancel func()) {
+	t.mu.Lock()
+	if t.subs == nil {
+		t.subs = make(map[int64]subscription)
+	}
+	t.subs[reqID] = subscription{request, cancel}
+	t.mu.Unlock()
+}
+
+func (t *subscriptionTable) remove(reqID int64) {
+	t.mu.Lock()
+	delete(t.subs, reqID)
+	t.mu.Unlock()
+}
+
+func (t *subscriptionTable) clear() {
+	t.mu.Lock()
+	t.subs = nil
+	t.mu.Unlock()
+}
+
+// reqIDs returns the reqIDs of the active subscriptions in ascending order
+func (t *subscriptionTable) reqIDs() []int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	reqIDs := make([]int64, 0, len(t.subs))
+	for reqID := range t.subs {
+		reqIDs = append(reqIDs, reqID)
+	}
+	sort.Slice(reqIDs, func(i, j int) bool { return reqIDs[i] < reqIDs[j] })
+	return reqIDs
+}
+
+func (t *subscriptionTable) get(reqID int64) (subscription, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.subs[reqID]
+	return s, ok
+}
+
+// Subscriptions returns the reqIDs of the active streaming market data requests, in ascending order.
+/*
+They are added by
+	ReqMktData, except the snapshots
+	ReqMktDepth
+	ReqRealTimeBars
+	ReqTickByTickData
+and removed by the Cancel of them, or the disconnection.
+*/
+func (ic *IbClient) Subscriptions() []int64 {
+	return ic.subscriptions.reqIDs()
+}
+
+// Resubscribe cancels the active subscriptions and sends them again with the same reqIDs,
+// which is required after TWS reports 1101, connectivity restored but the market data lost.
+func (ic *IbClient) Resubscribe() {
+	for _, reqID := range ic.subscriptions.reqIDs() {
+		if s, ok := ic.subscriptions.get(reqID); ok {
+			log.Info("resubscribe", zap.Int64("reqID", reqID))
+			s.cancel()
+			s.request()
+		}
+	}
+}
+
+// CancelSubscriptions cancels all the active subscriptions
+func (ic *IbClient) CancelSubscriptions() {
+	for _, reqID := range ic.subscriptions.reqIDs() {
+		if s, ok := ic.subscriptions.get(reqID); ok {
+			s.cancel()
+		}
+	}
+}
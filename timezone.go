@@ -0,0 +1,170 @@
+package ibapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// twsTimezones maps the time zone names used by TWS, which are not IANA names, to the IANA names.
+// The ambiguous abbreviations, such as CST, IST and BST, are left out, the long names in parentheses tell them apart.
+var twsTimezones = map[string]string{
+	"EST":                              "America/New_York",
+	"EDT":                              "America/New_York",
+	"Eastern Standard Time":            "America/New_York",
+	"CDT":                              "America/Chicago",
+	"Central Standard Time":            "America/Chicago",
+	"MST":                              "America/Denver",
+	"Mountain Standard Time":           "America/Denver",
+	"PST":                              "America/Los_Angeles",
+	"PDT":                              "America/Los_Angeles",
+	"Pacific Standard Time":            "America/Los_Angeles",
+	"HKT":                              "Asia/Hong_Kong",
+	"Hongkong Standard Time":           "Asia/Hong_Kong",
+	"Hong Kong Standard Time":          "Asia/Hong_Kong",
+	"China Standard Time":              "Asia/Shanghai",
+	"JST":                              "Asia/Tokyo",
+	"Japan Standard Time":              "Asia/Tokyo",
+	"Korea Standard Time":              "Asia/Seoul",
+	"Singapore Standard Time":          "Asia/Singapore",
+	"India Standard Time":              "Asia/Kolkata",
+	"Greenwich Mean Time":              "Europe/London",
+	"British Summer Time":              "Europe/London",
+	"CET":                              "Europe/Berlin",
+	"MET":                              "Europe/Berlin",
+	"Central European Time":            "Europe/Berlin",
+	"AEST":                             "Australia/Sydney",
+	"AEDT":                             "Australia/Sydney",
+	"Australian Eastern Standard Time": "Australia/Sydney",
+}
+
+var locationCache sync.Map // map[string]*time.Location
+
+// LoadTWSLocation loads the location of the time zone name used by TWS,
+// such as the IANA "US/Eastern", "EST (Eastern Standard Time)" or "Hongkong Standard Time".
+func LoadTWSLocation(name string) (*time.Location, error) {
+	if loc, ok := locationCache.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+
+	loc, err := loadTWSLocation(strings.TrimSpace(name))
+	if err != nil {
+		return nil, err
+	}
+
+	locationCache.Store(name, loc)
+	return loc, nil
+}
+
+func loadTWSLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, fmt.Errorf("empty time zone")
+	}
+
+	// "EST (Eastern Standard Time)", the long name goes before the abbreviation, which may be ambiguous
+	candidates := []string{name}
+	if i := strings.Index(name, " ("); i > 0 && strings.HasSuffix(name, ")") {
+		candidates = append(candidates, name[i+2:len(name)-1], name[:i])
+	}
+
+	for _, candidate := range candidates {
+		if iana, ok := twsTimezones[candidate]; ok {
+			return time.LoadLocation(iana)
+		}
+		// the table goes first, since the zoneinfo of "EST" is a fixed offset without daylight saving
+		if loc, err := time.LoadLocation(candidate); err == nil {
+			return loc, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown time zone %q", name)
+}
+
+// connTimeLocation is the location of the time zone in the connection time of the handshake, such as "20221019 10:00:00 EST",
+// time.Local if it is missing or unknown
+func connTimeLocation(connTime string) *time.Location {
+	fields := strings.Fields(connTime)
+	if len(fields) < 3 {
+		return time.Local
+	}
+
+	loc, err := LoadTWSLocation(strings.Join(fields[2:], " "))
+	if err != nil {
+		log.Warn("unknown time zone of TWS login", zap.String("connectionTime", connTime), zap.Error(err))
+		return time.Local
+	}
+	return loc
+}
+
+// ParseIBTime parses the timestamps sent by TWS, which are in the forms of
+/*
+	1577836800               the epoch seconds
+	20200101                 the day, at 00:00 in loc
+	20200101 09:30:00        in loc, the separator could be 2 spaces as in BarData
+	20200101 09:30:00 US/Eastern
+	20200101 09:30:00 Hongkong Standard Time
+	20200101-09:30:00        in UTC
+loc is used for the forms without time zone, it should be the time zone of TWS login, or of the contract for daily bars.
+*/
+func ParseIBTime(s string, loc *time.Location) (time.Time, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return time.Time{}, fmt.Errorf("empty time")
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+
+	if len(fields) == 1 {
+		f := fields[0]
+		switch {
+		case len(f) == 8:
+			return time.ParseInLocation("20060102", f, loc)
+		case len(f) == 17 && f[8] == '-':
+			return time.ParseInLocation("20060102-15:04:05", f, time.UTC)
+		}
+
+		epoch, err := strconv.ParseInt(f, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q", s)
+		}
+		return time.Unix(epoch, 0).In(loc), nil
+	}
+
+	if len(fields) > 2 {
+		tz, err := LoadTWSLocation(strings.Join(fields[2:], " "))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q: %v", s, err)
+		}
+		loc = tz
+	}
+
+	layout := "20060102 15:04:05"
+	if len(fields[1]) == 5 {
+		layout = "20060102 15:04"
+	}
+	return time.ParseInLocation(layout, fields[0]+" "+fields[1], loc)
+}
+
+// FormatIBTime formats t for the date time params of the requests, such as the endDateTime of ReqHistoricalData.
+/*
+The zero time is formatted as "", which means now for the end of the requests.
+t is formatted as "yyyymmdd hh:mm:ss tz" if its location has an IANA name, such as the ones from LoadTWSLocation,
+otherwise it is formatted in UTC as "yyyymmdd-hh:mm:ss".
+*/
+func FormatIBTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	if name := t.Location().String(); name != "UTC" && name != "Local" && name != "" {
+		if _, err := time.LoadLocation(name); err == nil {
+			return t.Format("20060102 15:04:05") + " " + name
+		}
+	}
+	return t.UTC().Format("20060102-15:04:05")
+}
@@ -0,0 +1,75 @@
+package ibapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseIBTime(t *testing.T) {
+	ny, err := LoadTWSLocation("US/Eastern")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hk, err := LoadTWSLocation("Hongkong Standard Time")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		s    string
+		want time.Time
+	}{
+		{"1577836800", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"20200102", time.Date(2020, 1, 2, 0, 0, 0, 0, ny)},
+		{"20200102  09:30:00", time.Date(2020, 1, 2, 9, 30, 0, 0, ny)},
+		{"20200102 09:30", time.Date(2020, 1, 2, 9, 30, 0, 0, ny)},
+		{"20200102 09:30:00 US/Eastern", time.Date(2020, 1, 2, 9, 30, 0, 0, ny)},
+		{"20200102 09:30:00 EST", time.Date(2020, 1, 2, 9, 30, 0, 0, ny)},
+		{"20200102 09:30:00 Hongkong Standard Time", time.Date(2020, 1, 2, 9, 30, 0, 0, hk)},
+		{"20200102-14:30:00", time.Date(2020, 1, 2, 14, 30, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		got, err := ParseIBTime(c.s, ny)
+		if err != nil {
+			t.Errorf("%q: %v", c.s, err)
+			continue
+		}
+		if !got.Equal(c.want) {
+			t.Errorf("%q: got %s, want %s", c.s, got, c.want)
+		}
+	}
+
+	for _, s := range []string{"", "2020-01-02", "20200102 09:30:00 Nowhere Time"} {
+		if _, err := ParseIBTime(s, ny); err == nil {
+			t.Errorf("%q should fail", s)
+		}
+	}
+
+	if got := bytesToTime([]byte("20200102 09:30:00 US/Eastern")); !got.Equal(time.Date(2020, 1, 2, 9, 30, 0, 0, ny)) {
+		t.Errorf("unexpected bytesToTime: %s", got)
+	}
+}
+
+func TestFormatIBTime(t *testing.T) {
+	ny, err := LoadTWSLocation("EST")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := FormatIBTime(time.Time{}); s != "" {
+		t.Errorf("zero time should be empty, got %q", s)
+	}
+	if s := FormatIBTime(time.Date(2020, 1, 2, 9, 30, 0, 0, ny)); s != "20200102 09:30:00 America/New_York" {
+		t.Errorf("unexpected format %q", s)
+	}
+	if s := FormatIBTime(time.Date(2020, 1, 2, 9, 30, 0, 0, time.FixedZone("", 8*3600))); s != "20200102-01:30:00" {
+		t.Errorf("unexpected format %q", s)
+	}
+
+	for _, tm := range []time.Time{time.Date(2020, 1, 2, 9, 30, 0, 0, ny), time.Date(2020, 1, 2, 9, 30, 0, 0, time.Local)} {
+		got, err := ParseIBTime(FormatIBTime(tm), time.UTC)
+		if err != nil || !got.Equal(tm) {
+			t.Errorf("%s is not round-tripped: %s, %v", tm, got, err)
+		}
+	}
+}
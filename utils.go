@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/binary"
+	"fmt"
 	"io"
 	"math"
 	"reflect"
@@ -45,15 +46,11 @@ func GetLogger() *zap.Logger {
 }
 
 func bytesToTime(b []byte) time.Time {
-	// format := "20060102 15:04:05 Mountain Standard Time"
-	// 214 208 185 250 177 234 215 188 202 177 188 228
-	format := "20060102 15:04:05 MST"
-	t := string(b)
-	localtime, err := time.ParseInLocation(format, t, time.Local)
+	t, err := ParseIBTime(string(b), time.Local)
 	if err != nil {
-		log.Error("btyes to time error", zap.Error(err))
+		log.Error("btyes to time error", zap.ByteString("time", b), zap.Error(err))
 	}
-	return localtime
+	return t
 }
 
 // readMsgBytes try to read the msg based on the message size
@@ -156,7 +153,7 @@ func makeMsgBytes(fields ...interface{}) []byte {
 		case int64:
 			msgBytes = strconv.AppendInt(msgBytes, v, 10)
 		case float64:
-			msgBytes = strconv.AppendFloat(msgBytes, v, 'g', 10, 64)
+			msgBytes = appendFloat(msgBytes, v)
 		case string:
 			msgBytes = append(msgBytes, []byte(v)...)
 		case bool:
@@ -169,6 +166,8 @@ func makeMsgBytes(fields ...interface{}) []byte {
 			msgBytes = strconv.AppendInt(msgBytes, int64(v), 10)
 		case []byte:
 			msgBytes = append(msgBytes, v...)
+		case Decimal:
+			msgBytes = append(msgBytes, v.String()...)
 		default:
 			log.Panic("failed to covert the field", zap.Reflect("field", f)) // never reach here
 		}
@@ -182,20 +181,36 @@ func makeMsgBytes(fields ...interface{}) []byte {
 	return msgBytes
 }
 
+// appendFloat appends the shortest decimal of f which is parsed back to the same float64,
+// the exponent form is only used if f is too large or too small, such as UNSETFLOAT
+func appendFloat(b []byte, f float64) []byte {
+	if math.IsInf(f, 0) {
+		// Infinity is what TWS takes, such as COMPETE_AGAINST_BEST_OFFSET_UP_TO_MID
+		if f > 0 {
+			return append(b, "Infinity"...)
+		}
+		return append(b, "-Infinity"...)
+	}
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-4 || abs >= 1e21) {
+		return strconv.AppendFloat(b, f, 'g', -1, 64)
+	}
+	return strconv.AppendFloat(b, f, 'f', -1, 64)
+}
+
 func splitMsgBytes(data []byte) [][]byte {
 	fields := bytes.Split(data, []byte{fieldSplit})
 	return fields[:len(fields)-1]
 }
 
-func decodeInt(field []byte) int64 {
+func decodeInt(field []byte) (int64, error) {
 	if bytes.Equal(field, []byte{}) {
-		return 0
+		return 0, nil
 	}
 	i, err := strconv.ParseInt(string(field), 10, 64)
 	if err != nil {
-		log.Panic("failed to decode int", zap.Error(err))
+		return 0, fmt.Errorf("failed to decode int: %w", err)
 	}
-	return i
+	return i, nil
 }
 
 func decodeString(field []byte) string {
@@ -238,46 +253,71 @@ func handleEmpty(d interface{}) string {
 		if v == UNSETFLOAT {
 			return ""
 		}
-		return strconv.FormatFloat(v, 'g', 10, 64)
+		return string(appendFloat(nil, v))
+
+	case int:
+		return strconv.Itoa(v)
+
+	case Decimal:
+		return v.String()
 
 	default:
-		log.Panic("no handler for such type", zap.Reflect("val", d))
-		return "" // never reach here
+		log.Warn("no handler for such type", zap.Reflect("val", d))
+		return fmt.Sprint(v)
 	}
 }
 
 //InitDefault try to init the object with the default tag, that is a common way but not a efficent way
-func InitDefault(o interface{}) {
-	t := reflect.TypeOf(o).Elem()
-	v := reflect.ValueOf(o).Elem()
+//it returns an error if o is not a pointer to struct or the default tag is unknown or mismatches the field type
+func InitDefault(o interface{}) error {
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("InitDefault: %T is not a pointer to struct", o)
+	}
+	v = v.Elem()
+	t := v.Type()
 
 	fieldCount := t.NumField()
 
 	for i := 0; i < fieldCount; i++ {
 		field := t.Field(i)
+		fv := v.Field(i)
 
-		if v.Field(i).Kind() == reflect.Struct {
-			InitDefault(v.Field(i).Addr().Interface())
+		if fv.Kind() == reflect.Struct && fv.Type() != decimalType {
+			if !fv.CanSet() {
+				continue
+			}
+			if err := InitDefault(fv.Addr().Interface()); err != nil {
+				return err
+			}
 			continue
 		}
 
 		if defaultValue, ok := field.Tag.Lookup("default"); ok {
+			if !fv.CanSet() {
+				return fmt.Errorf("InitDefault: field %s of %s is not settable", field.Name, t)
+			}
 
-			switch defaultValue {
-			case "UNSETFLOAT":
-				v.Field(i).SetFloat(UNSETFLOAT)
-			case "UNSETINT":
-				v.Field(i).SetInt(UNSETINT)
-			case "-1":
-				v.Field(i).SetInt(-1)
-			case "true":
-				v.Field(i).SetBool(true)
+			kind := fv.Kind()
+			switch {
+			case defaultValue == "UNSETFLOAT" && kind == reflect.Float64:
+				fv.SetFloat(UNSETFLOAT)
+			case defaultValue == "UNSETINT" && kind == reflect.Int64:
+				fv.SetInt(UNSETINT)
+			case defaultValue == "UNSETDECIMAL" && fv.Type() == decimalType:
+				fv.Set(reflect.ValueOf(UNSETDECIMAL))
+			case defaultValue == "-1" && kind >= reflect.Int && kind <= reflect.Int64:
+				fv.SetInt(-1)
+			case defaultValue == "true" && kind == reflect.Bool:
+				fv.SetBool(true)
 			default:
-				log.Panic("Unknown defaultValue", zap.Reflect("default", v))
+				return fmt.Errorf("InitDefault: unknown default %q for field %s %s of %s", defaultValue, field.Name, kind, t)
 			}
 		}
 
 	}
+
+	return nil
 }
 
 // MsgBuffer is the buffer that contains a whole msg
@@ -285,109 +325,122 @@ type MsgBuffer struct {
 	bytes.Buffer
 	bs  []byte
 	err error
+	loc *time.Location // the time zone of TWS login for the times without time zone, time.Local if nil
 }
 
-func (m *MsgBuffer) readInt() int64 {
-	var i int64
+// Err returns the first error of reading the fields, the reads after it return the zero values
+func (m *MsgBuffer) Err() error {
+	return m.err
+}
+
+// readField reads the next field into m.bs without the fieldSplit, it returns false once the buffer has an error
+func (m *MsgBuffer) readField() bool {
+	if m.err != nil {
+		return false
+	}
+
 	m.bs, m.err = m.ReadBytes(fieldSplit)
 	if m.err != nil {
-		log.Panic("decode int64 error", zap.Error(m.err))
+		m.err = fmt.Errorf("failed to read field %q: %w", m.bs, m.err)
+		return false
 	}
 
 	m.bs = m.bs[:len(m.bs)-1]
-	if bytes.Equal(m.bs, nil) {
+	return true
+}
+
+func (m *MsgBuffer) readInt() int64 {
+	if !m.readField() || len(m.bs) == 0 {
 		return 0
 	}
 
-	i, m.err = strconv.ParseInt(string(m.bs), 10, 64)
-	if m.err != nil {
-		log.Panic("decode int64 error", zap.Error(m.err))
+	i, err := strconv.ParseInt(string(m.bs), 10, 64)
+	if err != nil {
+		m.err = fmt.Errorf("decode int64 error: %w", err)
+		return 0
 	}
 
 	return i
 }
 
 func (m *MsgBuffer) readIntCheckUnset() int64 {
-	var i int64
-	m.bs, m.err = m.ReadBytes(fieldSplit)
-	if m.err != nil {
-		log.Panic("decode int64 error", zap.Error(m.err))
+	if !m.readField() {
+		return 0
 	}
 
-	m.bs = m.bs[:len(m.bs)-1]
-	if bytes.Equal(m.bs, nil) {
+	if len(m.bs) == 0 {
 		return UNSETINT
 	}
 
-	i, m.err = strconv.ParseInt(string(m.bs), 10, 64)
-	if m.err != nil {
-		log.Panic("decode int64 error", zap.Error(m.err))
+	i, err := strconv.ParseInt(string(m.bs), 10, 64)
+	if err != nil {
+		m.err = fmt.Errorf("decode int64 error: %w", err)
+		return 0
 	}
 
 	return i
 }
 
 func (m *MsgBuffer) readFloat() float64 {
-	var f float64
-	m.bs, m.err = m.ReadBytes(fieldSplit)
-	if m.err != nil {
-		log.Panic("decode float64 error", zap.Error(m.err))
-	}
-
-	m.bs = m.bs[:len(m.bs)-1]
-	if bytes.Equal(m.bs, nil) {
+	if !m.readField() || len(m.bs) == 0 {
 		return 0.0
 	}
 
-	f, m.err = strconv.ParseFloat(string(m.bs), 64)
-	if m.err != nil {
-		log.Panic("decode float64 error", zap.Error(m.err))
+	f, err := strconv.ParseFloat(string(m.bs), 64)
+	if err != nil {
+		m.err = fmt.Errorf("decode float64 error: %w", err)
+		return 0.0
 	}
 
 	return f
 }
 
 func (m *MsgBuffer) readFloatCheckUnset() float64 {
-	var f float64
-	m.bs, m.err = m.ReadBytes(fieldSplit)
-	if m.err != nil {
-		log.Panic("decode float64 error", zap.Error(m.err))
+	if !m.readField() {
+		return 0.0
 	}
 
-	m.bs = m.bs[:len(m.bs)-1]
-	if bytes.Equal(m.bs, nil) {
+	if len(m.bs) == 0 {
 		return UNSETFLOAT
 	}
 
-	f, m.err = strconv.ParseFloat(string(m.bs), 64)
-	if m.err != nil {
-		log.Panic("decode float64 error", zap.Error(m.err))
+	f, err := strconv.ParseFloat(string(m.bs), 64)
+	if err != nil {
+		m.err = fmt.Errorf("decode float64 error: %w", err)
+		return 0.0
 	}
 
 	return f
 }
 
 func (m *MsgBuffer) readBool() bool {
-	m.bs, m.err = m.ReadBytes(fieldSplit)
-	if m.err != nil {
-		log.Panic("decode bool error", zap.Error(m.err))
+	if !m.readField() {
+		return false
 	}
 
-	m.bs = m.bs[:len(m.bs)-1]
-
-	if bytes.Equal(m.bs, []byte{'0'}) || bytes.Equal(m.bs, nil) {
+	if bytes.Equal(m.bs, []byte{'0'}) || len(m.bs) == 0 {
 		return false
 	}
 	return true
 }
 
 func (m *MsgBuffer) readString() string {
-	m.bs, m.err = m.ReadBytes(fieldSplit)
-	if m.err != nil {
-		log.Panic("decode string error", zap.Error(m.err))
+	if !m.readField() {
+		return ""
 	}
 
-	return string(m.bs[:len(m.bs)-1])
+	return string(m.bs)
+}
+
+// capacity bounds the count n read from the msg by the remaining bytes, which is a sane capacity to make a slice
+func (m *MsgBuffer) capacity(n int64) int64 {
+	if n < 0 {
+		return 0
+	}
+	if l := int64(m.Len()); n > l {
+		return l
+	}
+	return n
 }
 
 // NewMsgBuffer create a new MsgBuffer
@@ -395,6 +448,7 @@ func NewMsgBuffer(bs []byte) *MsgBuffer {
 	return &MsgBuffer{
 		*bytes.NewBuffer(bs),
 		nil,
+		nil,
 		nil}
 }
 
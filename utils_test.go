@@ -56,3 +56,24 @@ func BenchmarkMakeMsg(b *testing.B) {
 		makeMsgBytes(v1, v2, v3, v4, v5, v6)
 	}
 }
+
+func TestInitDefault(t *testing.T) {
+	s := NewScannerSubscription()
+	var o ScannerSubscription
+	if err := InitDefault(&o); err != nil {
+		t.Fatal(err)
+	}
+	if o != *s {
+		t.Errorf("unexpected default %+v", o)
+	}
+
+	var bad struct {
+		Price string `default:"UNSETFLOAT"`
+	}
+	if err := InitDefault(&bad); err == nil {
+		t.Error("mismatched default should fail")
+	}
+	if err := InitDefault(o); err == nil {
+		t.Error("non-pointer should fail")
+	}
+}
@@ -0,0 +1,490 @@
+/*
+wsh contains the request and the typed models of the Wall Street Horizon calendar API,
+which are exchanged as JSON by ReqWshMetaData and ReqWshEventData, and the calendar query helper.
+*/
+
+package ibapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the tags of the WSH event types
+const (
+	WSH_EARNINGS_DATE       = "wshe_ed"
+	WSH_BOARD_OF_DIRECTORS  = "wshe_bod"
+	WSH_DIVIDEND            = "wshe_div"
+	WSH_SPLIT               = "wshe_spl"
+	WSH_CONFERENCE          = "wshe_conf"
+	WSH_SHAREHOLDER_MEETING = "wshe_sm"
+)
+
+// WshEventData is the request of ReqWshEventDataWith.
+/*
+ConID requests the events of the contract, or Filter requests the events matched it, see WshFilter.
+FillWatchlist, FillPortfolio and FillCompetitors add the contracts of the watchlist, portfolio or competitors to the Filter.
+StartDate and EndDate are yyyymmdd, TotalLimit limits the number of the events.
+*/
+type WshEventData struct {
+	ConID           int64 `default:"UNSETINT"`
+	Filter          string
+	FillWatchlist   bool
+	FillPortfolio   bool
+	FillCompetitors bool
+	StartDate       string
+	EndDate         string
+	TotalLimit      int64 `default:"UNSETINT"`
+}
+
+func (w WshEventData) String() string {
+	return fmt.Sprintf("WshEventData<ConID: %d, Filter: %s, FillWatchlist: %t, FillPortfolio: %t, FillCompetitors: %t, StartDate: %s, EndDate: %s, TotalLimit: %d>",
+		w.ConID,
+		w.Filter,
+		w.FillWatchlist,
+		w.FillPortfolio,
+		w.FillCompetitors,
+		w.StartDate,
+		w.EndDate,
+		w.TotalLimit)
+}
+
+// NewWshEventData create a default WshEventData
+func NewWshEventData() *WshEventData {
+	wshEventData := &WshEventData{}
+	InitDefault(wshEventData)
+	return wshEventData
+}
+
+// WshFilter is the Filter of WshEventData, such as
+/*
+	{"country": "All", "watchlist": ["8314"], "limit_region": 10, "limit": 10, "wshe_ed": "true", "wshe_bod": "true"}
+Watchlist is the conIDs, EventTypes are the tags of the event types from WshMeta.
+*/
+type WshFilter struct {
+	Country     string
+	Watchlist   []int64
+	LimitRegion int
+	Limit       int
+	EventTypes  []string
+}
+
+// MarshalJSON marshals the filter as the JSON accepted by TWS
+func (f WshFilter) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{})
+	if f.Country != "" {
+		m["country"] = f.Country
+	}
+	if len(f.Watchlist) > 0 {
+		watchlist := make([]string, 0, len(f.Watchlist))
+		for _, conID := range f.Watchlist {
+			watchlist = append(watchlist, strconv.FormatInt(conID, 10))
+		}
+		m["watchlist"] = watchlist
+	}
+	if f.LimitRegion > 0 {
+		m["limit_region"] = f.LimitRegion
+	}
+	if f.Limit > 0 {
+		m["limit"] = f.Limit
+	}
+	for _, tag := range f.EventTypes {
+		m[tag] = "true"
+	}
+	return json.Marshal(m)
+}
+
+func (f WshFilter) String() string {
+	b, _ := f.MarshalJSON()
+	return string(b)
+}
+
+/*
+   #########################################################################
+   ################## Meta Data
+   #########################################################################
+*/
+
+// WshMeta is the meta data of WshMetaData, Raw is the whole JSON
+type WshMeta struct {
+	EventTypes []WshEventType  `json:"event_types"`
+	Filters    []WshMetaFilter `json:"filters"`
+	Raw        json.RawMessage `json:"-"`
+}
+
+// WshEventType is an event type of WshMeta, Tag is used in WshFilter.EventTypes
+type WshEventType struct {
+	Tag      string `json:"tag"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
+
+// WshMetaFilter is a filter of WshMeta
+type WshMetaFilter struct {
+	Tag    string   `json:"tag"`
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	Values []string `json:"values"`
+}
+
+// unwrapWshJSON returns the payload of the JSON from TWS, which could be wrapped by {"data": ...} or {"metadata": ...}
+func unwrapWshJSON(data []byte, keys ...string) json.RawMessage {
+	for {
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(data, &m); err != nil {
+			return data
+		}
+
+		unwrapped := false
+		for _, key := range keys {
+			if v, ok := m[key]; ok {
+				data, unwrapped = v, true
+				break
+			}
+		}
+		if !unwrapped {
+			return data
+		}
+	}
+}
+
+// ParseWshMeta parses the dataJson of WshMetaData
+func ParseWshMeta(dataJson string) (*WshMeta, error) {
+	meta := &WshMeta{Raw: json.RawMessage(dataJson)}
+	if err := json.Unmarshal(unwrapWshJSON([]byte(dataJson), "data", "metadata", "meta_data"), meta); err != nil {
+		return nil, fmt.Errorf("failed to parse WSH meta data: %w", err)
+	}
+	return meta, nil
+}
+
+/*
+   #########################################################################
+   ################## Event Data
+   #########################################################################
+*/
+
+// WshEvent is an event of WshEventData, Data is the payload of the event type, see Earnings, Dividend, Split and Conference
+type WshEvent struct {
+	Type   string
+	ConID  int64
+	Symbol string
+	Date   string
+	Data   json.RawMessage
+}
+
+func (e WshEvent) String() string {
+	return fmt.Sprintf("WshEvent<Type: %s, ConID: %d, Symbol: %s, Date: %s>", e.Type, e.ConID, e.Symbol, e.Date)
+}
+
+// Time returns the Date in loc, which is yyyymmdd or yyyy-mm-dd
+func (e WshEvent) Time(loc *time.Location) (time.Time, error) {
+	return parseWshDate(e.Date, loc)
+}
+
+func parseWshDate(date string, loc *time.Location) (time.Time, error) {
+	if len(date) >= 10 && date[4] == '-' {
+		return time.ParseInLocation("2006-01-02", date[:10], loc)
+	}
+	return ParseIBTime(date, loc)
+}
+
+// the keys of the fields of the event JSON, the first present one is taken
+var (
+	wshEventTypeKeys   = []string{"event_type", "type", "index_date_type"}
+	wshEventConIDKeys  = []string{"conid", "con_id", "conId"}
+	wshEventSymbolKeys = []string{"symbol", "ticker"}
+	wshEventDateKeys   = []string{"index_date", "date", "event_date"}
+)
+
+// UnmarshalJSON takes the common fields of the event, Data is the "data" of the event, or the whole event without it
+func (e *WshEvent) UnmarshalJSON(b []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+
+	str := func(keys []string) string {
+		for _, key := range keys {
+			if v, ok := m[key]; ok {
+				var s string
+				if json.Unmarshal(v, &s) == nil {
+					return s
+				}
+				return strings.Trim(string(v), `"`)
+			}
+		}
+		return ""
+	}
+
+	e.Type = str(wshEventTypeKeys)
+	e.Symbol = str(wshEventSymbolKeys)
+	e.Date = str(wshEventDateKeys)
+	if conID := str(wshEventConIDKeys); conID != "" {
+		id, err := strconv.ParseInt(conID, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid conid %s of WSH event: %w", conID, err)
+		}
+		e.ConID = id
+	}
+	if data, ok := m["data"]; ok {
+		e.Data = data
+	} else {
+		e.Data = append(json.RawMessage(nil), b...)
+	}
+	return nil
+}
+
+// ParseWshEvents parses the dataJson of WshEventData, which is an event or a list of the events,
+// could be wrapped by {"data": ...} or {"events": ...}
+func ParseWshEvents(dataJson string) ([]WshEvent, error) {
+	events, err := parseWshEvents([]byte(dataJson))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse WSH events: %w", err)
+	}
+	return events, nil
+}
+
+func parseWshEvents(data []byte) ([]WshEvent, error) {
+	data = []byte(strings.TrimSpace(string(data)))
+	if len(data) > 0 && data[0] == '[' {
+		var events []WshEvent
+		err := json.Unmarshal(data, &events)
+		return events, err
+	}
+
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	for _, key := range wshEventTypeKeys {
+		if _, ok := m[key]; ok {
+			var event WshEvent
+			err := json.Unmarshal(data, &event)
+			return []WshEvent{event}, err
+		}
+	}
+	for _, key := range []string{"data", "events"} {
+		if v, ok := m[key]; ok {
+			return parseWshEvents(v)
+		}
+	}
+	return nil, fmt.Errorf("no event in %s", data)
+}
+
+// WshEarnings is the payload of WSH_EARNINGS_DATE, TimeOfDay is BMO, AMC or DMT etc
+type WshEarnings struct {
+	EarningsDate string `json:"earnings_date"`
+	TimeOfDay    string `json:"time_of_day"`
+	FiscalPeriod string `json:"fiscal_period"`
+	FiscalYear   string `json:"fiscal_year"`
+	Status       string `json:"status"`
+}
+
+// WshDividend is the payload of WSH_DIVIDEND
+type WshDividend struct {
+	DeclarationDate string      `json:"declaration_date"`
+	ExDate          string      `json:"ex_date"`
+	RecordDate      string      `json:"record_date"`
+	PayDate         string      `json:"pay_date"`
+	Amount          json.Number `json:"amount"`
+	Currency        string      `json:"currency"`
+	Frequency       string      `json:"frequency"`
+}
+
+// WshSplit is the payload of WSH_SPLIT, Ratio is such as "4:1"
+type WshSplit struct {
+	AnnouncementDate string `json:"announcement_date"`
+	ExDate           string `json:"ex_date"`
+	PayDate          string `json:"pay_date"`
+	Ratio            string `json:"ratio"`
+}
+
+// WshConference is the payload of WSH_CONFERENCE
+type WshConference struct {
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Location  string `json:"location"`
+	URL       string `json:"url"`
+}
+
+func (e WshEvent) decode(typ string, v interface{}) error {
+	if e.Type != typ {
+		return fmt.Errorf("%s is not a %s event", e.Type, typ)
+	}
+	return json.Unmarshal(e.Data, v)
+}
+
+// Earnings decodes the payload of WSH_EARNINGS_DATE
+func (e WshEvent) Earnings() (*WshEarnings, error) {
+	v := &WshEarnings{}
+	return v, e.decode(WSH_EARNINGS_DATE, v)
+}
+
+// Dividend decodes the payload of WSH_DIVIDEND
+func (e WshEvent) Dividend() (*WshDividend, error) {
+	v := &WshDividend{}
+	return v, e.decode(WSH_DIVIDEND, v)
+}
+
+// Split decodes the payload of WSH_SPLIT
+func (e WshEvent) Split() (*WshSplit, error) {
+	v := &WshSplit{}
+	return v, e.decode(WSH_SPLIT, v)
+}
+
+// Conference decodes the payload of WSH_CONFERENCE
+func (e WshEvent) Conference() (*WshConference, error) {
+	v := &WshConference{}
+	return v, e.decode(WSH_CONFERENCE, v)
+}
+
+/*
+   #########################################################################
+   ################## Request
+   #########################################################################
+*/
+
+// FetchWshMeta requests and parses the WSH meta data
+func (ic *IbClient) FetchWshMeta(ctx context.Context) (*WshMeta, error) {
+	if !ic.IsConnected() {
+		return nil, NOT_CONNECTED
+	}
+
+	reqID := ic.GetReqID()
+	var dataJSON string
+	err := ic.request(ctx, reqID, func() { ic.ReqWshMetaData(reqID) }, func(msg interface{}) bool {
+		if m, ok := msg.(*wshMetaDataMsg); ok {
+			dataJSON = m.dataJSON
+			return true
+		}
+		return false
+	})
+
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		ic.CancelWshMetaData(reqID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseWshMeta(dataJSON)
+}
+
+// FetchWshEvents requests and parses the WSH events matched wshEventData
+func (ic *IbClient) FetchWshEvents(ctx context.Context, wshEventData *WshEventData) ([]WshEvent, error) {
+	if !ic.IsConnected() {
+		return nil, NOT_CONNECTED
+	}
+
+	reqID := ic.GetReqID()
+	var dataJSON string
+	err := ic.request(ctx, reqID, func() { ic.ReqWshEventDataWith(reqID, wshEventData) }, func(msg interface{}) bool {
+		if m, ok := msg.(*wshEventDataMsg); ok {
+			dataJSON = m.dataJSON
+			return true
+		}
+		return false
+	})
+
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		ic.CancelWshEventData(reqID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseWshEvents(dataJSON)
+}
+
+// WshCalendarQuery is the query of WshCalendar, the zero values match any
+type WshCalendarQuery struct {
+	ConIDs     []int64
+	EventTypes []string
+	Start      time.Time
+	End        time.Time
+	Limit      int
+}
+
+// WshCalendar returns the WSH events of the contracts in [Start, End], sorted by Date.
+/*
+The servers supporting the filters are requested once with the filter of the contracts and the event types,
+the older ones are requested for each contract, and the events are filtered locally.
+The events without a valid Date are dropped if Start or End is set.
+*/
+func (ic *IbClient) WshCalendar(ctx context.Context, q WshCalendarQuery) ([]WshEvent, error) {
+	var events []WshEvent
+	if ic.serverVersion >= mMIN_SERVER_VER_WSH_EVENT_DATA_FILTERS {
+		wshEventData := NewWshEventData()
+		wshEventData.Filter = WshFilter{Watchlist: q.ConIDs, EventTypes: q.EventTypes, Limit: q.Limit}.String()
+		if ic.serverVersion >= mMIN_SERVER_VER_WSH_EVENT_DATA_FILTERS_DATE {
+			if !q.Start.IsZero() {
+				wshEventData.StartDate = q.Start.Format("20060102")
+			}
+			if !q.End.IsZero() {
+				wshEventData.EndDate = q.End.Format("20060102")
+			}
+			if q.Limit > 0 {
+				wshEventData.TotalLimit = int64(q.Limit)
+			}
+		}
+
+		var err error
+		if events, err = ic.FetchWshEvents(ctx, wshEventData); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, conID := range q.ConIDs {
+			wshEventData := NewWshEventData()
+			wshEventData.ConID = conID
+			es, err := ic.FetchWshEvents(ctx, wshEventData)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch the WSH events of %d: %w", conID, err)
+			}
+			events = append(events, es...)
+		}
+	}
+
+	return filterWshEvents(events, q), nil
+}
+
+func filterWshEvents(events []WshEvent, q WshCalendarQuery) []WshEvent {
+	types := make(map[string]bool, len(q.EventTypes))
+	for _, tag := range q.EventTypes {
+		types[tag] = true
+	}
+
+	filtered := make([]WshEvent, 0, len(events))
+	for _, e := range events {
+		if len(types) > 0 && !types[e.Type] {
+			continue
+		}
+		if !q.Start.IsZero() || !q.End.IsZero() {
+			t, err := e.Time(q.Start.Location())
+			if err != nil {
+				continue
+			}
+			day := t.Format("20060102")
+			if (!q.Start.IsZero() && day < q.Start.Format("20060102")) || (!q.End.IsZero() && day > q.End.Format("20060102")) {
+				continue
+			}
+		}
+		filtered = append(filtered, e)
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool { return wshSortKey(filtered[i]) < wshSortKey(filtered[j]) })
+	if q.Limit > 0 && len(filtered) > q.Limit {
+		filtered = filtered[:q.Limit]
+	}
+	return filtered
+}
+
+// wshSortKey is yyyymmdd of the Date
+func wshSortKey(e WshEvent) string {
+	return strings.Replace(e.Date, "-", "", -1)
+}
@@ -0,0 +1,122 @@
+package ibapi
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+const testWshMeta = `{"validated": true, "data": {"metadata": {
+	"event_types": [
+		{"tag": "wshe_ed", "name": "Earnings Date", "category": "Earnings"},
+		{"tag": "wshe_div", "name": "Dividend", "category": "Corporate Actions"}
+	],
+	"filters": [{"tag": "country", "name": "Country", "type": "string", "values": ["All", "US"]}]
+}}}`
+
+const testWshEvents = `{"data": [
+	{"event_type": "wshe_div", "conid": 265598, "symbol": "AAPL", "index_date": "20200807",
+		"data": {"ex_date": "20200807", "pay_date": "20200813", "amount": "0.82", "currency": "USD", "frequency": "Quarterly"}},
+	{"event_type": "wshe_ed", "conid": "265598", "symbol": "AAPL", "index_date": "2020-07-30",
+		"data": {"earnings_date": "20200730", "time_of_day": "AMC", "fiscal_period": "Q3", "fiscal_year": "2020", "status": "Confirmed"}},
+	{"event_type": "wshe_spl", "conid": 265598, "symbol": "AAPL", "index_date": "20200831",
+		"data": {"ex_date": "20200831", "ratio": "4:1"}}
+]}`
+
+func TestParseWsh(t *testing.T) {
+	meta, err := ParseWshMeta(testWshMeta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(meta.EventTypes) != 2 || meta.EventTypes[0].Tag != WSH_EARNINGS_DATE || len(meta.Filters) != 1 || meta.Filters[0].Values[1] != "US" {
+		t.Errorf("unexpected meta: %+v", meta)
+	}
+
+	events, err := ParseWshEvents(testWshEvents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 || events[1].ConID != 265598 || events[1].Symbol != "AAPL" {
+		t.Fatalf("unexpected events: %v", events)
+	}
+
+	div, err := events[0].Dividend()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if amount, _ := div.Amount.Float64(); amount != 0.82 || div.PayDate != "20200813" {
+		t.Errorf("unexpected dividend: %+v", div)
+	}
+	ed, err := events[1].Earnings()
+	if err != nil || ed.TimeOfDay != "AMC" || ed.FiscalPeriod != "Q3" {
+		t.Errorf("unexpected earnings: %+v, %v", ed, err)
+	}
+	if _, err := events[1].Split(); err == nil {
+		t.Error("decoding the payload of another type should fail")
+	}
+	if tm, err := events[1].Time(time.UTC); err != nil || !tm.Equal(time.Date(2020, 7, 30, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected event time %s, %v", tm, err)
+	}
+
+	single, err := ParseWshEvents(`{"event_type": "wshe_spl", "conid": 1, "index_date": "20200831", "data": {"ratio": "4:1"}}`)
+	if err != nil || len(single) != 1 {
+		t.Fatalf("unexpected single event: %v, %v", single, err)
+	}
+	if spl, err := single[0].Split(); err != nil || spl.Ratio != "4:1" {
+		t.Errorf("unexpected split: %+v, %v", spl, err)
+	}
+
+	if s := (WshFilter{Watchlist: []int64{8314}, Limit: 10, EventTypes: []string{WSH_EARNINGS_DATE}}).String(); s != `{"limit":10,"watchlist":["8314"],"wshe_ed":"true"}` {
+		t.Errorf("unexpected filter %s", s)
+	}
+}
+
+func TestReqWshEventData(t *testing.T) {
+	reqs := make(chan [][]byte, 10)
+	ic := newTestClient(func(ic *IbClient, req []byte) {
+		fields := splitMsgBytes(req[4:])
+		reqs <- fields
+		if fieldInt(fields[0]) != mREQ_WSH_EVENT_DATA {
+			return
+		}
+		ic.dispatcher.WshEventData(fieldInt(fields[1]), testWshEvents)
+	})
+	ic.serverVersion = mMIN_SERVER_VER_WSHE_CALENDAR
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	q := WshCalendarQuery{
+		ConIDs:     []int64{265598},
+		EventTypes: []string{WSH_EARNINGS_DATE, WSH_SPLIT},
+		Start:      time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC),
+		End:        time.Date(2020, 8, 31, 0, 0, 0, 0, time.UTC),
+	}
+	events, err := ic.WshCalendar(ctx, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Type != WSH_EARNINGS_DATE || events[1].Type != WSH_SPLIT {
+		t.Errorf("unexpected calendar: %v", events)
+	}
+
+	fields := <-reqs
+	if len(fields) != 3 || fieldInt(fields[2]) != 265598 {
+		t.Errorf("unexpected request fields of server %d: %q", ic.serverVersion, fields)
+	}
+
+	wshEventData := NewWshEventData()
+	wshEventData.Filter = WshFilter{Watchlist: []int64{265598}}.String()
+	if _, err := ic.FetchWshEvents(ctx, wshEventData); err == nil {
+		t.Error("the filter should be rejected by the old server")
+	}
+
+	ic.serverVersion = mMIN_SERVER_VER_WSH_EVENT_DATA_FILTERS_DATE
+	if _, err := ic.WshCalendar(ctx, q); err != nil {
+		t.Fatal(err)
+	}
+	fields = <-reqs
+	if len(fields) != 10 || decodeString(fields[2]) != "" || decodeString(fields[7]) != "20200701" || decodeString(fields[8]) != "20200831" || decodeString(fields[9]) != "" {
+		t.Errorf("unexpected request fields of server %d: %q", ic.serverVersion, fields)
+	}
+}